@@ -0,0 +1,145 @@
+package gameapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+type auditLogEntryResponse struct {
+	Action     string                 `json:"action"`
+	ActorCode  string                 `json:"actor_code,omitempty"`
+	ActorName  string                 `json:"actor_name,omitempty"`
+	TargetType string                 `json:"target_type"`
+	TargetCode string                 `json:"target_code,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+}
+
+type auditLogResponse struct {
+	Entries  []auditLogEntryResponse `json:"entries"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+}
+
+// GET /api/leagues/:code/audit?action=&actor=&target=&page=1&page_size=50 - League audit log (league admin)
+func (h *Handler) listAuditLog(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.AuditFilter{Action: services.AuditAction(query.Get("action"))}
+	if actor := query.Get("actor"); actor != "" {
+		actorIdAndCode, err := h.idCodeCache.GetByCode(actor)
+		if err != nil {
+			http.Error(w, "Invalid actor code", http.StatusBadRequest)
+			return
+		}
+		filter.ActorID = actorIdAndCode.ID
+	}
+	if target := query.Get("target"); target != "" {
+		targetIdAndCode, err := h.idCodeCache.GetByCode(target)
+		if err != nil {
+			http.Error(w, "Invalid target code", http.StatusBadRequest)
+			return
+		}
+		filter.TargetID = targetIdAndCode.ID
+	}
+
+	page := 1
+	if pageParam := query.Get("page"); pageParam != "" {
+		page, err = strconv.Atoi(pageParam)
+		if err != nil || page < 1 {
+			http.Error(w, "Invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+	pageSize := defaultAuditPageSize
+	if pageSizeParam := query.Get("page_size"); pageSizeParam != "" {
+		pageSize, err = strconv.Atoi(pageSizeParam)
+		if err != nil || pageSize < 1 || pageSize > maxAuditPageSize {
+			http.Error(w, "Invalid page size", http.StatusBadRequest)
+			return
+		}
+	}
+
+	auditPage, err := h.auditService.ListLeagueActions(r.Context(), leagueID, filter, int64((page-1)*pageSize), int64(pageSize))
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get audit log")
+		return
+	}
+
+	response := auditLogResponse{
+		Entries:  make([]auditLogEntryResponse, 0, len(auditPage.Entries)),
+		Total:    auditPage.Total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	actorNames := make(map[primitive.ObjectID]string)
+	for _, entry := range auditPage.Entries {
+		response.Entries = append(response.Entries, h.auditLogEntryToResponse(r.Context(), entry, actorNames))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// auditLogEntryToResponse converts an audit log entry, actorNames caches actor names within the request
+func (h *Handler) auditLogEntryToResponse(ctx context.Context, entry *models.AuditLog, actorNames map[primitive.ObjectID]string) auditLogEntryResponse {
+	resp := auditLogEntryResponse{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		Details:    entry.Details,
+		CreatedAt:  entry.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if !entry.ActorID.IsZero() {
+		resp.ActorCode = h.idCodeCache.GetByID(entry.ActorID).Code
+		name, ok := actorNames[entry.ActorID]
+		if !ok {
+			if user, err := h.userService.FindByID(ctx, entry.ActorID); err == nil && user != nil {
+				name = user.Name
+			}
+			actorNames[entry.ActorID] = name
+		}
+		resp.ActorName = name
+	}
+	if !entry.TargetID.IsZero() {
+		resp.TargetCode = h.idCodeCache.GetByID(entry.TargetID).Code
+	}
+
+	return resp
+}
+
+// logAction records a league action of the current user in the audit log in background
+func (h *Handler) logAction(r *http.Request, leagueID primitive.ObjectID, action services.AuditAction, targetType services.AuditTargetType, targetID primitive.ObjectID, details services.AuditDetails) {
+	var actorID primitive.ObjectID
+	if profile, err := user_profile.GetUserProfile(r); err == nil && profile != nil {
+		if userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code); err == nil {
+			actorID = userIdAndCode.ID
+		}
+	}
+
+	go func() {
+		ctx := context.Background()
+		if err := h.auditService.LogAction(ctx, leagueID, actorID, action, targetType, targetID, details); err != nil {
+			glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+		}
+	}()
+}
@@ -0,0 +1,222 @@
+package gameapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// calendarRefreshInterval is suggested to subscribed calendar apps
+const calendarRefreshInterval = time.Hour
+
+type calendarFeedResponse struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	WebcalURL string `json:"webcal_url"` // Opens the subscription dialog of the calendar app
+}
+
+// GET /api/leagues/:code/calendar - Personal calendar feed URL of the current member
+func (h *Handler) getCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	h.writeCalendarFeed(w, r, false)
+}
+
+// POST /api/leagues/:code/calendar/reset - Replace the calendar feed token, the old URL stops working
+func (h *Handler) resetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	h.writeCalendarFeed(w, r, true)
+}
+
+func (h *Handler) writeCalendarFeed(w http.ResponseWriter, r *http.Request, reset bool) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var token string
+	if reset {
+		token, err = h.gameNightService.ResetCalendarToken(r.Context(), leagueID, userID)
+	} else {
+		token, err = h.gameNightService.GetCalendarToken(r.Context(), leagueID, userID)
+	}
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to get calendar feed")
+		return
+	}
+
+	url := fmt.Sprintf("%s/api/leagues/%s/calendar.ics?token=%s", utils.GetHostUrl(r), utils.IdToCode(leagueID), token)
+	webcalURL := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	utils.WriteJSON(r, w, calendarFeedResponse{Token: token, URL: url, WebcalURL: webcalURL}, http.StatusOK)
+}
+
+// GET /api/leagues/:code/calendar.ics?token=...&lang=uk - Calendar with upcoming game nights and played rounds (public, token auth)
+func (h *Handler) getLeagueCalendar(w http.ResponseWriter, r *http.Request) {
+	leagueIdAndCode, err := h.idCodeCache.GetByCode(chi.URLParam(r, "code"))
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+	leagueID := leagueIdAndCode.ID
+
+	if _, err := h.gameNightService.GetCalendarMembership(r.Context(), leagueID, r.URL.Query().Get("token")); err != nil {
+		http.Error(w, "Calendar not found", http.StatusNotFound)
+		return
+	}
+
+	league, err := h.leagueService.GetLeague(r.Context(), leagueID)
+	if err != nil {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	nights, err := h.gameNightService.ListNights(r.Context(), leagueID, true)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list game nights")
+		return
+	}
+
+	rounds, err := h.gameRoundRepository.FindByLeague(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game rounds")
+		return
+	}
+
+	aliases, err := h.memberAliases(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get league members")
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	calendar := utils.ICalendar{
+		Name:            league.Name,
+		RefreshInterval: calendarRefreshInterval,
+		Events:          make([]utils.ICalEvent, 0, len(nights)+len(rounds)),
+	}
+	for _, night := range nights {
+		calendar.Events = append(calendar.Events, h.gameNightEvent(night, aliases))
+	}
+
+	gameTypeNames := make(map[primitive.ObjectID]string)
+	for _, round := range rounds {
+		if round.StartTime.IsZero() {
+			continue
+		}
+		gameTypeName, ok := gameTypeNames[round.GameTypeID]
+		if !ok && !round.GameTypeID.IsZero() {
+			gameType, err := h.gameTypeRepository.FindByID(r.Context(), round.GameTypeID)
+			if err != nil {
+				utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+				return
+			}
+			if gameType != nil {
+				gameTypeName = gameType.GetName(lang)
+			}
+			gameTypeNames[round.GameTypeID] = gameTypeName
+		}
+		calendar.Events = append(calendar.Events, h.gameRoundEvent(round, gameTypeName, aliases))
+	}
+
+	// The feed is built in memory, so that a failure still gets a proper error response
+	var feed bytes.Buffer
+	if err := utils.WriteICalendar(&feed, calendar); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to build calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="league-%s.ics"`, utils.IdToCode(leagueID)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(feed.Bytes())
+}
+
+func (h *Handler) gameNightEvent(night *models.GameNight, aliases map[primitive.ObjectID]string) utils.ICalEvent {
+	status := utils.ICalStatusConfirmed
+	if night.Status == models.GameNightCancelled {
+		status = utils.ICalStatusCancelled
+	}
+
+	var description []string
+	if night.Notes != "" {
+		description = append(description, night.Notes)
+	}
+	for _, response := range []models.RSVPResponse{models.RSVPYes, models.RSVPMaybe, models.RSVPNo} {
+		var names []string
+		for _, rsvp := range night.RSVPs {
+			if rsvp.Response == response {
+				names = append(names, aliases[rsvp.MembershipID])
+			}
+		}
+		if len(names) > 0 {
+			description = append(description, fmt.Sprintf("%s: %s", rsvpLabels[response], strings.Join(names, ", ")))
+		}
+	}
+
+	return utils.ICalEvent{
+		UID:         fmt.Sprintf("night-%s@bgl", h.idCodeCache.GetByID(night.ID).Code),
+		Summary:     night.Title,
+		Description: strings.Join(description, "\n"),
+		Location:    night.Location,
+		Status:      status,
+		Start:       night.StartTime,
+		End:         night.EndTime,
+		Sequence:    night.Version,
+		Updated:     night.UpdatedAt,
+	}
+}
+
+var rsvpLabels = map[models.RSVPResponse]string{
+	models.RSVPYes:   "Going",
+	models.RSVPMaybe: "Maybe",
+	models.RSVPNo:    "Not going",
+}
+
+func (h *Handler) gameRoundEvent(round *models.GameRound, gameTypeName string, aliases map[primitive.ObjectID]string) utils.ICalEvent {
+	summary := round.Name
+	if gameTypeName != "" && gameTypeName != round.Name {
+		summary = fmt.Sprintf("%s: %s", gameTypeName, round.Name)
+	}
+
+	lines := make([]string, 0, len(round.Players))
+	for _, player := range round.Players {
+		line := aliases[player.MembershipID]
+		if player.Position > 0 {
+			line = fmt.Sprintf("%d. %s", player.Position, line)
+		}
+		if round.Status == models.StatusCompleted {
+			line = fmt.Sprintf("%s (%d)", line, player.Score)
+		}
+		lines = append(lines, line)
+	}
+
+	status := utils.ICalStatusConfirmed
+	if round.Status != models.StatusCompleted {
+		status = utils.ICalStatusTentative
+	}
+
+	return utils.ICalEvent{
+		UID:         fmt.Sprintf("round-%s@bgl", h.idCodeCache.GetByID(round.ID).Code),
+		Summary:     summary,
+		Description: strings.Join(lines, "\n"),
+		Status:      status,
+		Start:       round.StartTime,
+		End:         round.EndTime,
+		Sequence:    round.Version,
+		Updated:     round.UpdatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package gameapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type calendarLeagueService struct {
+	membersLeagueService
+	league *models.League
+}
+
+func (s *calendarLeagueService) GetLeague(ctx context.Context, leagueID primitive.ObjectID) (*models.League, error) {
+	return s.league, nil
+}
+
+func TestLeagueCalendar(t *testing.T) {
+	league := &models.League{ID: primitive.NewObjectID(), Name: "Friday League"}
+	olena := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, Status: models.MembershipActive, CalendarToken: "secret"}
+	petroID := primitive.NewObjectID()
+	gameType := &models.GameType{ID: primitive.NewObjectID(), Key: "catan", Names: map[string]string{"en": "Catan", "uk": "Колонізатори"}}
+
+	night := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: league.ID, Version: 2, Title: "Catan night", Location: "Club",
+		Status:    models.GameNightScheduled,
+		StartTime: time.Date(2026, 11, 6, 17, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 11, 6, 21, 0, 0, 0, time.UTC),
+		RSVPs: []models.GameNightRSVP{{MembershipID: olena.ID, Response: models.RSVPYes}, {MembershipID: petroID, Response: models.RSVPMaybe}}}
+	round := &models.GameRound{ID: primitive.NewObjectID(), Name: "Round 1", GameTypeID: gameType.ID, Status: models.StatusCompleted,
+		StartTime: time.Date(2026, 10, 2, 17, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 10, 2, 18, 30, 0, 0, time.UTC),
+		Players: []models.GameRoundPlayer{{MembershipID: petroID, Position: 1, Score: 10}, {MembershipID: olena.ID, Position: 2, Score: 8}}}
+
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockMembershipRepo.On("FindByCalendarToken", mock.Anything, "secret").Return(olena, nil)
+	mockMembershipRepo.On("FindByCalendarToken", mock.Anything, mock.Anything).Return(nil, nil)
+	mockNightRepo.On("FindByLeague", mock.Anything, league.ID, mock.AnythingOfType("time.Time")).Return([]*models.GameNight{night}, nil)
+	mockGameRoundRepo.On("FindByLeague", mock.Anything, league.ID).Return([]*models.GameRound{round}, nil)
+	mockGameTypeRepo.On("FindByID", mock.Anything, gameType.ID).Return(gameType, nil)
+
+	handler := &Handler{
+		gameRoundRepository: mockGameRoundRepo,
+		gameTypeRepository:  mockGameTypeRepo,
+		gameNightService:    services.NewGameNightService(mockNightRepo, mockMembershipRepo, mockGameRoundRepo, mockGameTypeRepo, services.NewNoopAuditService()),
+		idCodeCache:         services.NewIdAndCodeCache(),
+		leagueService: &calendarLeagueService{
+			league: league,
+			membersLeagueService: membersLeagueService{members: []*services.LeagueMemberInfo{
+				{MembershipID: olena.ID, UserAlias: "Olena"},
+				{MembershipID: petroID, UserAlias: "Petro"},
+			}},
+		},
+	}
+
+	router := chi.NewRouter()
+	var authHit bool
+	router.Route("/api", func(r chi.Router) {
+		handler.RegisterPublicRoutes(r)
+		r.Group(func(r chi.Router) {
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					authHit = true
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				})
+			})
+			handler.RegisterRoutes(r, nil)
+		})
+	})
+	feedURL := "/api/leagues/" + utils.IdToCode(league.ID) + "/calendar.ics"
+
+	t.Run("Feed with nights and rounds", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", feedURL+"?token=secret&lang=uk", nil))
+
+		assert.False(t, authHit)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+		feed := rr.Body.String()
+		assert.Contains(t, feed, "X-WR-CALNAME:Friday League\r\n")
+		assert.Contains(t, feed, "UID:night-"+utils.IdToCode(night.ID)+"@bgl\r\n")
+		assert.Contains(t, feed, "DTSTART:20261106T170000Z\r\n")
+		assert.Contains(t, feed, "LOCATION:Club\r\n")
+		assert.Contains(t, feed, "DESCRIPTION:Going: Olena\\nMaybe: Petro\r\n")
+		assert.Contains(t, feed, "SEQUENCE:2\r\n")
+		assert.Contains(t, feed, "UID:round-"+utils.IdToCode(round.ID)+"@bgl\r\n")
+		assert.Contains(t, feed, "SUMMARY:Колонізатори: Round 1\r\n")
+		assert.Contains(t, feed, "DESCRIPTION:1. Petro (10)\\n2. Olena (8)\r\n")
+		assert.Contains(t, feed, "DTEND:20261002T183000Z\r\n")
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", feedURL+"?token=guess", nil))
+
+		assert.False(t, authHit)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
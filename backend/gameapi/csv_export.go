@@ -0,0 +1,203 @@
+package gameapi
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var gameRoundsCSVHeader = []string{
+	"round_code", "round_name", "game_type", "status", "start_time", "end_time",
+	"membership_code", "alias", "position", "score", "team", "role", "is_moderator",
+}
+
+var standingsCSVHeader = []string{
+	"rank", "membership_code", "user_code", "user_name", "user_alias", "user_avatar", "is_pending",
+	"total_points", "games_played", "games_moderated", "first_place_count", "second_place_count", "third_place_count",
+	"participation_points", "position_points", "moderation_points",
+	"team_wins", "team_draws", "team_losses", "coop_wins", "coop_losses",
+}
+
+// GET /api/leagues/:code/game_rounds/export.csv?status=...&active=true&from=...&to=... - One row per player per round
+func (h *Handler) exportGameRoundsCSV(w http.ResponseWriter, r *http.Request) {
+	leagueID, ok := r.Context().Value("leagueID").(primitive.ObjectID)
+	if !ok {
+		http.Error(w, "League not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseGameRoundFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rounds, err := h.findGameRounds(r.Context(), leagueID, filter)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game rounds")
+		return
+	}
+
+	aliases, err := h.memberAliases(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get league members")
+		return
+	}
+
+	gameTypeKeys := make(map[primitive.ObjectID]string)
+	rows := [][]string{gameRoundsCSVHeader}
+	for _, round := range rounds {
+		gameTypeKey, ok := gameTypeKeys[round.GameTypeID]
+		if !ok && !round.GameTypeID.IsZero() {
+			gameType, err := h.gameTypeRepository.FindByID(r.Context(), round.GameTypeID)
+			if err != nil {
+				utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+				return
+			}
+			if gameType != nil {
+				gameTypeKey = gameType.Key
+			}
+			gameTypeKeys[round.GameTypeID] = gameTypeKey
+		}
+
+		roundCode := h.idCodeCache.GetByID(round.ID).Code
+		for _, player := range round.Players {
+			membershipCode := ""
+			if !player.MembershipID.IsZero() {
+				membershipCode = h.idCodeCache.GetByID(player.MembershipID).Code
+			}
+			rows = append(rows, []string{
+				roundCode,
+				csvText(round.Name),
+				gameTypeKey,
+				string(round.Status),
+				formatCSVTime(round.StartTime),
+				formatCSVTime(round.EndTime),
+				membershipCode,
+				csvText(aliases[player.MembershipID]),
+				optionalInt(int64(player.Position)),
+				strconv.FormatInt(player.Score, 10),
+				csvText(player.TeamName),
+				csvText(player.LabelName),
+				strconv.FormatBool(player.IsModerator),
+			})
+		}
+	}
+
+	writeCSV(w, r, fmt.Sprintf("game-rounds-%s.csv", utils.IdToCode(leagueID)), rows)
+}
+
+// GET /api/leagues/:code/standings/export.csv?season=...&game_type=...&status=...&from=...&to=... - League standings as CSV
+func (h *Handler) exportStandingsCSV(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := h.parseStandingsFilter(w, r, leagueID)
+	if !ok {
+		return
+	}
+	roundFilter, err := parseGameRoundFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if roundFilter.ActiveOnly {
+		http.Error(w, "Standings count finished rounds only", http.StatusBadRequest)
+		return
+	}
+	// Dates narrow the season, if both are given
+	filter.Status = roundFilter.Status
+	if roundFilter.From.After(filter.From) {
+		filter.From = roundFilter.From
+	}
+	if !roundFilter.To.IsZero() && (filter.To.IsZero() || roundFilter.To.Before(filter.To)) {
+		filter.To = roundFilter.To
+	}
+
+	standings, err := h.leagueService.GetLeagueStandings(r.Context(), leagueID, filter)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get standings")
+		return
+	}
+
+	rows := [][]string{standingsCSVHeader}
+	for _, standing := range standings {
+		userCode := ""
+		if !standing.UserID.IsZero() {
+			userCode = h.idCodeCache.GetByID(standing.UserID).Code
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(standing.Rank),
+			h.idCodeCache.GetByID(standing.MembershipID).Code,
+			userCode,
+			csvText(standing.UserName),
+			csvText(standing.UserAlias),
+			csvText(standing.UserAvatar),
+			strconv.FormatBool(standing.IsPending),
+			strconv.FormatInt(standing.TotalPoints, 10),
+			strconv.Itoa(standing.GamesPlayed),
+			strconv.Itoa(standing.GamesModerated),
+			strconv.Itoa(standing.FirstPlaceCount),
+			strconv.Itoa(standing.SecondPlaceCount),
+			strconv.Itoa(standing.ThirdPlaceCount),
+			strconv.FormatInt(standing.ParticipationPoints, 10),
+			strconv.FormatInt(standing.PositionPoints, 10),
+			strconv.FormatInt(standing.ModerationPoints, 10),
+			strconv.Itoa(standing.TeamWins),
+			strconv.Itoa(standing.TeamDraws),
+			strconv.Itoa(standing.TeamLosses),
+			strconv.Itoa(standing.CoopWins),
+			strconv.Itoa(standing.CoopLosses),
+		})
+	}
+
+	writeCSV(w, r, fmt.Sprintf("standings-%s.csv", utils.IdToCode(leagueID)), rows)
+}
+
+func writeCSV(w http.ResponseWriter, r *http.Request, filename string, rows [][]string) {
+	// The file is built in memory, so that a failure still gets a proper error response
+	var file bytes.Buffer
+	if err := csv.NewWriter(&file).WriteAll(rows); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to write %s", filename)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(file.Bytes())
+}
+
+// csvText escapes text entered by users, which spreadsheets would otherwise run as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatCSVTime leaves the cell empty for a zero time, e.g. the end of an unfinished round
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// optionalInt leaves the cell empty for zero, e.g. a player without position
+func optionalInt(value int64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
@@ -0,0 +1,141 @@
+package gameapi
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type membersLeagueService struct {
+	stubLeagueService
+	members []*services.LeagueMemberInfo
+}
+
+func (s *membersLeagueService) GetLeagueMemberships(ctx context.Context, leagueID primitive.ObjectID) ([]*services.LeagueMemberInfo, error) {
+	return s.members, nil
+}
+
+func TestExportGameRoundsCSV(t *testing.T) {
+	leagueID := primitive.NewObjectID()
+	olenaID, petroID := primitive.NewObjectID(), primitive.NewObjectID()
+	gameType := &models.GameType{ID: primitive.NewObjectID(), Key: "mafia"}
+	start := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+
+	oldRound := &models.GameRound{ID: primitive.NewObjectID(), Name: "Old", GameTypeID: gameType.ID, Status: models.StatusCompleted,
+		StartTime: start.AddDate(0, -1, 0), EndTime: start.AddDate(0, -1, 0).Add(time.Hour),
+		Players: []models.GameRoundPlayer{{MembershipID: olenaID, Position: 1}}}
+	round := &models.GameRound{ID: primitive.NewObjectID(), Name: "Friday", GameTypeID: gameType.ID, Status: models.StatusInProgress, StartTime: start,
+		Players: []models.GameRoundPlayer{
+			{MembershipID: olenaID, TeamName: "Town", LabelName: "sheriff", Score: 3},
+			{MembershipID: petroID, IsModerator: true},
+		}}
+
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockGameRoundRepo.On("FindByLeague", mock.Anything, leagueID).Return([]*models.GameRound{oldRound, round}, nil)
+	mockGameTypeRepo.On("FindByID", mock.Anything, gameType.ID).Return(gameType, nil)
+
+	handler := &Handler{
+		gameRoundRepository: mockGameRoundRepo,
+		gameTypeRepository:  mockGameTypeRepo,
+		idCodeCache:         services.NewIdAndCodeCache(),
+		leagueService: &membersLeagueService{members: []*services.LeagueMemberInfo{
+			{MembershipID: olenaID, UserAlias: "Olena"},
+			{MembershipID: petroID, UserAlias: "Petro"},
+		}},
+	}
+	router := chi.NewRouter()
+	router.Use(leagueIDMiddleware(leagueID))
+	router.Get("/export.csv", handler.exportGameRoundsCSV)
+
+	t.Run("One row per player", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/export.csv?from=2026-03-01", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		rows, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{
+			gameRoundsCSVHeader,
+			{utils.IdToCode(round.ID), "Friday", "mafia", "in_progress", "2026-03-06T19:00:00Z", "", utils.IdToCode(olenaID), "Olena", "", "3", "Town", "sheriff", "false"},
+			{utils.IdToCode(round.ID), "Friday", "mafia", "in_progress", "2026-03-06T19:00:00Z", "", utils.IdToCode(petroID), "Petro", "", "0", "", "", "true"},
+		}, rows)
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/export.csv?to=yesterday", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+type standingsLeagueService struct {
+	stubLeagueService
+	standings []*services.LeagueStanding
+}
+
+func (s *standingsLeagueService) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID, filter services.StandingsFilter) ([]*services.LeagueStanding, error) {
+	return s.standings, nil
+}
+
+func TestExportStandingsCSV(t *testing.T) {
+	leagueID := primitive.NewObjectID()
+	olena := &services.LeagueStanding{Rank: 1, MembershipID: primitive.NewObjectID(), UserName: "Olena", UserAlias: "Olena", TotalPoints: 10, GamesPlayed: 2}
+	petro := &services.LeagueStanding{Rank: 1, MembershipID: primitive.NewObjectID(), UserName: "Petro", UserAlias: "=HYPERLINK(\"http://evil\")", TotalPoints: 10, GamesPlayed: 2}
+	anna := &services.LeagueStanding{Rank: 3, MembershipID: primitive.NewObjectID(), UserName: "@Anna", UserAlias: "Anna", TotalPoints: 4, GamesPlayed: 1, IsPending: true}
+
+	handler := &Handler{
+		idCodeCache:   services.NewIdAndCodeCache(),
+		leagueService: &standingsLeagueService{standings: []*services.LeagueStanding{olena, petro, anna}},
+	}
+	router := chi.NewRouter()
+	router.Get("/leagues/{code}/standings/export.csv", handler.exportStandingsCSV)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/leagues/"+utils.IdToCode(leagueID)+"/standings/export.csv", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rows, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 4) {
+		assert.Equal(t, standingsCSVHeader, rows[0])
+		assert.Equal(t, []string{"1", utils.IdToCode(olena.MembershipID), "", "Olena", "Olena", "", "false", "10", "2"}, rows[1][:9])
+		assert.Equal(t, []string{"1", utils.IdToCode(petro.MembershipID), "", "Petro", "'=HYPERLINK(\"http://evil\")"}, rows[2][:5], "Tied members share the place")
+		assert.Equal(t, []string{"3", utils.IdToCode(anna.MembershipID), "", "'@Anna", "Anna", "", "true"}, rows[3][:7])
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"Olena", "Olena"},
+		{"=1+2", "'=1+2"},
+		{"+380501234567", "'+380501234567"},
+		{"-2", "'-2"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, csvText(tt.value), "csvText(%q)", tt.value)
+	}
+}
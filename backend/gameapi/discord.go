@@ -0,0 +1,141 @@
+package gameapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+)
+
+type discordSettingsRequest struct {
+	WebhookURL string `json:"webhook_url"` // Empty - disconnect the league from Discord
+}
+
+type discordSettingsResponse struct {
+	Connected bool   `json:"connected"`
+	Language  string `json:"language"` // Language of the posted results
+}
+
+type updateLeagueLanguageRequest struct {
+	Language string `json:"language"`
+}
+
+// GET /api/leagues/:code/discord - Whether the league posts game results to Discord (league admin)
+func (h *Handler) getDiscordSettings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.leagueService.GetLeague(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "league not found")
+		return
+	}
+
+	utils.WriteJSON(r, w, discordSettingsToResponse(league), http.StatusOK)
+}
+
+// PUT /api/leagues/:code/discord - Connect the league to a Discord channel webhook or disconnect it (league admin)
+func (h *Handler) updateDiscordSettings(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req discordSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.discordNotifier.SetWebhookURL(r.Context(), leagueID, req.WebhookURL, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update Discord settings")
+		return
+	}
+
+	utils.WriteJSON(r, w, discordSettingsToResponse(league), http.StatusOK)
+}
+
+// POST /api/leagues/:code/discord/test - Post a test message to the league Discord channel (league admin)
+func (h *Handler) sendDiscordTestMessage(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.discordNotifier.SendTestMessage(r.Context(), leagueID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadGateway, err, "failed to post to Discord")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /api/leagues/:code/language - Change the language of league messages posted outside of the app (league admin)
+func (h *Handler) updateLeagueLanguage(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req updateLeagueLanguageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.leagueService.SetLeagueLanguage(r.Context(), leagueID, req.Language, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update league language")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.leagueToResponse(league), http.StatusOK)
+}
+
+func discordSettingsToResponse(league *models.League) discordSettingsResponse {
+	return discordSettingsResponse{
+		Connected: league.DiscordWebhookURL != "",
+		Language:  league.GetLanguage(),
+	}
+}
+
+// postToChatsInBackground posts the finalized round to the league chats without delaying the response, failures are only logged
+func (h *Handler) postToChatsInBackground(round *models.GameRound) {
+	var notifiers []services.ChatNotifier
+	if h.discordNotifier != nil {
+		notifiers = append(notifiers, h.discordNotifier)
+	}
+	if h.telegramNotifier != nil {
+		notifiers = append(notifiers, h.telegramNotifier)
+	}
+	if len(notifiers) == 0 {
+		return
+	}
+
+	go func() {
+		if err := services.ChatNotifiers(notifiers).NotifyGameFinalized(context.Background(), round); err != nil {
+			glog.Warn("Failed to post finalized game %s to chats: %v", round.ID.Hex(), err)
+		}
+	}()
+}
@@ -0,0 +1,395 @@
+package gameapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// gameNightRequest times are RFC 3339 or local times without offset ("2026-01-16T19:00") in the timezone
+type gameNightRequest struct {
+	Title     string `json:"title"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Timezone  string `json:"timezone"`
+	Location  string `json:"location"`
+	Notes     string `json:"notes"`
+}
+
+type rsvpRequest struct {
+	Response string `json:"response"` // yes, no or maybe
+}
+
+type gameNightRSVPResponse struct {
+	MembershipCode string `json:"membership_code"`
+	Alias          string `json:"alias"`
+	Response       string `json:"response"`
+	RespondedAt    string `json:"responded_at"`
+}
+
+type gameNightResponse struct {
+	Code      string                  `json:"code"`
+	Title     string                  `json:"title"`
+	StartTime string                  `json:"start_time"`
+	EndTime   string                  `json:"end_time"`
+	Timezone  string                  `json:"timezone"`
+	LocalTime string                  `json:"local_time"` // Start time in the night timezone
+	Location  string                  `json:"location,omitempty"`
+	Notes     string                  `json:"notes,omitempty"`
+	Status    string                  `json:"status"`
+	RSVPs     []gameNightRSVPResponse `json:"rsvps"`
+	CreatedAt string                  `json:"created_at"`
+}
+
+type gameNightPlayerResponse struct {
+	MembershipCode string `json:"membership_code"`
+	Alias          string `json:"alias"`
+	Wins           int    `json:"wins,omitempty"`
+}
+
+type gameNightRoundResponse struct {
+	Code         string                    `json:"code"`
+	Name         string                    `json:"name"`
+	GameType     string                    `json:"game_type,omitempty"` // Game type key
+	GameTypeName string                    `json:"game_type_name,omitempty"`
+	Status       string                    `json:"status"`
+	StartTime    string                    `json:"start_time"`
+	EndTime      string                    `json:"end_time,omitempty"`
+	Winners      []gameNightPlayerResponse `json:"winners"`
+}
+
+type gameNightSummaryResponse struct {
+	Night   gameNightResponse         `json:"night"`
+	Rounds  []gameNightRoundResponse  `json:"rounds"`
+	Winners []gameNightPlayerResponse `json:"winners"` // Members who won rounds, the most wins first
+}
+
+// GET /api/leagues/:code/nights?upcoming=true - List league game nights, the earliest first
+func (h *Handler) listGameNights(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	nights, err := h.gameNightService.ListNights(r.Context(), leagueID, r.URL.Query().Get("upcoming") == "true")
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list game nights")
+		return
+	}
+
+	aliases, err := h.memberAliases(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get league members")
+		return
+	}
+
+	response := make([]gameNightResponse, 0, len(nights))
+	for _, night := range nights {
+		response = append(response, h.gameNightToResponse(night, aliases))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/nights - Schedule game night (league admin)
+func (h *Handler) scheduleGameNight(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	input, ok := parseGameNightRequest(w, r)
+	if !ok {
+		return
+	}
+
+	night, err := h.gameNightService.ScheduleNight(r.Context(), leagueID, actorID, input)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to schedule game night")
+		return
+	}
+
+	h.writeGameNight(w, r, leagueID, night, http.StatusCreated)
+}
+
+// GET /api/leagues/:code/nights/:nightCode - Get game night with RSVPs
+func (h *Handler) getGameNight(w http.ResponseWriter, r *http.Request) {
+	leagueID, nightID, ok := h.parseGameNightURL(w, r)
+	if !ok {
+		return
+	}
+
+	night, err := h.gameNightService.GetNight(r.Context(), leagueID, nightID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "game night not found")
+		return
+	}
+
+	h.writeGameNight(w, r, leagueID, night, http.StatusOK)
+}
+
+// PUT /api/leagues/:code/nights/:nightCode - Reschedule game night (league admin)
+func (h *Handler) updateGameNight(w http.ResponseWriter, r *http.Request) {
+	leagueID, nightID, ok := h.parseGameNightURL(w, r)
+	if !ok {
+		return
+	}
+
+	input, ok := parseGameNightRequest(w, r)
+	if !ok {
+		return
+	}
+
+	night, err := h.gameNightService.UpdateNight(r.Context(), leagueID, nightID, input)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update game night")
+		return
+	}
+
+	h.writeGameNight(w, r, leagueID, night, http.StatusOK)
+}
+
+// POST /api/leagues/:code/nights/:nightCode/cancel - Cancel game night (league admin)
+func (h *Handler) cancelGameNight(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, nightID, ok := h.parseGameNightURL(w, r)
+	if !ok {
+		return
+	}
+
+	night, err := h.gameNightService.CancelNight(r.Context(), leagueID, nightID, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to cancel game night")
+		return
+	}
+
+	h.writeGameNight(w, r, leagueID, night, http.StatusOK)
+}
+
+// PUT /api/leagues/:code/nights/:nightCode/rsvp - Answer yes, no or maybe for the current member
+func (h *Handler) respondToGameNight(w http.ResponseWriter, r *http.Request) {
+	membership, ok := r.Context().Value("membership").(*models.LeagueMembership)
+	if !ok || membership == nil {
+		http.Error(w, "Only league members can respond", http.StatusForbidden)
+		return
+	}
+
+	leagueID, nightID, ok := h.parseGameNightURL(w, r)
+	if !ok {
+		return
+	}
+
+	var req rsvpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	night, err := h.gameNightService.RespondToNight(r.Context(), leagueID, nightID, membership.ID, models.RSVPResponse(req.Response))
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to respond to game night")
+		return
+	}
+
+	h.writeGameNight(w, r, leagueID, night, http.StatusOK)
+}
+
+// GET /api/leagues/:code/nights/:nightCode/summary?lang=uk - Rounds played on the night and their winners
+func (h *Handler) getGameNightSummary(w http.ResponseWriter, r *http.Request) {
+	leagueID, nightID, ok := h.parseGameNightURL(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := h.gameNightService.GetNightSummary(r.Context(), leagueID, nightID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "game night not found")
+		return
+	}
+
+	aliases, err := h.memberAliases(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get league members")
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = "en"
+	}
+
+	response := gameNightSummaryResponse{
+		Night:   h.gameNightToResponse(summary.Night, aliases),
+		Rounds:  make([]gameNightRoundResponse, 0, len(summary.Rounds)),
+		Winners: make([]gameNightPlayerResponse, 0, len(summary.Wins)),
+	}
+	for _, played := range summary.Rounds {
+		round := gameNightRoundResponse{
+			Code:      h.idCodeCache.GetByID(played.Round.ID).Code,
+			Name:      played.Round.Name,
+			Status:    string(played.Round.Status),
+			StartTime: played.Round.StartTime.Format("2006-01-02T15:04:05Z07:00"),
+			Winners:   make([]gameNightPlayerResponse, 0, len(played.Winners)),
+		}
+		if !played.Round.EndTime.IsZero() {
+			round.EndTime = played.Round.EndTime.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if played.GameType != nil {
+			round.GameType = played.GameType.Key
+			round.GameTypeName = played.GameType.GetName(lang)
+		}
+		for _, membershipID := range played.Winners {
+			round.Winners = append(round.Winners, gameNightPlayerResponse{
+				MembershipCode: h.idCodeCache.GetByID(membershipID).Code,
+				Alias:          aliases[membershipID],
+			})
+		}
+		response.Rounds = append(response.Rounds, round)
+	}
+	for membershipID, wins := range summary.Wins {
+		response.Winners = append(response.Winners, gameNightPlayerResponse{
+			MembershipCode: h.idCodeCache.GetByID(membershipID).Code,
+			Alias:          aliases[membershipID],
+			Wins:           wins,
+		})
+	}
+	sort.Slice(response.Winners, func(i, j int) bool {
+		if response.Winners[i].Wins != response.Winners[j].Wins {
+			return response.Winners[i].Wins > response.Winners[j].Wins
+		}
+		return response.Winners[i].Alias < response.Winners[j].Alias
+	})
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+func parseGameNightRequest(w http.ResponseWriter, r *http.Request) (services.GameNightInput, bool) {
+	var req gameNightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return services.GameNightInput{}, false
+	}
+
+	startTime, err := services.ParseNightTime(req.StartTime, req.Timezone)
+	if err != nil {
+		http.Error(w, "Invalid start_time: "+err.Error(), http.StatusBadRequest)
+		return services.GameNightInput{}, false
+	}
+	endTime, err := services.ParseNightTime(req.EndTime, req.Timezone)
+	if err != nil {
+		http.Error(w, "Invalid end_time: "+err.Error(), http.StatusBadRequest)
+		return services.GameNightInput{}, false
+	}
+
+	return services.GameNightInput{
+		Title:     req.Title,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Timezone:  req.Timezone,
+		Location:  req.Location,
+		Notes:     req.Notes,
+	}, true
+}
+
+func (h *Handler) parseGameNightURL(w http.ResponseWriter, r *http.Request) (leagueID, nightID primitive.ObjectID, ok bool) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return leagueID, nightID, false
+	}
+
+	nightID, err = h.getIDFromChiURL(r, "nightCode")
+	if err != nil {
+		http.Error(w, "Invalid game night code", http.StatusBadRequest)
+		return leagueID, nightID, false
+	}
+
+	return leagueID, nightID, true
+}
+
+// currentUserID resolves the authenticated user, writes the error response if it can't
+func (h *Handler) currentUserID(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return primitive.NilObjectID, false
+	}
+
+	userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return primitive.NilObjectID, false
+	}
+
+	return userIdAndCode.ID, true
+}
+
+// memberAliases maps league memberships to their aliases
+func (h *Handler) memberAliases(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	members, err := h.leagueService.GetLeagueMemberships(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make(map[primitive.ObjectID]string, len(members))
+	for _, member := range members {
+		aliases[member.MembershipID] = member.UserAlias
+	}
+	return aliases, nil
+}
+
+func (h *Handler) writeGameNight(w http.ResponseWriter, r *http.Request, leagueID primitive.ObjectID, night *models.GameNight, status int) {
+	aliases, err := h.memberAliases(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get league members")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.gameNightToResponse(night, aliases), status)
+}
+
+func (h *Handler) gameNightToResponse(night *models.GameNight, aliases map[primitive.ObjectID]string) gameNightResponse {
+	resp := gameNightResponse{
+		Code:      h.idCodeCache.GetByID(night.ID).Code,
+		Title:     night.Title,
+		StartTime: night.StartTime.Format("2006-01-02T15:04:05Z07:00"),
+		EndTime:   night.EndTime.Format("2006-01-02T15:04:05Z07:00"),
+		Timezone:  night.Timezone,
+		LocalTime: night.StartTime.Format("2006-01-02T15:04:05Z07:00"),
+		Location:  night.Location,
+		Notes:     night.Notes,
+		Status:    string(night.Status),
+		RSVPs:     make([]gameNightRSVPResponse, 0, len(night.RSVPs)),
+		CreatedAt: night.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if location, err := time.LoadLocation(night.Timezone); err == nil {
+		resp.LocalTime = night.StartTime.In(location).Format("2006-01-02T15:04:05Z07:00")
+	}
+	for _, rsvp := range night.RSVPs {
+		resp.RSVPs = append(resp.RSVPs, gameNightRSVPResponse{
+			MembershipCode: h.idCodeCache.GetByID(rsvp.MembershipID).Code,
+			Alias:          aliases[rsvp.MembershipID],
+			Response:       string(rsvp.Response),
+			RespondedAt:    rsvp.RespondedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return resp
+}
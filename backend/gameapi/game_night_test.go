@@ -0,0 +1,128 @@
+package gameapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStartGame_LinksRoundToNight(t *testing.T) {
+	leagueID := primitive.NewObjectID()
+	gameType := &models.GameType{ID: primitive.NewObjectID(), Key: "catan"}
+	night := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.GameNightScheduled}
+	cancelled := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.GameNightCancelled}
+
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	mockGameTypeRepo.On("FindByKey", mock.Anything, "catan").Return(gameType, nil)
+	mockNightRepo.On("FindByID", mock.Anything, night.ID).Return(night, nil)
+	mockNightRepo.On("FindByID", mock.Anything, cancelled.ID).Return(cancelled, nil)
+	mockGameRoundRepo.On("Create", mock.Anything, mock.MatchedBy(func(round *models.GameRound) bool {
+		return round.NightID == night.ID
+	})).Return(nil).Once()
+
+	handler := &Handler{
+		gameRoundRepository: mockGameRoundRepo,
+		gameTypeRepository:  mockGameTypeRepo,
+		gameNightService:    services.NewGameNightService(mockNightRepo, nil, mockGameRoundRepo, mockGameTypeRepo, services.NewNoopAuditService()),
+		idCodeCache:         services.NewIdAndCodeCache(),
+		auditService:        services.NewNoopAuditService(),
+	}
+	router := chi.NewRouter()
+	router.Use(leagueIDMiddleware(leagueID))
+	router.Post("/games", handler.startGame)
+
+	startGame := func(nightCode string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(startGameRequest{
+			Name:      "Catan",
+			Type:      "catan",
+			StartTime: time.Now(),
+			NightCode: nightCode,
+			Players:   []playerSetup{{MembershipCode: utils.IdToCode(primitive.NewObjectID()), Position: 1}},
+		})
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/games", bytes.NewBuffer(body)))
+		return rr
+	}
+
+	rr := startGame(utils.IdToCode(night.ID))
+	if assert.Equal(t, http.StatusCreated, rr.Code) {
+		var round models.GameRound
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &round))
+		assert.Equal(t, utils.IdToCode(night.ID), round.NightCode)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, startGame(utils.IdToCode(cancelled.ID)).Code)
+	mockGameRoundRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestGetGameNightSummary(t *testing.T) {
+	leagueID := primitive.NewObjectID()
+	olenaID, petroID := primitive.NewObjectID(), primitive.NewObjectID()
+	classic := &models.GameType{ID: primitive.NewObjectID(), Key: "catan", ScoringType: models.ScoringTypeClassic, Names: map[string]string{"en": "Catan"}}
+	night := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: leagueID, Title: "Friday", Timezone: "UTC", Status: models.GameNightScheduled}
+	start := time.Date(2026, 11, 6, 18, 0, 0, 0, time.UTC)
+	rounds := []*models.GameRound{
+		{ID: primitive.NewObjectID(), Name: "First", GameTypeID: classic.ID, Status: models.StatusCompleted, StartTime: start, EndTime: start.Add(time.Hour),
+			Players: []models.GameRoundPlayer{{MembershipID: olenaID, Position: 2}, {MembershipID: petroID, Position: 1}}},
+		{ID: primitive.NewObjectID(), Name: "Second", GameTypeID: classic.ID, Status: models.StatusCompleted, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour),
+			Players: []models.GameRoundPlayer{{MembershipID: olenaID, Position: 1}, {MembershipID: petroID, Position: 2}}},
+		{ID: primitive.NewObjectID(), Name: "Third", GameTypeID: classic.ID, Status: models.StatusCompleted, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour),
+			Players: []models.GameRoundPlayer{{MembershipID: olenaID, Position: 1}, {MembershipID: petroID, Position: 2}}},
+		{ID: primitive.NewObjectID(), Name: "Unfinished", GameTypeID: classic.ID, Status: models.StatusInProgress, StartTime: start.Add(3 * time.Hour),
+			Players: []models.GameRoundPlayer{{MembershipID: olenaID}, {MembershipID: petroID}}},
+	}
+
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockNightRepo.On("FindByID", mock.Anything, night.ID).Return(night, nil)
+	mockGameRoundRepo.On("FindByNight", mock.Anything, night.ID).Return(rounds, nil)
+	mockGameTypeRepo.On("FindByID", mock.Anything, classic.ID).Return(classic, nil).Once()
+
+	handler := &Handler{
+		gameNightService: services.NewGameNightService(mockNightRepo, nil, mockGameRoundRepo, mockGameTypeRepo, services.NewNoopAuditService()),
+		idCodeCache:      services.NewIdAndCodeCache(),
+		leagueService: &membersLeagueService{members: []*services.LeagueMemberInfo{
+			{MembershipID: olenaID, UserAlias: "Olena"},
+			{MembershipID: petroID, UserAlias: "Petro"},
+		}},
+	}
+	router := chi.NewRouter()
+	router.Get("/leagues/{code}/nights/{nightCode}/summary", handler.getGameNightSummary)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/leagues/"+utils.IdToCode(leagueID)+"/nights/"+utils.IdToCode(night.ID)+"/summary", nil))
+	if !assert.Equal(t, http.StatusOK, rr.Code) {
+		return
+	}
+
+	var summary gameNightSummaryResponse
+	if !assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summary)) {
+		return
+	}
+	assert.Equal(t, "Friday", summary.Night.Title)
+	if assert.Len(t, summary.Rounds, 4) {
+		assert.Equal(t, "Catan", summary.Rounds[0].GameTypeName)
+		assert.Equal(t, []gameNightPlayerResponse{{MembershipCode: utils.IdToCode(petroID), Alias: "Petro"}}, summary.Rounds[0].Winners)
+		assert.Empty(t, summary.Rounds[3].Winners)
+		assert.Empty(t, summary.Rounds[3].EndTime)
+	}
+	assert.Equal(t, []gameNightPlayerResponse{
+		{MembershipCode: utils.IdToCode(olenaID), Alias: "Olena", Wins: 2},
+		{MembershipCode: utils.IdToCode(petroID), Alias: "Petro", Wins: 1},
+	}, summary.Winners)
+}
@@ -8,8 +8,11 @@ import (
 	"sort"
 	"time"
 
+	"github.com/andriyg76/bgl/middleware"
 	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
 	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
 	"github.com/andriyg76/hexerr"
 	"github.com/go-chi/chi/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -55,18 +58,7 @@ func (h *Handler) startGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to find game type by code first (IdAndCode), then fallback to key
-	var gameType *models.GameType
-	var err error
-
-	idAndCode, codeErr := h.idCodeCache.GetByCode(req.Type)
-	if codeErr == nil && idAndCode != nil {
-		// Found by code, use FindByID
-		gameType, err = h.gameTypeRepository.FindByID(r.Context(), idAndCode.ID)
-	} else {
-		// Not found by code, try FindByKey (for backward compatibility)
-		gameType, err = h.gameTypeRepository.FindByKey(r.Context(), req.Type)
-	}
-
+	gameType, err := h.findGameTypeByCodeOrKey(r.Context(), req.Type)
 	if err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "database error fetching game type: %s", req.Type)
 		return
@@ -124,11 +116,29 @@ func (h *Handler) startGame(w http.ResponseWriter, r *http.Request) {
 		TeamScores: teamScores,
 	}
 
+	// Link the round to the game night it's played on
+	if req.NightCode != "" {
+		nightIdAndCode, err := h.idCodeCache.GetByCode(req.NightCode)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid night code: %s", req.NightCode), http.StatusBadRequest)
+			return
+		}
+		night, err := h.gameNightService.GetNightForRound(r.Context(), leagueID, nightIdAndCode.ID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "invalid game night: %s", req.NightCode)
+			return
+		}
+		round.NightID = night.ID
+		round.NightCode = req.NightCode
+	}
+
 	if err := h.gameRoundRepository.Create(r.Context(), round); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error creating game round")
 		return
 	}
 
+	h.logAction(r, leagueID, services.AuditActionGameCreated, services.AuditTargetGame, round.ID, services.AuditDetails{"name": round.Name})
+
 	utils.WriteJSON(r, w, round, http.StatusCreated)
 }
 
@@ -144,26 +154,13 @@ func (h *Handler) listGameRounds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check for status filter
-	statusFilter := r.URL.Query().Get("status")
-	activeOnly := r.URL.Query().Get("active") == "true"
-
-	var rounds []*models.GameRound
-	var err error
-
-	if activeOnly {
-		rounds, err = h.gameRoundRepository.FindActiveByLeague(r.Context(), leagueID)
-	} else if statusFilter != "" {
-		status := models.GameRoundStatus(statusFilter)
-		if !status.IsValidStatus() {
-			http.Error(w, "Invalid status filter", http.StatusBadRequest)
-			return
-		}
-		rounds, err = h.gameRoundRepository.FindByLeagueAndStatus(r.Context(), leagueID, []models.GameRoundStatus{status})
-	} else {
-		rounds, err = h.gameRoundRepository.FindByLeague(r.Context(), leagueID)
+	filter, err := parseGameRoundFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	rounds, err := h.findGameRounds(r.Context(), leagueID, filter)
 	if err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game rounds")
 		return
@@ -187,6 +184,11 @@ func (h *Handler) listGameRounds(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// Fill NightCode
+		if !round.NightID.IsZero() {
+			round.NightCode = h.idCodeCache.GetByID(round.NightID).Code
+		}
+
 		// Fill MembershipCode for each player
 		for i := range round.Players {
 			if !round.Players[i].MembershipID.IsZero() {
@@ -200,6 +202,76 @@ func (h *Handler) listGameRounds(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(r, w, rounds, http.StatusOK)
 }
 
+// gameRoundFilter holds the query filters of the game round list: status=..., active=true, from=..., to=...
+type gameRoundFilter struct {
+	Status     models.GameRoundStatus // empty - any status
+	ActiveOnly bool                   // Rounds which are not completed yet
+	From       time.Time              // zero - no lower bound on round start time
+	To         time.Time              // zero - no upper bound on round start time
+}
+
+func parseGameRoundFilter(r *http.Request) (gameRoundFilter, error) {
+	query := r.URL.Query()
+	filter := gameRoundFilter{
+		Status:     models.GameRoundStatus(query.Get("status")),
+		ActiveOnly: query.Get("active") == "true",
+	}
+	if filter.Status != "" && !filter.Status.IsValidStatus() {
+		return filter, hexerr.New("Invalid status filter")
+	}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = parseFilterDate(from, false); err != nil {
+			return filter, hexerr.New("Invalid from date")
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = parseFilterDate(to, true); err != nil {
+			return filter, hexerr.New("Invalid to date")
+		}
+	}
+	return filter, nil
+}
+
+// parseFilterDate accepts RFC 3339 or a plain date, a plain date is the start of the day or the end of it
+func parseFilterDate(value string, endOfDay bool) (time.Time, error) {
+	if endOfDay {
+		return parseAsOfDate(value)
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func (h *Handler) findGameRounds(ctx context.Context, leagueID primitive.ObjectID, filter gameRoundFilter) ([]*models.GameRound, error) {
+	var rounds []*models.GameRound
+	var err error
+	if filter.ActiveOnly {
+		rounds, err = h.gameRoundRepository.FindActiveByLeague(ctx, leagueID)
+	} else if filter.Status != "" {
+		rounds, err = h.gameRoundRepository.FindByLeagueAndStatus(ctx, leagueID, []models.GameRoundStatus{filter.Status})
+	} else {
+		rounds, err = h.gameRoundRepository.FindByLeague(ctx, leagueID)
+	}
+	if err != nil || (filter.From.IsZero() && filter.To.IsZero()) {
+		return rounds, err
+	}
+
+	filtered := make([]*models.GameRound, 0, len(rounds))
+	for _, round := range rounds {
+		if !filter.From.IsZero() && round.StartTime.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && round.StartTime.After(filter.To) {
+			continue
+		}
+		filtered = append(filtered, round)
+	}
+	return filtered, nil
+}
+
 func (h *Handler) getGameRound(w http.ResponseWriter, r *http.Request) {
 	id, err := utils.GetIDFromChiURL(r, "code")
 	if err != nil {
@@ -235,6 +307,11 @@ func (h *Handler) getGameRound(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Fill NightCode
+	if !round.NightID.IsZero() {
+		round.NightCode = h.idCodeCache.GetByID(round.NightID).Code
+	}
+
 	// Fill MembershipCode for each player
 	for i := range round.Players {
 		if !round.Players[i].MembershipID.IsZero() {
@@ -273,6 +350,10 @@ func (h *Handler) updateGameRound(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Game round not found", http.StatusNotFound)
 		return
 	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
 
 	// Update basic fields
 	round.Name = req.Name
@@ -309,6 +390,10 @@ func (h *Handler) updateGameRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !round.LeagueID.IsZero() && len(req.Players) > 0 {
+		h.logAction(r, round.LeagueID, services.AuditActionGameScoresUpdated, services.AuditTargetGame, round.ID, services.AuditDetails{"name": round.Name})
+	}
+
 	utils.WriteJSON(r, w, round, http.StatusOK)
 }
 
@@ -348,6 +433,10 @@ func (h *Handler) updatePlayerScore(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Game round not found", http.StatusNotFound)
 		return
 	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
 
 	// Convert player code to membership ID
 	playerIdAndCode, err := h.idCodeCache.GetByCode(playerCode)
@@ -376,6 +465,11 @@ func (h *Handler) updatePlayerScore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !round.LeagueID.IsZero() {
+		h.logAction(r, round.LeagueID, services.AuditActionGameScoresUpdated, services.AuditTargetGame, round.ID,
+			services.AuditDetails{"membership_code": playerCode, "score": req.Score})
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -401,6 +495,10 @@ func (h *Handler) finalizeGame(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Game round not found", http.StatusNotFound)
 		return
 	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
 
 	// Update player scores and calculate positions
 	for i := range round.Players {
@@ -446,6 +544,14 @@ func (h *Handler) finalizeGame(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Cooperative games: the group wins or loses together
+	if req.CooperativeScore != 0 {
+		round.CooperativeScore = req.CooperativeScore
+	}
+	if req.CooperativeWin != nil {
+		round.CooperativeWin = req.CooperativeWin
+	}
+
 	round.EndTime = time.Now()
 	round.Status = models.StatusCompleted
 
@@ -456,6 +562,14 @@ func (h *Handler) finalizeGame(w http.ResponseWriter, r *http.Request) {
 
 	// Update recent co-players cache for all players (if game is in a league)
 	if !round.LeagueID.IsZero() {
+		h.logAction(r, round.LeagueID, services.AuditActionGameFinalized, services.AuditTargetGame, round.ID, services.AuditDetails{"name": round.Name})
+
+		// Standings after the game are recorded before answering, so that rank movement and standings history
+		// see finalized games in order. The game is finalized anyway, a failure is only logged
+		if err := h.standingsHistory.RecordSnapshot(r.Context(), round.LeagueID, round.ID); err != nil {
+			glog.Warn("Failed to record standings snapshot of league %s after game %s: %v", round.LeagueID.Hex(), round.ID.Hex(), err)
+		}
+
 		playerMembershipIDs := make([]primitive.ObjectID, 0, len(round.Players))
 		for _, player := range round.Players {
 			if !player.MembershipID.IsZero() {
@@ -468,10 +582,18 @@ func (h *Handler) finalizeGame(w http.ResponseWriter, r *http.Request) {
 				ctx := context.Background()
 				if err := h.leagueService.UpdatePlayersAfterGame(ctx, playerMembershipIDs); err != nil {
 					// Log error but don't fail the request
-					fmt.Printf("Failed to update players after game: %v\n", err)
+					glog.Warn("Failed to update players after game: %v", err)
 				}
 			}()
 		}
+
+		h.notifyInBackground("finalized game", func(ctx context.Context, notifications services.NotificationService) error {
+			return notifications.NotifyGameFinalized(ctx, round)
+		})
+		h.publishInBackground("finalized game", func(ctx context.Context, webhooks services.WebhookService) error {
+			return webhooks.PublishGameFinalized(ctx, round)
+		})
+		h.postToChatsInBackground(round)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -485,6 +607,7 @@ type finalizeGameRequest struct {
 	PlayerScores     map[string]int64 `json:"player_scores"`
 	TeamScores       map[string]int64 `json:"team_scores,omitempty"`
 	CooperativeScore int64            `json:"cooperative_score,omitempty"`
+	CooperativeWin   *bool            `json:"cooperative_win,omitempty"` // Group result of a cooperative game
 }
 
 type updateGameRoundRequest struct {
@@ -505,6 +628,7 @@ type startGameRequest struct {
 	Type      string        `json:"type" validate:"required"`
 	StartTime time.Time     `json:"start_time"`
 	Players   []playerSetup `json:"players" validate:"required,min=1"`
+	NightCode string        `json:"night_code,omitempty"` // Game night the round is played on
 }
 
 type playerSetup struct {
@@ -562,6 +686,10 @@ func (h *Handler) updateRoles(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Game round not found", http.StatusNotFound)
 		return
 	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
 
 	// Update player roles
 	for _, update := range req.Players {
@@ -616,6 +744,10 @@ func (h *Handler) updateScores(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Game round not found", http.StatusNotFound)
 		return
 	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
 
 	// Update player scores
 	for membershipCode, score := range req.PlayerScores {
@@ -651,6 +783,11 @@ func (h *Handler) updateScores(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !round.LeagueID.IsZero() {
+		h.logAction(r, round.LeagueID, services.AuditActionGameScoresUpdated, services.AuditTargetGame, round.ID,
+			services.AuditDetails{"player_scores": req.PlayerScores, "team_scores": req.TeamScores})
+	}
+
 	utils.WriteJSON(r, w, round, http.StatusOK)
 }
 
@@ -673,6 +810,20 @@ func (h *Handler) updateRoundStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	round, err := h.gameRoundRepository.FindByID(r.Context(), id)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game round")
+		return
+	}
+	if round == nil {
+		http.Error(w, "Game round not found", http.StatusNotFound)
+		return
+	}
+	if !h.canEditRound(r, round) {
+		http.Error(w, "Forbidden: only league admins can correct finalized games", http.StatusForbidden)
+		return
+	}
+
 	if err := h.gameRoundRepository.UpdateStatus(r.Context(), id, req.Status, req.Version); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error updating game round status")
 		return
@@ -680,3 +831,8 @@ func (h *Handler) updateRoundStatus(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// canEditRound checks if the current user may change the round, finalized league rounds can only be corrected by league admins
+func (h *Handler) canEditRound(r *http.Request, round *models.GameRound) bool {
+	return round.Status != models.StatusCompleted || round.LeagueID.IsZero() || middleware.HasLeagueRole(r, models.LeagueRoleAdmin)
+}
@@ -47,6 +47,7 @@ func TestStartGame(t *testing.T) {
 		gameTypeRepository:  mockGameTypeRepo,
 		idCodeCache:         idCodeCache,
 		leagueService:       nil,
+		auditService:        services.NewNoopAuditService(),
 	}
 
 	router := chi.NewRouter()
@@ -216,6 +217,7 @@ func TestUpdatePlayerScore(t *testing.T) {
 		gameRoundRepository: mockRepo,
 		idCodeCache:         idCodeCache,
 		leagueService:       nil,
+		auditService:        services.NewNoopAuditService(),
 	}
 
 	router := chi.NewRouter()
@@ -282,6 +284,7 @@ func TestFinalizeGame(t *testing.T) {
 		gameRoundRepository: mockRepo,
 		idCodeCache:         idCodeCache,
 		leagueService:       nil,
+		auditService:        services.NewNoopAuditService(),
 	}
 
 	router := chi.NewRouter()
@@ -336,4 +339,64 @@ func TestFinalizeGame(t *testing.T) {
 		
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
+
+	t.Run("Finalize cooperative game stores group result", func(t *testing.T) {
+		gameID := primitive.NewObjectID()
+		membershipID := primitive.NewObjectID()
+
+		gameRound := &models.GameRound{
+			ID: gameID,
+			Players: []models.GameRoundPlayer{
+				{MembershipID: membershipID},
+			},
+		}
+
+		mockRepo.On("FindByID", mock.Anything, gameID).Return(gameRound, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(round *models.GameRound) bool {
+			return round.CooperativeWin != nil && *round.CooperativeWin && round.CooperativeScore == 42
+		})).Return(nil).Once()
+
+		body := []byte(`{"player_scores": {}, "cooperative_score": 42, "cooperative_win": true}`)
+		httpReq := httptest.NewRequest("PUT", "/games/"+utils.IdToCode(gameID)+"/finalize", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, httpReq)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+type recordingStandingsHistory struct {
+	services.StandingsHistoryService
+	recorded []primitive.ObjectID
+}
+
+func (s *recordingStandingsHistory) RecordSnapshot(ctx context.Context, leagueID, gameRoundID primitive.ObjectID) error {
+	s.recorded = append(s.recorded, gameRoundID)
+	return nil
+}
+
+func TestFinalizeGame_RecordsStandingsSnapshotBeforeAnswering(t *testing.T) {
+	mockRepo := new(mocks.MockGameRoundRepository)
+	history := &recordingStandingsHistory{}
+	handler := &Handler{
+		gameRoundRepository: mockRepo,
+		idCodeCache:         services.NewIdAndCodeCache(),
+		leagueService:       &stubLeagueService{},
+		auditService:        services.NewNoopAuditService(),
+		standingsHistory:    history,
+	}
+	router := chi.NewRouter()
+	router.Put("/games/{code}/finalize", handler.finalizeGame)
+
+	gameRound := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID()}
+	mockRepo.On("FindByID", mock.Anything, gameRound.ID).Return(gameRound, nil).Once()
+	mockRepo.On("Update", mock.Anything, gameRound).Return(nil).Once()
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("PUT", "/games/"+utils.IdToCode(gameRound.ID)+"/finalize", bytes.NewBufferString(`{"player_scores": {}}`)))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []primitive.ObjectID{gameRound.ID}, history.recorded)
 }
@@ -1,9 +1,11 @@
 package gameapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/andriyg76/bgl/auth"
@@ -51,6 +53,28 @@ func requireSuperAdmin(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+// findGameTypeByCodeOrKey looks up a game type by code (IdAndCode) first, then falls back to key
+func (h *Handler) findGameTypeByCodeOrKey(ctx context.Context, codeOrKey string) (*models.GameType, error) {
+	idAndCode, codeErr := h.idCodeCache.GetByCode(codeOrKey)
+	if codeErr == nil && idAndCode != nil {
+		// Found by code, use FindByID
+		return h.gameTypeRepository.FindByID(ctx, idAndCode.ID)
+	}
+	// Not found by code, try FindByKey (for backward compatibility)
+	return h.gameTypeRepository.FindByKey(ctx, codeOrKey)
+}
+
+// requestLanguage returns the language requested by ?lang= or by the Accept-Language header
+func requestLanguage(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+	if accept := r.Header.Get("Accept-Language"); len(accept) >= 2 {
+		return strings.ToLower(accept[:2])
+	}
+	return "en"
+}
+
 // Handlers
 
 func (h *Handler) listGameTypes(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,7 @@ package gameapi
 
 import (
 	"github.com/andriyg76/bgl/middleware"
+	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/repositories"
 	"github.com/andriyg76/bgl/services"
 	"github.com/go-chi/chi/v5"
@@ -16,6 +17,16 @@ type Handler struct {
 	gameTypeRepository  repositories.GameTypeRepository
 	userService         services.UserService
 	leagueService       services.LeagueService
+	seasonService       services.SeasonService
+	statsService        services.StatsService
+	standingsHistory    services.StandingsHistoryService
+	leagueArchive       services.LeagueArchiveService
+	gameNightService    services.GameNightService
+	auditService        services.AuditService
+	notificationService services.NotificationService
+	webhookService      services.WebhookService
+	discordNotifier     services.DiscordNotifier
+	telegramNotifier    services.TelegramNotifier
 	leagueMiddleware    *middleware.LeagueMiddleware
 	idCodeCache         services.IdAndCodeCache
 }
@@ -36,9 +47,11 @@ func (h *Handler) RegisterRoutes(r chi.Router, wizardHandler WizardHandler) {
 	})
 
 	r.Route("/leagues", func(r chi.Router) {
-		r.Post("/", h.createLeague)                         // Create league (superadmin)
-		r.Get("/", h.listLeagues)                           // List leagues
-		r.Post("/join/{token}", h.acceptInvitation)         // Accept invitation
+		r.Post("/", h.createLeague)                      // Create league, creator becomes owner
+		r.Get("/", h.listLeagues)                        // List leagues
+		r.Post("/join/{token}", h.acceptInvitation)      // Accept invitation
+		r.Get("/public", h.listPublicLeagues)            // List public leagues
+		r.Post("/{code}/join-requests", h.requestToJoin) // Request to join public league
 
 		// Routes that require league membership - apply middleware
 		r.Route("/{code}", func(r chi.Router) {
@@ -46,14 +59,54 @@ func (h *Handler) RegisterRoutes(r chi.Router, wizardHandler WizardHandler) {
 				r.Use(h.leagueMiddleware.RequireLeagueMembership)
 			}
 
-			r.Get("/", h.getLeague)                            // Get league details
-			r.Get("/members", h.getLeagueMembers)              // Get league members
-			r.Get("/standings", h.getLeagueStandings)          // Get league standings
-			r.Get("/suggested-players", h.getSuggestedPlayers) // Get suggested players for game
+			r.Get("/", h.getLeague)                                          // Get league details
+			r.Get("/members", h.getLeagueMembers)                            // Get league members
+			r.Get("/standings", h.getLeagueStandings)                        // Get league standings
+			r.Get("/standings/by-game-type", h.getLeagueStandingsByGameType) // Get standings per game type
+			r.Get("/standings/as-of", h.getLeagueStandingsAsOf)              // Get standings as of a past date
+			r.Get("/standings/export.csv", h.exportStandingsCSV)             // Get standings as CSV
+			r.Get("/ratings", h.getLeagueRatings)                            // Get league skill ratings (Elo / Glicko-2)
+			r.Get("/settings", h.getLeagueSettings)                          // Get league settings
+			r.Get("/suggested-players", h.getSuggestedPlayers)               // Get suggested players for game
+
+			// Seasons - time-boxed standings periods
+			r.Route("/seasons", func(r chi.Router) {
+				r.Get("/", h.listSeasons)                              // List seasons
+				r.Get("/{seasonCode}", h.getSeason)                    // Get season
+				r.Get("/{seasonCode}/standings", h.getSeasonStandings) // Get season standings
+				r.Group(func(r chi.Router) {
+					if h.leagueMiddleware != nil {
+						r.Use(h.leagueMiddleware.RequireLeagueRole(models.LeagueRoleAdmin))
+					}
+					r.Post("/", h.createSeason)                  // Create season
+					r.Put("/{seasonCode}", h.updateSeason)       // Update open season
+					r.Delete("/{seasonCode}", h.deleteSeason)    // Delete season
+					r.Post("/{seasonCode}/close", h.closeSeason) // Close season, freeze standings
+				})
+			})
+
+			// Game nights - scheduled sessions with RSVPs
+			r.Route("/nights", func(r chi.Router) {
+				r.Get("/", h.listGameNights)                         // List game nights, ?upcoming=true skips past ones
+				r.Get("/{nightCode}", h.getGameNight)                // Get game night with RSVPs
+				r.Get("/{nightCode}/summary", h.getGameNightSummary) // Rounds played on the night and winners
+				r.Put("/{nightCode}/rsvp", h.respondToGameNight)     // Answer yes, no or maybe
+				r.Group(func(r chi.Router) {
+					if h.leagueMiddleware != nil {
+						r.Use(h.leagueMiddleware.RequireLeagueRole(models.LeagueRoleAdmin))
+					}
+					r.Post("/", h.scheduleGameNight)                 // Schedule game night
+					r.Put("/{nightCode}", h.updateGameNight)         // Reschedule game night
+					r.Post("/{nightCode}/cancel", h.cancelGameNight) // Cancel game night
+				})
+			})
+			r.Get("/calendar", h.getCalendarFeed)          // Personal calendar feed URL
+			r.Post("/calendar/reset", h.resetCalendarFeed) // Replace calendar feed token
 
 			// Game rounds routes - all under league
 			r.Route("/game_rounds", func(r chi.Router) {
 				r.Get("/", h.listGameRounds)                                              // List game rounds for league
+				r.Get("/export.csv", h.exportGameRoundsCSV)                               // Game rounds as CSV, one row per player
 				r.Post("/", h.startGame)                                                  // Create game round in league
 				r.Get("/{code}", h.getGameRound)                                          // Get game round by code
 				r.Put("/{code}", h.updateGameRound)                                       // Update game round
@@ -64,17 +117,68 @@ func (h *Handler) RegisterRoutes(r chi.Router, wizardHandler WizardHandler) {
 				r.Put("/{gameRoundCode}/players/{playerCode}/score", h.updatePlayerScore) // Update player score
 			})
 
-			r.Post("/invitations", h.createInvitation)                       // Create invitation
-			r.Get("/invitations", h.listMyInvitations)                       // List my active invitations
-			r.Get("/invitations/expired", h.listMyExpiredInvitations)        // List my expired invitations
-			r.Post("/invitations/{token}/cancel", h.cancelInvitation)        // Cancel invitation by token
-			r.Post("/invitations/{token}/extend", h.extendInvitation)        // Extend invitation by 7 days
 			r.Put("/members/{memberCode}/alias", h.updatePendingMemberAlias) // Edit pending member alias
+			r.Get("/members/{memberCode}/vs/{otherCode}", h.getHeadToHead)   // Head-to-head of two members
+			r.Get("/members/{memberCode}/stats", h.getMemberStats)           // Member profile statistics
 			r.Post("/memberships", h.createMembershipForSuperAdmin)          // Create membership for superadmin (superadmin only)
-			r.Post("/ban/{userCode}", h.banUserFromLeague)                   // Ban user (superadmin)
-			r.Post("/unban/{userCode}", h.unbanUserFromLeague)               // Unban user (superadmin)
 			r.Post("/archive", h.archiveLeague)                              // Archive league (superadmin)
 			r.Post("/unarchive", h.unarchiveLeague)                          // Unarchive league (superadmin)
+			r.Post("/leave", h.leaveLeague)                                  // Leave league
+
+			// League administration - league admins and owner (superadmins are allowed everywhere)
+			r.Group(func(r chi.Router) {
+				if h.leagueMiddleware != nil {
+					r.Use(h.leagueMiddleware.RequireLeagueRole(models.LeagueRoleAdmin))
+				}
+				r.Get("/audit", h.listAuditLog)                                // Get league audit log
+				r.Put("/settings", h.updateLeagueSettings)                     // Update points system
+				r.Post("/invitations", h.createInvitation)                     // Create invitation or join link
+				r.Get("/invitations", h.listMyInvitations)                     // List my active invitations
+				r.Get("/invitations/expired", h.listMyExpiredInvitations)      // List my expired invitations
+				r.Post("/invitations/{token}/cancel", h.cancelInvitation)      // Cancel invitation by token
+				r.Post("/invitations/{token}/extend", h.extendInvitation)      // Extend invitation by 7 days
+				r.Get("/invitations/{token}/uses", h.listInvitationUses)       // Players who joined by a join link
+				r.Post("/ban/{userCode}", h.banUserFromLeague)                 // Ban user
+				r.Post("/unban/{userCode}", h.unbanUserFromLeague)             // Unban user
+				r.Post("/members/{memberCode}/promote", h.promoteMember)       // Make member a league admin
+				r.Put("/visibility", h.updateLeagueVisibility)                 // Make league public or private
+				r.Put("/language", h.updateLeagueLanguage)                     // Language of messages posted outside of the app
+				r.Get("/join-requests", h.listJoinRequests)                    // List members waiting for approval
+				r.Post("/join-requests/{memberCode}/approve", h.approveMember) // Approve member waiting for approval
+				r.Post("/join-requests/{memberCode}/reject", h.rejectMember)   // Reject member waiting for approval
+				r.Post("/members/{memberCode}/approve", h.approveMember)       // Same as join-requests approve, kept for existing clients
+				r.Post("/members/{memberCode}/reject", h.rejectMember)         // Same as join-requests reject, kept for existing clients
+				r.Post("/members/{memberCode}/merge", h.mergeMembers)          // Merge virtual or pending member into an active one
+				r.Get("/merges", h.listMembershipMerges)                       // Member merges which can be undone
+				r.Post("/merges/{mergeCode}/undo", h.undoMembershipMerge)      // Undo member merge
+
+				// Outbound webhooks - signed league events sent to outside services
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Get("/", h.listWebhooks)                                  // List webhooks
+					r.Post("/", h.createWebhook)                                // Create webhook, returns its secret
+					r.Put("/{webhookCode}", h.updateWebhook)                    // Update webhook
+					r.Delete("/{webhookCode}", h.deleteWebhook)                 // Delete webhook with its delivery log
+					r.Get("/{webhookCode}/deliveries", h.listWebhookDeliveries) // Delivery log
+					r.Post("/{webhookCode}/test", h.sendWebhookTestEvent)       // Send test event now
+				})
+
+				// Discord channel for game results
+				r.Get("/discord", h.getDiscordSettings)           // Whether the league is connected
+				r.Put("/discord", h.updateDiscordSettings)        // Connect or disconnect channel webhook
+				r.Post("/discord/test", h.sendDiscordTestMessage) // Post test message now
+
+				// Telegram chats for game results and bot commands
+				r.Get("/telegram", h.getTelegramSettings)                    // Bot and linked chats
+				r.Post("/telegram/link-code", h.createTelegramLinkCode)      // One-time code for linking a chat
+				r.Delete("/telegram/chats/{chatCode}", h.unlinkTelegramChat) // Unlink chat
+			})
+			r.Group(func(r chi.Router) {
+				if h.leagueMiddleware != nil {
+					r.Use(h.leagueMiddleware.RequireLeagueRole(models.LeagueRoleOwner))
+				}
+				r.Post("/members/{memberCode}/demote", h.demoteMember) // Make league admin an ordinary member
+				r.Post("/transfer", h.transferOwnLeague)               // Hand the league over to another member
+			})
 
 			// Wizard routes
 			if wizardHandler != nil {
@@ -84,20 +188,65 @@ func (h *Handler) RegisterRoutes(r chi.Router, wizardHandler WizardHandler) {
 			}
 		})
 	})
+
+	// Ownership review of all leagues (superadmin)
+	r.Route("/admin/leagues", func(r chi.Router) {
+		r.Get("/", h.listLeagueOverviews)                     // List leagues with owners
+		r.Post("/{code}/transfer", h.transferLeagueOwnership) // Transfer league ownership
+		r.Get("/{code}/export", h.exportLeague)               // Download league as a ZIP archive
+		r.Post("/import", h.importLeague)                     // Create league from a ZIP archive, ?dry_run=true only checks it
+	})
 }
 
 // RegisterPublicRoutes registers public (no auth) endpoints.
 func (h *Handler) RegisterPublicRoutes(r chi.Router) {
 	r.Get("/leagues/join/{token}/preview", h.previewInvitation) // Preview invitation (public)
+	r.Get("/leagues/{code}/calendar.ics", h.getLeagueCalendar)  // League calendar feed (public, personal token)
+	r.Post("/telegram/webhook", h.telegramWebhook)              // Telegram bot updates (public, secret token)
+}
+
+// HandlerDeps lists the repositories and services the game API works with.
+// Notifiers left nil are skipped, so are the webhooks
+type HandlerDeps struct {
+	UserService         services.UserService
+	GameRoundRepository repositories.GameRoundRepository
+	GameTypeRepository  repositories.GameTypeRepository
+	LeagueService       services.LeagueService
+	SeasonService       services.SeasonService
+	StatsService        services.StatsService
+	StandingsHistory    services.StandingsHistoryService
+	LeagueArchive       services.LeagueArchiveService
+	GameNightService    services.GameNightService
+	AuditService        services.AuditService // Actions are not recorded when nil
+	NotificationService services.NotificationService
+	WebhookService      services.WebhookService
+	DiscordNotifier     services.DiscordNotifier
+	TelegramNotifier    services.TelegramNotifier
+	LeagueMiddleware    *middleware.LeagueMiddleware
+	IdCodeCache         services.IdAndCodeCache
 }
 
-func NewHandler(r services.UserService, r2 repositories.GameRoundRepository, r3 repositories.GameTypeRepository, leagueService services.LeagueService, leagueMiddleware *middleware.LeagueMiddleware, idCodeCache services.IdAndCodeCache) *Handler {
+func NewHandler(deps HandlerDeps) *Handler {
+	auditService := deps.AuditService
+	if auditService == nil {
+		auditService = services.NewNoopAuditService()
+	}
 	return &Handler{
-		gameRoundRepository: r2,
-		gameTypeRepository:  r3,
-		userService:         r,
-		leagueService:       leagueService,
-		leagueMiddleware:    leagueMiddleware,
-		idCodeCache:         idCodeCache,
+		gameRoundRepository: deps.GameRoundRepository,
+		gameTypeRepository:  deps.GameTypeRepository,
+		userService:         deps.UserService,
+		leagueService:       deps.LeagueService,
+		seasonService:       deps.SeasonService,
+		statsService:        deps.StatsService,
+		standingsHistory:    deps.StandingsHistory,
+		leagueArchive:       deps.LeagueArchive,
+		gameNightService:    deps.GameNightService,
+		auditService:        auditService,
+		notificationService: deps.NotificationService,
+		webhookService:      deps.WebhookService,
+		discordNotifier:     deps.DiscordNotifier,
+		telegramNotifier:    deps.TelegramNotifier,
+		leagueMiddleware:    deps.LeagueMiddleware,
+		idCodeCache:         deps.IdCodeCache,
 	}
 }
@@ -6,11 +6,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/andriyg76/bgl/middleware"
 	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,14 +26,19 @@ type stubLeagueService struct {
 	preview    *services.InvitationPreview
 	previewErr error
 	lastToken  string
+	league     *models.League
+	membership *models.LeagueMembership
 }
 
-func (s *stubLeagueService) CreateLeague(ctx context.Context, name string) (*models.League, error) {
+func (s *stubLeagueService) CreateLeague(ctx context.Context, name string, createdBy primitive.ObjectID, isSuperAdmin bool) (*models.League, error) {
 	return nil, errNotImplemented
 }
 
 func (s *stubLeagueService) GetLeague(ctx context.Context, leagueID primitive.ObjectID) (*models.League, error) {
-	return nil, errNotImplemented
+	if s.league == nil {
+		return nil, errNotImplemented
+	}
+	return s.league, nil
 }
 
 func (s *stubLeagueService) ListLeagues(ctx context.Context) ([]*models.League, error) {
@@ -40,11 +49,11 @@ func (s *stubLeagueService) ListActiveLeagues(ctx context.Context) ([]*models.Le
 	return nil, errNotImplemented
 }
 
-func (s *stubLeagueService) ArchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error {
+func (s *stubLeagueService) ArchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error {
 	return errNotImplemented
 }
 
-func (s *stubLeagueService) UnarchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error {
+func (s *stubLeagueService) UnarchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error {
 	return errNotImplemented
 }
 
@@ -64,11 +73,11 @@ func (s *stubLeagueService) IsUserMember(ctx context.Context, leagueID, userID p
 	return false, errNotImplemented
 }
 
-func (s *stubLeagueService) BanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error {
+func (s *stubLeagueService) BanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error {
 	return errNotImplemented
 }
 
-func (s *stubLeagueService) UnbanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error {
+func (s *stubLeagueService) UnbanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error {
 	return errNotImplemented
 }
 
@@ -76,7 +85,7 @@ func (s *stubLeagueService) CreateMembershipForSuperAdmin(ctx context.Context, l
 	return nil, errNotImplemented
 }
 
-func (s *stubLeagueService) CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string) (*models.LeagueInvitation, error) {
+func (s *stubLeagueService) CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string, options services.InvitationOptions) (*models.LeagueInvitation, error) {
 	return nil, errNotImplemented
 }
 
@@ -113,7 +122,7 @@ func (s *stubLeagueService) UpdatePendingMemberAlias(ctx context.Context, member
 	return errNotImplemented
 }
 
-func (s *stubLeagueService) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID) ([]*services.LeagueStanding, error) {
+func (s *stubLeagueService) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID, filter services.StandingsFilter) ([]*services.LeagueStanding, error) {
 	return nil, errNotImplemented
 }
 
@@ -126,7 +135,10 @@ func (s *stubLeagueService) GetSuggestedPlayers(ctx context.Context, leagueID, u
 }
 
 func (s *stubLeagueService) GetMembershipByLeagueAndUser(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
-	return nil, errNotImplemented
+	if s.membership == nil {
+		return nil, errNotImplemented
+	}
+	return s.membership, nil
 }
 
 var _ services.LeagueService = (*stubLeagueService)(nil)
@@ -177,3 +189,191 @@ func TestInvitationPreviewRouteIsPublic(t *testing.T) {
 	assert.Equal(t, service.preview.Status, response.Status)
 	assert.Equal(t, expiresAt.Format("2006-01-02T15:04:05Z07:00"), response.ExpiresAt)
 }
+
+// leagueRoutesAs serves league routes to a member with the given role, behind the league middleware
+func leagueRoutesAs(role models.LeagueRole) func(method, path string) *httptest.ResponseRecorder {
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	service := &stubLeagueService{
+		league: &models.League{ID: leagueID, Name: "Test League"},
+		membership: &models.LeagueMembership{
+			LeagueID: leagueID,
+			UserID:   userID,
+			Status:   models.MembershipActive,
+			Role:     role,
+		},
+	}
+	idCodeCache := services.NewIdAndCodeCache()
+	handler := &Handler{
+		leagueService:    service,
+		leagueMiddleware: middleware.NewLeagueMiddleware(service, idCodeCache),
+		idCodeCache:      idCodeCache,
+	}
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router, nil)
+
+	return func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/leagues/"+utils.IdToCode(leagueID)+path, strings.NewReader("{}"))
+		req = req.WithContext(context.WithValue(req.Context(), "user", &user_profile.UserProfile{Code: utils.IdToCode(userID)}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+}
+
+func TestInvitationManagementRequiresLeagueAdmin(t *testing.T) {
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/invitations"},
+		{"GET", "/invitations"},
+		{"GET", "/invitations/expired"},
+		{"POST", "/invitations/token/cancel"},
+		{"POST", "/invitations/token/extend"},
+		{"GET", "/invitations/token/uses"},
+	}
+
+	for _, role := range []models.LeagueRole{models.LeagueRoleMember, models.LeagueRoleAdmin} {
+		serve := leagueRoutesAs(role)
+		for _, route := range routes {
+			t.Run(string(role)+" "+route.method+" "+route.path, func(t *testing.T) {
+				rr := serve(route.method, route.path)
+
+				if role == models.LeagueRoleMember {
+					assert.Equal(t, http.StatusForbidden, rr.Code)
+				} else {
+					assert.NotEqual(t, http.StatusForbidden, rr.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestSettingsAndSeasonChangesRequireLeagueAdmin(t *testing.T) {
+	serveMember := leagueRoutesAs(models.LeagueRoleMember)
+	seasonCode := utils.IdToCode(primitive.NewObjectID())
+
+	for _, route := range []struct {
+		method string
+		path   string
+	}{
+		{"PUT", "/settings"},
+		{"POST", "/seasons"},
+		{"PUT", "/seasons/" + seasonCode},
+		{"DELETE", "/seasons/" + seasonCode},
+		{"POST", "/seasons/" + seasonCode + "/close"},
+	} {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			assert.Equal(t, http.StatusForbidden, serveMember(route.method, route.path).Code)
+		})
+	}
+
+	// The stub service fails, so an admin reaches the handler and gets its error
+	rr := leagueRoutesAs(models.LeagueRoleAdmin)("PUT", "/settings")
+	assert.NotEqual(t, http.StatusForbidden, rr.Code)
+	assert.NotEqual(t, http.StatusNotFound, rr.Code)
+}
+
+func TestApproveAndRejectRoutesKeepMemberPaths(t *testing.T) {
+	memberCode := utils.IdToCode(primitive.NewObjectID())
+	serveAdmin := leagueRoutesAs(models.LeagueRoleAdmin)
+	serveMember := leagueRoutesAs(models.LeagueRoleMember)
+
+	for _, path := range []string{
+		"/join-requests/" + memberCode + "/approve",
+		"/join-requests/" + memberCode + "/reject",
+		"/members/" + memberCode + "/approve",
+		"/members/" + memberCode + "/reject",
+	} {
+		t.Run(path, func(t *testing.T) {
+			// The stub service fails, so an admin reaches the handler and gets its error
+			rr := serveAdmin("POST", path)
+			assert.NotEqual(t, http.StatusNotFound, rr.Code)
+			assert.NotEqual(t, http.StatusMethodNotAllowed, rr.Code)
+			assert.NotEqual(t, http.StatusForbidden, rr.Code)
+
+			assert.Equal(t, http.StatusForbidden, serveMember("POST", path).Code)
+		})
+	}
+}
+
+func (s *stubLeagueService) UpdateLeagueSettings(ctx context.Context, leagueID primitive.ObjectID, settings services.LeagueSettings) (*models.League, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) SetLeagueLanguage(ctx context.Context, leagueID primitive.ObjectID, language string, actorID primitive.ObjectID) (*models.League, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) GetLastFinishedRound(ctx context.Context, leagueID primitive.ObjectID) (*models.GameRound, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) GetLeagueRatings(ctx context.Context, leagueID primitive.ObjectID, algorithm services.RatingAlgorithm) ([]*services.MemberRating, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) GetLeagueStandingsByGameType(ctx context.Context, leagueID primitive.ObjectID) ([]*services.GameTypeStandings, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) SetMemberRole(ctx context.Context, leagueID, membershipID primitive.ObjectID, role models.LeagueRole, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) ListLeagueOverviews(ctx context.Context) ([]*services.LeagueOverview, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) TransferOwnership(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) AssignMissingOwners(ctx context.Context) (int, error) {
+	return 0, errNotImplemented
+}
+
+func (s *stubLeagueService) ListInvitationUses(ctx context.Context, token string, userID primitive.ObjectID) ([]*services.InvitationUseInfo, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) ApproveMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) RejectMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) SetLeaguePublic(ctx context.Context, leagueID primitive.ObjectID, isPublic bool, actorID primitive.ObjectID) (*models.League, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) ListPublicLeagues(ctx context.Context) ([]*models.League, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) RequestToJoin(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) ListJoinRequests(ctx context.Context, leagueID primitive.ObjectID) ([]*services.LeagueMemberInfo, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) MergeMemberships(ctx context.Context, leagueID, sourceID, targetID, actorID primitive.ObjectID) (*models.MembershipMerge, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) ListUndoableMerges(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) UndoMerge(ctx context.Context, leagueID, mergeID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
+
+func (s *stubLeagueService) LeaveLeague(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
+	return nil, errNotImplemented
+}
@@ -1,11 +1,13 @@
 package gameapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/andriyg76/bgl/auth"
+	"github.com/andriyg76/bgl/middleware"
 	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/services"
 	"github.com/andriyg76/bgl/user_profile"
@@ -30,11 +32,6 @@ func (h *Handler) createLeague(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !auth.IsSuperAdmin(user) {
-		http.Error(w, "Forbidden: superadmin only", http.StatusForbidden)
-		return
-	}
-
 	// Parse request
 	var req createLeagueRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -43,8 +40,12 @@ func (h *Handler) createLeague(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create league
-	league, err := h.leagueService.CreateLeague(r.Context(), req.Name)
+	league, err := h.leagueService.CreateLeague(r.Context(), req.Name, user.ID, auth.IsSuperAdmin(user))
 	if err != nil {
+		if services.IsOwnedLeaguesLimitError(err) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to create league")
 		return
 	}
@@ -110,6 +111,7 @@ func (h *Handler) getLeagueMembers(w http.ResponseWriter, r *http.Request) {
 			UserAvatar: member.UserAvatar,
 			Alias:      member.UserAlias,
 			Status:     string(member.Status),
+			Role:       string(member.Role),
 			JoinedAt:   member.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 		if member.InvitationToken != "" {
@@ -121,7 +123,7 @@ func (h *Handler) getLeagueMembers(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(r, w, response, http.StatusOK)
 }
 
-// GET /api/leagues/:code/standings - Get league standings
+// GET /api/leagues/:code/standings?game_type=<code|key>&season=<code> - Get league standings
 func (h *Handler) getLeagueStandings(w http.ResponseWriter, r *http.Request) {
 	leagueID, err := h.getIDFromChiURL(r, "code")
 	if err != nil {
@@ -129,28 +131,137 @@ func (h *Handler) getLeagueStandings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	standings, err := h.leagueService.GetLeagueStandings(r.Context(), leagueID)
+	filter, ok := h.parseStandingsFilter(w, r, leagueID)
+	if !ok {
+		return
+	}
+
+	standings, err := h.leagueService.GetLeagueStandings(r.Context(), leagueID, filter)
 	if err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get standings")
 		return
 	}
 
-	response := make([]standingResponse, 0, len(standings))
-	for _, standing := range standings {
-		userIdAndCode := h.idCodeCache.GetByID(standing.UserID)
-		response = append(response, standingResponse{
-			UserID:              userIdAndCode.Code,
-			UserName:            standing.UserName,
-			UserAvatar:          standing.UserAvatar,
-			TotalPoints:         standing.TotalPoints,
-			GamesPlayed:         standing.GamesPlayed,
-			GamesModerated:      standing.GamesModerated,
-			FirstPlaceCount:     standing.FirstPlaceCount,
-			SecondPlaceCount:    standing.SecondPlaceCount,
-			ThirdPlaceCount:     standing.ThirdPlaceCount,
-			ParticipationPoints: standing.ParticipationPoints,
-			PositionPoints:      standing.PositionPoints,
-			ModerationPoints:    standing.ModerationPoints,
+	response := h.standingsToResponse(standings)
+
+	// Rank movement is tracked for the overall standings only
+	if filter == (services.StandingsFilter{}) {
+		previousRanks, err := h.standingsHistory.GetPreviousRanks(r.Context(), leagueID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get previous ranks")
+			return
+		}
+		for i, standing := range standings {
+			if previousRank, ok := previousRanks[standing.MembershipID]; ok {
+				delta := previousRank - response[i].Rank
+				response[i].RankDelta = &delta
+			}
+		}
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// GET /api/leagues/:code/standings/as-of?date=2025-03-01 - Get league standings as they were at a past moment.
+// The date is either RFC 3339 or YYYY-MM-DD meaning the end of that day (UTC).
+func (h *Handler) getLeagueStandingsAsOf(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	at, err := parseAsOfDate(r.URL.Query().Get("date"))
+	if err != nil {
+		http.Error(w, "Invalid date", http.StatusBadRequest)
+		return
+	}
+
+	standings, err := h.standingsHistory.GetStandingsAsOf(r.Context(), leagueID, at)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get standings")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.standingsToResponse(standings), http.StatusOK)
+}
+
+// parseStandingsFilter reads the season=... and game_type=... filters, writes the error response if it can't
+func (h *Handler) parseStandingsFilter(w http.ResponseWriter, r *http.Request, leagueID primitive.ObjectID) (services.StandingsFilter, bool) {
+	var filter services.StandingsFilter
+	if seasonCode := r.URL.Query().Get("season"); seasonCode != "" {
+		seasonIdAndCode, err := h.idCodeCache.GetByCode(seasonCode)
+		if err != nil {
+			http.Error(w, "Invalid season code", http.StatusBadRequest)
+			return filter, false
+		}
+		season, err := h.seasonService.GetSeason(r.Context(), leagueID, seasonIdAndCode.ID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "season not found")
+			return filter, false
+		}
+		filter = services.SeasonStandingsFilter(season)
+	}
+	if gameTypeParam := r.URL.Query().Get("game_type"); gameTypeParam != "" {
+		gameType, err := h.findGameTypeByCodeOrKey(r.Context(), gameTypeParam)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "database error fetching game type: %s", gameTypeParam)
+			return filter, false
+		}
+		if gameType == nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, nil, "game type not found: %s", gameTypeParam)
+			return filter, false
+		}
+		filter.GameTypeID = gameType.ID
+	}
+	return filter, true
+}
+
+func parseAsOfDate(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// GET /api/leagues/:code/standings/by-game-type?lang=uk - Get one leaderboard per game type
+func (h *Handler) getLeagueStandingsByGameType(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	byGameType, err := h.leagueService.GetLeagueStandingsByGameType(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get standings")
+		return
+	}
+
+	lang := requestLanguage(r)
+	response := make([]gameTypeStandingsResponse, 0, len(byGameType))
+	for _, item := range byGameType {
+		gameType, err := h.gameTypeRepository.FindByID(r.Context(), item.GameTypeID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+			return
+		}
+		if gameType == nil {
+			// Game type was deleted, its rounds can't be presented
+			continue
+		}
+
+		response = append(response, gameTypeStandingsResponse{
+			GameTypeCode: h.idCodeCache.GetByID(gameType.ID).Code,
+			GameTypeKey:  gameType.Key,
+			GameTypeName: gameType.GetName(lang),
+			Icon:         gameType.Icon,
+			RoundsCount:  item.RoundsCount,
+			Standings:    h.standingsToResponse(item.Standings),
 		})
 	}
 
@@ -160,6 +271,11 @@ func (h *Handler) getLeagueStandings(w http.ResponseWriter, r *http.Request) {
 // CreateInvitationRequest represents the request body for creating an invitation
 type CreateInvitationRequest struct {
 	Alias string `json:"alias"`
+
+	// Reusable join link instead of a personal invitation (league admin)
+	MaxUses          int  `json:"max_uses,omitempty"`
+	ExpiresInHours   int  `json:"expires_in_hours,omitempty"` // Default 7 days
+	RequiresApproval bool `json:"requires_approval,omitempty"`
 }
 
 // POST /api/leagues/:code/invitations - Create invitation
@@ -177,10 +293,15 @@ func (h *Handler) createInvitation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Alias == "" {
+	isJoinLink := req.MaxUses != 0
+	if req.Alias == "" && !isJoinLink {
 		http.Error(w, "Alias is required", http.StatusBadRequest)
 		return
 	}
+	if isJoinLink && !middleware.HasLeagueRole(r, models.LeagueRoleAdmin) {
+		http.Error(w, "Forbidden: only league admins can create join links", http.StatusForbidden)
+		return
+	}
 
 	// Get current user
 	profile, err := user_profile.GetUserProfile(r)
@@ -216,10 +337,19 @@ func (h *Handler) createInvitation(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create invitation with alias
-	invitation, err := h.leagueService.CreateInvitation(r.Context(), leagueID, userID, req.Alias)
+	// Create invitation with alias or a join link
+	options := services.InvitationOptions{
+		MaxUses:          req.MaxUses,
+		ExpiresIn:        time.Duration(req.ExpiresInHours) * time.Hour,
+		RequiresApproval: req.RequiresApproval,
+	}
+	invitation, err := h.leagueService.CreateInvitation(r.Context(), leagueID, userID, req.Alias, options)
 	if err != nil {
-		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to create invitation")
+		status := http.StatusInternalServerError
+		if isJoinLink {
+			status = http.StatusBadRequest
+		}
+		utils.LogAndWriteHTTPError(r, w, status, err, "failed to create invitation")
 		return
 	}
 
@@ -417,11 +547,14 @@ func (h *Handler) updatePendingMemberAlias(w http.ResponseWriter, r *http.Reques
 
 // InvitationPreviewResponse represents public invitation preview data
 type InvitationPreviewResponse struct {
-	LeagueName   string `json:"league_name"`
-	InviterAlias string `json:"inviter_alias"`
-	PlayerAlias  string `json:"player_alias"`
-	ExpiresAt    string `json:"expires_at"`
-	Status       string `json:"status"` // valid, expired, used
+	LeagueName       string `json:"league_name"`
+	InviterAlias     string `json:"inviter_alias"`
+	PlayerAlias      string `json:"player_alias"`
+	ExpiresAt        string `json:"expires_at"`
+	Status           string `json:"status"`             // valid, expired, used
+	MaxUses          int    `json:"max_uses,omitempty"` // Set for reusable join links
+	RemainingUses    int    `json:"remaining_uses"`
+	RequiresApproval bool   `json:"requires_approval,omitempty"`
 }
 
 // GET /api/leagues/join/:token/preview - Preview invitation (public, no auth required)
@@ -439,16 +572,25 @@ func (h *Handler) previewInvitation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := InvitationPreviewResponse{
-		LeagueName:   preview.LeagueName,
-		InviterAlias: preview.InviterAlias,
-		PlayerAlias:  preview.PlayerAlias,
-		ExpiresAt:    preview.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-		Status:       preview.Status,
+		LeagueName:       preview.LeagueName,
+		InviterAlias:     preview.InviterAlias,
+		PlayerAlias:      preview.PlayerAlias,
+		ExpiresAt:        preview.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+		Status:           preview.Status,
+		MaxUses:          preview.MaxUses,
+		RemainingUses:    preview.RemainingUses,
+		RequiresApproval: preview.RequiresApproval,
 	}
 
 	utils.WriteJSON(r, w, response, http.StatusOK)
 }
 
+// acceptInvitationResponse is the joined league, membership_status is "pending" if it waits for approval
+type acceptInvitationResponse struct {
+	leagueResponse
+	MembershipStatus string `json:"membership_status"`
+}
+
 // POST /api/leagues/join/:token - Accept invitation
 func (h *Handler) acceptInvitation(w http.ResponseWriter, r *http.Request) {
 	token := chi.URLParam(r, "token")
@@ -489,28 +631,45 @@ func (h *Handler) acceptInvitation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.WriteJSON(r, w, h.leagueToResponse(league), http.StatusOK)
+	membership, err := h.leagueService.GetMembershipByLeagueAndUser(r.Context(), league.ID, userID)
+	if err != nil || membership == nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get membership")
+		return
+	}
+
+	h.notifyInBackground("accepted invitation", func(ctx context.Context, notifications services.NotificationService) error {
+		invitation, err := h.leagueService.GetInvitationByToken(ctx, token)
+		if err != nil {
+			return err
+		}
+		return notifications.NotifyInvitationAccepted(ctx, invitation, membership)
+	})
+	if membership.Status == models.MembershipActive {
+		h.publishInBackground("new member", func(ctx context.Context, webhooks services.WebhookService) error {
+			return webhooks.PublishMemberJoined(ctx, membership)
+		})
+	}
+
+	utils.WriteJSON(r, w, acceptInvitationResponse{
+		leagueResponse:   h.leagueToResponse(league),
+		MembershipStatus: string(membership.Status),
+	}, http.StatusOK)
 }
 
-// POST /api/leagues/:code/ban/:userCode - Ban user from league (superadmin only)
+// POST /api/leagues/:code/ban/:userCode - Ban user from league (league admin)
 func (h *Handler) banUserFromLeague(w http.ResponseWriter, r *http.Request) {
-	// Check if user is superadmin
 	profile, err := user_profile.GetUserProfile(r)
 	if err != nil || profile == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	user, err := h.userService.FindByCode(r.Context(), profile.Code)
-	if err != nil || user == nil {
-		http.Error(w, "User not found", http.StatusUnauthorized)
-		return
-	}
-
-	if !auth.IsSuperAdmin(user) {
-		http.Error(w, "Forbidden: superadmin only", http.StatusForbidden)
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
 		return
 	}
+	actorID := actorIdAndCode.ID
 
 	// Get league and user IDs
 	leagueID, err := h.getIDFromChiURL(r, "code")
@@ -525,38 +684,45 @@ func (h *Handler) banUserFromLeague(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Prevent superadmin from banning themselves
-	if user.ID == userID {
+	// Prevent admins from banning themselves
+	if actorID == userID {
 		http.Error(w, "Cannot ban yourself", http.StatusBadRequest)
 		return
 	}
 
+	// Only the owner can ban other admins
+	if !middleware.HasLeagueRole(r, models.LeagueRoleOwner) {
+		target, err := h.leagueService.GetMembershipByLeagueAndUser(r.Context(), leagueID, userID)
+		if err == nil && target != nil && target.EffectiveRole().AtLeast(models.LeagueRoleAdmin) {
+			http.Error(w, "Forbidden: only the league owner can ban admins", http.StatusForbidden)
+			return
+		}
+	}
+
 	// Ban user
-	if err := h.leagueService.BanUserFromLeague(r.Context(), leagueID, userID); err != nil {
+	if err := h.leagueService.BanUserFromLeague(r.Context(), leagueID, userID, actorID); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to ban user")
 		return
 	}
 
+	h.notifyInBackground("ban", func(ctx context.Context, notifications services.NotificationService) error {
+		return notifications.NotifyMemberBanned(ctx, leagueID, userID)
+	})
+
 	w.WriteHeader(http.StatusOK)
 }
 
-// POST /api/leagues/:code/unban/:userCode - Unban user from league (superadmin only)
+// POST /api/leagues/:code/unban/:userCode - Unban user from league (league admin)
 func (h *Handler) unbanUserFromLeague(w http.ResponseWriter, r *http.Request) {
-	// Check if user is superadmin
 	profile, err := user_profile.GetUserProfile(r)
 	if err != nil || profile == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	user, err := h.userService.FindByCode(r.Context(), profile.Code)
-	if err != nil || user == nil {
-		http.Error(w, "User not found", http.StatusUnauthorized)
-		return
-	}
-
-	if !auth.IsSuperAdmin(user) {
-		http.Error(w, "Forbidden: superadmin only", http.StatusForbidden)
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
 		return
 	}
 
@@ -574,7 +740,7 @@ func (h *Handler) unbanUserFromLeague(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Unban user
-	if err := h.leagueService.UnbanUserFromLeague(r.Context(), leagueID, userID); err != nil {
+	if err := h.leagueService.UnbanUserFromLeague(r.Context(), leagueID, userID, actorIdAndCode.ID); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to unban user")
 		return
 	}
@@ -610,7 +776,7 @@ func (h *Handler) archiveLeague(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Archive league
-	if err := h.leagueService.ArchiveLeague(r.Context(), leagueID); err != nil {
+	if err := h.leagueService.ArchiveLeague(r.Context(), leagueID, user.ID); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to archive league")
 		return
 	}
@@ -646,7 +812,7 @@ func (h *Handler) unarchiveLeague(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Unarchive league
-	if err := h.leagueService.UnarchiveLeague(r.Context(), leagueID); err != nil {
+	if err := h.leagueService.UnarchiveLeague(r.Context(), leagueID, user.ID); err != nil {
 		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to unarchive league")
 		return
 	}
@@ -664,6 +830,8 @@ type leagueResponse struct {
 	Code      string `json:"code"`
 	Name      string `json:"name"`
 	Status    string `json:"status"`
+	IsPublic  bool   `json:"is_public"` // Anyone can request to join
+	Language  string `json:"language"`  // Language of messages posted outside of the app
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -675,23 +843,41 @@ type memberResponse struct {
 	UserAvatar      string `json:"user_avatar"`
 	Alias           string `json:"alias"`
 	Status          string `json:"status"`
+	Role            string `json:"role"`
 	JoinedAt        string `json:"joined_at"`
 	InvitationToken string `json:"invitation_token,omitempty"` // Token of the invitation if exists
 }
 
 type standingResponse struct {
-	UserID              string `json:"user_id"`
-	UserName            string `json:"user_name"`
-	UserAvatar          string `json:"user_avatar"`
-	TotalPoints         int64  `json:"total_points"`
-	GamesPlayed         int    `json:"games_played"`
-	GamesModerated      int    `json:"games_moderated"`
-	FirstPlaceCount     int    `json:"first_place_count"`
-	SecondPlaceCount    int    `json:"second_place_count"`
-	ThirdPlaceCount     int    `json:"third_place_count"`
-	ParticipationPoints int64  `json:"participation_points"`
-	PositionPoints      int64  `json:"position_points"`
-	ModerationPoints    int64  `json:"moderation_points"`
+	Rank                int     `json:"rank"`
+	RankDelta           *int    `json:"rank_delta,omitempty"` // Positive - moved up since the previous game
+	UserID              string  `json:"user_id"`
+	UserName            string  `json:"user_name"`
+	UserAvatar          string  `json:"user_avatar"`
+	TotalPoints         int64   `json:"total_points"`
+	GamesPlayed         int     `json:"games_played"`
+	GamesModerated      int     `json:"games_moderated"`
+	FirstPlaceCount     int     `json:"first_place_count"`
+	SecondPlaceCount    int     `json:"second_place_count"`
+	ThirdPlaceCount     int     `json:"third_place_count"`
+	ParticipationPoints int64   `json:"participation_points"`
+	PositionPoints      int64   `json:"position_points"`
+	ModerationPoints    int64   `json:"moderation_points"`
+	TeamWins            int     `json:"team_wins"`
+	TeamDraws           int     `json:"team_draws"`
+	TeamLosses          int     `json:"team_losses"`
+	CoopWins            int     `json:"coop_wins"`
+	CoopLosses          int     `json:"coop_losses"`
+	CoopWinRate         float64 `json:"coop_win_rate"`
+}
+
+type gameTypeStandingsResponse struct {
+	GameTypeCode string             `json:"game_type_code"`
+	GameTypeKey  string             `json:"game_type_key"`
+	GameTypeName string             `json:"game_type_name"`
+	Icon         string             `json:"icon,omitempty"`
+	RoundsCount  int                `json:"rounds_count"`
+	Standings    []standingResponse `json:"standings"`
 }
 
 type invitationResponse struct {
@@ -701,6 +887,10 @@ type invitationResponse struct {
 	MembershipCode string `json:"membership_code,omitempty"`
 	ExpiresAt      string `json:"expires_at"`
 	CreatedAt      string `json:"created_at"`
+
+	MaxUses          int  `json:"max_uses,omitempty"` // Set for reusable join links
+	RemainingUses    int  `json:"remaining_uses"`
+	RequiresApproval bool `json:"requires_approval,omitempty"`
 }
 
 // Helper functions
@@ -724,11 +914,42 @@ func (h *Handler) leagueToResponse(league *models.League) leagueResponse {
 		Code:      leagueIdAndCode.Code,
 		Name:      league.Name,
 		Status:    string(league.Status),
+		IsPublic:  league.IsPublic,
+		Language:  league.GetLanguage(),
 		CreatedAt: league.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: league.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
+func (h *Handler) standingsToResponse(standings []*services.LeagueStanding) []standingResponse {
+	response := make([]standingResponse, 0, len(standings))
+	for _, standing := range standings {
+		userIdAndCode := h.idCodeCache.GetByID(standing.UserID)
+		response = append(response, standingResponse{
+			Rank:                standing.Rank,
+			UserID:              userIdAndCode.Code,
+			UserName:            standing.UserName,
+			UserAvatar:          standing.UserAvatar,
+			TotalPoints:         standing.TotalPoints,
+			GamesPlayed:         standing.GamesPlayed,
+			GamesModerated:      standing.GamesModerated,
+			FirstPlaceCount:     standing.FirstPlaceCount,
+			SecondPlaceCount:    standing.SecondPlaceCount,
+			ThirdPlaceCount:     standing.ThirdPlaceCount,
+			ParticipationPoints: standing.ParticipationPoints,
+			PositionPoints:      standing.PositionPoints,
+			ModerationPoints:    standing.ModerationPoints,
+			TeamWins:            standing.TeamWins,
+			TeamDraws:           standing.TeamDraws,
+			TeamLosses:          standing.TeamLosses,
+			CoopWins:            standing.CoopWins,
+			CoopLosses:          standing.CoopLosses,
+			CoopWinRate:         standing.CoopWinRate(),
+		})
+	}
+	return response
+}
+
 func (h *Handler) invitationToResponse(inv *models.LeagueInvitation) invitationResponse {
 	leagueIdAndCode := h.idCodeCache.GetByID(inv.LeagueID)
 	resp := invitationResponse{
@@ -737,6 +958,10 @@ func (h *Handler) invitationToResponse(inv *models.LeagueInvitation) invitationR
 		PlayerAlias: inv.PlayerAlias,
 		ExpiresAt:   inv.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
 		CreatedAt:   inv.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		MaxUses:          inv.MaxUses,
+		RemainingUses:    inv.RemainingUses(),
+		RequiresApproval: inv.RequiresApproval,
 	}
 	if !inv.MembershipID.IsZero() {
 		membershipIdAndCode := h.idCodeCache.GetByID(inv.MembershipID)
@@ -0,0 +1,118 @@
+package gameapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+)
+
+// maxLeagueArchiveSize limits the uploaded league archive
+const maxLeagueArchiveSize = 64 << 20
+
+type leagueImportResponse struct {
+	DryRun           bool            `json:"dry_run"`
+	League           *leagueResponse `json:"league,omitempty"` // Absent on a dry run
+	SourceCode       string          `json:"source_code"`
+	LeagueName       string          `json:"league_name"`
+	Memberships      int             `json:"memberships"`
+	Invitations      int             `json:"invitations"`
+	GameNights       int             `json:"game_nights"`
+	GameRounds       int             `json:"game_rounds"`
+	WizardGames      int             `json:"wizard_games"`
+	CreatedGameTypes []string        `json:"created_game_types"`
+	UnmatchedUsers   []string        `json:"unmatched_users"` // Members who become virtual
+}
+
+// GET /api/admin/leagues/:code/export - Download league with its history as a ZIP archive (superadmin only)
+func (h *Handler) exportLeague(w http.ResponseWriter, r *http.Request) {
+	if !requireSuperAdmin(w, r) {
+		return
+	}
+
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	// The archive is built in memory, so that a failure still gets a proper error response
+	var archive bytes.Buffer
+	if err := h.leagueArchive.Export(r.Context(), leagueID, actorIdAndCode.ID, &archive); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to export league")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="league-%s.zip"`, utils.IdToCode(leagueID)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+}
+
+// POST /api/admin/leagues/import?dry_run=true - Create a league from an exported ZIP archive in the request body (superadmin only)
+func (h *Handler) importLeague(w http.ResponseWriter, r *http.Request) {
+	if !requireSuperAdmin(w, r) {
+		return
+	}
+
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	archive, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLeagueArchiveSize))
+	if err != nil {
+		http.Error(w, "Archive is too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	result, err := h.leagueArchive.Import(r.Context(), bytes.NewReader(archive), int64(len(archive)), dryRun, actorIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to import league")
+		return
+	}
+
+	response := leagueImportResponse{
+		DryRun:           result.DryRun,
+		SourceCode:       result.SourceCode,
+		LeagueName:       result.LeagueName,
+		Memberships:      result.Memberships,
+		Invitations:      result.Invitations,
+		GameNights:       result.GameNights,
+		GameRounds:       result.GameRounds,
+		WizardGames:      result.WizardGames,
+		CreatedGameTypes: append([]string{}, result.CreatedGameTypes...),
+		UnmatchedUsers:   append([]string{}, result.UnmatchedUsers...),
+	}
+	status := http.StatusOK
+	if result.League != nil {
+		league := h.leagueToResponse(result.League)
+		response.League = &league
+		status = http.StatusCreated
+	}
+
+	utils.WriteJSON(r, w, response, status)
+}
@@ -0,0 +1,58 @@
+package gameapi
+
+import (
+	"net/http"
+
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type invitationUseResponse struct {
+	MembershipCode string `json:"membership_code"`
+	UserCode       string `json:"user_code"`
+	Alias          string `json:"alias"`
+	Status         string `json:"status"` // active, pending, banned or removed
+	UsedAt         string `json:"used_at"`
+}
+
+// GET /api/leagues/:code/invitations/:token/uses - List players who joined by a join link
+func (h *Handler) listInvitationUses(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		http.Error(w, "Invalid invitation token", http.StatusBadRequest)
+		return
+	}
+
+	// Get current user
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	uses, err := h.leagueService.ListInvitationUses(r.Context(), token, userIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to list invitation uses")
+		return
+	}
+
+	response := make([]invitationUseResponse, 0, len(uses))
+	for _, use := range uses {
+		response = append(response, invitationUseResponse{
+			MembershipCode: h.idCodeCache.GetByID(use.MembershipID).Code,
+			UserCode:       h.idCodeCache.GetByID(use.UserID).Code,
+			Alias:          use.Alias,
+			Status:         use.Status,
+			UsedAt:         use.UsedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
@@ -0,0 +1,264 @@
+package gameapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type updateLeagueVisibilityRequest struct {
+	IsPublic bool `json:"is_public"`
+}
+
+// GET /api/leagues/public - List public leagues open for join requests
+func (h *Handler) listPublicLeagues(w http.ResponseWriter, r *http.Request) {
+	leagues, err := h.leagueService.ListPublicLeagues(r.Context())
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list public leagues")
+		return
+	}
+
+	response := make([]leagueResponse, 0, len(leagues))
+	for _, league := range leagues {
+		response = append(response, h.leagueToResponse(league))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/join-requests - Request to join a public league
+func (h *Handler) requestToJoin(w http.ResponseWriter, r *http.Request) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.leagueService.RequestToJoin(r.Context(), leagueID, userIdAndCode.ID)
+	if err != nil {
+		// Check if user is already a member - return league code for redirect
+		if leagueCode, ok := services.IsAlreadyMemberError(err); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			response := map[string]string{
+				"error":       err.Error(),
+				"league_code": leagueCode,
+			}
+			_ = json.NewEncoder(w).Encode(response)
+			return
+		}
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to request to join league")
+		return
+	}
+
+	h.notifyInBackground("join request", func(ctx context.Context, notifications services.NotificationService) error {
+		return notifications.NotifyJoinRequested(ctx, membership)
+	})
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   userIdAndCode.Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusCreated)
+}
+
+// PUT /api/leagues/:code/visibility - Make league public or private (league admin)
+func (h *Handler) updateLeagueVisibility(w http.ResponseWriter, r *http.Request) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req updateLeagueVisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.leagueService.SetLeaguePublic(r.Context(), leagueID, req.IsPublic, actorIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update league visibility")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.leagueToResponse(league), http.StatusOK)
+}
+
+// GET /api/leagues/:code/join-requests - List users waiting for approval (league admin)
+func (h *Handler) listJoinRequests(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	requests, err := h.leagueService.ListJoinRequests(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list join requests")
+		return
+	}
+
+	response := make([]memberResponse, 0, len(requests))
+	for _, request := range requests {
+		response = append(response, memberResponse{
+			Code:       h.idCodeCache.GetByID(request.MembershipID).Code,
+			UserID:     h.idCodeCache.GetByID(request.UserID).Code,
+			UserName:   request.UserName,
+			UserAvatar: request.UserAvatar,
+			Alias:      request.UserAlias,
+			Status:     string(request.Status),
+			Role:       string(request.Role),
+			JoinedAt:   request.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/join-requests/:memberCode/approve - Approve membership waiting for approval (league admin)
+func (h *Handler) approveMember(w http.ResponseWriter, r *http.Request) {
+	actorID, leagueID, membershipID, ok := h.parseMemberActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	membership, err := h.leagueService.ApproveMember(r.Context(), leagueID, membershipID, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to approve member")
+		return
+	}
+
+	h.notifyInBackground("approved join request", func(ctx context.Context, notifications services.NotificationService) error {
+		return notifications.NotifyJoinRequestDecided(ctx, membership, true)
+	})
+	h.publishInBackground("new member", func(ctx context.Context, webhooks services.WebhookService) error {
+		return webhooks.PublishMemberJoined(ctx, membership)
+	})
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   h.idCodeCache.GetByID(membership.UserID).Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusOK)
+}
+
+// POST /api/leagues/:code/join-requests/:memberCode/reject - Reject membership waiting for approval (league admin)
+func (h *Handler) rejectMember(w http.ResponseWriter, r *http.Request) {
+	actorID, leagueID, membershipID, ok := h.parseMemberActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	membership, err := h.leagueService.RejectMember(r.Context(), leagueID, membershipID, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to reject member")
+		return
+	}
+
+	h.notifyInBackground("rejected join request", func(ctx context.Context, notifications services.NotificationService) error {
+		return notifications.NotifyJoinRequestDecided(ctx, membership, false)
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// POST /api/leagues/:code/leave - Leave league, history and standings stay under the alias
+func (h *Handler) leaveLeague(w http.ResponseWriter, r *http.Request) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.leagueService.LeaveLeague(r.Context(), leagueID, userIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to leave league")
+		return
+	}
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   userIdAndCode.Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusOK)
+}
+
+// parseMemberActionRequest resolves the current user, league and member of the request, writes the error response if it can't
+func (h *Handler) parseMemberActionRequest(w http.ResponseWriter, r *http.Request) (actorID, leagueID, membershipID primitive.ObjectID, ok bool) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err = h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membershipID, err = h.getIDFromChiURL(r, "memberCode")
+	if err != nil {
+		http.Error(w, "Invalid member code", http.StatusBadRequest)
+		return
+	}
+
+	return actorIdAndCode.ID, leagueID, membershipID, true
+}
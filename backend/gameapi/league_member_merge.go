@@ -0,0 +1,143 @@
+package gameapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mergeMembersRequest struct {
+	TargetCode string `json:"target_code"` // Membership the member is merged into
+}
+
+type membershipMergeResponse struct {
+	Code        string `json:"code"`
+	SourceCode  string `json:"source_code"`
+	SourceAlias string `json:"source_alias"`
+	TargetCode  string `json:"target_code"`
+	TargetAlias string `json:"target_alias"`
+	GameRounds  int    `json:"game_rounds"`  // Number of moved game rounds
+	WizardGames int    `json:"wizard_games"` // Number of moved Wizard games
+	MergedAt    string `json:"merged_at"`
+	UndoUntil   string `json:"undo_until"`
+}
+
+// POST /api/leagues/:code/members/:memberCode/merge - Merge virtual or pending member into an active one (league admin)
+func (h *Handler) mergeMembers(w http.ResponseWriter, r *http.Request) {
+	actorID, leagueID, sourceID, ok := h.parseMemberActionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req mergeMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	target, err := h.idCodeCache.GetByCode(req.TargetCode)
+	if err != nil {
+		http.Error(w, "Invalid target code", http.StatusBadRequest)
+		return
+	}
+
+	merge, err := h.leagueService.MergeMemberships(r.Context(), leagueID, sourceID, target.ID, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to merge members")
+		return
+	}
+	h.refreshStandingsSnapshot(r, leagueID)
+
+	utils.WriteJSON(r, w, h.membershipMergeToResponse(merge), http.StatusCreated)
+}
+
+// GET /api/leagues/:code/merges - List member merges which can still be undone (league admin)
+func (h *Handler) listMembershipMerges(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	merges, err := h.leagueService.ListUndoableMerges(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list merges")
+		return
+	}
+
+	response := make([]membershipMergeResponse, 0, len(merges))
+	for _, merge := range merges {
+		response = append(response, h.membershipMergeToResponse(merge))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/merges/:mergeCode/undo - Undo member merge (league admin)
+func (h *Handler) undoMembershipMerge(w http.ResponseWriter, r *http.Request) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	mergeID, err := h.getIDFromChiURL(r, "mergeCode")
+	if err != nil {
+		http.Error(w, "Invalid merge code", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.leagueService.UndoMerge(r.Context(), leagueID, mergeID, actorIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to undo merge")
+		return
+	}
+	h.refreshStandingsSnapshot(r, leagueID)
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   h.idCodeCache.GetByID(membership.UserID).Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusOK)
+}
+
+// refreshStandingsSnapshot recalculates the latest standings snapshot, so that it doesn't keep the merged membership.
+// Done before answering like after finalized games, a failure is only logged
+func (h *Handler) refreshStandingsSnapshot(r *http.Request, leagueID primitive.ObjectID) {
+	if err := h.standingsHistory.RefreshLatest(r.Context(), leagueID); err != nil {
+		glog.Warn("Failed to refresh standings snapshot of league %s: %v", leagueID.Hex(), err)
+	}
+}
+
+func (h *Handler) membershipMergeToResponse(merge *models.MembershipMerge) membershipMergeResponse {
+	return membershipMergeResponse{
+		Code:        h.idCodeCache.GetByID(merge.ID).Code,
+		SourceCode:  h.idCodeCache.GetByID(merge.Source.ID).Code,
+		SourceAlias: merge.Source.Alias,
+		TargetCode:  h.idCodeCache.GetByID(merge.Target.ID).Code,
+		TargetAlias: merge.Target.Alias,
+		GameRounds:  len(merge.GameRoundIDs),
+		WizardGames: len(merge.WizardGameIDs),
+		MergedAt:    merge.MergedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UndoUntil:   merge.UndoUntil.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,116 @@
+package gameapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+)
+
+type leagueOverviewResponse struct {
+	League       leagueResponse  `json:"league"`
+	Owner        *memberResponse `json:"owner,omitempty"` // Absent for leagues without owner
+	MembersCount int             `json:"members_count"`
+}
+
+type transferOwnershipRequest struct {
+	MembershipCode string `json:"membership_code"`
+}
+
+// GET /api/admin/leagues - List all leagues with owners and members count (superadmin only)
+func (h *Handler) listLeagueOverviews(w http.ResponseWriter, r *http.Request) {
+	if !requireSuperAdmin(w, r) {
+		return
+	}
+
+	overviews, err := h.leagueService.ListLeagueOverviews(r.Context())
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list leagues")
+		return
+	}
+
+	response := make([]leagueOverviewResponse, 0, len(overviews))
+	for _, overview := range overviews {
+		item := leagueOverviewResponse{
+			League:       h.leagueToResponse(overview.League),
+			MembersCount: overview.MembersCount,
+		}
+		if owner := overview.Owner; owner != nil {
+			item.Owner = &memberResponse{
+				Code:       h.idCodeCache.GetByID(owner.MembershipID).Code,
+				UserID:     h.idCodeCache.GetByID(owner.UserID).Code,
+				UserName:   owner.UserName,
+				UserAvatar: owner.UserAvatar,
+				Alias:      owner.UserAlias,
+				Status:     string(owner.Status),
+				Role:       string(owner.Role),
+				JoinedAt:   owner.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+			}
+		}
+		response = append(response, item)
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/admin/leagues/:code/transfer - Make an active member the league owner (superadmin only)
+func (h *Handler) transferLeagueOwnership(w http.ResponseWriter, r *http.Request) {
+	if !requireSuperAdmin(w, r) {
+		return
+	}
+
+	h.transferOwnership(w, r)
+}
+
+// POST /api/leagues/:code/transfer - Hand the league over to another active member (league owner)
+func (h *Handler) transferOwnLeague(w http.ResponseWriter, r *http.Request) {
+	h.transferOwnership(w, r)
+}
+
+func (h *Handler) transferOwnership(w http.ResponseWriter, r *http.Request) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req transferOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	membershipIdAndCode, err := h.idCodeCache.GetByCode(req.MembershipCode)
+	if err != nil {
+		http.Error(w, "Invalid membership code", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.leagueService.TransferOwnership(r.Context(), leagueID, membershipIdAndCode.ID, actorIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to transfer league ownership")
+		return
+	}
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   h.idCodeCache.GetByID(membership.UserID).Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusOK)
+}
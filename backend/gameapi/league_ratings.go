@@ -0,0 +1,87 @@
+package gameapi
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+)
+
+type ratingHistoryResponse struct {
+	GameRoundCode string  `json:"game_round_code"`
+	PlayedAt      string  `json:"played_at"`
+	Rating        float64 `json:"rating"`
+	Deviation     float64 `json:"deviation,omitempty"`
+}
+
+type ratingResponse struct {
+	MembershipCode string                  `json:"membership_code"`
+	UserID         string                  `json:"user_id,omitempty"`
+	UserName       string                  `json:"user_name"`
+	UserAvatar     string                  `json:"user_avatar"`
+	IsPending      bool                    `json:"is_pending,omitempty"`
+	Rating         float64                 `json:"rating"`
+	Deviation      float64                 `json:"deviation,omitempty"`
+	Volatility     float64                 `json:"volatility,omitempty"`
+	GamesRated     int                     `json:"games_rated"`
+	History        []ratingHistoryResponse `json:"history"`
+}
+
+// GET /api/leagues/:code/ratings?algorithm=elo|glicko2 - Get league skill ratings with per-member history
+func (h *Handler) getLeagueRatings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	algorithm := services.RatingAlgorithm(r.URL.Query().Get("algorithm"))
+	if algorithm == "" {
+		algorithm = services.RatingElo
+	}
+	if services.NewRatingEngine(algorithm) == nil {
+		http.Error(w, "Unknown rating algorithm", http.StatusBadRequest)
+		return
+	}
+
+	ratings, err := h.leagueService.GetLeagueRatings(r.Context(), leagueID, algorithm)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to get ratings")
+		return
+	}
+
+	response := make([]ratingResponse, 0, len(ratings))
+	for _, rating := range ratings {
+		resp := ratingResponse{
+			MembershipCode: h.idCodeCache.GetByID(rating.MembershipID).Code,
+			UserName:       rating.UserName,
+			UserAvatar:     rating.UserAvatar,
+			IsPending:      rating.IsPending,
+			Rating:         roundRating(rating.Rating),
+			Deviation:      roundRating(rating.Deviation),
+			Volatility:     rating.Volatility,
+			GamesRated:     rating.GamesRated,
+			History:        make([]ratingHistoryResponse, 0, len(rating.History)),
+		}
+		if !rating.UserID.IsZero() {
+			resp.UserID = h.idCodeCache.GetByID(rating.UserID).Code
+		}
+		for _, point := range rating.History {
+			resp.History = append(resp.History, ratingHistoryResponse{
+				GameRoundCode: h.idCodeCache.GetByID(point.GameRoundID).Code,
+				PlayedAt:      point.PlayedAt.Format("2006-01-02T15:04:05Z07:00"),
+				Rating:        roundRating(point.Rating),
+				Deviation:     roundRating(point.Deviation),
+			})
+		}
+		response = append(response, resp)
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// roundRating rounds a rating value to 2 decimal places
+func roundRating(value float64) float64 {
+	return math.Round(value*100) / 100
+}
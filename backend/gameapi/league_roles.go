@@ -0,0 +1,60 @@
+package gameapi
+
+import (
+	"net/http"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+)
+
+// POST /api/leagues/:code/members/:memberCode/promote - Make member a league admin (league admin)
+func (h *Handler) promoteMember(w http.ResponseWriter, r *http.Request) {
+	h.setMemberRole(w, r, models.LeagueRoleAdmin)
+}
+
+// POST /api/leagues/:code/members/:memberCode/demote - Make league admin an ordinary member (league owner)
+func (h *Handler) demoteMember(w http.ResponseWriter, r *http.Request) {
+	h.setMemberRole(w, r, models.LeagueRoleMember)
+}
+
+func (h *Handler) setMemberRole(w http.ResponseWriter, r *http.Request, role models.LeagueRole) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actorIdAndCode, err := h.idCodeCache.GetByCode(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membershipID, err := h.getIDFromChiURL(r, "memberCode")
+	if err != nil {
+		http.Error(w, "Invalid member code", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := h.leagueService.SetMemberRole(r.Context(), leagueID, membershipID, role, actorIdAndCode.ID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to change member role")
+		return
+	}
+
+	utils.WriteJSON(r, w, memberResponse{
+		Code:     h.idCodeCache.GetByID(membership.ID).Code,
+		UserID:   h.idCodeCache.GetByID(membership.UserID).Code,
+		Alias:    membership.Alias,
+		Status:   string(membership.Status),
+		Role:     string(membership.EffectiveRole()),
+		JoinedAt: membership.JoinedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, http.StatusOK)
+}
@@ -0,0 +1,107 @@
+package gameapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+)
+
+type pointsConfigDto struct {
+	ParticipationPoints int64   `json:"participation_points"`
+	ModerationPoints    int64   `json:"moderation_points"`
+	PositionPoints      []int64 `json:"position_points"` // index 0 - 1st place
+	// Omitted from an update request - the default
+	TeamWinPoints  *int64 `json:"team_win_points"`
+	TeamDrawPoints *int64 `json:"team_draw_points"`
+	TeamLossPoints *int64 `json:"team_loss_points"`
+	CoopWinPoints  *int64 `json:"coop_win_points"`
+}
+
+type leagueSettingsResponse struct {
+	PointsConfig      pointsConfigDto `json:"points_config"`
+	UsesDefaultPoints bool            `json:"uses_default_points"`
+}
+
+type updateLeagueSettingsRequest struct {
+	PointsConfig *pointsConfigDto `json:"points_config"` // null - reset to the default points system
+}
+
+// GET /api/leagues/:code/settings - Get league settings
+func (h *Handler) getLeagueSettings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.leagueService.GetLeague(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "league not found")
+		return
+	}
+
+	utils.WriteJSON(r, w, leagueSettingsToResponse(league), http.StatusOK)
+}
+
+// PUT /api/leagues/:code/settings - Update league settings (league admin)
+func (h *Handler) updateLeagueSettings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req updateLeagueSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	var settings services.LeagueSettings
+	if req.PointsConfig != nil {
+		settings.PointsConfig = &models.LeaguePointsConfig{
+			ParticipationPoints: req.PointsConfig.ParticipationPoints,
+			ModerationPoints:    req.PointsConfig.ModerationPoints,
+			PositionPoints:      req.PointsConfig.PositionPoints,
+			TeamWinPoints:       req.PointsConfig.TeamWinPoints,
+			TeamDrawPoints:      req.PointsConfig.TeamDrawPoints,
+			TeamLossPoints:      req.PointsConfig.TeamLossPoints,
+			CoopWinPoints:       req.PointsConfig.CoopWinPoints,
+		}
+	}
+
+	if err := services.ValidatePointsConfig(settings.PointsConfig); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	league, err := h.leagueService.UpdateLeagueSettings(r.Context(), leagueID, settings)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to update league settings")
+		return
+	}
+
+	utils.WriteJSON(r, w, leagueSettingsToResponse(league), http.StatusOK)
+}
+
+func leagueSettingsToResponse(league *models.League) leagueSettingsResponse {
+	cfg := services.CompletePointsConfig(league.PointsConfig)
+	if cfg == nil {
+		cfg = services.DefaultLeaguePointsConfig()
+	}
+	return leagueSettingsResponse{
+		PointsConfig: pointsConfigDto{
+			ParticipationPoints: cfg.ParticipationPoints,
+			ModerationPoints:    cfg.ModerationPoints,
+			PositionPoints:      cfg.PositionPoints,
+			TeamWinPoints:       cfg.TeamWinPoints,
+			TeamDrawPoints:      cfg.TeamDrawPoints,
+			TeamLossPoints:      cfg.TeamLossPoints,
+			CoopWinPoints:       cfg.CoopWinPoints,
+		},
+		UsesDefaultPoints: league.PointsConfig == nil,
+	}
+}
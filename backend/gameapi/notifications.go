@@ -0,0 +1,21 @@
+package gameapi
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/glog"
+)
+
+// notifyInBackground sends in-app notifications without delaying the response, failures are only logged
+func (h *Handler) notifyInBackground(subject string, notify func(ctx context.Context, notifications services.NotificationService) error) {
+	if h.notificationService == nil {
+		return
+	}
+
+	go func() {
+		if err := notify(context.Background(), h.notificationService); err != nil {
+			glog.Warn("Failed to notify about %s: %v", subject, err)
+		}
+	}()
+}
@@ -0,0 +1,206 @@
+package gameapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/utils"
+)
+
+type seasonRequest struct {
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type seasonResponse struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Status    string `json:"status"`
+	ClosedAt  string `json:"closed_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GET /api/leagues/:code/seasons - List league seasons
+func (h *Handler) listSeasons(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasons, err := h.seasonService.ListSeasons(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list seasons")
+		return
+	}
+
+	response := make([]seasonResponse, 0, len(seasons))
+	for _, season := range seasons {
+		response = append(response, h.seasonToResponse(season))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/seasons - Create season (league admin)
+func (h *Handler) createSeason(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	var req seasonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasonService.CreateSeason(r.Context(), leagueID, req.Name, req.StartDate, req.EndDate)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to create season")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.seasonToResponse(season), http.StatusCreated)
+}
+
+// GET /api/leagues/:code/seasons/:seasonCode - Get season details
+func (h *Handler) getSeason(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := h.getIDFromChiURL(r, "seasonCode")
+	if err != nil {
+		http.Error(w, "Invalid season code", http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasonService.GetSeason(r.Context(), leagueID, seasonID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "season not found")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.seasonToResponse(season), http.StatusOK)
+}
+
+// PUT /api/leagues/:code/seasons/:seasonCode - Update open season (league admin)
+func (h *Handler) updateSeason(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := h.getIDFromChiURL(r, "seasonCode")
+	if err != nil {
+		http.Error(w, "Invalid season code", http.StatusBadRequest)
+		return
+	}
+
+	var req seasonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasonService.UpdateSeason(r.Context(), leagueID, seasonID, req.Name, req.StartDate, req.EndDate)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update season")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.seasonToResponse(season), http.StatusOK)
+}
+
+// DELETE /api/leagues/:code/seasons/:seasonCode - Delete season (league admin)
+func (h *Handler) deleteSeason(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := h.getIDFromChiURL(r, "seasonCode")
+	if err != nil {
+		http.Error(w, "Invalid season code", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.seasonService.DeleteSeason(r.Context(), leagueID, seasonID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "failed to delete season")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/leagues/:code/seasons/:seasonCode/close - Close season and freeze its standings (league admin)
+func (h *Handler) closeSeason(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := h.getIDFromChiURL(r, "seasonCode")
+	if err != nil {
+		http.Error(w, "Invalid season code", http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasonService.CloseSeason(r.Context(), leagueID, seasonID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to close season")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.seasonToResponse(season), http.StatusOK)
+}
+
+// GET /api/leagues/:code/seasons/:seasonCode/standings - Get season standings
+func (h *Handler) getSeasonStandings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := h.getIDFromChiURL(r, "seasonCode")
+	if err != nil {
+		http.Error(w, "Invalid season code", http.StatusBadRequest)
+		return
+	}
+
+	standings, err := h.seasonService.GetSeasonStandings(r.Context(), leagueID, seasonID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "failed to get season standings")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.standingsToResponse(standings), http.StatusOK)
+}
+
+func (h *Handler) seasonToResponse(season *models.Season) seasonResponse {
+	resp := seasonResponse{
+		Code:      h.idCodeCache.GetByID(season.ID).Code,
+		Name:      season.Name,
+		StartDate: season.StartDate.Format("2006-01-02T15:04:05Z07:00"),
+		EndDate:   season.EndDate.Format("2006-01-02T15:04:05Z07:00"),
+		Status:    string(season.Status),
+		CreatedAt: season.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !season.ClosedAt.IsZero() {
+		resp.ClosedAt = season.ClosedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
@@ -0,0 +1,311 @@
+package gameapi
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultHeadToHeadLastGames = 10
+	maxHeadToHeadLastGames     = 100
+)
+
+type statsMemberResponse struct {
+	MembershipCode string `json:"membership_code"`
+	Alias          string `json:"alias"`
+}
+
+type headToHeadRecordResponse struct {
+	Games     int `json:"games"`
+	AAhead    int `json:"a_ahead"`
+	BAhead    int `json:"b_ahead"`
+	Ties      int `json:"ties"`
+	Teammates int `json:"teammates"`
+}
+
+type headToHeadGameTypeResponse struct {
+	GameTypeCode string `json:"game_type_code"`
+	GameTypeKey  string `json:"game_type_key"`
+	GameTypeName string `json:"game_type_name"`
+	headToHeadRecordResponse
+}
+
+type headToHeadGameResponse struct {
+	GameRoundCode string `json:"game_round_code"`
+	Name          string `json:"name"`
+	GameTypeCode  string `json:"game_type_code"`
+	GameTypeName  string `json:"game_type_name"`
+	PlayedAt      string `json:"played_at"`
+	APosition     int    `json:"a_position"`
+	BPosition     int    `json:"b_position"`
+	Outcome       string `json:"outcome"`
+}
+
+type headToHeadResponse struct {
+	MemberA    statsMemberResponse          `json:"member_a"`
+	MemberB    statsMemberResponse          `json:"member_b"`
+	Total      headToHeadRecordResponse     `json:"total"`
+	ByGameType []headToHeadGameTypeResponse `json:"by_game_type"`
+	LastGames  []headToHeadGameResponse     `json:"last_games"`
+}
+
+// GET /api/leagues/:code/members/:memberCode/vs/:otherCode?last=10 - Head-to-head record of two members
+func (h *Handler) getHeadToHead(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membershipA, err := h.getIDFromChiURL(r, "memberCode")
+	if err != nil {
+		http.Error(w, "Invalid member code", http.StatusBadRequest)
+		return
+	}
+	membershipB, err := h.getIDFromChiURL(r, "otherCode")
+	if err != nil {
+		http.Error(w, "Invalid member code", http.StatusBadRequest)
+		return
+	}
+
+	lastGames := defaultHeadToHeadLastGames
+	if lastParam := r.URL.Query().Get("last"); lastParam != "" {
+		lastGames, err = strconv.Atoi(lastParam)
+		if err != nil || lastGames < 0 || lastGames > maxHeadToHeadLastGames {
+			http.Error(w, "Invalid last games count", http.StatusBadRequest)
+			return
+		}
+	}
+
+	headToHead, err := h.statsService.HeadToHead(r.Context(), leagueID, membershipA, membershipB, lastGames)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to get head-to-head")
+		return
+	}
+
+	lang := requestLanguage(r)
+	getGameType := h.gameTypeLookup(r)
+
+	response := headToHeadResponse{
+		MemberA:    h.statsMemberToResponse(headToHead.MemberA),
+		MemberB:    h.statsMemberToResponse(headToHead.MemberB),
+		Total:      headToHeadRecordToResponse(headToHead.Total),
+		ByGameType: make([]headToHeadGameTypeResponse, 0, len(headToHead.ByGameType)),
+		LastGames:  make([]headToHeadGameResponse, 0, len(headToHead.LastGames)),
+	}
+
+	for _, item := range headToHead.ByGameType {
+		gameType, err := getGameType(item.GameTypeID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+			return
+		}
+		if gameType == nil {
+			// Game type was deleted, its rounds can't be presented
+			continue
+		}
+		response.ByGameType = append(response.ByGameType, headToHeadGameTypeResponse{
+			GameTypeCode:             h.idCodeCache.GetByID(gameType.ID).Code,
+			GameTypeKey:              gameType.Key,
+			GameTypeName:             gameType.GetName(lang),
+			headToHeadRecordResponse: headToHeadRecordToResponse(item.HeadToHeadRecord),
+		})
+	}
+
+	for _, game := range headToHead.LastGames {
+		gameType, err := getGameType(game.GameTypeID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+			return
+		}
+		resp := headToHeadGameResponse{
+			GameRoundCode: h.idCodeCache.GetByID(game.GameRoundID).Code,
+			Name:          game.Name,
+			PlayedAt:      game.PlayedAt.Format("2006-01-02T15:04:05Z07:00"),
+			APosition:     game.APosition,
+			BPosition:     game.BPosition,
+			Outcome:       string(game.Outcome),
+		}
+		if gameType != nil {
+			resp.GameTypeCode = h.idCodeCache.GetByID(gameType.ID).Code
+			resp.GameTypeName = gameType.GetName(lang)
+		}
+		response.LastGames = append(response.LastGames, resp)
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+type memberStatsGameTypeResponse struct {
+	GameTypeCode              string  `json:"game_type_code"`
+	GameTypeKey               string  `json:"game_type_key"`
+	GameTypeName              string  `json:"game_type_name"`
+	Games                     int     `json:"games"`
+	GamesModerated            int     `json:"games_moderated"`
+	Wins                      int     `json:"wins"`
+	Draws                     int     `json:"draws"`
+	Losses                    int     `json:"losses"`
+	RankedGames               int     `json:"ranked_games"`
+	AverageNormalizedPosition float64 `json:"average_normalized_position"`
+}
+
+type memberRoleStatsResponse struct {
+	Name  string `json:"name"`
+	Games int    `json:"games"`
+	Wins  int    `json:"wins"`
+}
+
+type memberPointsPointResponse struct {
+	GameRoundCode string `json:"game_round_code"`
+	PlayedAt      string `json:"played_at"`
+	Points        int64  `json:"points"`
+	TotalPoints   int64  `json:"total_points"`
+}
+
+type memberStatsResponse struct {
+	Member                    statsMemberResponse           `json:"member"`
+	Games                     int                           `json:"games"`
+	GamesModerated            int                           `json:"games_moderated"`
+	Wins                      int                           `json:"wins"`
+	Draws                     int                           `json:"draws"`
+	Losses                    int                           `json:"losses"`
+	WinRate                   float64                       `json:"win_rate"`
+	RankedGames               int                           `json:"ranked_games"`
+	AverageNormalizedPosition float64                       `json:"average_normalized_position"`
+	BestStreak                int                           `json:"best_streak"`
+	WorstStreak               int                           `json:"worst_streak"`
+	CurrentStreak             int                           `json:"current_streak"`
+	ByGameType                []memberStatsGameTypeResponse `json:"by_game_type"`
+	FavouriteRoles            []memberRoleStatsResponse     `json:"favourite_roles"`
+	FavouriteTeams            []memberRoleStatsResponse     `json:"favourite_teams"`
+	PointsOverTime            []memberPointsPointResponse   `json:"points_over_time"`
+}
+
+// GET /api/leagues/:code/members/:memberCode/stats - Profile statistics of a member
+func (h *Handler) getMemberStats(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	membershipID, err := h.getIDFromChiURL(r, "memberCode")
+	if err != nil {
+		http.Error(w, "Invalid member code", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.statsService.MemberStats(r.Context(), leagueID, membershipID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to get member stats")
+		return
+	}
+
+	response := memberStatsResponse{
+		Member:                    h.statsMemberToResponse(stats.Member),
+		Games:                     stats.Games,
+		GamesModerated:            stats.GamesModerated,
+		Wins:                      stats.Wins,
+		Draws:                     stats.Draws,
+		Losses:                    stats.Losses,
+		WinRate:                   roundRatio(stats.WinRate),
+		RankedGames:               stats.RankedGames,
+		AverageNormalizedPosition: roundRatio(stats.AverageNormalizedPosition),
+		BestStreak:                stats.BestStreak,
+		WorstStreak:               stats.WorstStreak,
+		CurrentStreak:             stats.CurrentStreak,
+		ByGameType:                make([]memberStatsGameTypeResponse, 0, len(stats.ByGameType)),
+		FavouriteRoles:            roleStatsToResponse(stats.FavouriteRoles),
+		FavouriteTeams:            roleStatsToResponse(stats.FavouriteTeams),
+		PointsOverTime:            make([]memberPointsPointResponse, 0, len(stats.PointsOverTime)),
+	}
+
+	lang := requestLanguage(r)
+	getGameType := h.gameTypeLookup(r)
+	for _, item := range stats.ByGameType {
+		gameType, err := getGameType(item.GameTypeID)
+		if err != nil {
+			utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching game type")
+			return
+		}
+		if gameType == nil {
+			// Game type was deleted, its rounds can't be presented
+			continue
+		}
+		response.ByGameType = append(response.ByGameType, memberStatsGameTypeResponse{
+			GameTypeCode:              h.idCodeCache.GetByID(gameType.ID).Code,
+			GameTypeKey:               gameType.Key,
+			GameTypeName:              gameType.GetName(lang),
+			Games:                     item.Games,
+			GamesModerated:            item.GamesModerated,
+			Wins:                      item.Wins,
+			Draws:                     item.Draws,
+			Losses:                    item.Losses,
+			RankedGames:               item.RankedGames,
+			AverageNormalizedPosition: roundRatio(item.AverageNormalizedPosition),
+		})
+	}
+
+	for _, point := range stats.PointsOverTime {
+		response.PointsOverTime = append(response.PointsOverTime, memberPointsPointResponse{
+			GameRoundCode: h.idCodeCache.GetByID(point.GameRoundID).Code,
+			PlayedAt:      point.PlayedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Points:        point.Points,
+			TotalPoints:   point.TotalPoints,
+		})
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+func roleStatsToResponse(roles []services.MemberRoleStats) []memberRoleStatsResponse {
+	response := make([]memberRoleStatsResponse, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, memberRoleStatsResponse{Name: role.Name, Games: role.Games, Wins: role.Wins})
+	}
+	return response
+}
+
+// roundRatio rounds a ratio to 3 decimal places
+func roundRatio(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// gameTypeLookup returns a game type getter which fetches every game type once per request
+func (h *Handler) gameTypeLookup(r *http.Request) func(id primitive.ObjectID) (*models.GameType, error) {
+	gameTypes := make(map[primitive.ObjectID]*models.GameType)
+	return func(id primitive.ObjectID) (*models.GameType, error) {
+		if gameType, ok := gameTypes[id]; ok {
+			return gameType, nil
+		}
+		gameType, err := h.gameTypeRepository.FindByID(r.Context(), id)
+		if err != nil {
+			return nil, err
+		}
+		gameTypes[id] = gameType
+		return gameType, nil
+	}
+}
+
+func (h *Handler) statsMemberToResponse(membership *models.LeagueMembership) statsMemberResponse {
+	return statsMemberResponse{
+		MembershipCode: h.idCodeCache.GetByID(membership.ID).Code,
+		Alias:          membership.Alias,
+	}
+}
+
+func headToHeadRecordToResponse(record services.HeadToHeadRecord) headToHeadRecordResponse {
+	return headToHeadRecordResponse{
+		Games:     record.Games,
+		AAhead:    record.AAhead,
+		BAhead:    record.BAhead,
+		Ties:      record.Ties,
+		Teammates: record.Teammates,
+	}
+}
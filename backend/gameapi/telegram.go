@@ -0,0 +1,163 @@
+package gameapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+)
+
+type telegramChatResponse struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	LinkedAt string `json:"linked_at"`
+}
+
+type telegramSettingsResponse struct {
+	Enabled     bool                   `json:"enabled"` // Whether the bot is configured on the server
+	BotUsername string                 `json:"bot_username,omitempty"`
+	Chats       []telegramChatResponse `json:"chats"`
+}
+
+type telegramLinkCodeResponse struct {
+	Code      string `json:"code"`
+	Command   string `json:"command"`        // Message to send to the bot in the chat
+	Link      string `json:"link,omitempty"` // Opens the bot to add it to a group with the code, when the bot username is known
+	ExpiresAt string `json:"expires_at"`
+}
+
+// GET /api/leagues/:code/telegram - Telegram chats linked to the league (league admin)
+func (h *Handler) getTelegramSettings(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	response := telegramSettingsResponse{Chats: []telegramChatResponse{}}
+	if h.telegramNotifier == nil || !h.telegramNotifier.Enabled() {
+		utils.WriteJSON(r, w, response, http.StatusOK)
+		return
+	}
+
+	chats, err := h.telegramNotifier.ListChats(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list Telegram chats")
+		return
+	}
+
+	response.Enabled = true
+	response.BotUsername = h.telegramNotifier.BotUsername()
+	for _, chat := range chats {
+		response.Chats = append(response.Chats, h.telegramChatToResponse(chat))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/telegram/link-code - Create a one-time code which links a Telegram chat to the league (league admin)
+func (h *Handler) createTelegramLinkCode(w http.ResponseWriter, r *http.Request) {
+	if h.telegramNotifier == nil || !h.telegramNotifier.Enabled() {
+		http.Error(w, "Telegram bot is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	linkCode, err := h.telegramNotifier.CreateLinkCode(r.Context(), leagueID, actorID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to create link code")
+		return
+	}
+
+	response := telegramLinkCodeResponse{
+		Code:      linkCode.Code,
+		Command:   "/link " + linkCode.Code,
+		ExpiresAt: linkCode.ExpiresAt.Format(time.RFC3339),
+	}
+	if username := h.telegramNotifier.BotUsername(); username != "" {
+		response.Link = fmt.Sprintf("https://t.me/%s?startgroup=%s", url.PathEscape(username), url.QueryEscape(linkCode.Code))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusCreated)
+}
+
+// DELETE /api/leagues/:code/telegram/chats/:chatCode - Stop posting league results to the Telegram chat (league admin)
+func (h *Handler) unlinkTelegramChat(w http.ResponseWriter, r *http.Request) {
+	if h.telegramNotifier == nil {
+		http.Error(w, "Telegram bot is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	linkID, err := h.getIDFromChiURL(r, "chatCode")
+	if err != nil {
+		http.Error(w, "Invalid chat code", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.telegramNotifier.UnlinkChat(r.Context(), leagueID, linkID, actorID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "failed to unlink Telegram chat")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/telegram/webhook - Updates of the Telegram bot (public, checked by the secret token given to setWebhook)
+func (h *Handler) telegramWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.telegramNotifier == nil || !h.telegramNotifier.Enabled() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !h.telegramNotifier.CheckWebhookSecret(r.Header.Get(utils.TelegramSecretTokenHeader)) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var update utils.TelegramUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Telegram repeats updates which weren't answered with 200, a failed command isn't worth repeating
+	if err := h.telegramNotifier.HandleUpdate(r.Context(), &update); err != nil {
+		glog.Warn("Failed to handle Telegram update %d: %v", update.UpdateID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) telegramChatToResponse(chat *models.TelegramChat) telegramChatResponse {
+	return telegramChatResponse{
+		Code:     h.idCodeCache.GetByID(chat.ID).Code,
+		Title:    chat.Title,
+		LinkedAt: chat.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,105 @@
+package gameapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTelegramWebhook(t *testing.T) {
+	// Stubbed Bot API which records the texts sent to chats
+	var sent []string
+	botAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			Text string `json:"text"`
+		}
+		if assert.Equal(t, "/bot1:token/sendMessage", r.URL.Path) && assert.NoError(t, json.NewDecoder(r.Body).Decode(&message)) {
+			sent = append(sent, message.Text)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer botAPI.Close()
+
+	mockLinkCodeRepo := new(mocks.MockTelegramLinkCodeRepository)
+	mockLinkCodeRepo.On("Consume", mock.Anything, "expired", mock.Anything).Return(nil, nil)
+	config := services.TelegramConfig{BotToken: "1:token", WebhookSecret: "hook-secret", APIURL: botAPI.URL}
+	handler := &Handler{
+		idCodeCache: services.NewIdAndCodeCache(),
+		telegramNotifier: services.NewTelegramNotifier(config, &membersLeagueService{}, new(mocks.MockTelegramChatRepository), mockLinkCodeRepo,
+			new(mocks.MockGameTypeRepository), services.NewNoopAuditService()),
+	}
+	router := chi.NewRouter()
+	router.Route("/api", handler.RegisterPublicRoutes)
+
+	postUpdate := func(secret, text string) int {
+		body, _ := json.Marshal(utils.TelegramUpdate{UpdateID: 1, Message: &utils.TelegramMessage{
+			From: &utils.TelegramUser{ID: 7, LanguageCode: "uk"},
+			Chat: utils.TelegramChat{ID: -100, Type: "group", Title: "Board gamers"},
+			Text: text,
+		}})
+		request := httptest.NewRequest("POST", "/api/telegram/webhook", bytes.NewBuffer(body))
+		if secret != "" {
+			request.Header.Set(utils.TelegramSecretTokenHeader, secret)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, request)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, postUpdate("", "/link expired"))
+	assert.Equal(t, http.StatusForbidden, postUpdate("guess", "/link expired"))
+	assert.Empty(t, sent)
+
+	assert.Equal(t, http.StatusOK, postUpdate("hook-secret", "/link expired"))
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "Код недійсний або прострочений, створіть новий у налаштуваннях ліги", sent[0])
+	}
+
+	// Without a bot the endpoint doesn't exist
+	handler.telegramNotifier = services.NewTelegramNotifier(services.TelegramConfig{}, &membersLeagueService{}, nil, nil, nil, services.NewNoopAuditService())
+	assert.Equal(t, http.StatusNotFound, postUpdate("hook-secret", "/help"))
+}
+
+func TestCreateTelegramLinkCode(t *testing.T) {
+	leagueID, userID := primitive.NewObjectID(), primitive.NewObjectID()
+	mockLinkCodeRepo := new(mocks.MockTelegramLinkCodeRepository)
+	mockLinkCodeRepo.On("Create", mock.Anything, mock.MatchedBy(func(code *models.TelegramLinkCode) bool {
+		return code.LeagueID == leagueID && code.CreatedBy == userID && code.Code != ""
+	})).Return(nil)
+
+	config := services.TelegramConfig{BotToken: "1:token", BotUsername: "bgl_bot"}
+	handler := &Handler{
+		idCodeCache: services.NewIdAndCodeCache(),
+		telegramNotifier: services.NewTelegramNotifier(config, &membersLeagueService{}, new(mocks.MockTelegramChatRepository), mockLinkCodeRepo,
+			new(mocks.MockGameTypeRepository), services.NewNoopAuditService()),
+	}
+	router := chi.NewRouter()
+	router.Post("/leagues/{code}/telegram/link-code", handler.createTelegramLinkCode)
+
+	request := httptest.NewRequest("POST", "/leagues/"+utils.IdToCode(leagueID)+"/telegram/link-code", nil)
+	request = request.WithContext(context.WithValue(request.Context(), "user", &user_profile.UserProfile{Code: utils.IdToCode(userID)}))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, request)
+
+	if assert.Equal(t, http.StatusCreated, rr.Code) {
+		var response telegramLinkCodeResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Code)
+		assert.Equal(t, "/link "+response.Code, response.Command)
+		assert.Equal(t, "https://t.me/bgl_bot?startgroup="+response.Code, response.Link)
+		assert.NotEmpty(t, response.ExpiresAt)
+	}
+}
@@ -0,0 +1,288 @@
+package gameapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+type webhookRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type webhookResponse struct {
+	Code        string   `json:"code"`
+	URL         string   `json:"url"`
+	Description string   `json:"description,omitempty"`
+	Events      []string `json:"events"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"` // Only returned when the webhook is created
+	CreatedAt   string   `json:"created_at"`
+}
+
+type webhookAttemptResponse struct {
+	At         string `json:"at"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type webhookDeliveryResponse struct {
+	Code          string                   `json:"code"`
+	EventID       string                   `json:"event_id"`
+	EventType     string                   `json:"event_type"`
+	Status        string                   `json:"status"`
+	Payload       json.RawMessage          `json:"payload"`
+	Attempts      []webhookAttemptResponse `json:"attempts"`
+	NextAttemptAt string                   `json:"next_attempt_at,omitempty"`
+	CreatedAt     string                   `json:"created_at"`
+}
+
+// GET /api/leagues/:code/webhooks - List league webhooks (league admin)
+func (h *Handler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	subscriptions, err := h.webhookService.ListSubscriptions(r.Context(), leagueID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "failed to list webhooks")
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, h.webhookToResponse(subscription))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/webhooks - Create league webhook, the response has its signing secret (league admin)
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return
+	}
+
+	input, ok := parseWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(r.Context(), leagueID, actorID, input)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to create webhook")
+		return
+	}
+
+	response := h.webhookToResponse(subscription)
+	response.Secret = subscription.Secret
+	utils.WriteJSON(r, w, response, http.StatusCreated)
+}
+
+// PUT /api/leagues/:code/webhooks/:webhookCode - Update league webhook (league admin)
+func (h *Handler) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, subscriptionID, ok := h.parseWebhookURL(w, r)
+	if !ok {
+		return
+	}
+
+	input, ok := parseWebhookRequest(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(r.Context(), leagueID, subscriptionID, actorID, input)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to update webhook")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.webhookToResponse(subscription), http.StatusOK)
+}
+
+// DELETE /api/leagues/:code/webhooks/:webhookCode - Delete league webhook with its delivery log (league admin)
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	leagueID, subscriptionID, ok := h.parseWebhookURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), leagueID, subscriptionID, actorID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/leagues/:code/webhooks/:webhookCode/deliveries?limit=50 - Latest deliveries of the webhook (league admin)
+func (h *Handler) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	leagueID, subscriptionID, ok := h.parseWebhookURL(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultWebhookDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxWebhookDeliveriesLimit {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), leagueID, subscriptionID, int64(limit))
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "failed to list webhook deliveries")
+		return
+	}
+
+	response := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, h.webhookDeliveryToResponse(delivery))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// POST /api/leagues/:code/webhooks/:webhookCode/test - Send a test event right away and return the delivery (league admin)
+func (h *Handler) sendWebhookTestEvent(w http.ResponseWriter, r *http.Request) {
+	leagueID, subscriptionID, ok := h.parseWebhookURL(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTestEvent(r.Context(), leagueID, subscriptionID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusBadRequest, err, "failed to send test event")
+		return
+	}
+
+	utils.WriteJSON(r, w, h.webhookDeliveryToResponse(delivery), http.StatusOK)
+}
+
+func parseWebhookRequest(w http.ResponseWriter, r *http.Request) (services.WebhookInput, bool) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return services.WebhookInput{}, false
+	}
+
+	events := make([]models.WebhookEventType, 0, len(req.Events))
+	for _, event := range req.Events {
+		events = append(events, models.WebhookEventType(event))
+	}
+
+	return services.WebhookInput{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      events,
+		Active:      req.Active,
+	}, true
+}
+
+func (h *Handler) parseWebhookURL(w http.ResponseWriter, r *http.Request) (leagueID, subscriptionID primitive.ObjectID, ok bool) {
+	leagueID, err := h.getIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid league code", http.StatusBadRequest)
+		return leagueID, subscriptionID, false
+	}
+
+	subscriptionID, err = h.getIDFromChiURL(r, "webhookCode")
+	if err != nil {
+		http.Error(w, "Invalid webhook code", http.StatusBadRequest)
+		return leagueID, subscriptionID, false
+	}
+
+	return leagueID, subscriptionID, true
+}
+
+func (h *Handler) webhookToResponse(subscription *models.WebhookSubscription) webhookResponse {
+	events := make([]string, 0, len(subscription.Events))
+	for _, event := range subscription.Events {
+		events = append(events, string(event))
+	}
+
+	return webhookResponse{
+		Code:        h.idCodeCache.GetByID(subscription.ID).Code,
+		URL:         subscription.URL,
+		Description: subscription.Description,
+		Events:      events,
+		Active:      subscription.Active,
+		CreatedAt:   subscription.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) webhookDeliveryToResponse(delivery *models.WebhookDelivery) webhookDeliveryResponse {
+	response := webhookDeliveryResponse{
+		Code:      h.idCodeCache.GetByID(delivery.ID).Code,
+		EventID:   delivery.EventID,
+		EventType: string(delivery.EventType),
+		Status:    string(delivery.Status),
+		Payload:   json.RawMessage(delivery.Payload),
+		Attempts:  make([]webhookAttemptResponse, 0, len(delivery.Attempts)),
+		CreatedAt: delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if !delivery.NextAttemptAt.IsZero() {
+		response.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	for _, attempt := range delivery.Attempts {
+		response.Attempts = append(response.Attempts, webhookAttemptResponse{
+			At:         attempt.At.Format(time.RFC3339),
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMs,
+		})
+	}
+	return response
+}
+
+// publishInBackground queues a league event for the webhooks without delaying the response, failures are only logged
+func (h *Handler) publishInBackground(subject string, publish func(ctx context.Context, webhooks services.WebhookService) error) {
+	if h.webhookService == nil {
+		return
+	}
+
+	go func() {
+		if err := publish(context.Background(), h.webhookService); err != nil {
+			glog.Warn("Failed to publish %s to webhooks: %v", subject, err)
+		}
+	}()
+}
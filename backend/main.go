@@ -145,11 +145,61 @@ func main() {
 		log.Fatal("Failed to initialise leagueInvitationRepository %v", err)
 	}
 
+	seasonRepository, err := repositories.NewSeasonRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise seasonRepository %v", err)
+	}
+
+	standingsSnapshotRepository, err := repositories.NewStandingsSnapshotRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise standingsSnapshotRepository %v", err)
+	}
+
+	auditLogRepository, err := repositories.NewAuditLogRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise auditLogRepository %v", err)
+	}
+
+	notificationRepository, err := repositories.NewNotificationRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise notificationRepository %v", err)
+	}
+
 	wizardGameRepository, err := repositories.NewWizardGameRepository(mongodb)
 	if err != nil {
 		log.Fatal("Failed to initialise wizardGameRepository %v", err)
 	}
 
+	membershipMergeRepository, err := repositories.NewMembershipMergeRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise membershipMergeRepository %v", err)
+	}
+
+	gameNightRepository, err := repositories.NewGameNightRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise gameNightRepository %v", err)
+	}
+
+	webhookSubscriptionRepository, err := repositories.NewWebhookSubscriptionRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise webhookSubscriptionRepository %v", err)
+	}
+
+	webhookDeliveryRepository, err := repositories.NewWebhookDeliveryRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise webhookDeliveryRepository %v", err)
+	}
+
+	telegramChatRepository, err := repositories.NewTelegramChatRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise telegramChatRepository %v", err)
+	}
+
+	telegramLinkCodeRepository, err := repositories.NewTelegramLinkCodeRepository(mongodb)
+	if err != nil {
+		log.Fatal("Failed to initialise telegramLinkCodeRepository %v", err)
+	}
+
 	log.Info("Database connector initialised")
 
 	// Initialize caches first (needed for services)
@@ -162,14 +212,32 @@ func main() {
 	sessionService := services.NewSessionService(sessionRepository, userRepository)
 	requestService := services.NewRequestService()
 	geoIPService := services.NewGeoIPService()
-	leagueService := services.NewLeagueService(
-		leagueRepository,
-		leagueMembershipRepository,
-		leagueInvitationRepository,
-		userRepository,
-		gameRoundRepository,
-	)
+	auditService := services.NewAuditService(auditLogRepository)
+	leagueService := services.NewLeagueService(services.LeagueServiceDeps{
+		LeagueRepo:      leagueRepository,
+		MembershipRepo:  leagueMembershipRepository,
+		InvitationRepo:  leagueInvitationRepository,
+		UserRepo:        userRepository,
+		GameRoundRepo:   gameRoundRepository,
+		GameTypeRepo:    gameTypeRepository,
+		WizardGameRepo:  wizardGameRepository,
+		MergeRepo:       membershipMergeRepository,
+		NightRepo:       gameNightRepository,
+		AuditService:    auditService,
+		MembershipCache: membershipCache,
+	})
 
+	seasonService := services.NewSeasonService(seasonRepository, leagueService)
+	notificationHub := services.NewGameEventHub()
+	notificationService := services.NewNotificationService(notificationRepository, leagueRepository, leagueMembershipRepository, notificationHub)
+	webhookService := services.NewWebhookService(webhookSubscriptionRepository, webhookDeliveryRepository, leagueMembershipRepository, gameTypeRepository, auditService)
+	discordNotifier := services.NewDiscordNotifier(leagueService, leagueRepository, gameTypeRepository, auditService)
+	telegramNotifier := services.NewTelegramNotifier(services.TelegramConfigFromEnv(), leagueService, telegramChatRepository, telegramLinkCodeRepository, gameTypeRepository, auditService)
+	chatNotifiers := services.ChatNotifiers{discordNotifier, telegramNotifier}
+	standingsHistoryService := services.NewStandingsHistoryService(standingsSnapshotRepository, leagueService, notificationService, webhookService)
+	statsService := services.NewStatsService(leagueRepository, gameRoundRepository, gameTypeRepository, leagueMembershipRepository)
+	leagueArchiveService := services.NewLeagueArchiveService(leagueRepository, leagueMembershipRepository, leagueInvitationRepository, userRepository, gameRoundRepository, gameTypeRepository, wizardGameRepository, gameNightRepository, auditService)
+	gameNightService := services.NewGameNightService(gameNightRepository, leagueMembershipRepository, gameRoundRepository, gameTypeRepository, auditService)
 	gameTypeService := services.NewGameTypeService(gameTypeRepository)
 	gameEventHub := services.NewGameEventHub()
 
@@ -180,6 +248,13 @@ func main() {
 		log.Warn("Failed to load built-in game types: %v", err)
 	}
 
+	// Leagues created before league roles have nobody to manage invitations and members
+	if assigned, err := leagueService.AssignMissingOwners(context.Background()); err != nil {
+		log.Warn("Failed to assign owners to leagues: %v", err)
+	} else if assigned > 0 {
+		log.Info("Assigned owners to %d leagues", assigned)
+	}
+
 	// Initialize cache cleanup service
 	cacheCleanupService := services.NewCacheCleanupService()
 
@@ -249,10 +324,40 @@ func main() {
 	// Create league middleware
 	leagueMiddleware := bglmiddleware.NewLeagueMiddleware(leagueService, idCodeCache)
 
-	gameApiHandler := gameapi.NewHandler(userService, gameRoundRepository, gameTypeRepository, leagueService, leagueMiddleware, idCodeCache)
-	wizardApiHandler := wizardapi.NewHandler(wizardGameRepository, gameRoundRepository, gameTypeRepository, leagueService, userService, idCodeCache, gameEventHub)
+	gameApiHandler := gameapi.NewHandler(gameapi.HandlerDeps{
+		UserService:         userService,
+		GameRoundRepository: gameRoundRepository,
+		GameTypeRepository:  gameTypeRepository,
+		LeagueService:       leagueService,
+		SeasonService:       seasonService,
+		StatsService:        statsService,
+		StandingsHistory:    standingsHistoryService,
+		LeagueArchive:       leagueArchiveService,
+		GameNightService:    gameNightService,
+		AuditService:        auditService,
+		NotificationService: notificationService,
+		WebhookService:      webhookService,
+		DiscordNotifier:     discordNotifier,
+		TelegramNotifier:    telegramNotifier,
+		LeagueMiddleware:    leagueMiddleware,
+		IdCodeCache:         idCodeCache,
+	})
+	wizardApiHandler := wizardapi.NewHandler(wizardapi.HandlerDeps{
+		WizardRepo:              wizardGameRepository,
+		GameRoundRepo:           gameRoundRepository,
+		GameTypeRepo:            gameTypeRepository,
+		LeagueService:           leagueService,
+		UserService:             userService,
+		IdCodeCache:             idCodeCache,
+		EventHub:                gameEventHub,
+		StandingsHistoryService: standingsHistoryService,
+		AuditService:            auditService,
+		NotificationService:     notificationService,
+		WebhookService:          webhookService,
+		ChatNotifier:            chatNotifiers,
+	})
 	authHandler := auth.NewDefaultHandler(userRepository, sessionService, requestService)
-	userProfileHandler := userapi.NewHandlerWithServices(userRepository, sessionRepository, geoIPService)
+	userProfileHandler := userapi.NewHandlerWithServices(userRepository, sessionRepository, geoIPService, notificationService, notificationHub)
 	diagnosticsHandler := api.NewDiagnosticsHandler(requestService, geoIPService, cacheCleanupService)
 	serverAdminHandler := api.NewServerAdminHandler()
 
@@ -289,6 +394,7 @@ func main() {
 
 			r.Post("/user/alias/exist", userProfileHandler.CheckAliasUniquenessHandler)
 			r.Put("/user/update", userProfileHandler.UpdateUser)
+			userProfileHandler.RegisterNotificationRoutes(r)
 
 			r.Put("/admin/user/create", userProfileHandler.AdminCreateUserHandler)
 			r.Get("/admin/diagnostics", diagnosticsHandler.GetDiagnosticsHandler)
@@ -345,6 +451,13 @@ func main() {
 	cacheCleanupService.Start(ctx, 15*time.Minute)
 	log.Info("Cache cleanup service started")
 
+	// Deliver queued webhook events, retries are checked every 30 seconds
+	webhookService.Start(ctx, 30*time.Second)
+	log.Info("Webhook delivery started")
+
+	// Post finalized game results to league Discord channels and Telegram chats
+	chatNotifiers.Start(ctx)
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/andriyg76/bgl/auth"
+	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/services"
 	"github.com/andriyg76/bgl/user_profile"
 	"github.com/go-chi/chi/v5"
@@ -95,6 +97,44 @@ func (m *LeagueMiddleware) RequireLeagueMembership(next http.Handler) http.Handl
 	})
 }
 
+// RequireLeagueRole verifies that the authenticated user has at least the given role in the league,
+// superadmins are allowed regardless of their membership. Must be used after RequireLeagueMembership
+func (m *LeagueMiddleware) RequireLeagueRole(role models.LeagueRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value("user").(*user_profile.UserProfile); !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !HasLeagueRole(r, role) {
+				http.Error(w, fmt.Sprintf("League %s role required", role), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasLeagueRole checks if the authenticated user has at least the given role in the league loaded into the request
+// context by RequireLeagueMembership, superadmins have every role
+func HasLeagueRole(r *http.Request, role models.LeagueRole) bool {
+	profile, ok := r.Context().Value("user").(*user_profile.UserProfile)
+	if !ok || profile == nil {
+		return false
+	}
+	if auth.IsSuperAdminByExternalIDs(profile.ExternalIDs) {
+		return true
+	}
+
+	membership, ok := r.Context().Value("membership").(*models.LeagueMembership)
+	if !ok || membership == nil || membership.Status != models.MembershipActive {
+		return false
+	}
+	return membership.EffectiveRole().AtLeast(role)
+}
+
 // RequireSuperAdmin verifies that the authenticated user has superadmin privileges
 func (m *LeagueMiddleware) RequireSuperAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog is a recorded user action, removed by MongoDB once ExpiresAt has passed
+type AuditLog struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty"`
+	LeagueID   primitive.ObjectID     `bson:"league_id,omitempty"` // League the action belongs to, if any
+	ActorID    primitive.ObjectID     `bson:"actor_id,omitempty"`  // User who performed the action
+	Action     string                 `bson:"action"`
+	TargetType string                 `bson:"target_type"`
+	TargetID   primitive.ObjectID     `bson:"target_id,omitempty"`
+	Details    map[string]interface{} `bson:"details,omitempty"`
+	CreatedAt  time.Time              `bson:"created_at"`
+	ExpiresAt  time.Time              `bson:"expires_at"`
+}
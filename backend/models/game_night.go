@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GameNightStatus string
+
+const (
+	GameNightScheduled GameNightStatus = "scheduled"
+	GameNightCancelled GameNightStatus = "cancelled"
+)
+
+// RSVPResponse is the answer of a member to a game night invitation
+type RSVPResponse string
+
+const (
+	RSVPYes   RSVPResponse = "yes"
+	RSVPNo    RSVPResponse = "no"
+	RSVPMaybe RSVPResponse = "maybe"
+)
+
+// IsValid checks if the response is one of the known RSVP answers
+func (r RSVPResponse) IsValid() bool {
+	return r == RSVPYes || r == RSVPNo || r == RSVPMaybe
+}
+
+// GameNightRSVP is the answer of one league member
+type GameNightRSVP struct {
+	MembershipID primitive.ObjectID `bson:"membership_id"`
+	Response     RSVPResponse       `bson:"response"`
+	RespondedAt  time.Time          `bson:"responded_at"`
+}
+
+// GameNight is a scheduled game session of a league.
+// Times are stored in UTC, Timezone is the IANA zone the night was planned in and is used to show local times
+type GameNight struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Version   int64              `bson:"version"`
+	LeagueID  primitive.ObjectID `bson:"league_id"`
+	Title     string             `bson:"title"`
+	StartTime time.Time          `bson:"start_time"`
+	EndTime   time.Time          `bson:"end_time"`
+	Timezone  string             `bson:"timezone"`
+	Location  string             `bson:"location,omitempty"`
+	Notes     string             `bson:"notes,omitempty"`
+	Status    GameNightStatus    `bson:"status"`
+	RSVPs     []GameNightRSVP    `bson:"rsvps,omitempty"`
+	CreatedBy primitive.ObjectID `bson:"created_by"` // User who scheduled the night
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+// RSVPOf returns the answer of the member, nil if the member didn't answer yet
+func (n *GameNight) RSVPOf(membershipID primitive.ObjectID) *GameNightRSVP {
+	for i := range n.RSVPs {
+		if n.RSVPs[i].MembershipID == membershipID {
+			return &n.RSVPs[i]
+		}
+	}
+	return nil
+}
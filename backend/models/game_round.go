@@ -69,6 +69,9 @@ type GameRound struct {
 	Players          []GameRoundPlayer  `bson:"players" json:"players"`
 	TeamScores       []TeamScore        `bson:"team_scores,omitempty" json:"team_scores,omitempty"`
 	CooperativeScore int64              `bson:"cooperative_score,omitempty" json:"cooperative_score,omitempty"`
+	CooperativeWin   *bool              `bson:"cooperative_win,omitempty" json:"cooperative_win,omitempty"` // Group result of a cooperative game, nil - not set
+	NightID          primitive.ObjectID `bson:"night_id,omitempty" json:"-"`
+	NightCode        string             `bson:"-" json:"night_code,omitempty"` // Game night the round was played on (populated from NightID)
 	CreatedAt        time.Time          `bson:"created_at" json:"created_at,omitempty"`
 	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at,omitempty"`
 }
@@ -59,9 +59,9 @@ type Role struct {
 // Label - застаріла структура, залишена для сумісності
 // Deprecated: використовуйте Role
 type Label struct {
-	Name  string `bson:"name"`
-	Color string `bson:"color"`
-	Icon  string `bson:"icon"`
+	Name  string `bson:"name" json:"name"`
+	Color string `bson:"color" json:"color"`
+	Icon  string `bson:"icon" json:"icon"`
 }
 
 // GameType визначає тип настільної гри
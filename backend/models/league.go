@@ -12,11 +12,45 @@ const (
 	LeagueArchived LeagueStatus = "archived"
 )
 
+// LeaguePointsConfig is a league-specific points system used for standings.
+// PositionPoints[0] is awarded for the 1st place, PositionPoints[1] for the 2nd and so on;
+// positions beyond the table earn no position points.
+type LeaguePointsConfig struct {
+	ParticipationPoints int64   `bson:"participation_points" json:"participation_points"`
+	ModerationPoints    int64   `bson:"moderation_points" json:"moderation_points"`
+	PositionPoints      []int64 `bson:"position_points" json:"position_points"`
+	// Team result points for team_vs_team and mafia games, nil in configs saved before team scoring - the default
+	TeamWinPoints  *int64 `bson:"team_win_points,omitempty" json:"team_win_points,omitempty"`
+	TeamDrawPoints *int64 `bson:"team_draw_points,omitempty" json:"team_draw_points,omitempty"`
+	TeamLossPoints *int64 `bson:"team_loss_points,omitempty" json:"team_loss_points,omitempty"`
+	// Points for every player when the group wins a cooperative game, nil in configs saved before cooperative scoring
+	CoopWinPoints *int64 `bson:"coop_win_points,omitempty" json:"coop_win_points,omitempty"`
+}
+
 type League struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Version   int64              `bson:"version"`
-	Name      string             `bson:"name"`
-	Status    LeagueStatus       `bson:"status"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID           primitive.ObjectID  `bson:"_id,omitempty"`
+	Version      int64               `bson:"version"`
+	Name         string              `bson:"name"`
+	Status       LeagueStatus        `bson:"status"`
+	PointsConfig *LeaguePointsConfig `bson:"points_config"`       // nil - the default points system
+	IsPublic     bool                `bson:"is_public,omitempty"` // Listed for discovery, users can request to join
+	Language     string              `bson:"language,omitempty"`  // Language of messages sent outside of the app, empty - DefaultLeagueLanguage
+	// Discord channel webhook receiving results of finalized games, empty - not connected
+	DiscordWebhookURL string    `bson:"discord_webhook_url,omitempty"`
+	CreatedAt         time.Time `bson:"created_at"`
+	UpdatedAt         time.Time `bson:"updated_at"`
+}
+
+// DefaultLeagueLanguage is used by leagues which didn't choose a language
+const DefaultLeagueLanguage = "en"
+
+// LeagueLanguages are the languages a league can choose, the same as the UI languages
+var LeagueLanguages = []string{"en", "uk", "et"}
+
+// GetLanguage returns the league language, DefaultLeagueLanguage if it's not chosen
+func (l *League) GetLanguage() string {
+	if l.Language == "" {
+		return DefaultLeagueLanguage
+	}
+	return l.Language
 }
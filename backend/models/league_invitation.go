@@ -19,4 +19,33 @@ type LeagueInvitation struct {
 	ExpiresAt    time.Time          `bson:"expires_at"`
 	CreatedAt    time.Time          `bson:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at"`
+
+	// Reusable join links, MaxUses is 0 for single-use personal invitations
+	MaxUses          int             `bson:"max_uses,omitempty"`
+	RequiresApproval bool            `bson:"requires_approval,omitempty"` // Joined players wait for league admin approval
+	Uses             []InvitationUse `bson:"uses,omitempty"`
+}
+
+// InvitationUse is a membership created by accepting a reusable join link
+type InvitationUse struct {
+	UserID       primitive.ObjectID `bson:"user_id"`
+	MembershipID primitive.ObjectID `bson:"membership_id"`
+	Alias        string             `bson:"alias"`
+	UsedAt       time.Time          `bson:"used_at"`
+}
+
+// IsReusable checks if the invitation is a join link that can be accepted by several players
+func (i *LeagueInvitation) IsReusable() bool {
+	return i.MaxUses > 0
+}
+
+// RemainingUses returns how many more times the invitation can be accepted, ignoring expiration
+func (i *LeagueInvitation) RemainingUses() int {
+	if i.IsReusable() {
+		return max(i.MaxUses-len(i.Uses), 0)
+	}
+	if i.IsUsed {
+		return 0
+	}
+	return 1
 }
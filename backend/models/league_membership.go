@@ -12,8 +12,35 @@ const (
 	MembershipBanned  LeagueMembershipStatus = "banned"
 	MembershipPending LeagueMembershipStatus = "pending"
 	MembershipVirtual LeagueMembershipStatus = "virtual"
+	MembershipLeft    LeagueMembershipStatus = "left" // The user left the league, game history stays under the alias
 )
 
+// LeagueRole is the role of a member within the league, independent of the global superadmin role
+type LeagueRole string
+
+const (
+	LeagueRoleOwner  LeagueRole = "owner"
+	LeagueRoleAdmin  LeagueRole = "admin"
+	LeagueRoleMember LeagueRole = "member"
+)
+
+var leagueRoleLevels = map[LeagueRole]int{
+	LeagueRoleMember: 1,
+	LeagueRoleAdmin:  2,
+	LeagueRoleOwner:  3,
+}
+
+// IsValid checks if the role is one of the known league roles
+func (r LeagueRole) IsValid() bool {
+	_, ok := leagueRoleLevels[r]
+	return ok
+}
+
+// AtLeast checks if the role grants at least the privileges of the other role
+func (r LeagueRole) AtLeast(other LeagueRole) bool {
+	return leagueRoleLevels[r] >= leagueRoleLevels[other]
+}
+
 // RecentCoPlayer represents a player who recently played with this member
 type RecentCoPlayer struct {
 	MembershipID primitive.ObjectID `bson:"membership_id"`
@@ -31,9 +58,30 @@ type LeagueMembership struct {
 	InvitationID    primitive.ObjectID     `bson:"invitation_id,omitempty"`
 	Alias           string                 `bson:"alias,omitempty"`
 	Status          LeagueMembershipStatus `bson:"status"`
+	Role            LeagueRole             `bson:"role,omitempty"` // Empty for memberships created before roles, means member
 	JoinedAt        time.Time              `bson:"joined_at"`
 	CreatedAt       time.Time              `bson:"created_at"`
 	UpdatedAt       time.Time              `bson:"updated_at"`
 	LastActivityAt  time.Time              `bson:"last_activity_at,omitempty"`  // Last game or invitation activity
+	LeftAt          time.Time              `bson:"left_at,omitempty"`           // When the user left the league, zero if never left or rejoined
 	RecentCoPlayers []RecentCoPlayer       `bson:"recent_co_players,omitempty"` // Max 10 recent co-players
+	CalendarToken   string                 `bson:"calendar_token,omitempty"`    // Secret of the personal calendar feed, empty until requested
+}
+
+// EffectiveRole returns the role of the member, memberships without a role are ordinary members
+func (m *LeagueMembership) EffectiveRole() LeagueRole {
+	if m.Role == "" {
+		return LeagueRoleMember
+	}
+	return m.Role
+}
+
+// HasLeft checks if the user left the league or asked to rejoin it after leaving
+func (m *LeagueMembership) HasLeft() bool {
+	return !m.LeftAt.IsZero()
+}
+
+// AwaitsApproval checks if a user joined the league and waits for a league admin to approve the membership
+func (m *LeagueMembership) AwaitsApproval() bool {
+	return m.Status == MembershipPending && !m.UserID.IsZero()
 }
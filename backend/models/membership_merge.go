@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CoPlayersBackup keeps recent co-players of a member as they were before a merge
+type CoPlayersBackup struct {
+	MembershipID    primitive.ObjectID `bson:"membership_id"`
+	RecentCoPlayers []RecentCoPlayer   `bson:"recent_co_players,omitempty"`
+}
+
+// MembershipMerge records a virtual or pending membership merged into another membership of the league,
+// holds everything needed to undo or resume it and is removed by MongoDB once UndoUntil has passed
+type MembershipMerge struct {
+	ID            primitive.ObjectID   `bson:"_id,omitempty"`
+	LeagueID      primitive.ObjectID   `bson:"league_id"`
+	Source        LeagueMembership     `bson:"source"` // The deleted membership as it was
+	Target        LeagueMembership     `bson:"target"` // The target membership before the merge
+	GameRoundIDs  []primitive.ObjectID `bson:"game_round_ids,omitempty"`
+	WizardGameIDs []primitive.ObjectID `bson:"wizard_game_ids,omitempty"`
+	CoPlayers     []CoPlayersBackup    `bson:"co_players,omitempty"` // Other members whose recent co-players pointed to the source
+	MergedBy      primitive.ObjectID   `bson:"merged_by"`
+	MergedAt      time.Time            `bson:"merged_at"`
+	UndoUntil     time.Time            `bson:"undo_until"`
+	Completed     bool                 `bson:"completed"` // False while the merge is in progress or was interrupted
+}
@@ -8,17 +8,21 @@ import (
 type NotificationType string
 
 const (
-	NotificationLeagueJoin NotificationType = "league_join"
-	NotificationLeagueBan  NotificationType = "league_ban"
+	NotificationLeagueJoin    NotificationType = "league_join"    // Somebody accepted your invitation
+	NotificationLeagueBan     NotificationType = "league_ban"     // You were banned from a league
+	NotificationGameFinalized NotificationType = "game_finalized" // A game you played was finalized
+	NotificationRankChanged   NotificationType = "rank_changed"   // Your place in league standings changed
+	NotificationJoinRequest   NotificationType = "join_request"   // Somebody requested to join your league, or your request was decided
 )
 
 type Notification struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	UserID    primitive.ObjectID `bson:"user_id"`
-	Type      NotificationType   `bson:"type"`
-	Title     string             `bson:"title"`
-	Message   string             `bson:"message"`
-	LeagueID  primitive.ObjectID `bson:"league_id,omitempty"`
-	IsRead    bool               `bson:"is_read"`
-	CreatedAt time.Time          `bson:"created_at"`
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id"`
+	Type        NotificationType   `bson:"type"`
+	Title       string             `bson:"title"`
+	Message     string             `bson:"message"`
+	LeagueID    primitive.ObjectID `bson:"league_id,omitempty"`
+	GameRoundID primitive.ObjectID `bson:"game_round_id,omitempty"`
+	IsRead      bool               `bson:"is_read"`
+	CreatedAt   time.Time          `bson:"created_at"`
 }
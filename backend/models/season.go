@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SeasonStatus string
+
+const (
+	SeasonOpen   SeasonStatus = "open"
+	SeasonClosed SeasonStatus = "closed"
+)
+
+// Season is a time-boxed standings period inside a league.
+// Only rounds finished between StartDate and EndDate (inclusive) count for the season.
+type Season struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	Version        int64              `bson:"version"`
+	LeagueID       primitive.ObjectID `bson:"league_id"`
+	Name           string             `bson:"name"`
+	StartDate      time.Time          `bson:"start_date"`
+	EndDate        time.Time          `bson:"end_date"`
+	Status         SeasonStatus       `bson:"status"`
+	FinalStandings []FrozenStanding   `bson:"final_standings,omitempty"` // Filled when the season is closed
+	ClosedAt       time.Time          `bson:"closed_at,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
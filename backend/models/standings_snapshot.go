@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FrozenStanding is a member's standing stored as it was at some moment:
+// in the final standings of a closed season or in a standings snapshot
+type FrozenStanding struct {
+	Rank                int                `bson:"rank"`
+	MembershipID        primitive.ObjectID `bson:"membership_id"`
+	UserID              primitive.ObjectID `bson:"user_id,omitempty"`
+	UserName            string             `bson:"user_name"`
+	UserAvatar          string             `bson:"user_avatar,omitempty"`
+	TotalPoints         int64              `bson:"total_points"`
+	GamesPlayed         int                `bson:"games_played"`
+	GamesModerated      int                `bson:"games_moderated"`
+	FirstPlaceCount     int                `bson:"first_place_count"`
+	SecondPlaceCount    int                `bson:"second_place_count"`
+	ThirdPlaceCount     int                `bson:"third_place_count"`
+	ParticipationPoints int64              `bson:"participation_points"`
+	PositionPoints      int64              `bson:"position_points"`
+	ModerationPoints    int64              `bson:"moderation_points"`
+	TeamWins            int                `bson:"team_wins"`
+	TeamDraws           int                `bson:"team_draws"`
+	TeamLosses          int                `bson:"team_losses"`
+	CoopWins            int                `bson:"coop_wins"`
+	CoopLosses          int                `bson:"coop_losses"`
+}
+
+// StandingsSnapshot is the league standings recorded right after a game round was finalized
+type StandingsSnapshot struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	LeagueID    primitive.ObjectID `bson:"league_id"`
+	GameRoundID primitive.ObjectID `bson:"game_round_id"` // The round which finalization triggered the snapshot
+	TakenAt     time.Time          `bson:"taken_at"`
+	Standings   []FrozenStanding   `bson:"standings"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelegramChat is a Telegram chat linked to a league, a chat can be linked to several leagues
+type TelegramChat struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	LeagueID  primitive.ObjectID `bson:"league_id"`
+	ChatID    int64              `bson:"chat_id"`
+	Title     string             `bson:"title,omitempty"` // Chat title or username at the time of linking
+	LinkedBy  primitive.ObjectID `bson:"linked_by"`       // User who generated the link code
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// TelegramLinkCode is a one-time code which links a Telegram chat to a league when sent to the bot
+type TelegramLinkCode struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	LeagueID  primitive.ObjectID `bson:"league_id"`
+	Code      string             `bson:"code"`
+	CreatedBy primitive.ObjectID `bson:"created_by"`
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"` // The code is removed by the TTL index after it
+}
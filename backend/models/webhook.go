@@ -0,0 +1,79 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookEventType is the kind of league event sent to webhook subscriptions
+type WebhookEventType string
+
+const (
+	WebhookEventGameFinalized    WebhookEventType = "game.finalized"
+	WebhookEventMemberJoined     WebhookEventType = "member.joined"
+	WebhookEventStandingsChanged WebhookEventType = "standings.changed"
+	WebhookEventTest             WebhookEventType = "webhook.test" // Sent on request only, can't be subscribed to
+)
+
+// WebhookEventTypes are the event types a subscription can ask for
+var WebhookEventTypes = []WebhookEventType{WebhookEventGameFinalized, WebhookEventMemberJoined, WebhookEventStandingsChanged}
+
+// IsValid checks if the subscription can ask for the event type
+func (t WebhookEventType) IsValid() bool {
+	return slices.Contains(WebhookEventTypes, t)
+}
+
+// WebhookSubscription is an outside URL receiving league events as signed JSON POST requests
+type WebhookSubscription struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Version     int64              `bson:"version"`
+	LeagueID    primitive.ObjectID `bson:"league_id"`
+	URL         string             `bson:"url"`
+	Description string             `bson:"description,omitempty"`
+	Events      []WebhookEventType `bson:"events"`
+	Secret      string             `bson:"secret"` // HMAC-SHA256 key of the payload signature
+	Active      bool               `bson:"active"`
+	CreatedBy   primitive.ObjectID `bson:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+// Wants checks if the subscription receives events of the type
+func (s *WebhookSubscription) Wants(eventType WebhookEventType) bool {
+	return slices.Contains(s.Events, eventType)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Waits for the first attempt or a retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // The receiver answered with 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // All attempts failed, no more retries
+)
+
+// WebhookAttempt is one POST of the payload to the subscription URL
+type WebhookAttempt struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"status_code,omitempty"` // Zero when the receiver wasn't reached
+	Error      string    `bson:"error,omitempty"`
+	DurationMs int64     `bson:"duration_ms"`
+}
+
+// WebhookDelivery is an event sent to one subscription, with all attempts - the delivery log.
+// Removed by MongoDB once ExpiresAt has passed
+type WebhookDelivery struct {
+	ID             primitive.ObjectID    `bson:"_id,omitempty"`
+	SubscriptionID primitive.ObjectID    `bson:"subscription_id"`
+	LeagueID       primitive.ObjectID    `bson:"league_id"`
+	EventID        string                `bson:"event_id"` // Same for all subscriptions receiving the event
+	EventType      WebhookEventType      `bson:"event_type"`
+	Payload        string                `bson:"payload"` // Request body exactly as signed
+	Status         WebhookDeliveryStatus `bson:"status"`
+	Attempts       []WebhookAttempt      `bson:"attempts,omitempty"`
+	NextAttemptAt  time.Time             `bson:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time             `bson:"created_at"`
+	UpdatedAt      time.Time             `bson:"updated_at"`
+	ExpiresAt      time.Time             `bson:"expires_at"`
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditLogRetention is how long audit log entries are kept
+const AuditLogRetention = 365 * 24 * time.Hour
+
+// AuditLogFilter selects audit log entries, zero fields match everything
+type AuditLogFilter struct {
+	LeagueID primitive.ObjectID
+	Action   string
+	ActorID  primitive.ObjectID
+	TargetID primitive.ObjectID
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	// Find returns up to limit entries matching the filter after skipping offset ones, the most recent first
+	Find(ctx context.Context, filter AuditLogFilter, offset, limit int64) ([]*models.AuditLog, error)
+	Count(ctx context.Context, filter AuditLogFilter) (int64, error)
+}
+
+type AuditLogRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(mongodb *db.MongoDB) (AuditLogRepository, error) {
+	repository := &AuditLogRepositoryInstance{
+		collection: mongodb.Collection("audit_logs"),
+	}
+	if err := ensureAuditLogIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureAuditLogIndexes(r *AuditLogRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	})
+	return err
+}
+
+func (r *AuditLogRepositoryInstance) Create(ctx context.Context, log *models.AuditLog) error {
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	if log.ExpiresAt.IsZero() {
+		log.ExpiresAt = log.CreatedAt.Add(AuditLogRetention)
+	}
+
+	result, err := r.collection.InsertOne(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	log.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *AuditLogRepositoryInstance) Find(ctx context.Context, filter AuditLogFilter, offset, limit int64) ([]*models.AuditLog, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, auditLogQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []*models.AuditLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (r *AuditLogRepositoryInstance) Count(ctx context.Context, filter AuditLogFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, auditLogQuery(filter))
+}
+
+func auditLogQuery(filter AuditLogFilter) bson.M {
+	query := bson.M{}
+	if !filter.LeagueID.IsZero() {
+		query["league_id"] = filter.LeagueID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if !filter.ActorID.IsZero() {
+		query["actor_id"] = filter.ActorID
+	}
+	if !filter.TargetID.IsZero() {
+		query["target_id"] = filter.TargetID
+	}
+	return query
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GameNightRepository interface {
+	Create(ctx context.Context, night *models.GameNight) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.GameNight, error)
+	// FindByLeague returns league nights ending after the given time (zero - all nights), the earliest first
+	FindByLeague(ctx context.Context, leagueID primitive.ObjectID, endingAfter time.Time) ([]*models.GameNight, error)
+	// FindCurrent returns the scheduled league night going on at the given time, nil if there is none
+	FindCurrent(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.GameNight, error)
+	Update(ctx context.Context, night *models.GameNight) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type GameNightRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewGameNightRepository(mongodb *db.MongoDB) (GameNightRepository, error) {
+	repository := &GameNightRepositoryInstance{
+		collection: mongodb.Collection("game_nights"),
+	}
+	if err := ensureGameNightIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureGameNightIndexes(r *GameNightRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "start_time", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *GameNightRepositoryInstance) Create(ctx context.Context, night *models.GameNight) error {
+	night.CreatedAt = time.Now()
+	night.UpdatedAt = time.Now()
+	night.Version = 1
+	if night.Status == "" {
+		night.Status = models.GameNightScheduled
+	}
+
+	result, err := r.collection.InsertOne(ctx, night)
+	if err != nil {
+		return err
+	}
+
+	night.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *GameNightRepositoryInstance) FindByID(ctx context.Context, id primitive.ObjectID) (*models.GameNight, error) {
+	var night models.GameNight
+	filter := bson.M{"_id": id}
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&night); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &night, nil
+}
+
+func (r *GameNightRepositoryInstance) FindByLeague(ctx context.Context, leagueID primitive.ObjectID, endingAfter time.Time) ([]*models.GameNight, error) {
+	filter := bson.M{"league_id": leagueID}
+	if !endingAfter.IsZero() {
+		filter["end_time"] = bson.M{"$gt": endingAfter}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var nights []*models.GameNight
+	if err := cursor.All(ctx, &nights); err != nil {
+		return nil, err
+	}
+
+	return nights, nil
+}
+
+func (r *GameNightRepositoryInstance) FindCurrent(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.GameNight, error) {
+	var night models.GameNight
+	filter := bson.M{
+		"league_id":  leagueID,
+		"status":     models.GameNightScheduled,
+		"start_time": bson.M{"$lte": at},
+		"end_time":   bson.M{"$gt": at},
+	}
+	// The latest started night wins when nights overlap
+	opts := options.FindOne().SetSort(bson.D{{Key: "start_time", Value: -1}})
+
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&night); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &night, nil
+}
+
+func (r *GameNightRepositoryInstance) Update(ctx context.Context, night *models.GameNight) error {
+	night.UpdatedAt = time.Now()
+	night.Version++
+
+	filter := bson.M{
+		"_id":     night.ID,
+		"version": night.Version - 1,
+	}
+
+	update := bson.M{
+		"$set": night,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return hexerr.New("game night not found or version mismatch (optimistic locking)")
+	}
+
+	return nil
+}
+
+func (r *GameNightRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
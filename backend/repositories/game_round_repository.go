@@ -24,6 +24,14 @@ type GameRoundRepository interface {
 	UpdateStatus(ctx context.Context, id primitive.ObjectID, status models.GameRoundStatus, version int64) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	HasGamesForMembership(ctx context.Context, membershipID primitive.ObjectID) (bool, error)
+	// FindFinishedByMemberships returns finished league rounds played by all the given memberships, latest first.
+	// Legacy players without a membership are matched by the user of the membership. Whole rounds are returned rather
+	// than totals, member statistics replay them in order for streaks and points over time
+	FindFinishedByMemberships(ctx context.Context, leagueID primitive.ObjectID, memberships []*models.LeagueMembership) ([]*models.GameRound, error)
+	// FindByMembership returns all league rounds the membership takes part in
+	FindByMembership(ctx context.Context, leagueID, membershipID primitive.ObjectID) ([]*models.GameRound, error)
+	// FindByNight returns rounds played on the game night, the earliest first
+	FindByNight(ctx context.Context, nightID primitive.ObjectID) ([]*models.GameRound, error)
 }
 
 type gameRoundRepositoryInstance struct {
@@ -71,6 +79,17 @@ func (r *gameRoundRepositoryInstance) ensureIndexes() error {
 				{"start_time", -1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "league_id", Value: 1},
+				{Key: "players.membership_id", Value: 1},
+				{Key: "end_time", Value: -1},
+			},
+		},
+		{
+			Keys:    bson.D{{Key: "night_id", Value: 1}, {Key: "start_time", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"night_id": bson.M{"$exists": true}}),
+		},
 	})
 	return err
 }
@@ -169,6 +188,81 @@ func (r *gameRoundRepositoryInstance) HasGamesForMembership(ctx context.Context,
 	return count > 0, nil
 }
 
+func (r *gameRoundRepositoryInstance) FindFinishedByMemberships(ctx context.Context, leagueID primitive.ObjectID, memberships []*models.LeagueMembership) ([]*models.GameRound, error) {
+	filter := bson.M{
+		"league_id": leagueID,
+		"end_time":  bson.M{"$gt": time.Time{}},
+		"$and":      playedByAll(memberships),
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "end_time", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rounds []*models.GameRound
+	if err = cursor.All(ctx, &rounds); err != nil {
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
+// playedByAll builds conditions matching rounds where every membership played, under its ID or, in legacy rounds
+// recorded before memberships, under its user ID
+func playedByAll(memberships []*models.LeagueMembership) bson.A {
+	conditions := make(bson.A, 0, len(memberships))
+	for _, membership := range memberships {
+		played := bson.A{bson.M{"players.membership_id": membership.ID}}
+		if !membership.UserID.IsZero() {
+			played = append(played, bson.M{"players": bson.M{"$elemMatch": bson.M{
+				"player_id":     membership.UserID,
+				"membership_id": bson.M{"$exists": false},
+			}}})
+		}
+		conditions = append(conditions, bson.M{"$or": played})
+	}
+	return conditions
+}
+
+func (r *gameRoundRepositoryInstance) FindByMembership(ctx context.Context, leagueID, membershipID primitive.ObjectID) ([]*models.GameRound, error) {
+	filter := bson.M{
+		"league_id":             leagueID,
+		"players.membership_id": membershipID,
+	}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rounds []*models.GameRound
+	if err = cursor.All(ctx, &rounds); err != nil {
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
+func (r *gameRoundRepositoryInstance) FindByNight(ctx context.Context, nightID primitive.ObjectID) ([]*models.GameRound, error) {
+	filter := bson.M{"night_id": nightID}
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rounds []*models.GameRound
+	if err = cursor.All(ctx, &rounds); err != nil {
+		return nil, err
+	}
+
+	return rounds, nil
+}
+
 func (r *gameRoundRepositoryInstance) FindByLeagueAndStatus(ctx context.Context, leagueID primitive.ObjectID, statuses []models.GameRoundStatus) ([]*models.GameRound, error) {
 	filter := bson.M{
 		"league_id": leagueID,
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StandingsAggregator computes league standings counts and points on the database side.
+// It is an optional capability of a GameRoundRepository.
+type StandingsAggregator interface {
+	AggregateStandings(ctx context.Context, leagueID primitive.ObjectID, config StandingsAggregationConfig) ([]*StandingsAggregate, error)
+	// CountFinishedRoundsByGameType returns the number of finished league rounds of every game type
+	CountFinishedRoundsByGameType(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]int, error)
+}
+
+// StandingsAggregationConfig selects the rounds and defines how they are scored
+type StandingsAggregationConfig struct {
+	GameTypeID     primitive.ObjectID     // zero - all game types
+	Status         models.GameRoundStatus // empty - any status
+	From           time.Time              // zero - no lower bound on round end_time, inclusive
+	To             time.Time              // zero - no upper bound on round end_time, inclusive
+	ExcludeRoundID primitive.ObjectID     // zero - none
+	ByGameType     bool                   // Aggregate every game type separately, rounds without game type are skipped
+
+	CooperativeGameTypeIDs []primitive.ObjectID // Game types scored by the group result
+	TeamGameTypeIDs        []primitive.ObjectID // Game types scored by the team result
+
+	ParticipationPoints int64
+	ModerationPoints    int64
+	PositionPoints      []int64 // Points for position i+1
+	TeamWinPoints       int64
+	TeamDrawPoints      int64
+	TeamLossPoints      int64
+	CoopWinPoints       int64
+}
+
+// StandingsAggregateKey identifies the player the aggregate belongs to
+type StandingsAggregateKey struct {
+	MembershipID primitive.ObjectID `bson:"membership_id,omitempty"`
+	PlayerID     primitive.ObjectID `bson:"player_id,omitempty"`    // Legacy rounds without membership
+	GameTypeID   primitive.ObjectID `bson:"game_type_id,omitempty"` // Only with ByGameType
+}
+
+// StandingsAggregate is the sum of a player's results over the selected rounds
+type StandingsAggregate struct {
+	Key                 StandingsAggregateKey `bson:"_id"`
+	GamesPlayed         int                   `bson:"games_played"`
+	GamesModerated      int                   `bson:"games_moderated"`
+	FirstPlaceCount     int                   `bson:"first_place_count"`
+	SecondPlaceCount    int                   `bson:"second_place_count"`
+	ThirdPlaceCount     int                   `bson:"third_place_count"`
+	ParticipationPoints int64                 `bson:"participation_points"`
+	PositionPoints      int64                 `bson:"position_points"`
+	ModerationPoints    int64                 `bson:"moderation_points"`
+	TeamWins            int                   `bson:"team_wins"`
+	TeamDraws           int                   `bson:"team_draws"`
+	TeamLosses          int                   `bson:"team_losses"`
+	CoopWins            int                   `bson:"coop_wins"`
+	CoopLosses          int                   `bson:"coop_losses"`
+}
+
+func (r *gameRoundRepositoryInstance) AggregateStandings(ctx context.Context, leagueID primitive.ObjectID, config StandingsAggregationConfig) ([]*StandingsAggregate, error) {
+	cursor, err := r.collection.Aggregate(ctx, StandingsPipeline(leagueID, config))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var aggregates []*StandingsAggregate
+	if err := cursor.All(ctx, &aggregates); err != nil {
+		return nil, err
+	}
+
+	return aggregates, nil
+}
+
+func (r *gameRoundRepositoryInstance) CountFinishedRoundsByGameType(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	cursor, err := r.collection.Aggregate(ctx, FinishedRoundsByGameTypePipeline(leagueID))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		GameTypeID primitive.ObjectID `bson:"_id"`
+		Rounds     int                `bson:"rounds"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	result := make(map[primitive.ObjectID]int, len(counts))
+	for _, count := range counts {
+		result[count.GameTypeID] = count.Rounds
+	}
+	return result, nil
+}
+
+// FinishedRoundsByGameTypePipeline counts finished league rounds by game type, rounds without game type are skipped
+func FinishedRoundsByGameTypePipeline(leagueID primitive.ObjectID) bson.A {
+	return bson.A{
+		bson.M{"$match": bson.M{"league_id": leagueID, "end_time": bson.M{"$gt": time.Time{}}, "game_type_id": bson.M{"$exists": true}}},
+		bson.M{"$group": bson.M{"_id": "$game_type_id", "rounds": bson.M{"$sum": 1}}},
+	}
+}
+
+// StandingsPipeline mirrors the scoring rules of services.CalculateStandings
+func StandingsPipeline(leagueID primitive.ObjectID, config StandingsAggregationConfig) bson.A {
+	endTime := bson.M{"$gt": time.Time{}}
+	if !config.From.IsZero() {
+		endTime["$gte"] = config.From
+	}
+	if !config.To.IsZero() {
+		endTime["$lte"] = config.To
+	}
+	match := bson.M{"league_id": leagueID, "end_time": endTime}
+	if !config.GameTypeID.IsZero() {
+		match["game_type_id"] = config.GameTypeID
+	} else if config.ByGameType {
+		match["game_type_id"] = bson.M{"$exists": true}
+	}
+	if config.Status != "" {
+		match["status"] = config.Status
+	}
+	if !config.ExcludeRoundID.IsZero() {
+		match["_id"] = bson.M{"$ne": config.ExcludeRoundID}
+	}
+
+	groupKey := bson.M{
+		"membership_id": "$players.membership_id",
+		"player_id":     "$players.player_id",
+	}
+	if config.ByGameType {
+		groupKey["game_type_id"] = "$game_type_id"
+	}
+
+	flag := func(condition interface{}) bson.M {
+		return bson.M{"$cond": bson.A{condition, 1, 0}}
+	}
+	kindIs := func(kind string) bson.M {
+		return bson.M{"$eq": bson.A{"$kind", kind}}
+	}
+
+	return bson.A{
+		bson.M{"$match": match},
+		bson.M{"$project": bson.M{
+			"game_type_id":    1,
+			"cooperative_win": 1,
+			"players":         1,
+			"team_scores": bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$team_scores", bson.A{}}},
+				"as":    "t",
+				"in":    bson.M{"name": "$$t.name", "score": bson.M{"$ifNull": bson.A{"$$t.cooperative_score", 0}}},
+			}},
+		}},
+		bson.M{"$addFields": bson.M{
+			"coop": bson.M{"$and": bson.A{
+				bson.M{"$in": bson.A{"$game_type_id", objectIDArray(config.CooperativeGameTypeIDs)}},
+				bson.M{"$eq": bson.A{bson.M{"$type": "$cooperative_win"}, "bool"}},
+			}},
+			"team": bson.M{"$and": bson.A{
+				bson.M{"$in": bson.A{"$game_type_id", objectIDArray(config.TeamGameTypeIDs)}},
+				bson.M{"$gt": bson.A{bson.M{"$size": "$team_scores"}, 0}},
+			}},
+			"best_score": bson.M{"$max": "$team_scores.score"},
+		}},
+		bson.M{"$addFields": bson.M{
+			"leaders": bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": "$team_scores",
+				"cond":  bson.M{"$eq": bson.A{"$$this.score", "$best_score"}},
+			}}},
+		}},
+		bson.M{"$unwind": "$players"},
+		bson.M{"$addFields": bson.M{
+			"position":     bson.M{"$ifNull": bson.A{"$players.position", 0}},
+			"team_name":    bson.M{"$ifNull": bson.A{"$players.team_name", ""}},
+			"is_moderator": bson.M{"$eq": bson.A{"$players.is_moderator", true}},
+			"team_score": bson.M{"$arrayElemAt": bson.A{bson.M{"$filter": bson.M{
+				"input": "$team_scores",
+				"cond":  bson.M{"$eq": bson.A{"$$this.name", "$players.team_name"}},
+			}}, 0}},
+		}},
+		bson.M{"$addFields": bson.M{
+			"kind": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": "$coop", "then": "coop"},
+					bson.M{"case": "$team", "then": "team"},
+					bson.M{"case": bson.M{"$gt": bson.A{"$position", 0}}, "then": "position"},
+				},
+				"default": "none",
+			}},
+			"team_result": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$ne": bson.A{"$team_name", ""}},
+					bson.M{"$eq": bson.A{bson.M{"$type": "$team_score"}, "object"}},
+				}},
+				bson.M{"$switch": bson.M{
+					"branches": bson.A{
+						bson.M{"case": bson.M{"$lt": bson.A{"$team_score.score", "$best_score"}}, "then": "loss"},
+						bson.M{"case": bson.M{"$gt": bson.A{"$leaders", 1}}, "then": "draw"},
+					},
+					"default": "win",
+				}},
+				"",
+			}},
+		}},
+		bson.M{"$addFields": bson.M{
+			"coop_win":  flag(bson.M{"$and": bson.A{kindIs("coop"), bson.M{"$not": "$is_moderator"}, bson.M{"$eq": bson.A{"$cooperative_win", true}}}}),
+			"coop_loss": flag(bson.M{"$and": bson.A{kindIs("coop"), bson.M{"$not": "$is_moderator"}, bson.M{"$eq": bson.A{"$cooperative_win", false}}}}),
+			"team_win":  flag(bson.M{"$and": bson.A{kindIs("team"), bson.M{"$eq": bson.A{"$team_result", "win"}}}}),
+			"team_draw": flag(bson.M{"$and": bson.A{kindIs("team"), bson.M{"$eq": bson.A{"$team_result", "draw"}}}}),
+			"team_loss": flag(bson.M{"$and": bson.A{kindIs("team"), bson.M{"$eq": bson.A{"$team_result", "loss"}}}}),
+			"first":     flag(bson.M{"$and": bson.A{kindIs("position"), bson.M{"$eq": bson.A{"$position", 1}}}}),
+			"second":    flag(bson.M{"$and": bson.A{kindIs("position"), bson.M{"$eq": bson.A{"$position", 2}}}}),
+			"third":     flag(bson.M{"$and": bson.A{kindIs("position"), bson.M{"$eq": bson.A{"$position", 3}}}}),
+			"moderated": flag("$is_moderator"),
+		}},
+		bson.M{"$addFields": bson.M{
+			"position_points": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{"$coop_win", 1}}, "then": config.CoopWinPoints},
+					bson.M{"case": bson.M{"$eq": bson.A{"$team_win", 1}}, "then": config.TeamWinPoints},
+					bson.M{"case": bson.M{"$eq": bson.A{"$team_draw", 1}}, "then": config.TeamDrawPoints},
+					bson.M{"case": bson.M{"$eq": bson.A{"$team_loss", 1}}, "then": config.TeamLossPoints},
+					bson.M{"case": kindIs("position"), "then": positionPointsExpression(config)},
+				},
+				"default": int64(0),
+			}},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":                  groupKey,
+			"games_played":         bson.M{"$sum": 1},
+			"games_moderated":      bson.M{"$sum": "$moderated"},
+			"first_place_count":    bson.M{"$sum": "$first"},
+			"second_place_count":   bson.M{"$sum": "$second"},
+			"third_place_count":    bson.M{"$sum": "$third"},
+			"participation_points": bson.M{"$sum": config.ParticipationPoints},
+			"position_points":      bson.M{"$sum": "$position_points"},
+			"moderation_points":    bson.M{"$sum": bson.M{"$multiply": bson.A{"$moderated", config.ModerationPoints}}},
+			"team_wins":            bson.M{"$sum": "$team_win"},
+			"team_draws":           bson.M{"$sum": "$team_draw"},
+			"team_losses":          bson.M{"$sum": "$team_loss"},
+			"coop_wins":            bson.M{"$sum": "$coop_win"},
+			"coop_losses":          bson.M{"$sum": "$coop_loss"},
+		}},
+	}
+}
+
+// positionPointsExpression looks up points for $position in the table, positions past its end get no points
+func positionPointsExpression(config StandingsAggregationConfig) bson.M {
+	table := make(bson.A, 0, len(config.PositionPoints))
+	for _, points := range config.PositionPoints {
+		table = append(table, points)
+	}
+
+	return bson.M{"$cond": bson.A{
+		bson.M{"$lte": bson.A{"$position", len(table)}},
+		bson.M{"$arrayElemAt": bson.A{table, bson.M{"$subtract": bson.A{"$position", 1}}}},
+		int64(0),
+	}}
+}
+
+// objectIDArray converts IDs to an array which is never encoded as null
+func objectIDArray(ids []primitive.ObjectID) bson.A {
+	array := make(bson.A, 0, len(ids))
+	for _, id := range ids {
+		array = append(array, id)
+	}
+	return array
+}
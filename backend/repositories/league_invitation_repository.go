@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/andriyg76/bgl/db"
@@ -20,9 +21,13 @@ type LeagueInvitationRepository interface {
 	FindByToken(ctx context.Context, token string) (*models.LeagueInvitation, error)
 	FindActiveByCreator(ctx context.Context, leagueID, createdBy primitive.ObjectID) ([]*models.LeagueInvitation, error)
 	FindExpiredByCreator(ctx context.Context, leagueID, createdBy primitive.ObjectID) ([]*models.LeagueInvitation, error)
+	// FindByLeague returns all invitations of the league, used and expired included
+	FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.LeagueInvitation, error)
 	MarkAsUsed(ctx context.Context, id primitive.ObjectID, usedBy primitive.ObjectID) error
+	AddUse(ctx context.Context, id primitive.ObjectID, use models.InvitationUse, maxUses int) error
 	Cancel(ctx context.Context, id primitive.ObjectID) error
 	Extend(ctx context.Context, id primitive.ObjectID, duration time.Duration) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type LeagueInvitationRepositoryInstance struct {
@@ -129,6 +134,21 @@ func (r *LeagueInvitationRepositoryInstance) FindActiveByCreator(ctx context.Con
 	return invitations, nil
 }
 
+func (r *LeagueInvitationRepositoryInstance) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.LeagueInvitation, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"league_id": leagueID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invitations []*models.LeagueInvitation
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
 func (r *LeagueInvitationRepositoryInstance) MarkAsUsed(ctx context.Context, id primitive.ObjectID, usedBy primitive.ObjectID) error {
 	filter := bson.M{
 		"_id":     id,
@@ -159,6 +179,44 @@ func (r *LeagueInvitationRepositoryInstance) MarkAsUsed(ctx context.Context, id
 	return nil
 }
 
+// AddUse records an acceptance of a reusable invitation, fails if it's expired or all maxUses are taken
+func (r *LeagueInvitationRepositoryInstance) AddUse(ctx context.Context, id primitive.ObjectID, use models.InvitationUse, maxUses int) error {
+	if maxUses < 1 {
+		return hexerr.New("invitation is not reusable")
+	}
+
+	filter := bson.M{
+		"_id":        id,
+		"is_used":    false,
+		"expires_at": bson.M{"$gt": time.Now()},
+		// The uses array has less than maxUses elements
+		fmt.Sprintf("uses.%d", maxUses-1): bson.M{"$exists": false},
+	}
+
+	update := bson.M{
+		"$push": bson.M{
+			"uses": use,
+		},
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return hexerr.New("invitation not found, expired or has no uses left")
+	}
+
+	return nil
+}
+
 func (r *LeagueInvitationRepositoryInstance) Cancel(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{
 		"_id":        id,
@@ -239,3 +297,8 @@ func (r *LeagueInvitationRepositoryInstance) Extend(ctx context.Context, id prim
 
 	return nil
 }
+
+func (r *LeagueInvitationRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
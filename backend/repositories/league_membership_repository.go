@@ -19,6 +19,7 @@ type LeagueMembershipRepository interface {
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.LeagueMembership, error)
 	FindByLeagueAndUser(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error)
 	FindByLeagueAndAlias(ctx context.Context, leagueID primitive.ObjectID, alias string) (*models.LeagueMembership, error)
+	FindByCalendarToken(ctx context.Context, token string) (*models.LeagueMembership, error)
 	FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.LeagueMembership, error)
 	FindByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.LeagueMembership, error)
 	FindByLeagueSortedByActivity(ctx context.Context, leagueID primitive.ObjectID, excludeIDs []primitive.ObjectID, limit int) ([]*models.LeagueMembership, error)
@@ -73,6 +74,10 @@ func ensureLeagueMembershipIndexes(r *LeagueMembershipRepositoryInstance) error
 		{
 			Keys: bson.D{{"league_id", 1}, {"last_activity_at", -1}},
 		},
+		{
+			Keys:    bson.D{{"calendar_token", 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"calendar_token": bson.M{"$exists": true}}),
+		},
 	})
 	return err
 }
@@ -217,6 +222,20 @@ func (r *LeagueMembershipRepositoryInstance) FindByLeagueAndAlias(ctx context.Co
 	return &membership, nil
 }
 
+func (r *LeagueMembershipRepositoryInstance) FindByCalendarToken(ctx context.Context, token string) (*models.LeagueMembership, error) {
+	var membership models.LeagueMembership
+	filter := bson.M{"calendar_token": token}
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&membership); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
 func (r *LeagueMembershipRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -19,7 +19,9 @@ type LeagueRepository interface {
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.League, error)
 	FindAll(ctx context.Context) ([]*models.League, error)
 	FindByStatus(ctx context.Context, status models.LeagueStatus) ([]*models.League, error)
+	FindPublic(ctx context.Context) ([]*models.League, error)
 	Update(ctx context.Context, league *models.League) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type LeagueRepositoryInstance struct {
@@ -45,6 +47,9 @@ func ensureLeagueIndexes(r *LeagueRepositoryInstance) error {
 		{
 			Keys: bson.M{"status": 1},
 		},
+		{
+			Keys: bson.M{"is_public": 1},
+		},
 	})
 	return err
 }
@@ -111,6 +116,23 @@ func (r *LeagueRepositoryInstance) FindByStatus(ctx context.Context, status mode
 	return leagues, nil
 }
 
+// FindPublic returns active leagues open for join requests, sorted by name
+func (r *LeagueRepositoryInstance) FindPublic(ctx context.Context) ([]*models.League, error) {
+	filter := bson.M{"is_public": true, "status": models.LeagueActive}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var leagues []*models.League
+	if err := cursor.All(ctx, &leagues); err != nil {
+		return nil, err
+	}
+
+	return leagues, nil
+}
+
 func (r *LeagueRepositoryInstance) Update(ctx context.Context, league *models.League) error {
 	league.UpdatedAt = time.Now()
 	league.Version++
@@ -135,3 +157,8 @@ func (r *LeagueRepositoryInstance) Update(ctx context.Context, league *models.Le
 
 	return nil
 }
+
+func (r *LeagueRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
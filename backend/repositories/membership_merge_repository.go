@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MembershipMergeRepository interface {
+	Create(ctx context.Context, merge *models.MembershipMerge) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.MembershipMerge, error)
+	// FindUndoable returns merges of the league which can still be undone, the most recent first
+	FindUndoable(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error)
+	// FindUnfinished returns the interrupted merge of the source membership, nil if there is none
+	FindUnfinished(ctx context.Context, leagueID, sourceID primitive.ObjectID) (*models.MembershipMerge, error)
+	Update(ctx context.Context, merge *models.MembershipMerge) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type MembershipMergeRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewMembershipMergeRepository(mongodb *db.MongoDB) (MembershipMergeRepository, error) {
+	repository := &MembershipMergeRepositoryInstance{
+		collection: mongodb.Collection("membership_merges"),
+	}
+	if err := ensureMembershipMergeIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureMembershipMergeIndexes(r *MembershipMergeRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "merged_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "undo_until", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	})
+	return err
+}
+
+func (r *MembershipMergeRepositoryInstance) Create(ctx context.Context, merge *models.MembershipMerge) error {
+	if merge.MergedAt.IsZero() {
+		merge.MergedAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, merge)
+	if err != nil {
+		return err
+	}
+
+	merge.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *MembershipMergeRepositoryInstance) FindByID(ctx context.Context, id primitive.ObjectID) (*models.MembershipMerge, error) {
+	var merge models.MembershipMerge
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&merge)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *MembershipMergeRepositoryInstance) FindUndoable(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error) {
+	filter := bson.M{
+		"league_id":  leagueID,
+		"undo_until": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "merged_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var merges []*models.MembershipMerge
+	if err := cursor.All(ctx, &merges); err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+func (r *MembershipMergeRepositoryInstance) FindUnfinished(ctx context.Context, leagueID, sourceID primitive.ObjectID) (*models.MembershipMerge, error) {
+	var merge models.MembershipMerge
+	// Records stored before merges could be resumed have no completed flag and are never matched
+	filter := bson.M{"league_id": leagueID, "source._id": sourceID, "completed": false}
+	err := r.collection.FindOne(ctx, filter).Decode(&merge)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *MembershipMergeRepositoryInstance) Update(ctx context.Context, merge *models.MembershipMerge) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": merge.ID}, merge)
+	return err
+}
+
+func (r *MembershipMergeRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
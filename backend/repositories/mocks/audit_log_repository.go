@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	mock2 "github.com/stretchr/testify/mock"
+)
+
+// MockAuditLogRepository is a mock implementation of AuditLogRepository
+type MockAuditLogRepository struct {
+	mock2.Mock
+}
+
+func (m *MockAuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) Find(ctx context.Context, filter repositories.AuditLogFilter, offset, limit int64) ([]*models.AuditLog, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	logs := args.Get(0)
+	if logs == nil {
+		return nil, args.Error(1)
+	}
+	return logs.([]*models.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditLogRepository) Count(ctx context.Context, filter repositories.AuditLogFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
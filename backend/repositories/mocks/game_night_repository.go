@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockGameNightRepository is a mock implementation of GameNightRepository
+type MockGameNightRepository struct {
+	mock2.Mock
+}
+
+func (m *MockGameNightRepository) Create(ctx context.Context, night *models.GameNight) error {
+	args := m.Called(ctx, night)
+	return args.Error(0)
+}
+
+func (m *MockGameNightRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.GameNight, error) {
+	args := m.Called(ctx, id)
+	night := args.Get(0)
+	if night == nil {
+		return nil, args.Error(1)
+	}
+	return night.(*models.GameNight), args.Error(1)
+}
+
+func (m *MockGameNightRepository) FindByLeague(ctx context.Context, leagueID primitive.ObjectID, endingAfter time.Time) ([]*models.GameNight, error) {
+	args := m.Called(ctx, leagueID, endingAfter)
+	nights := args.Get(0)
+	if nights == nil {
+		return nil, args.Error(1)
+	}
+	return nights.([]*models.GameNight), args.Error(1)
+}
+
+func (m *MockGameNightRepository) FindCurrent(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.GameNight, error) {
+	args := m.Called(ctx, leagueID, at)
+	night := args.Get(0)
+	if night == nil {
+		return nil, args.Error(1)
+	}
+	return night.(*models.GameNight), args.Error(1)
+}
+
+func (m *MockGameNightRepository) Update(ctx context.Context, night *models.GameNight) error {
+	args := m.Called(ctx, night)
+	return args.Error(0)
+}
+
+func (m *MockGameNightRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
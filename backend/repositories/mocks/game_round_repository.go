@@ -55,6 +55,22 @@ func (m *MockGameRoundRepository) HasGamesForMembership(ctx context.Context, mem
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockGameRoundRepository) FindFinishedByMemberships(ctx context.Context, leagueID primitive.ObjectID, memberships []*models.LeagueMembership) ([]*models.GameRound, error) {
+	args := m.Called(ctx, leagueID, memberships)
+	if rounds := args.Get(0); rounds != nil {
+		return rounds.([]*models.GameRound), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockGameRoundRepository) FindByMembership(ctx context.Context, leagueID, membershipID primitive.ObjectID) ([]*models.GameRound, error) {
+	args := m.Called(ctx, leagueID, membershipID)
+	if rounds := args.Get(0); rounds != nil {
+		return rounds.([]*models.GameRound), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockGameRoundRepository) FindByLeagueAndStatus(ctx context.Context, leagueID primitive.ObjectID, statuses []models.GameRoundStatus) ([]*models.GameRound, error) {
 	args := m.Called(ctx, leagueID, statuses)
 	if rounds := args.Get(0); rounds != nil {
@@ -75,3 +91,11 @@ func (m *MockGameRoundRepository) UpdateStatus(ctx context.Context, id primitive
 	args := m.Called(ctx, id, status, version)
 	return args.Error(0)
 }
+
+func (m *MockGameRoundRepository) FindByNight(ctx context.Context, nightID primitive.ObjectID) ([]*models.GameRound, error) {
+	args := m.Called(ctx, nightID)
+	if rounds := args.Get(0); rounds != nil {
+		return rounds.([]*models.GameRound), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -46,6 +46,15 @@ func (m *MockLeagueInvitationRepository) FindActiveByCreator(ctx context.Context
 	return inv.([]*models.LeagueInvitation), args.Error(1)
 }
 
+func (m *MockLeagueInvitationRepository) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.LeagueInvitation, error) {
+	args := m.Called(ctx, leagueID)
+	inv := args.Get(0)
+	if inv == nil {
+		return nil, args.Error(1)
+	}
+	return inv.([]*models.LeagueInvitation), args.Error(1)
+}
+
 func (m *MockLeagueInvitationRepository) FindExpiredByCreator(ctx context.Context, leagueID, createdBy primitive.ObjectID) ([]*models.LeagueInvitation, error) {
 	args := m.Called(ctx, leagueID, createdBy)
 	inv := args.Get(0)
@@ -60,6 +69,11 @@ func (m *MockLeagueInvitationRepository) MarkAsUsed(ctx context.Context, id prim
 	return args.Error(0)
 }
 
+func (m *MockLeagueInvitationRepository) AddUse(ctx context.Context, id primitive.ObjectID, use models.InvitationUse, maxUses int) error {
+	args := m.Called(ctx, id, use, maxUses)
+	return args.Error(0)
+}
+
 func (m *MockLeagueInvitationRepository) Cancel(ctx context.Context, id primitive.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -70,4 +84,7 @@ func (m *MockLeagueInvitationRepository) Extend(ctx context.Context, id primitiv
 	return args.Error(0)
 }
 
-
+func (m *MockLeagueInvitationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -74,6 +74,15 @@ func (m *MockLeagueMembershipRepository) FindByLeagueAndAlias(ctx context.Contex
 	return membership.(*models.LeagueMembership), args.Error(1)
 }
 
+func (m *MockLeagueMembershipRepository) FindByCalendarToken(ctx context.Context, token string) (*models.LeagueMembership, error) {
+	args := m.Called(ctx, token)
+	membership := args.Get(0)
+	if membership == nil {
+		return nil, args.Error(1)
+	}
+	return membership.(*models.LeagueMembership), args.Error(1)
+}
+
 func (m *MockLeagueMembershipRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -35,6 +35,15 @@ func (m *MockLeagueRepository) FindAll(ctx context.Context) ([]*models.League, e
 	return leagues.([]*models.League), args.Error(1)
 }
 
+func (m *MockLeagueRepository) FindPublic(ctx context.Context) ([]*models.League, error) {
+	args := m.Called(ctx)
+	leagues := args.Get(0)
+	if leagues == nil {
+		return nil, args.Error(1)
+	}
+	return leagues.([]*models.League), args.Error(1)
+}
+
 func (m *MockLeagueRepository) FindByStatus(ctx context.Context, status models.LeagueStatus) ([]*models.League, error) {
 	args := m.Called(ctx, status)
 	leagues := args.Get(0)
@@ -49,4 +58,7 @@ func (m *MockLeagueRepository) Update(ctx context.Context, league *models.League
 	return args.Error(0)
 }
 
-
+func (m *MockLeagueRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
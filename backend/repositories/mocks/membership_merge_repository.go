@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockMembershipMergeRepository is a mock implementation of MembershipMergeRepository
+type MockMembershipMergeRepository struct {
+	mock2.Mock
+}
+
+func (m *MockMembershipMergeRepository) Create(ctx context.Context, merge *models.MembershipMerge) error {
+	args := m.Called(ctx, merge)
+	return args.Error(0)
+}
+
+func (m *MockMembershipMergeRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.MembershipMerge, error) {
+	args := m.Called(ctx, id)
+	merge := args.Get(0)
+	if merge == nil {
+		return nil, args.Error(1)
+	}
+	return merge.(*models.MembershipMerge), args.Error(1)
+}
+
+func (m *MockMembershipMergeRepository) FindUndoable(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error) {
+	args := m.Called(ctx, leagueID)
+	merges := args.Get(0)
+	if merges == nil {
+		return nil, args.Error(1)
+	}
+	return merges.([]*models.MembershipMerge), args.Error(1)
+}
+
+func (m *MockMembershipMergeRepository) FindUnfinished(ctx context.Context, leagueID, sourceID primitive.ObjectID) (*models.MembershipMerge, error) {
+	args := m.Called(ctx, leagueID, sourceID)
+	merge := args.Get(0)
+	if merge == nil {
+		return nil, args.Error(1)
+	}
+	return merge.(*models.MembershipMerge), args.Error(1)
+}
+
+func (m *MockMembershipMergeRepository) Update(ctx context.Context, merge *models.MembershipMerge) error {
+	args := m.Called(ctx, merge)
+	return args.Error(0)
+}
+
+func (m *MockMembershipMergeRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
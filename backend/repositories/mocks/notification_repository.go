@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockNotificationRepository is a mock implementation of NotificationRepository
+type MockNotificationRepository struct {
+	mock2.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]*models.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, limit)
+	notifications := args.Get(0)
+	if notifications == nil {
+		return nil, args.Error(1)
+	}
+	return notifications.([]*models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) (bool, error) {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
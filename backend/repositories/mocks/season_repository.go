@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockSeasonRepository is a mock implementation of SeasonRepository
+type MockSeasonRepository struct {
+	mock2.Mock
+}
+
+func (m *MockSeasonRepository) Create(ctx context.Context, season *models.Season) error {
+	args := m.Called(ctx, season)
+	return args.Error(0)
+}
+
+func (m *MockSeasonRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Season, error) {
+	args := m.Called(ctx, id)
+	season := args.Get(0)
+	if season == nil {
+		return nil, args.Error(1)
+	}
+	return season.(*models.Season), args.Error(1)
+}
+
+func (m *MockSeasonRepository) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.Season, error) {
+	args := m.Called(ctx, leagueID)
+	seasons := args.Get(0)
+	if seasons == nil {
+		return nil, args.Error(1)
+	}
+	return seasons.([]*models.Season), args.Error(1)
+}
+
+func (m *MockSeasonRepository) Update(ctx context.Context, season *models.Season) error {
+	args := m.Called(ctx, season)
+	return args.Error(0)
+}
+
+func (m *MockSeasonRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
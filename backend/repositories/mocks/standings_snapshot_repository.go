@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockStandingsSnapshotRepository is a mock implementation of StandingsSnapshotRepository
+type MockStandingsSnapshotRepository struct {
+	mock2.Mock
+}
+
+func (m *MockStandingsSnapshotRepository) Create(ctx context.Context, snapshot *models.StandingsSnapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
+
+func (m *MockStandingsSnapshotRepository) FindRecent(ctx context.Context, leagueID primitive.ObjectID, limit int64) ([]*models.StandingsSnapshot, error) {
+	args := m.Called(ctx, leagueID, limit)
+	snapshots := args.Get(0)
+	if snapshots == nil {
+		return nil, args.Error(1)
+	}
+	return snapshots.([]*models.StandingsSnapshot), args.Error(1)
+}
+
+func (m *MockStandingsSnapshotRepository) FindLatestAt(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.StandingsSnapshot, error) {
+	args := m.Called(ctx, leagueID, at)
+	snapshot := args.Get(0)
+	if snapshot == nil {
+		return nil, args.Error(1)
+	}
+	return snapshot.(*models.StandingsSnapshot), args.Error(1)
+}
+
+func (m *MockStandingsSnapshotRepository) Update(ctx context.Context, snapshot *models.StandingsSnapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
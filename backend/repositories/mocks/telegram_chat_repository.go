@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockTelegramChatRepository is a mock implementation of TelegramChatRepository
+type MockTelegramChatRepository struct {
+	mock2.Mock
+}
+
+func (m *MockTelegramChatRepository) Create(ctx context.Context, chat *models.TelegramChat) error {
+	args := m.Called(ctx, chat)
+	return args.Error(0)
+}
+
+func (m *MockTelegramChatRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.TelegramChat, error) {
+	args := m.Called(ctx, id)
+	chat := args.Get(0)
+	if chat == nil {
+		return nil, args.Error(1)
+	}
+	return chat.(*models.TelegramChat), args.Error(1)
+}
+
+func (m *MockTelegramChatRepository) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.TelegramChat, error) {
+	args := m.Called(ctx, leagueID)
+	chats := args.Get(0)
+	if chats == nil {
+		return nil, args.Error(1)
+	}
+	return chats.([]*models.TelegramChat), args.Error(1)
+}
+
+func (m *MockTelegramChatRepository) FindByChat(ctx context.Context, chatID int64) ([]*models.TelegramChat, error) {
+	args := m.Called(ctx, chatID)
+	chats := args.Get(0)
+	if chats == nil {
+		return nil, args.Error(1)
+	}
+	return chats.([]*models.TelegramChat), args.Error(1)
+}
+
+func (m *MockTelegramChatRepository) FindByLeagueAndChat(ctx context.Context, leagueID primitive.ObjectID, chatID int64) (*models.TelegramChat, error) {
+	args := m.Called(ctx, leagueID, chatID)
+	chat := args.Get(0)
+	if chat == nil {
+		return nil, args.Error(1)
+	}
+	return chat.(*models.TelegramChat), args.Error(1)
+}
+
+func (m *MockTelegramChatRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
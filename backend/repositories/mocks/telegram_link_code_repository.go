@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+)
+
+// MockTelegramLinkCodeRepository is a mock implementation of TelegramLinkCodeRepository
+type MockTelegramLinkCodeRepository struct {
+	mock2.Mock
+}
+
+func (m *MockTelegramLinkCodeRepository) Create(ctx context.Context, code *models.TelegramLinkCode) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+func (m *MockTelegramLinkCodeRepository) Consume(ctx context.Context, code string, at time.Time) (*models.TelegramLinkCode, error) {
+	args := m.Called(ctx, code, at)
+	linkCode := args.Get(0)
+	if linkCode == nil {
+		return nil, args.Error(1)
+	}
+	return linkCode.(*models.TelegramLinkCode), args.Error(1)
+}
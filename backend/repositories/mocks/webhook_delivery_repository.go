@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockWebhookDeliveryRepository is a mock implementation of WebhookDeliveryRepository
+type MockWebhookDeliveryRepository struct {
+	mock2.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) FindBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int64) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	deliveries := args.Get(0)
+	if deliveries == nil {
+		return nil, args.Error(1)
+	}
+	return deliveries.([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) FindDue(ctx context.Context, at time.Time, limit int64) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, at, limit)
+	deliveries := args.Get(0)
+	if deliveries == nil {
+		return nil, args.Error(1)
+	}
+	return deliveries.([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteBySubscription(ctx context.Context, subscriptionID primitive.ObjectID) error {
+	args := m.Called(ctx, subscriptionID)
+	return args.Error(0)
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	mock2 "github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockWebhookSubscriptionRepository is a mock implementation of WebhookSubscriptionRepository
+type MockWebhookSubscriptionRepository struct {
+	mock2.Mock
+}
+
+func (m *MockWebhookSubscriptionRepository) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	subscription := args.Get(0)
+	if subscription == nil {
+		return nil, args.Error(1)
+	}
+	return subscription.(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	args := m.Called(ctx, leagueID)
+	subscriptions := args.Get(0)
+	if subscriptions == nil {
+		return nil, args.Error(1)
+	}
+	return subscriptions.([]*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
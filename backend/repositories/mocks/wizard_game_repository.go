@@ -0,0 +1,65 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockWizardGameRepository struct {
+	mock.Mock
+}
+
+func (m *MockWizardGameRepository) Create(ctx context.Context, game *models.WizardGame) error {
+	args := m.Called(ctx, game)
+	return args.Error(0)
+}
+
+func (m *MockWizardGameRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.WizardGame, error) {
+	args := m.Called(ctx, id)
+	if game := args.Get(0); game != nil {
+		return game.(*models.WizardGame), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWizardGameRepository) FindByCode(ctx context.Context, code string) (*models.WizardGame, error) {
+	args := m.Called(ctx, code)
+	if game := args.Get(0); game != nil {
+		return game.(*models.WizardGame), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWizardGameRepository) FindByGameRoundID(ctx context.Context, gameRoundID primitive.ObjectID) (*models.WizardGame, error) {
+	args := m.Called(ctx, gameRoundID)
+	if game := args.Get(0); game != nil {
+		return game.(*models.WizardGame), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWizardGameRepository) FindByMembership(ctx context.Context, membershipID primitive.ObjectID) ([]*models.WizardGame, error) {
+	args := m.Called(ctx, membershipID)
+	if games := args.Get(0); games != nil {
+		return games.([]*models.WizardGame), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWizardGameRepository) Update(ctx context.Context, game *models.WizardGame) error {
+	args := m.Called(ctx, game)
+	return args.Error(0)
+}
+
+func (m *MockWizardGameRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWizardGameRepository) DeleteByCode(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
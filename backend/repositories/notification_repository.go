@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	// FindByUser returns up to limit latest notifications of the user, the most recent first
+	FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// MarkRead marks the user's notification as read, returns false if the user has no such notification
+	MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) (bool, error)
+	// MarkAllRead marks all notifications of the user as read, returns the number of changed notifications
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+type NotificationRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationRepository(mongodb *db.MongoDB) (NotificationRepository, error) {
+	repository := &NotificationRepositoryInstance{
+		collection: mongodb.Collection("notifications"),
+	}
+	if err := ensureNotificationIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureNotificationIndexes(r *NotificationRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "is_read", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *NotificationRepositoryInstance) Create(ctx context.Context, notification *models.Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, notification)
+	if err != nil {
+		return err
+	}
+
+	notification.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *NotificationRepositoryInstance) FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]*models.Notification, error) {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["is_read"] = false
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []*models.Notification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepositoryInstance) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "is_read": false})
+}
+
+func (r *NotificationRepositoryInstance) MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": notificationID, "user_id": userID},
+		bson.M{"$set": bson.M{"is_read": true}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *NotificationRepositoryInstance) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "is_read": false},
+		bson.M{"$set": bson.M{"is_read": true}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SeasonRepository interface {
+	Create(ctx context.Context, season *models.Season) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Season, error)
+	FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.Season, error)
+	Update(ctx context.Context, season *models.Season) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type SeasonRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewSeasonRepository(mongodb *db.MongoDB) (SeasonRepository, error) {
+	repository := &SeasonRepositoryInstance{
+		collection: mongodb.Collection("league_seasons"),
+	}
+	if err := ensureSeasonIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureSeasonIndexes(r *SeasonRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "start_date", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *SeasonRepositoryInstance) Create(ctx context.Context, season *models.Season) error {
+	season.CreatedAt = time.Now()
+	season.UpdatedAt = time.Now()
+	season.Version = 1
+	if season.Status == "" {
+		season.Status = models.SeasonOpen
+	}
+
+	result, err := r.collection.InsertOne(ctx, season)
+	if err != nil {
+		return err
+	}
+
+	season.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SeasonRepositoryInstance) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Season, error) {
+	var season models.Season
+	filter := bson.M{"_id": id}
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&season); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &season, nil
+}
+
+// FindByLeague returns league seasons, the most recent first
+func (r *SeasonRepositoryInstance) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.Season, error) {
+	filter := bson.M{"league_id": leagueID}
+	opts := options.Find().SetSort(bson.D{{Key: "start_date", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var seasons []*models.Season
+	if err := cursor.All(ctx, &seasons); err != nil {
+		return nil, err
+	}
+
+	return seasons, nil
+}
+
+func (r *SeasonRepositoryInstance) Update(ctx context.Context, season *models.Season) error {
+	season.UpdatedAt = time.Now()
+	season.Version++
+
+	filter := bson.M{
+		"_id":     season.ID,
+		"version": season.Version - 1,
+	}
+
+	update := bson.M{
+		"$set": season,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return hexerr.New("season not found or version mismatch (optimistic locking)")
+	}
+
+	return nil
+}
+
+func (r *SeasonRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StandingsSnapshotRepository interface {
+	Create(ctx context.Context, snapshot *models.StandingsSnapshot) error
+	// FindRecent returns up to limit latest snapshots of the league, the most recent first
+	FindRecent(ctx context.Context, leagueID primitive.ObjectID, limit int64) ([]*models.StandingsSnapshot, error)
+	// FindLatestAt returns the latest snapshot of the league taken at or before the moment, nil if there is none
+	FindLatestAt(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.StandingsSnapshot, error)
+	Update(ctx context.Context, snapshot *models.StandingsSnapshot) error
+}
+
+type StandingsSnapshotRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewStandingsSnapshotRepository(mongodb *db.MongoDB) (StandingsSnapshotRepository, error) {
+	repository := &StandingsSnapshotRepositoryInstance{
+		collection: mongodb.Collection("league_standings_snapshots"),
+	}
+	if err := ensureStandingsSnapshotIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureStandingsSnapshotIndexes(r *StandingsSnapshotRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "taken_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *StandingsSnapshotRepositoryInstance) Create(ctx context.Context, snapshot *models.StandingsSnapshot) error {
+	if snapshot.TakenAt.IsZero() {
+		snapshot.TakenAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *StandingsSnapshotRepositoryInstance) FindRecent(ctx context.Context, leagueID primitive.ObjectID, limit int64) ([]*models.StandingsSnapshot, error) {
+	filter := bson.M{"league_id": leagueID}
+	opts := options.Find().SetSort(bson.D{{Key: "taken_at", Value: -1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []*models.StandingsSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *StandingsSnapshotRepositoryInstance) FindLatestAt(ctx context.Context, leagueID primitive.ObjectID, at time.Time) (*models.StandingsSnapshot, error) {
+	var snapshot models.StandingsSnapshot
+	filter := bson.M{"league_id": leagueID, "taken_at": bson.M{"$lte": at}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "taken_at", Value: -1}})
+
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&snapshot); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+func (r *StandingsSnapshotRepositoryInstance) Update(ctx context.Context, snapshot *models.StandingsSnapshot) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": snapshot.ID}, snapshot)
+	return err
+}
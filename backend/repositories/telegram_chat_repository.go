@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TelegramChatRepository interface {
+	Create(ctx context.Context, chat *models.TelegramChat) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.TelegramChat, error)
+	// FindByLeague returns the chats linked to the league, the earliest linked first
+	FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.TelegramChat, error)
+	// FindByChat returns the league links of the Telegram chat, the earliest linked first
+	FindByChat(ctx context.Context, chatID int64) ([]*models.TelegramChat, error)
+	// FindByLeagueAndChat returns the link of the Telegram chat to the league, nil if the chat isn't linked
+	FindByLeagueAndChat(ctx context.Context, leagueID primitive.ObjectID, chatID int64) (*models.TelegramChat, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type TelegramChatRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewTelegramChatRepository(mongodb *db.MongoDB) (TelegramChatRepository, error) {
+	repository := &TelegramChatRepositoryInstance{
+		collection: mongodb.Collection("telegram_chats"),
+	}
+	if err := ensureTelegramChatIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureTelegramChatIndexes(r *TelegramChatRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "chat_id", Value: 1}, {Key: "league_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *TelegramChatRepositoryInstance) Create(ctx context.Context, chat *models.TelegramChat) error {
+	chat.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, chat)
+	if err != nil {
+		return err
+	}
+
+	chat.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *TelegramChatRepositoryInstance) FindByID(ctx context.Context, id primitive.ObjectID) (*models.TelegramChat, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *TelegramChatRepositoryInstance) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.TelegramChat, error) {
+	return r.find(ctx, bson.M{"league_id": leagueID})
+}
+
+func (r *TelegramChatRepositoryInstance) FindByChat(ctx context.Context, chatID int64) ([]*models.TelegramChat, error) {
+	return r.find(ctx, bson.M{"chat_id": chatID})
+}
+
+func (r *TelegramChatRepositoryInstance) FindByLeagueAndChat(ctx context.Context, leagueID primitive.ObjectID, chatID int64) (*models.TelegramChat, error) {
+	return r.findOne(ctx, bson.M{"league_id": leagueID, "chat_id": chatID})
+}
+
+func (r *TelegramChatRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *TelegramChatRepositoryInstance) findOne(ctx context.Context, filter bson.M) (*models.TelegramChat, error) {
+	var chat models.TelegramChat
+	if err := r.collection.FindOne(ctx, filter).Decode(&chat); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &chat, nil
+}
+
+func (r *TelegramChatRepositoryInstance) find(ctx context.Context, filter bson.M) ([]*models.TelegramChat, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var chats []*models.TelegramChat
+	if err := cursor.All(ctx, &chats); err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TelegramLinkCodeRepository interface {
+	Create(ctx context.Context, code *models.TelegramLinkCode) error
+	// Consume removes the code and returns it, nil if there is no such code or it expired before the given time
+	Consume(ctx context.Context, code string, at time.Time) (*models.TelegramLinkCode, error)
+}
+
+type TelegramLinkCodeRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewTelegramLinkCodeRepository(mongodb *db.MongoDB) (TelegramLinkCodeRepository, error) {
+	repository := &TelegramLinkCodeRepositoryInstance{
+		collection: mongodb.Collection("telegram_link_codes"),
+	}
+	if err := ensureTelegramLinkCodeIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureTelegramLinkCodeIndexes(r *TelegramLinkCodeRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	})
+	return err
+}
+
+func (r *TelegramLinkCodeRepositoryInstance) Create(ctx context.Context, code *models.TelegramLinkCode) error {
+	code.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	code.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *TelegramLinkCodeRepositoryInstance) Consume(ctx context.Context, code string, at time.Time) (*models.TelegramLinkCode, error) {
+	// The TTL index removes expired codes only once a minute, so the expiry is checked here too
+	filter := bson.M{"code": code, "expires_at": bson.M{"$gt": at}}
+
+	var linkCode models.TelegramLinkCode
+	if err := r.collection.FindOneAndDelete(ctx, filter).Decode(&linkCode); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &linkCode, nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookDeliveryRetention is how long the webhook delivery log is kept
+const WebhookDeliveryRetention = 30 * 24 * time.Hour
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *models.WebhookDelivery) error
+	// FindBySubscription returns up to limit latest deliveries of the subscription, the most recent first
+	FindBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int64) ([]*models.WebhookDelivery, error)
+	// FindDue returns up to limit pending deliveries which next attempt is due at the given time, the oldest first
+	FindDue(ctx context.Context, at time.Time, limit int64) ([]*models.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *models.WebhookDelivery) error
+	DeleteBySubscription(ctx context.Context, subscriptionID primitive.ObjectID) error
+}
+
+type WebhookDeliveryRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookDeliveryRepository(mongodb *db.MongoDB) (WebhookDeliveryRepository, error) {
+	repository := &WebhookDeliveryRepositoryInstance{
+		collection: mongodb.Collection("webhook_deliveries"),
+	}
+	if err := ensureWebhookDeliveryIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureWebhookDeliveryIndexes(r *WebhookDeliveryRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"status": models.WebhookDeliveryPending}),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index
+		},
+	})
+	return err
+}
+
+func (r *WebhookDeliveryRepositoryInstance) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	if delivery.ExpiresAt.IsZero() {
+		delivery.ExpiresAt = delivery.CreatedAt.Add(WebhookDeliveryRetention)
+	}
+
+	result, err := r.collection.InsertOne(ctx, delivery)
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *WebhookDeliveryRepositoryInstance) FindBySubscription(ctx context.Context, subscriptionID primitive.ObjectID, limit int64) ([]*models.WebhookDelivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	return r.find(ctx, bson.M{"subscription_id": subscriptionID}, opts)
+}
+
+func (r *WebhookDeliveryRepositoryInstance) FindDue(ctx context.Context, at time.Time, limit int64) ([]*models.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":          models.WebhookDeliveryPending,
+		"next_attempt_at": bson.M{"$lte": at},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(limit)
+	return r.find(ctx, filter, opts)
+}
+
+func (r *WebhookDeliveryRepositoryInstance) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*models.WebhookDelivery, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*models.WebhookDelivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepositoryInstance) Update(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+	return err
+}
+
+func (r *WebhookDeliveryRepositoryInstance) DeleteBySubscription(ctx context.Context, subscriptionID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"subscription_id": subscriptionID})
+	return err
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.WebhookSubscription) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookSubscription, error)
+	// FindByLeague returns all webhook subscriptions of the league, the oldest first
+	FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *models.WebhookSubscription) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type WebhookSubscriptionRepositoryInstance struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookSubscriptionRepository(mongodb *db.MongoDB) (WebhookSubscriptionRepository, error) {
+	repository := &WebhookSubscriptionRepositoryInstance{
+		collection: mongodb.Collection("webhook_subscriptions"),
+	}
+	if err := ensureWebhookSubscriptionIndexes(repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func ensureWebhookSubscriptionIndexes(r *WebhookSubscriptionRepositoryInstance) error {
+	_, err := r.collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "league_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *WebhookSubscriptionRepositoryInstance) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = time.Now()
+	subscription.Version = 1
+
+	result, err := r.collection.InsertOne(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	subscription.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *WebhookSubscriptionRepositoryInstance) FindByID(ctx context.Context, id primitive.ObjectID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	filter := bson.M{"_id": id}
+
+	if err := r.collection.FindOne(ctx, filter).Decode(&subscription); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *WebhookSubscriptionRepositoryInstance) FindByLeague(ctx context.Context, leagueID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"league_id": leagueID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []*models.WebhookSubscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepositoryInstance) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	subscription.UpdatedAt = time.Now()
+	subscription.Version++
+
+	filter := bson.M{
+		"_id":     subscription.ID,
+		"version": subscription.Version - 1,
+	}
+
+	update := bson.M{
+		"$set": subscription,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return hexerr.New("webhook subscription not found or version mismatch (optimistic locking)")
+	}
+
+	return nil
+}
+
+func (r *WebhookSubscriptionRepositoryInstance) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
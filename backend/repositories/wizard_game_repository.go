@@ -18,6 +18,7 @@ type WizardGameRepository interface {
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.WizardGame, error)
 	FindByCode(ctx context.Context, code string) (*models.WizardGame, error)
 	FindByGameRoundID(ctx context.Context, gameRoundID primitive.ObjectID) (*models.WizardGame, error)
+	FindByMembership(ctx context.Context, membershipID primitive.ObjectID) ([]*models.WizardGame, error)
 	Update(ctx context.Context, game *models.WizardGame) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	DeleteByCode(ctx context.Context, code string) error
@@ -52,6 +53,9 @@ func (r *wizardGameRepositoryInstance) ensureIndexes() error {
 		{
 			Keys: bson.D{{"status", 1}},
 		},
+		{
+			Keys: bson.D{{"players.membership_id", 1}},
+		},
 		{
 			Keys: bson.D{{"created_at", -1}},
 		},
@@ -109,6 +113,20 @@ func (r *wizardGameRepositoryInstance) FindByGameRoundID(ctx context.Context, ga
 	return &game, nil
 }
 
+func (r *wizardGameRepositoryInstance) FindByMembership(ctx context.Context, membershipID primitive.ObjectID) ([]*models.WizardGame, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"players.membership_id": membershipID})
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find wizard games")
+	}
+	defer cursor.Close(ctx)
+
+	var games []*models.WizardGame
+	if err := cursor.All(ctx, &games); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to decode wizard games")
+	}
+	return games, nil
+}
+
 func (r *wizardGameRepositoryInstance) Update(ctx context.Context, game *models.WizardGame) error {
 	game.UpdatedAt = time.Now()
 
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/hexerr"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -9,15 +13,39 @@ import (
 type AuditAction string
 
 const (
-	AuditActionInviteCreated   AuditAction = "invite_created"
-	AuditActionInviteCancelled AuditAction = "invite_cancelled"
-	AuditActionInviteAccepted  AuditAction = "invite_accepted"
-	AuditActionUserBanned      AuditAction = "user_banned"
-	AuditActionLeagueCreated   AuditAction = "league_created"
-	AuditActionLeagueArchived  AuditAction = "league_archived"
-	AuditActionLeagueUnarchived AuditAction = "league_unarchived"
-	AuditActionGameCreated     AuditAction = "game_created"
-	AuditActionGameFinalized   AuditAction = "game_finalized"
+	AuditActionInviteCreated           AuditAction = "invite_created"
+	AuditActionInviteCancelled         AuditAction = "invite_cancelled"
+	AuditActionInviteExtended          AuditAction = "invite_extended"
+	AuditActionInviteAccepted          AuditAction = "invite_accepted"
+	AuditActionUserBanned              AuditAction = "user_banned"
+	AuditActionUserUnbanned            AuditAction = "user_unbanned"
+	AuditActionLeagueCreated           AuditAction = "league_created"
+	AuditActionLeagueArchived          AuditAction = "league_archived"
+	AuditActionLeagueUnarchived        AuditAction = "league_unarchived"
+	AuditActionGameCreated             AuditAction = "game_created"
+	AuditActionGameFinalized           AuditAction = "game_finalized"
+	AuditActionGameScoresUpdated       AuditAction = "game_scores_updated"
+	AuditActionMemberRoleChanged       AuditAction = "member_role_changed"
+	AuditActionOwnershipTransferred    AuditAction = "ownership_transferred"
+	AuditActionMemberApproved          AuditAction = "member_approved"
+	AuditActionMemberRejected          AuditAction = "member_rejected"
+	AuditActionJoinRequested           AuditAction = "join_requested"
+	AuditActionLeagueVisibilityChanged AuditAction = "league_visibility_changed"
+	AuditActionMembersMerged           AuditAction = "members_merged"
+	AuditActionMemberLeft              AuditAction = "member_left"
+	AuditActionMemberRejoined          AuditAction = "member_rejoined"
+	AuditActionMembersMergeUndone      AuditAction = "members_merge_undone"
+	AuditActionLeagueExported          AuditAction = "league_exported"
+	AuditActionLeagueImported          AuditAction = "league_imported"
+	AuditActionNightScheduled          AuditAction = "night_scheduled"
+	AuditActionNightCancelled          AuditAction = "night_cancelled"
+	AuditActionWebhookCreated          AuditAction = "webhook_created"
+	AuditActionWebhookUpdated          AuditAction = "webhook_updated"
+	AuditActionWebhookDeleted          AuditAction = "webhook_deleted"
+	AuditActionLeagueLanguageChanged   AuditAction = "league_language_changed"
+	AuditActionDiscordWebhookChanged   AuditAction = "discord_webhook_changed"
+	AuditActionTelegramChatLinked      AuditAction = "telegram_chat_linked"
+	AuditActionTelegramChatUnlinked    AuditAction = "telegram_chat_unlinked"
 )
 
 // AuditTargetType represents the type of object being acted upon
@@ -28,19 +56,91 @@ const (
 	AuditTargetInvitation AuditTargetType = "invitation"
 	AuditTargetUser       AuditTargetType = "user"
 	AuditTargetGame       AuditTargetType = "game"
+	AuditTargetMembership AuditTargetType = "membership"
+	AuditTargetGameNight  AuditTargetType = "game_night"
+	AuditTargetWebhook    AuditTargetType = "webhook"
 )
 
 // AuditDetails contains additional information about the audit event
 type AuditDetails map[string]interface{}
 
+// AuditFilter narrows the listed audit log entries, zero fields match everything
+type AuditFilter struct {
+	Action   AuditAction
+	ActorID  primitive.ObjectID
+	TargetID primitive.ObjectID
+}
+
+// AuditPage is a page of audit log entries, the most recent first
+type AuditPage struct {
+	Entries []*models.AuditLog
+	Total   int64
+}
+
 // AuditService defines the interface for logging user actions
 type AuditService interface {
-	// LogAction logs a user action for audit purposes
-	LogAction(ctx context.Context, userID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) error
+	// LogAction logs a user action for audit purposes, leagueID is zero for actions outside of leagues
+	LogAction(ctx context.Context, leagueID, userID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) error
+	// ListLeagueActions returns a page of the league audit log
+	ListLeagueActions(ctx context.Context, leagueID primitive.ObjectID, filter AuditFilter, offset, limit int64) (*AuditPage, error)
+}
+
+type auditServiceInstance struct {
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewAuditService creates an audit service which stores audit logs in MongoDB
+func NewAuditService(auditLogRepo repositories.AuditLogRepository) AuditService {
+	return &auditServiceInstance{auditLogRepo: auditLogRepo}
+}
+
+func (s *auditServiceInstance) LogAction(ctx context.Context, leagueID, userID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) error {
+	log := &models.AuditLog{
+		LeagueID:   leagueID,
+		ActorID:    userID,
+		Action:     string(action),
+		TargetType: string(targetType),
+		TargetID:   targetID,
+		Details:    details,
+	}
+	if err := s.auditLogRepo.Create(ctx, log); err != nil {
+		return hexerr.Wrapf(err, "failed to save audit log")
+	}
+	return nil
+}
+
+func (s *auditServiceInstance) ListLeagueActions(ctx context.Context, leagueID primitive.ObjectID, filter AuditFilter, offset, limit int64) (*AuditPage, error) {
+	if leagueID.IsZero() {
+		return nil, hexerr.New("league is required")
+	}
+	if offset < 0 || limit <= 0 {
+		return nil, hexerr.New("invalid page")
+	}
+
+	logFilter := repositories.AuditLogFilter{
+		LeagueID: leagueID,
+		Action:   string(filter.Action),
+		ActorID:  filter.ActorID,
+		TargetID: filter.TargetID,
+	}
+
+	total, err := s.auditLogRepo.Count(ctx, logFilter)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to count audit logs")
+	}
+
+	entries, err := s.auditLogRepo.Find(ctx, logFilter, offset, limit)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get audit logs")
+	}
+	if entries == nil {
+		entries = []*models.AuditLog{}
+	}
+
+	return &AuditPage{Entries: entries, Total: total}, nil
 }
 
 // NoopAuditService is a no-operation implementation of AuditService
-// TODO: Replace with a real implementation that stores audit logs in MongoDB
 type NoopAuditService struct{}
 
 // NewNoopAuditService creates a new no-op audit service
@@ -49,10 +149,11 @@ func NewNoopAuditService() AuditService {
 }
 
 // LogAction does nothing in the no-op implementation
-func (s *NoopAuditService) LogAction(ctx context.Context, userID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) error {
-	// No-op: This is a placeholder implementation
-	// In a real implementation, this would save the audit log to MongoDB
+func (s *NoopAuditService) LogAction(ctx context.Context, leagueID, userID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) error {
 	return nil
 }
 
-
+// ListLeagueActions returns an empty page in the no-op implementation
+func (s *NoopAuditService) ListLeagueActions(ctx context.Context, leagueID primitive.ObjectID, filter AuditFilter, offset, limit int64) (*AuditPage, error) {
+	return &AuditPage{Entries: []*models.AuditLog{}}, nil
+}
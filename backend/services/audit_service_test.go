@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListLeagueActions_FiltersAndPages(t *testing.T) {
+	ctx := context.Background()
+	mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+	service := NewAuditService(mockAuditLogRepo)
+
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+	logFilter := repositories.AuditLogFilter{LeagueID: leagueID, Action: string(AuditActionUserBanned), ActorID: actorID}
+	entries := []*models.AuditLog{{ID: primitive.NewObjectID(), LeagueID: leagueID, ActorID: actorID, Action: string(AuditActionUserBanned)}}
+
+	mockAuditLogRepo.On("Count", ctx, logFilter).Return(int64(21), nil)
+	mockAuditLogRepo.On("Find", ctx, logFilter, int64(20), int64(10)).Return(entries, nil)
+
+	page, err := service.ListLeagueActions(ctx, leagueID, AuditFilter{Action: AuditActionUserBanned, ActorID: actorID}, 20, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(21), page.Total)
+	assert.Equal(t, entries, page.Entries)
+	mockAuditLogRepo.AssertExpectations(t)
+}
+
+func TestListLeagueActions_RejectsInvalidPage(t *testing.T) {
+	service := NewAuditService(new(mocks.MockAuditLogRepository))
+
+	_, err := service.ListLeagueActions(context.Background(), primitive.NewObjectID(), AuditFilter{}, 0, 0)
+
+	assert.Error(t, err)
+}
+
+func TestBanUserFromLeague_RecordsAuditLog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Ban is recorded with the actor", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			AuditService:   NewAuditService(mockAuditLogRepo),
+		})
+
+		leagueID := primitive.NewObjectID()
+		userID := primitive.NewObjectID()
+		actorID := primitive.NewObjectID()
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Alias: "Bob", Status: models.MembershipActive}
+
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.MatchedBy(func(log *models.AuditLog) bool {
+			return log.LeagueID == leagueID && log.ActorID == actorID && log.TargetID == userID &&
+				log.Action == string(AuditActionUserBanned) && log.TargetType == string(AuditTargetUser) &&
+				log.Details["alias"] == "Bob"
+		})).Return(nil)
+
+		err := service.BanUserFromLeague(ctx, leagueID, userID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipBanned, membership.Status)
+		mockAuditLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("Audit failure doesn't fail the ban", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			AuditService:   NewAuditService(mockAuditLogRepo),
+		})
+
+		leagueID := primitive.NewObjectID()
+		userID := primitive.NewObjectID()
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Status: models.MembershipActive}
+
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(errors.New("database unavailable"))
+
+		err := service.BanUserFromLeague(ctx, leagueID, userID, primitive.NewObjectID())
+
+		assert.NoError(t, err)
+		mockAuditLogRepo.AssertExpectations(t)
+	})
+}
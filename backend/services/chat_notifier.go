@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChatStandingsSize is how many top places of the league standings are posted with a game result
+const ChatStandingsSize = 5
+
+// chatRetryDelays are the delays before repeated attempts to post a chat message, the message is dropped after the last one
+var chatRetryDelays = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute}
+
+// ChatNotifier posts results of finalized league games to a chat outside of the app
+type ChatNotifier interface {
+	// NotifyGameFinalized queues a message about the finalized round, chat failures never reach the caller
+	NotifyGameFinalized(ctx context.Context, round *models.GameRound) error
+	// Start posts queued messages in background until the context is done
+	Start(ctx context.Context)
+}
+
+// ChatNotifiers posts to all the chats of the list
+type ChatNotifiers []ChatNotifier
+
+func (n ChatNotifiers) NotifyGameFinalized(ctx context.Context, round *models.GameRound) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.NotifyGameFinalized(ctx, round); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n ChatNotifiers) Start(ctx context.Context) {
+	for _, notifier := range n {
+		notifier.Start(ctx)
+	}
+}
+
+// ChatGamePlayer is a player of a finalized round as shown in a chat
+type ChatGamePlayer struct {
+	Alias       string
+	Position    int
+	Score       int64
+	Team        string
+	IsModerator bool
+}
+
+// ChatGameResult is a finalized league round with the updated standings, prepared to be posted to a chat
+type ChatGameResult struct {
+	League       *models.League
+	RoundName    string
+	GameTypeName string // In the league language
+	EndTime      time.Time
+	Players      []ChatGamePlayer  // By position, moderators last
+	TopStandings []*LeagueStanding // Up to ChatStandingsSize places
+}
+
+var chatMedals = map[int]string{1: "🥇", 2: "🥈", 3: "🥉"}
+
+// chatTexts are the fixed words of chat messages in one language
+type chatTexts struct {
+	Results     string
+	Standings   string
+	Moderator   string
+	Points      string // Short form, after a number
+	TestMessage string // Has the league name placeholder
+}
+
+var chatTranslations = map[string]chatTexts{
+	"en": {
+		Results:     "Results",
+		Standings:   "Standings (top 5)",
+		Moderator:   "Moderator",
+		Points:      "pts",
+		TestMessage: "Game results of %s will be posted here",
+	},
+	"uk": {
+		Results:     "Результати",
+		Standings:   "Рейтинг (топ-5)",
+		Moderator:   "Модератор",
+		Points:      "оч.",
+		TestMessage: "Сюди надходитимуть результати ігор ліги %s",
+	},
+	"et": {
+		Results:     "Tulemused",
+		Standings:   "Edetabel (top 5)",
+		Moderator:   "Moderaator",
+		Points:      "p",
+		TestMessage: "Siia postitatakse liiga %s mängude tulemused",
+	},
+}
+
+// chatTextsFor returns the chat texts in the language, English for unknown languages
+func chatTextsFor(language string) chatTexts {
+	if texts, ok := chatTranslations[language]; ok {
+		return texts
+	}
+	return chatTranslations[models.DefaultLeagueLanguage]
+}
+
+// buildChatGameResult collects everything a chat message about the finalized league round shows
+func buildChatGameResult(ctx context.Context, leagueService LeagueService, gameTypeRepo repositories.GameTypeRepository,
+	league *models.League, round *models.GameRound) (*ChatGameResult, error) {
+	members, err := leagueService.GetLeagueMemberships(ctx, league.ID)
+	if err != nil {
+		return nil, err
+	}
+	aliases := make(map[primitive.ObjectID]string, len(members))
+	for _, member := range members {
+		aliases[member.MembershipID] = member.UserAlias
+	}
+
+	result := &ChatGameResult{
+		League:    league,
+		RoundName: round.Name,
+		EndTime:   round.EndTime,
+		Players:   make([]ChatGamePlayer, 0, len(round.Players)),
+	}
+
+	gameType, err := gameTypeRepo.FindByID(ctx, round.GameTypeID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game type")
+	}
+	if gameType != nil {
+		result.GameTypeName = gameType.GetName(league.GetLanguage())
+	}
+
+	for _, player := range round.Players {
+		result.Players = append(result.Players, ChatGamePlayer{
+			Alias:       aliases[player.MembershipID],
+			Position:    player.Position,
+			Score:       player.Score,
+			Team:        player.TeamName,
+			IsModerator: player.IsModerator,
+		})
+	}
+	sort.SliceStable(result.Players, func(i, j int) bool {
+		a, b := result.Players[i], result.Players[j]
+		if a.IsModerator != b.IsModerator {
+			return b.IsModerator
+		}
+		// Unplaced players go after the placed ones
+		if (a.Position == 0) != (b.Position == 0) {
+			return b.Position == 0
+		}
+		return a.Position < b.Position
+	})
+
+	standings, err := leagueService.GetLeagueStandings(ctx, league.ID, StandingsFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if len(standings) > ChatStandingsSize {
+		standings = standings[:ChatStandingsSize]
+	}
+	result.TopStandings = standings
+
+	return result, nil
+}
+
+// Title is the game type and the round name, just one of them when they are the same
+func (r *ChatGameResult) Title() string {
+	if r.GameTypeName != "" && r.GameTypeName != r.RoundName {
+		return fmt.Sprintf("%s: %s", r.GameTypeName, r.RoundName)
+	}
+	return r.RoundName
+}
+
+// chatResultLines formats the players of the round one per line, with medals for the first three places
+func chatResultLines(result *ChatGameResult, texts chatTexts) []string {
+	lines := make([]string, 0, len(result.Players))
+	for _, player := range result.Players {
+		if player.IsModerator {
+			lines = append(lines, fmt.Sprintf("%s - %s", player.Alias, texts.Moderator))
+			continue
+		}
+
+		line := player.Alias
+		if player.Team != "" {
+			line = fmt.Sprintf("%s (%s)", line, player.Team)
+		}
+		if medal, ok := chatMedals[player.Position]; ok {
+			line = medal + " " + line
+		} else if player.Position > 0 {
+			line = fmt.Sprintf("%d. %s", player.Position, line)
+		}
+		lines = append(lines, fmt.Sprintf("%s - %d", line, player.Score))
+	}
+	return lines
+}
+
+// chatStandingLines formats the standings one place per line
+func chatStandingLines(standings []*LeagueStanding, texts chatTexts) []string {
+	lines := make([]string, 0, len(standings))
+	for _, standing := range standings {
+		lines = append(lines, fmt.Sprintf("%d. %s - %d %s", standing.Rank, standingAlias(standing), standing.TotalPoints, texts.Points))
+	}
+	return lines
+}
+
+// standingAlias returns the name a standing is shown under in chats
+func standingAlias(standing *LeagueStanding) string {
+	if standing.UserAlias != "" {
+		return standing.UserAlias
+	}
+	return standing.UserName
+}
+
+// chatMessage is a queued chat message
+type chatMessage struct {
+	description string // For logs
+	send        func(ctx context.Context) error
+	attempt     int
+}
+
+// chatQueue posts chat messages in background one by one, so that a slow or unavailable chat never delays the caller
+type chatQueue struct {
+	name        string
+	messages    chan *chatMessage
+	retryDelays []time.Duration
+}
+
+func newChatQueue(name string, size int) *chatQueue {
+	return &chatQueue{
+		name:        name,
+		messages:    make(chan *chatMessage, size),
+		retryDelays: chatRetryDelays,
+	}
+}
+
+// enqueue adds the message to the queue, the message is dropped when the queue is full
+func (q *chatQueue) enqueue(description string, send func(ctx context.Context) error) {
+	q.push(&chatMessage{description: description, send: send})
+}
+
+func (q *chatQueue) push(message *chatMessage) {
+	select {
+	case q.messages <- message:
+	default:
+		glog.Warn("%s queue is full, dropped %s", q.name, message.description)
+	}
+}
+
+// run posts queued messages until the context is done, failed messages are queued again after a delay
+func (q *chatQueue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-q.messages:
+			err := message.send(ctx)
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			if message.attempt >= len(q.retryDelays) {
+				glog.Warn("Failed to post %s to %s, giving up: %v", message.description, q.name, err)
+				continue
+			}
+
+			glog.Warn("Failed to post %s to %s, will retry: %v", message.description, q.name, err)
+			delay := q.retryDelays[message.attempt]
+			message.attempt++
+			time.AfterFunc(delay, func() { q.push(message) })
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	discordEmbedColor      = 0x5865F2
+	discordFieldValueLimit = 1024
+	discordQueueSize       = 100
+)
+
+// discordWebhookHosts are the hosts Discord issues channel webhook URLs on
+var discordWebhookHosts = []string{"discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com"}
+
+// DiscordNotifier posts results of finalized games to the Discord channels of leagues
+type DiscordNotifier interface {
+	ChatNotifier
+	// SetWebhookURL connects the league to a Discord channel webhook, an empty URL disconnects it
+	SetWebhookURL(ctx context.Context, leagueID primitive.ObjectID, webhookURL string, actorID primitive.ObjectID) (*models.League, error)
+	// SendTestMessage posts a test message to the league channel right away
+	SendTestMessage(ctx context.Context, leagueID primitive.ObjectID) error
+}
+
+type discordNotifierInstance struct {
+	leagueService LeagueService
+	leagueRepo    repositories.LeagueRepository
+	gameTypeRepo  repositories.GameTypeRepository
+	auditService  AuditService
+	queue         *chatQueue
+}
+
+func NewDiscordNotifier(leagueService LeagueService, leagueRepo repositories.LeagueRepository, gameTypeRepo repositories.GameTypeRepository,
+	auditService AuditService) DiscordNotifier {
+	return &discordNotifierInstance{
+		leagueService: leagueService,
+		leagueRepo:    leagueRepo,
+		gameTypeRepo:  gameTypeRepo,
+		auditService:  auditService,
+		queue:         newChatQueue("Discord", discordQueueSize),
+	}
+}
+
+func (s *discordNotifierInstance) NotifyGameFinalized(ctx context.Context, round *models.GameRound) error {
+	if round.LeagueID.IsZero() {
+		return nil
+	}
+
+	league, err := s.leagueService.GetLeague(ctx, round.LeagueID)
+	if err != nil {
+		return err
+	}
+	if league.DiscordWebhookURL == "" {
+		return nil
+	}
+
+	result, err := buildChatGameResult(ctx, s.leagueService, s.gameTypeRepo, league, round)
+	if err != nil {
+		return err
+	}
+
+	webhookURL, message := league.DiscordWebhookURL, DiscordGameMessage(result)
+	s.queue.enqueue(fmt.Sprintf("result of %s in %s", round.Name, league.Name), func(ctx context.Context) error {
+		return utils.SendDiscordMessage(ctx, webhookURL, message)
+	})
+	return nil
+}
+
+func (s *discordNotifierInstance) Start(ctx context.Context) {
+	go s.queue.run(ctx)
+}
+
+func (s *discordNotifierInstance) SetWebhookURL(ctx context.Context, leagueID primitive.ObjectID, webhookURL string, actorID primitive.ObjectID) (*models.League, error) {
+	webhookURL = strings.TrimSpace(webhookURL)
+	if webhookURL != "" {
+		if err := ValidateDiscordWebhookURL(webhookURL); err != nil {
+			return nil, err
+		}
+	}
+
+	league, err := s.leagueService.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league.DiscordWebhookURL == webhookURL {
+		return league, nil
+	}
+
+	league.DiscordWebhookURL = webhookURL
+	if err := s.leagueRepo.Update(ctx, league); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update league")
+	}
+
+	// The URL is a credential, only whether the league is connected is recorded
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, AuditActionDiscordWebhookChanged, AuditTargetLeague, leagueID,
+		AuditDetails{"connected": webhookURL != ""}); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", AuditActionDiscordWebhookChanged, leagueID.Hex(), err)
+	}
+
+	return league, nil
+}
+
+func (s *discordNotifierInstance) SendTestMessage(ctx context.Context, leagueID primitive.ObjectID) error {
+	league, err := s.leagueService.GetLeague(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+	if league.DiscordWebhookURL == "" {
+		return hexerr.New("league is not connected to Discord")
+	}
+
+	texts := chatTextsFor(league.GetLanguage())
+	return utils.SendDiscordMessage(ctx, league.DiscordWebhookURL, utils.DiscordMessage{
+		Embeds: []utils.DiscordEmbed{{
+			Title:  league.Name,
+			Color:  discordEmbedColor,
+			Footer: &utils.DiscordEmbedFooter{Text: fmt.Sprintf(texts.TestMessage, league.Name)},
+		}},
+	})
+}
+
+// ValidateDiscordWebhookURL checks that the URL is a Discord channel webhook, so that league admins can't make the server call other hosts
+func ValidateDiscordWebhookURL(webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" || !slices.Contains(discordWebhookHosts, parsed.Hostname()) ||
+		!strings.HasPrefix(parsed.Path, "/api/webhooks/") {
+		return hexerr.New("not a Discord webhook URL, expected https://discord.com/api/webhooks/...")
+	}
+	return nil
+}
+
+// DiscordGameMessage formats the game result as a Discord embed in the league language
+func DiscordGameMessage(result *ChatGameResult) utils.DiscordMessage {
+	texts := chatTextsFor(result.League.GetLanguage())
+	players := chatResultLines(result, texts)
+	standings := chatStandingLines(result.TopStandings, texts)
+
+	embed := utils.DiscordEmbed{
+		Title:  result.Title(),
+		Color:  discordEmbedColor,
+		Footer: &utils.DiscordEmbedFooter{Text: result.League.Name},
+	}
+	if len(players) > 0 {
+		embed.Fields = append(embed.Fields, utils.DiscordEmbedField{Name: texts.Results, Value: discordFieldValue(players)})
+	}
+	if len(standings) > 0 {
+		embed.Fields = append(embed.Fields, utils.DiscordEmbedField{Name: texts.Standings, Value: discordFieldValue(standings)})
+	}
+	if !result.EndTime.IsZero() {
+		embed.Timestamp = result.EndTime.UTC().Format(time.RFC3339)
+	}
+
+	return utils.DiscordMessage{Embeds: []utils.DiscordEmbed{embed}}
+}
+
+// discordFieldValue joins the lines, cutting them to the Discord limit of the field value
+func discordFieldValue(lines []string) string {
+	value := strings.Join(lines, "\n")
+	if len(value) <= discordFieldValueLimit {
+		return value
+	}
+
+	// Cut at the last full line which fits together with the ellipsis
+	cut := strings.LastIndex(value[:discordFieldValueLimit-len("\n…")], "\n")
+	if cut < 0 {
+		cut = 0
+	}
+	return value[:cut] + "\n…"
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// chatLeagueService serves a league with its members and standings to the chat notifiers
+type chatLeagueService struct {
+	LeagueService
+	league    *models.League
+	others    []*models.League // Leagues without members and games
+	members   []*LeagueMemberInfo
+	standings []*LeagueStanding
+	lastRound *models.GameRound
+}
+
+func (s *chatLeagueService) GetLeague(ctx context.Context, leagueID primitive.ObjectID) (*models.League, error) {
+	for _, league := range s.others {
+		if league.ID == leagueID {
+			return league, nil
+		}
+	}
+	return s.league, nil
+}
+
+func (s *chatLeagueService) GetLastFinishedRound(ctx context.Context, leagueID primitive.ObjectID) (*models.GameRound, error) {
+	return s.lastRound, nil
+}
+
+func (s *chatLeagueService) GetLeagueMemberships(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueMemberInfo, error) {
+	return s.members, nil
+}
+
+func (s *chatLeagueService) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID, filter StandingsFilter) ([]*LeagueStanding, error) {
+	return s.standings, nil
+}
+
+// discordReceiver is a Discord channel webhook which records posted messages, the first failures answers fail
+type discordReceiver struct {
+	mu       sync.Mutex
+	failures int
+	messages []utils.DiscordMessage
+	received chan struct{}
+}
+
+func newDiscordReceiver(t *testing.T, failures int) (*discordReceiver, *httptest.Server) {
+	receiver := &discordReceiver{failures: failures, received: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+
+		if receiver.failures > 0 {
+			receiver.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var message utils.DiscordMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		receiver.messages = append(receiver.messages, message)
+		w.WriteHeader(http.StatusNoContent)
+		receiver.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func (r *discordReceiver) wait(t *testing.T) bool {
+	select {
+	case <-r.received:
+		return true
+	case <-time.After(2 * time.Second):
+		t.Error("no message was posted to Discord")
+		return false
+	}
+}
+
+func newChatFixture(webhookURL string) (*chatLeagueService, *models.GameRound, *mocks.MockGameTypeRepository) {
+	leagueID := primitive.NewObjectID()
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	leagueService := &chatLeagueService{
+		league: &models.League{ID: leagueID, Name: "Friday club", Language: "uk", DiscordWebhookURL: webhookURL},
+		members: []*LeagueMemberInfo{
+			{MembershipID: alice, UserAlias: "Alice"},
+			{MembershipID: bob, UserAlias: "Bob"},
+			{MembershipID: carol, UserAlias: "Carol"},
+		},
+		standings: []*LeagueStanding{
+			{Rank: 1, MembershipID: alice, UserAlias: "Alice", TotalPoints: 42},
+			{Rank: 2, MembershipID: bob, UserAlias: "Bob", TotalPoints: 30},
+		},
+	}
+
+	round := &models.GameRound{
+		ID:         primitive.NewObjectID(),
+		LeagueID:   leagueID,
+		GameTypeID: primitive.NewObjectID(),
+		Name:       "Round 7",
+		EndTime:    time.Date(2026, 3, 6, 21, 30, 0, 0, time.UTC),
+		Players: []models.GameRoundPlayer{
+			{MembershipID: carol, IsModerator: true},
+			{MembershipID: bob, Position: 2, Score: 8},
+			{MembershipID: alice, Position: 1, Score: 12},
+		},
+	}
+
+	gameTypeRepo := new(mocks.MockGameTypeRepository)
+	gameTypeRepo.On("FindByID", mock.Anything, round.GameTypeID).
+		Return(&models.GameType{Key: "mafia", Names: map[string]string{"en": "Mafia", "uk": "Мафія"}}, nil)
+
+	return leagueService, round, gameTypeRepo
+}
+
+func TestDiscordNotifier_PostsLocalizedEmbed(t *testing.T) {
+	receiver, server := newDiscordReceiver(t, 0)
+	leagueService, round, gameTypeRepo := newChatFixture(server.URL + "/api/webhooks/1/token")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := NewDiscordNotifier(leagueService, new(mocks.MockLeagueRepository), gameTypeRepo, NewNoopAuditService())
+	notifier.Start(ctx)
+
+	if !assert.NoError(t, notifier.NotifyGameFinalized(ctx, round)) || !receiver.wait(t) {
+		return
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if !assert.Len(t, receiver.messages, 1) || !assert.Len(t, receiver.messages[0].Embeds, 1) {
+		return
+	}
+	embed := receiver.messages[0].Embeds[0]
+	assert.Equal(t, "Мафія: Round 7", embed.Title)
+	assert.Equal(t, "Friday club", embed.Footer.Text)
+	assert.Equal(t, "2026-03-06T21:30:00Z", embed.Timestamp)
+	if assert.Len(t, embed.Fields, 2) {
+		assert.Equal(t, "Результати", embed.Fields[0].Name)
+		assert.Equal(t, "🥇 Alice - 12\n🥈 Bob - 8\nCarol - Модератор", embed.Fields[0].Value)
+		assert.Equal(t, "Рейтинг (топ-5)", embed.Fields[1].Name)
+		assert.Equal(t, "1. Alice - 42 оч.\n2. Bob - 30 оч.", embed.Fields[1].Value)
+	}
+}
+
+func TestDiscordNotifier_RetriesWhenDiscordIsDown(t *testing.T) {
+	receiver, server := newDiscordReceiver(t, 2)
+	leagueService, round, gameTypeRepo := newChatFixture(server.URL + "/api/webhooks/1/token")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := NewDiscordNotifier(leagueService, new(mocks.MockLeagueRepository), gameTypeRepo, NewNoopAuditService())
+	notifier.(*discordNotifierInstance).queue.retryDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	notifier.Start(ctx)
+
+	// Finalization doesn't wait for Discord, so the failures never reach it
+	assert.NoError(t, notifier.NotifyGameFinalized(ctx, round))
+	if receiver.wait(t) {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		assert.Len(t, receiver.messages, 1)
+	}
+}
+
+func TestDiscordNotifier_SkipsLeaguesWithoutChannel(t *testing.T) {
+	leagueService, round, gameTypeRepo := newChatFixture("")
+	notifier := NewDiscordNotifier(leagueService, new(mocks.MockLeagueRepository), gameTypeRepo, NewNoopAuditService())
+
+	assert.NoError(t, notifier.NotifyGameFinalized(context.Background(), round))
+	assert.Empty(t, notifier.(*discordNotifierInstance).queue.messages)
+}
+
+func TestDiscordNotifier_SetWebhookURL(t *testing.T) {
+	ctx := context.Background()
+	leagueService, _, gameTypeRepo := newChatFixture("")
+	leagueRepo := new(mocks.MockLeagueRepository)
+	leagueRepo.On("Update", ctx, leagueService.league).Return(nil)
+	notifier := NewDiscordNotifier(leagueService, leagueRepo, gameTypeRepo, NewNoopAuditService())
+	actorID := primitive.NewObjectID()
+
+	for _, webhookURL := range []string{
+		"http://discord.com/api/webhooks/1/token",
+		"https://example.com/api/webhooks/1/token",
+		"https://discord.com.example.com/api/webhooks/1/token",
+		"https://discord.com/channels/1/2",
+	} {
+		_, err := notifier.SetWebhookURL(ctx, leagueService.league.ID, webhookURL, actorID)
+		assert.Error(t, err, webhookURL)
+	}
+	leagueRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	league, err := notifier.SetWebhookURL(ctx, leagueService.league.ID, " https://discord.com/api/webhooks/1/token ", actorID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://discord.com/api/webhooks/1/token", league.DiscordWebhookURL)
+	}
+
+	league, err = notifier.SetWebhookURL(ctx, leagueService.league.ID, "", actorID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, league.DiscordWebhookURL)
+	}
+	leagueRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestDiscordFieldValue_CutsAtLimit(t *testing.T) {
+	lines := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "1234567890123456789")
+	}
+
+	value := discordFieldValue(lines)
+	assert.LessOrEqual(t, len(value), discordFieldValueLimit)
+	assert.Contains(t, value, "\n…")
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultGameNightDuration is used when a night is scheduled without an end time
+const DefaultGameNightDuration = 4 * time.Hour
+
+// GameNightInput holds the editable fields of a game night
+type GameNightInput struct {
+	Title     string
+	StartTime time.Time
+	EndTime   time.Time // zero - DefaultGameNightDuration after the start
+	Timezone  string    // IANA zone name, empty - UTC
+	Location  string
+	Notes     string
+}
+
+// GameNightRound is a round played on a game night with its winners
+type GameNightRound struct {
+	Round    *models.GameRound
+	GameType *models.GameType     // nil if the game type was deleted
+	Winners  []primitive.ObjectID // Memberships who won the round, empty while it's not finished
+}
+
+// GameNightSummary lists the rounds played on a game night
+type GameNightSummary struct {
+	Night  *models.GameNight
+	Rounds []GameNightRound
+	Wins   map[primitive.ObjectID]int // Rounds won by each membership
+}
+
+// GameNightService schedules league game sessions, collects RSVPs and issues personal calendar feed tokens
+type GameNightService interface {
+	ScheduleNight(ctx context.Context, leagueID, actorID primitive.ObjectID, input GameNightInput) (*models.GameNight, error)
+	GetNight(ctx context.Context, leagueID, nightID primitive.ObjectID) (*models.GameNight, error)
+	// ListNights returns league nights, the earliest first; upcomingOnly skips nights which already ended
+	ListNights(ctx context.Context, leagueID primitive.ObjectID, upcomingOnly bool) ([]*models.GameNight, error)
+	UpdateNight(ctx context.Context, leagueID, nightID primitive.ObjectID, input GameNightInput) (*models.GameNight, error)
+	CancelNight(ctx context.Context, leagueID, nightID, actorID primitive.ObjectID) (*models.GameNight, error)
+	// RespondToNight records the RSVP of a league member, a new answer replaces the previous one
+	RespondToNight(ctx context.Context, leagueID, nightID, membershipID primitive.ObjectID, response models.RSVPResponse) (*models.GameNight, error)
+	// GetNightForRound returns the night a new round can be linked to, cancelled nights are refused
+	GetNightForRound(ctx context.Context, leagueID, nightID primitive.ObjectID) (*models.GameNight, error)
+	// GetNightSummary returns the rounds played on the night and their winners
+	GetNightSummary(ctx context.Context, leagueID, nightID primitive.ObjectID) (*GameNightSummary, error)
+
+	// GetCalendarToken returns the calendar feed token of the user in the league, creating it on first use
+	GetCalendarToken(ctx context.Context, leagueID, userID primitive.ObjectID) (string, error)
+	// ResetCalendarToken replaces the calendar feed token, the old feed URL stops working
+	ResetCalendarToken(ctx context.Context, leagueID, userID primitive.ObjectID) (string, error)
+	// GetCalendarMembership resolves the active league membership owning the calendar feed token
+	GetCalendarMembership(ctx context.Context, leagueID primitive.ObjectID, token string) (*models.LeagueMembership, error)
+}
+
+type gameNightServiceInstance struct {
+	nightRepo      repositories.GameNightRepository
+	membershipRepo repositories.LeagueMembershipRepository
+	gameRoundRepo  repositories.GameRoundRepository
+	gameTypeRepo   repositories.GameTypeRepository
+	auditService   AuditService
+}
+
+func NewGameNightService(nightRepo repositories.GameNightRepository, membershipRepo repositories.LeagueMembershipRepository, gameRoundRepo repositories.GameRoundRepository, gameTypeRepo repositories.GameTypeRepository, auditService AuditService) GameNightService {
+	return &gameNightServiceInstance{
+		nightRepo:      nightRepo,
+		membershipRepo: membershipRepo,
+		gameRoundRepo:  gameRoundRepo,
+		gameTypeRepo:   gameTypeRepo,
+		auditService:   auditService,
+	}
+}
+
+// ParseNightTime parses RFC 3339 time or a local time without offset ("2006-01-02T15:04") in the IANA zone,
+// so that a night planned at 19:00 stays at 19:00 local time across daylight saving changes
+func ParseNightTime(value, timezone string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), nil
+	}
+
+	location, err := loadNightLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if at, err := time.ParseInLocation(layout, value, location); err == nil {
+			return at.UTC(), nil
+		}
+	}
+	return time.Time{}, hexerr.New("invalid time " + value + ", expected RFC 3339 or local time like 2006-01-02T15:04")
+}
+
+func loadNightLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, hexerr.New("unknown timezone " + timezone)
+	}
+	return location, nil
+}
+
+func (s *gameNightServiceInstance) ScheduleNight(ctx context.Context, leagueID, actorID primitive.ObjectID, input GameNightInput) (*models.GameNight, error) {
+	night := &models.GameNight{
+		LeagueID:  leagueID,
+		Status:    models.GameNightScheduled,
+		CreatedBy: actorID,
+	}
+	if err := applyGameNightInput(night, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.nightRepo.Create(ctx, night); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to schedule game night")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionNightScheduled, night.ID, AuditDetails{
+		"title":      night.Title,
+		"start_time": night.StartTime,
+	})
+
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) GetNight(ctx context.Context, leagueID, nightID primitive.ObjectID) (*models.GameNight, error) {
+	night, err := s.nightRepo.FindByID(ctx, nightID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game night")
+	}
+	if night == nil || night.LeagueID != leagueID {
+		return nil, hexerr.New("game night not found")
+	}
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) ListNights(ctx context.Context, leagueID primitive.ObjectID, upcomingOnly bool) ([]*models.GameNight, error) {
+	var endingAfter time.Time
+	if upcomingOnly {
+		endingAfter = time.Now()
+	}
+
+	nights, err := s.nightRepo.FindByLeague(ctx, leagueID, endingAfter)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list game nights")
+	}
+	return nights, nil
+}
+
+func (s *gameNightServiceInstance) UpdateNight(ctx context.Context, leagueID, nightID primitive.ObjectID, input GameNightInput) (*models.GameNight, error) {
+	night, err := s.GetNight(ctx, leagueID, nightID)
+	if err != nil {
+		return nil, err
+	}
+	if night.Status == models.GameNightCancelled {
+		return nil, hexerr.New("game night is cancelled")
+	}
+
+	if err := applyGameNightInput(night, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.nightRepo.Update(ctx, night); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update game night")
+	}
+
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) CancelNight(ctx context.Context, leagueID, nightID, actorID primitive.ObjectID) (*models.GameNight, error) {
+	night, err := s.GetNight(ctx, leagueID, nightID)
+	if err != nil {
+		return nil, err
+	}
+	if night.Status == models.GameNightCancelled {
+		return nil, hexerr.New("game night is already cancelled")
+	}
+
+	// The night is kept, so that subscribed calendars remove the event instead of keeping a stale copy
+	night.Status = models.GameNightCancelled
+	if err := s.nightRepo.Update(ctx, night); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to cancel game night")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionNightCancelled, night.ID, AuditDetails{"title": night.Title})
+
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) RespondToNight(ctx context.Context, leagueID, nightID, membershipID primitive.ObjectID, response models.RSVPResponse) (*models.GameNight, error) {
+	if !response.IsValid() {
+		return nil, hexerr.New("response must be yes, no or maybe")
+	}
+
+	night, err := s.GetNight(ctx, leagueID, nightID)
+	if err != nil {
+		return nil, err
+	}
+	if night.Status == models.GameNightCancelled {
+		return nil, hexerr.New("game night is cancelled")
+	}
+	if !night.EndTime.After(time.Now()) {
+		return nil, hexerr.New("game night is over")
+	}
+
+	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.LeagueID != leagueID || membership.Status != models.MembershipActive {
+		return nil, hexerr.New("only active league members can respond")
+	}
+
+	if rsvp := night.RSVPOf(membershipID); rsvp != nil {
+		rsvp.Response = response
+		rsvp.RespondedAt = time.Now()
+	} else {
+		night.RSVPs = append(night.RSVPs, models.GameNightRSVP{
+			MembershipID: membershipID,
+			Response:     response,
+			RespondedAt:  time.Now(),
+		})
+	}
+
+	if err := s.nightRepo.Update(ctx, night); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to save RSVP")
+	}
+
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) GetNightForRound(ctx context.Context, leagueID, nightID primitive.ObjectID) (*models.GameNight, error) {
+	night, err := s.GetNight(ctx, leagueID, nightID)
+	if err != nil {
+		return nil, err
+	}
+	if night.Status == models.GameNightCancelled {
+		return nil, hexerr.New("game night is cancelled")
+	}
+	return night, nil
+}
+
+func (s *gameNightServiceInstance) GetNightSummary(ctx context.Context, leagueID, nightID primitive.ObjectID) (*GameNightSummary, error) {
+	night, err := s.GetNight(ctx, leagueID, nightID)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds, err := s.gameRoundRepo.FindByNight(ctx, nightID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game night rounds")
+	}
+
+	summary := &GameNightSummary{
+		Night:  night,
+		Rounds: make([]GameNightRound, 0, len(rounds)),
+		Wins:   make(map[primitive.ObjectID]int),
+	}
+	gameTypes := make(map[primitive.ObjectID]*models.GameType)
+	for _, round := range rounds {
+		gameType, ok := gameTypes[round.GameTypeID]
+		if !ok && !round.GameTypeID.IsZero() {
+			if gameType, err = s.gameTypeRepo.FindByID(ctx, round.GameTypeID); err != nil {
+				return nil, hexerr.Wrapf(err, "failed to get game type")
+			}
+			gameTypes[round.GameTypeID] = gameType
+		}
+
+		var scoringType models.ScoringType
+		if gameType != nil {
+			scoringType = gameType.ScoringType
+		}
+		winners := RoundWinners(round, scoringType)
+		for _, membershipID := range winners {
+			summary.Wins[membershipID]++
+		}
+		summary.Rounds = append(summary.Rounds, GameNightRound{Round: round, GameType: gameType, Winners: winners})
+	}
+
+	return summary, nil
+}
+
+// RoundWinners returns memberships who won a finished round: the 1st place, the winning team,
+// or all players of a won cooperative game. Draws of teams have no winners
+func RoundWinners(round *models.GameRound, scoringType models.ScoringType) []primitive.ObjectID {
+	if round.EndTime.IsZero() {
+		return nil
+	}
+
+	var teamResults map[string]TeamResult
+	if IsTeamScoring(scoringType) {
+		teamResults = TeamResults(round)
+	}
+	coopResult := IsCooperativeScoring(scoringType) && round.CooperativeWin != nil
+
+	var winners []primitive.ObjectID
+	for _, player := range round.Players {
+		if player.MembershipID.IsZero() {
+			continue
+		}
+		var won bool
+		switch {
+		case coopResult:
+			won = *round.CooperativeWin && !player.IsModerator
+		case teamResults != nil:
+			won = player.TeamName != "" && teamResults[player.TeamName] == TeamWin
+		default:
+			won = player.Position == 1
+		}
+		if won {
+			winners = append(winners, player.MembershipID)
+		}
+	}
+	return winners
+}
+
+func (s *gameNightServiceInstance) GetCalendarToken(ctx context.Context, leagueID, userID primitive.ObjectID) (string, error) {
+	membership, err := s.activeMembership(ctx, leagueID, userID)
+	if err != nil {
+		return "", err
+	}
+	if membership.CalendarToken != "" {
+		return membership.CalendarToken, nil
+	}
+	return s.issueCalendarToken(ctx, membership)
+}
+
+func (s *gameNightServiceInstance) ResetCalendarToken(ctx context.Context, leagueID, userID primitive.ObjectID) (string, error) {
+	membership, err := s.activeMembership(ctx, leagueID, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.issueCalendarToken(ctx, membership)
+}
+
+func (s *gameNightServiceInstance) GetCalendarMembership(ctx context.Context, leagueID primitive.ObjectID, token string) (*models.LeagueMembership, error) {
+	if token == "" {
+		return nil, hexerr.New("calendar token is required")
+	}
+
+	membership, err := s.membershipRepo.FindByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find calendar token")
+	}
+	// Members who left or were banned lose the feed, the token is kept in case they come back
+	if membership == nil || membership.LeagueID != leagueID || membership.Status != models.MembershipActive {
+		return nil, hexerr.New("calendar not found")
+	}
+	return membership, nil
+}
+
+func (s *gameNightServiceInstance) activeMembership(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.Status != models.MembershipActive {
+		return nil, hexerr.New("you are not an active member of this league")
+	}
+	return membership, nil
+}
+
+func (s *gameNightServiceInstance) issueCalendarToken(ctx context.Context, membership *models.LeagueMembership) (string, error) {
+	token, err := generateInvitationToken()
+	if err != nil {
+		return "", hexerr.Wrapf(err, "failed to generate calendar token")
+	}
+
+	membership.CalendarToken = token
+	if err := s.membershipRepo.Update(ctx, membership); err != nil {
+		return "", hexerr.Wrapf(err, "failed to save calendar token")
+	}
+	return token, nil
+}
+
+func (s *gameNightServiceInstance) logAction(ctx context.Context, leagueID, actorID primitive.ObjectID, action AuditAction, nightID primitive.ObjectID, details AuditDetails) {
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, action, AuditTargetGameNight, nightID, details); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+	}
+}
+
+// applyGameNightInput validates the input and copies it into the night
+func applyGameNightInput(night *models.GameNight, input GameNightInput) error {
+	if input.StartTime.IsZero() {
+		return hexerr.New("game night start time is required")
+	}
+	timezone := strings.TrimSpace(input.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := loadNightLocation(timezone); err != nil {
+		return err
+	}
+
+	endTime := input.EndTime
+	if endTime.IsZero() {
+		endTime = input.StartTime.Add(DefaultGameNightDuration)
+	}
+	if !endTime.After(input.StartTime) {
+		return hexerr.New("game night end must be after its start")
+	}
+
+	night.Title = strings.TrimSpace(input.Title)
+	if night.Title == "" {
+		night.Title = "Game night"
+	}
+	night.StartTime = input.StartTime.UTC()
+	night.EndTime = endTime.UTC()
+	night.Timezone = timezone
+	night.Location = strings.TrimSpace(input.Location)
+	night.Notes = strings.TrimSpace(input.Notes)
+	return nil
+}
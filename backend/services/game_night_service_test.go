@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseNightTime_LocalTimeFollowsDaylightSaving(t *testing.T) {
+	winter, err := ParseNightTime("2026-01-16T19:00", "Europe/Kyiv")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2026, 1, 16, 17, 0, 0, 0, time.UTC), winter)
+	}
+
+	summer, err := ParseNightTime("2026-07-17T19:00", "Europe/Kyiv")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2026, 7, 17, 16, 0, 0, 0, time.UTC), summer)
+	}
+
+	// An explicit offset wins over the timezone
+	withOffset, err := ParseNightTime("2026-07-17T19:00:00+01:00", "Europe/Kyiv")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2026, 7, 17, 18, 0, 0, 0, time.UTC), withOffset)
+	}
+
+	_, err = ParseNightTime("2026-07-17T19:00", "Mars/Olympus")
+	assert.Error(t, err)
+	_, err = ParseNightTime("next friday", "")
+	assert.Error(t, err)
+}
+
+func TestScheduleNight_DefaultsAndValidation(t *testing.T) {
+	ctx := context.Background()
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	service := NewGameNightService(mockNightRepo, nil, nil, nil, NewNoopAuditService())
+	mockNightRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	leagueID, actorID := primitive.NewObjectID(), primitive.NewObjectID()
+	start := time.Date(2026, 11, 6, 17, 0, 0, 0, time.UTC)
+
+	night, err := service.ScheduleNight(ctx, leagueID, actorID, GameNightInput{StartTime: start, Location: " Club "})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Game night", night.Title)
+		assert.Equal(t, "UTC", night.Timezone)
+		assert.Equal(t, "Club", night.Location)
+		assert.Equal(t, start.Add(DefaultGameNightDuration), night.EndTime)
+		assert.Equal(t, models.GameNightScheduled, night.Status)
+		assert.Equal(t, actorID, night.CreatedBy)
+	}
+
+	_, err = service.ScheduleNight(ctx, leagueID, actorID, GameNightInput{StartTime: start, EndTime: start.Add(-time.Hour)})
+	assert.Error(t, err)
+	_, err = service.ScheduleNight(ctx, leagueID, actorID, GameNightInput{StartTime: start, Timezone: "Nowhere/City"})
+	assert.Error(t, err)
+	_, err = service.ScheduleNight(ctx, leagueID, actorID, GameNightInput{})
+	assert.Error(t, err)
+
+	mockNightRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestRespondToNight(t *testing.T) {
+	ctx := context.Background()
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	service := NewGameNightService(mockNightRepo, mockMembershipRepo, nil, nil, NewNoopAuditService())
+
+	leagueID := primitive.NewObjectID()
+	member := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.MembershipActive}
+	stranger := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID(), Status: models.MembershipActive}
+	night := &models.GameNight{
+		ID:        primitive.NewObjectID(),
+		LeagueID:  leagueID,
+		Status:    models.GameNightScheduled,
+		StartTime: time.Now().Add(24 * time.Hour),
+		EndTime:   time.Now().Add(28 * time.Hour),
+	}
+	pastNight := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.GameNightScheduled,
+		StartTime: time.Now().Add(-28 * time.Hour), EndTime: time.Now().Add(-24 * time.Hour)}
+
+	mockNightRepo.On("FindByID", ctx, night.ID).Return(night, nil)
+	mockNightRepo.On("FindByID", ctx, pastNight.ID).Return(pastNight, nil)
+	mockNightRepo.On("Update", ctx, night).Return(nil)
+	mockMembershipRepo.On("FindByID", ctx, member.ID).Return(member, nil)
+	mockMembershipRepo.On("FindByID", ctx, stranger.ID).Return(stranger, nil)
+
+	_, err := service.RespondToNight(ctx, leagueID, night.ID, member.ID, models.RSVPMaybe)
+	assert.NoError(t, err)
+	updated, err := service.RespondToNight(ctx, leagueID, night.ID, member.ID, models.RSVPYes)
+	if assert.NoError(t, err) && assert.Len(t, updated.RSVPs, 1) {
+		assert.Equal(t, models.RSVPYes, updated.RSVPs[0].Response)
+	}
+
+	_, err = service.RespondToNight(ctx, leagueID, night.ID, stranger.ID, models.RSVPYes)
+	assert.Error(t, err)
+	_, err = service.RespondToNight(ctx, leagueID, night.ID, member.ID, "sure")
+	assert.Error(t, err)
+	_, err = service.RespondToNight(ctx, leagueID, pastNight.ID, member.ID, models.RSVPNo)
+	assert.Error(t, err)
+}
+
+func TestCalendarToken(t *testing.T) {
+	ctx := context.Background()
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	service := NewGameNightService(nil, mockMembershipRepo, nil, nil, NewNoopAuditService())
+
+	leagueID, userID := primitive.NewObjectID(), primitive.NewObjectID()
+	membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Status: models.MembershipActive}
+	mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+	mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+
+	token, err := service.GetCalendarToken(ctx, leagueID, userID)
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, token) {
+		return
+	}
+	again, err := service.GetCalendarToken(ctx, leagueID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+	mockMembershipRepo.AssertNumberOfCalls(t, "Update", 1)
+
+	reset, err := service.ResetCalendarToken(ctx, leagueID, userID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, reset)
+
+	mockMembershipRepo.On("FindByCalendarToken", ctx, reset).Return(membership, nil)
+	found, err := service.GetCalendarMembership(ctx, leagueID, reset)
+	assert.NoError(t, err)
+	assert.Equal(t, membership, found)
+
+	_, err = service.GetCalendarMembership(ctx, primitive.NewObjectID(), reset)
+	assert.Error(t, err, "token of another league")
+
+	membership.Status = models.MembershipLeft
+	_, err = service.GetCalendarMembership(ctx, leagueID, reset)
+	assert.Error(t, err, "member left the league")
+}
+
+func TestRoundWinners(t *testing.T) {
+	first, second, host := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	end := time.Date(2026, 11, 6, 20, 0, 0, 0, time.UTC)
+	won, lost := true, false
+
+	classic := &models.GameRound{EndTime: end, Players: []models.GameRoundPlayer{{MembershipID: second, Position: 2}, {MembershipID: first, Position: 1}}}
+	assert.Equal(t, []primitive.ObjectID{first}, RoundWinners(classic, models.ScoringTypeClassic))
+
+	unfinished := &models.GameRound{Players: classic.Players}
+	assert.Empty(t, RoundWinners(unfinished, models.ScoringTypeClassic))
+
+	team := &models.GameRound{EndTime: end,
+		TeamScores: []models.TeamScore{{Name: "town", Score: 1}, {Name: "mafia", Score: 0}},
+		Players:    []models.GameRoundPlayer{{MembershipID: first, TeamName: "town"}, {MembershipID: second, TeamName: "mafia"}, {MembershipID: host, IsModerator: true}}}
+	assert.Equal(t, []primitive.ObjectID{first}, RoundWinners(team, models.ScoringTypeMafia))
+
+	draw := &models.GameRound{EndTime: end, TeamScores: []models.TeamScore{{Name: "town", Score: 1}, {Name: "mafia", Score: 1}}, Players: team.Players}
+	assert.Empty(t, RoundWinners(draw, models.ScoringTypeMafia))
+
+	coop := &models.GameRound{EndTime: end, CooperativeWin: &won, Players: team.Players}
+	assert.Equal(t, []primitive.ObjectID{first, second}, RoundWinners(coop, models.ScoringTypeCoopWithModerator))
+	coop.CooperativeWin = &lost
+	assert.Empty(t, RoundWinners(coop, models.ScoringTypeCoopWithModerator))
+}
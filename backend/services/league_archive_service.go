@@ -0,0 +1,1008 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LeagueArchiveFormatVersion is the version of the archive layout, bumped on incompatible changes
+const LeagueArchiveFormatVersion = 1
+
+// Files of the league archive
+const (
+	archiveManifestFile    = "manifest.json"
+	archiveLeagueFile      = "league.json"
+	archiveUsersFile       = "users.json"
+	archiveMembershipsFile = "memberships.json"
+	archiveInvitationsFile = "invitations.json"
+	archiveGameTypesFile   = "game_types.json"
+	archiveGameRoundsFile  = "game_rounds.json"
+	archiveWizardGamesFile = "wizard_games.json"
+	archiveGameNightsFile  = "game_nights.json" // Optional, archives of older versions have no game nights
+)
+
+// LeagueArchiveService moves a league with its whole history between deployments
+type LeagueArchiveService interface {
+	// Export writes the league with members, invitations, game nights, game rounds, Wizard games and referenced game types
+	// as a ZIP of JSON files, all ObjectIDs are replaced by codes
+	Export(ctx context.Context, leagueID, actorID primitive.ObjectID, w io.Writer) error
+	// Import recreates an exported league as a new league with new IDs. Users are matched by external IDs,
+	// members of unknown users become virtual. With dryRun the archive is only checked and nothing is stored
+	Import(ctx context.Context, archive io.ReaderAt, size int64, dryRun bool, actorID primitive.ObjectID) (*LeagueImportResult, error)
+}
+
+// LeagueArchiveManifest describes the archive
+type LeagueArchiveManifest struct {
+	FormatVersion int       `json:"format_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	LeagueCode    string    `json:"league_code"`
+	LeagueName    string    `json:"league_name"`
+}
+
+type ArchivedLeague struct {
+	Code         string                     `json:"code"`
+	Name         string                     `json:"name"`
+	Status       models.LeagueStatus        `json:"status"`
+	IsPublic     bool                       `json:"is_public"`
+	Language     string                     `json:"language,omitempty"`
+	PointsConfig *models.LeaguePointsConfig `json:"points_config,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+}
+
+// ArchivedUser is a user referenced by the league, external IDs are used to find the user on import
+type ArchivedUser struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Alias       string   `json:"alias"`
+	ExternalIDs []string `json:"external_ids"`
+}
+
+type ArchivedCoPlayer struct {
+	MembershipCode string    `json:"membership_code"`
+	LastPlayedAt   time.Time `json:"last_played_at"`
+}
+
+type ArchivedMembership struct {
+	Code            string                        `json:"code"`
+	UserCode        string                        `json:"user_code,omitempty"`
+	InvitationCode  string                        `json:"invitation_code,omitempty"`
+	Alias           string                        `json:"alias"`
+	Status          models.LeagueMembershipStatus `json:"status"`
+	Role            models.LeagueRole             `json:"role,omitempty"`
+	JoinedAt        time.Time                     `json:"joined_at"`
+	LastActivityAt  time.Time                     `json:"last_activity_at,omitzero"`
+	LeftAt          time.Time                     `json:"left_at,omitzero"`
+	RecentCoPlayers []ArchivedCoPlayer            `json:"recent_co_players,omitempty"`
+}
+
+type ArchivedInvitationUse struct {
+	UserCode       string    `json:"user_code,omitempty"`
+	MembershipCode string    `json:"membership_code"`
+	Alias          string    `json:"alias"`
+	UsedAt         time.Time `json:"used_at"`
+}
+
+// ArchivedInvitation is an invitation without its token, the token is a secret of the source deployment
+type ArchivedInvitation struct {
+	Code             string                  `json:"code"`
+	CreatedByCode    string                  `json:"created_by_code"`
+	PlayerAlias      string                  `json:"player_alias,omitempty"`
+	MembershipCode   string                  `json:"membership_code,omitempty"`
+	IsUsed           bool                    `json:"is_used"`
+	UsedByCode       string                  `json:"used_by_code,omitempty"`
+	UsedAt           time.Time               `json:"used_at,omitzero"`
+	ExpiresAt        time.Time               `json:"expires_at"`
+	CreatedAt        time.Time               `json:"created_at"`
+	MaxUses          int                     `json:"max_uses,omitempty"`
+	RequiresApproval bool                    `json:"requires_approval,omitempty"`
+	Uses             []ArchivedInvitationUse `json:"uses,omitempty"`
+}
+
+type ArchivedGameType struct {
+	Code        string             `json:"code"`
+	Key         string             `json:"key"`
+	Name        string             `json:"name,omitempty"`
+	Names       map[string]string  `json:"names"`
+	Icon        string             `json:"icon"`
+	ScoringType models.ScoringType `json:"scoring_type"`
+	Roles       []models.Role      `json:"roles"`
+	MinPlayers  int                `json:"min_players"`
+	MaxPlayers  int                `json:"max_players"`
+	BuiltIn     bool               `json:"built_in"`
+	Labels      []models.Label     `json:"labels,omitempty"`
+	Teams       []models.Label     `json:"teams,omitempty"`
+}
+
+// ArchivedGamePlayer is a player of a game round. Rounds recorded before memberships refer to the user only,
+// such players are exported with the membership of the user in the league, or with the user code if the user has none
+type ArchivedGamePlayer struct {
+	MembershipCode string `json:"membership_code,omitempty"`
+	UserCode       string `json:"user_code,omitempty"`
+	IsModerator    bool   `json:"is_moderator"`
+	TeamName       string `json:"team_name,omitempty"`
+	LabelName      string `json:"label_name,omitempty"`
+	Score          int64  `json:"score,omitempty"`
+	Position       int    `json:"position,omitempty"`
+}
+
+// ArchivedGameRound is a game round, JSON names are the ones of the game round API, which older archives were written with
+type ArchivedGameRound struct {
+	Code             string                 `json:"code"`
+	Name             string                 `json:"name"`
+	GameTypeCode     string                 `json:"game_type,omitempty"`
+	Status           models.GameRoundStatus `json:"status"`
+	StartTime        time.Time              `json:"start_time"`
+	EndTime          time.Time              `json:"end_time,omitempty"`
+	Players          []ArchivedGamePlayer   `json:"players"`
+	TeamScores       []models.TeamScore     `json:"team_scores,omitempty"`
+	CooperativeScore int64                  `json:"cooperative_score,omitempty"`
+	CooperativeWin   *bool                  `json:"cooperative_win,omitempty"`
+	NightCode        string                 `json:"night_code,omitempty"`
+	CreatedAt        time.Time              `json:"created_at,omitempty"`
+	UpdatedAt        time.Time              `json:"updated_at,omitempty"`
+}
+
+type ArchivedRSVP struct {
+	MembershipCode string              `json:"membership_code"`
+	Response       models.RSVPResponse `json:"response"`
+	RespondedAt    time.Time           `json:"responded_at"`
+}
+
+type ArchivedGameNight struct {
+	Code          string                 `json:"code"`
+	Title         string                 `json:"title"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	Timezone      string                 `json:"timezone"`
+	Location      string                 `json:"location,omitempty"`
+	Notes         string                 `json:"notes,omitempty"`
+	Status        models.GameNightStatus `json:"status"`
+	RSVPs         []ArchivedRSVP         `json:"rsvps,omitempty"`
+	CreatedByCode string                 `json:"created_by_code"`
+}
+
+// LeagueImportResult describes the imported league, or the league which would be imported on a dry run
+type LeagueImportResult struct {
+	DryRun           bool
+	League           *models.League // nil on a dry run
+	SourceCode       string
+	LeagueName       string
+	Memberships      int
+	Invitations      int // Open invitations recreated with new tokens
+	GameNights       int
+	GameRounds       int
+	WizardGames      int
+	CreatedGameTypes []string // Keys of game types missing in this deployment
+	UnmatchedUsers   []string // Aliases of members whose users are not found here, they become virtual
+}
+
+type leagueArchive struct {
+	manifest    LeagueArchiveManifest
+	league      ArchivedLeague
+	users       []ArchivedUser
+	memberships []ArchivedMembership
+	invitations []ArchivedInvitation
+	gameTypes   []ArchivedGameType
+	gameNights  []ArchivedGameNight
+	gameRounds  []ArchivedGameRound
+	wizardGames []*models.WizardGame
+}
+
+type leagueArchiveServiceInstance struct {
+	leagueRepo     repositories.LeagueRepository
+	membershipRepo repositories.LeagueMembershipRepository
+	invitationRepo repositories.LeagueInvitationRepository
+	userRepo       repositories.UserRepository
+	gameRoundRepo  repositories.GameRoundRepository
+	gameTypeRepo   repositories.GameTypeRepository
+	wizardGameRepo repositories.WizardGameRepository
+	gameNightRepo  repositories.GameNightRepository
+	auditService   AuditService
+}
+
+func NewLeagueArchiveService(
+	leagueRepo repositories.LeagueRepository,
+	membershipRepo repositories.LeagueMembershipRepository,
+	invitationRepo repositories.LeagueInvitationRepository,
+	userRepo repositories.UserRepository,
+	gameRoundRepo repositories.GameRoundRepository,
+	gameTypeRepo repositories.GameTypeRepository,
+	wizardGameRepo repositories.WizardGameRepository,
+	gameNightRepo repositories.GameNightRepository,
+	auditService AuditService,
+) LeagueArchiveService {
+	return &leagueArchiveServiceInstance{
+		leagueRepo:     leagueRepo,
+		membershipRepo: membershipRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		gameRoundRepo:  gameRoundRepo,
+		gameTypeRepo:   gameTypeRepo,
+		wizardGameRepo: wizardGameRepo,
+		gameNightRepo:  gameNightRepo,
+		auditService:   auditService,
+	}
+}
+
+func (s *leagueArchiveServiceInstance) Export(ctx context.Context, leagueID, actorID primitive.ObjectID, w io.Writer) error {
+	archive, err := s.collect(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, file := range []struct {
+		name    string
+		content interface{}
+	}{
+		{archiveManifestFile, archive.manifest},
+		{archiveLeagueFile, archive.league},
+		{archiveUsersFile, archive.users},
+		{archiveMembershipsFile, archive.memberships},
+		{archiveInvitationsFile, archive.invitations},
+		{archiveGameTypesFile, archive.gameTypes},
+		{archiveGameNightsFile, archive.gameNights},
+		{archiveGameRoundsFile, archive.gameRounds},
+		{archiveWizardGamesFile, archive.wizardGames},
+	} {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return hexerr.Wrapf(err, "failed to add %s to archive", file.name)
+		}
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return hexerr.Wrapf(err, "failed to write %s", file.name)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return hexerr.Wrapf(err, "failed to write archive")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionLeagueExported, AuditDetails{
+		"memberships": len(archive.memberships),
+		"game_rounds": len(archive.gameRounds),
+	})
+
+	return nil
+}
+
+// collect reads the league and everything it references, replacing ObjectIDs by codes
+func (s *leagueArchiveServiceInstance) collect(ctx context.Context, leagueID primitive.ObjectID) (*leagueArchive, error) {
+	league, err := s.leagueRepo.FindByID(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find league")
+	}
+	if league == nil {
+		return nil, hexerr.New("league not found")
+	}
+
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league members")
+	}
+	invitations, err := s.invitationRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league invitations")
+	}
+	nights, err := s.gameNightRepo.FindByLeague(ctx, leagueID, time.Time{})
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game nights")
+	}
+	rounds, err := s.gameRoundRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game rounds")
+	}
+
+	archive := &leagueArchive{
+		manifest: LeagueArchiveManifest{
+			FormatVersion: LeagueArchiveFormatVersion,
+			ExportedAt:    time.Now(),
+			LeagueCode:    utils.IdToCode(league.ID),
+			LeagueName:    league.Name,
+		},
+		league: ArchivedLeague{
+			Code:         utils.IdToCode(league.ID),
+			Name:         league.Name,
+			Status:       league.Status,
+			IsPublic:     league.IsPublic,
+			Language:     league.Language,
+			PointsConfig: league.PointsConfig,
+			CreatedAt:    league.CreatedAt,
+		},
+		users:       []ArchivedUser{},
+		memberships: make([]ArchivedMembership, 0, len(memberships)),
+		invitations: make([]ArchivedInvitation, 0, len(invitations)),
+		gameTypes:   []ArchivedGameType{},
+		gameNights:  make([]ArchivedGameNight, 0, len(nights)),
+		gameRounds:  make([]ArchivedGameRound, 0, len(rounds)),
+		wizardGames: []*models.WizardGame{},
+	}
+
+	users := make(map[primitive.ObjectID]bool)
+	addUser := func(userID primitive.ObjectID) (string, error) {
+		if userID.IsZero() {
+			return "", nil
+		}
+		if !users[userID] {
+			users[userID] = true
+			user, err := s.userRepo.FindByID(ctx, userID)
+			if err != nil {
+				return "", hexerr.Wrapf(err, "failed to find user %s", userID.Hex())
+			}
+			if user != nil {
+				archive.users = append(archive.users, ArchivedUser{
+					Code:        utils.IdToCode(user.ID),
+					Name:        user.Name,
+					Alias:       user.Alias,
+					ExternalIDs: user.ExternalIDs,
+				})
+			}
+		}
+		return utils.IdToCode(userID), nil
+	}
+
+	membershipOfUser := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, membership := range memberships {
+		if !membership.UserID.IsZero() {
+			membershipOfUser[membership.UserID] = membership.ID
+		}
+		userCode, err := addUser(membership.UserID)
+		if err != nil {
+			return nil, err
+		}
+		archived := ArchivedMembership{
+			Code:           utils.IdToCode(membership.ID),
+			UserCode:       userCode,
+			InvitationCode: optionalCode(membership.InvitationID),
+			Alias:          membership.Alias,
+			Status:         membership.Status,
+			Role:           membership.Role,
+			JoinedAt:       membership.JoinedAt,
+			LastActivityAt: membership.LastActivityAt,
+			LeftAt:         membership.LeftAt,
+		}
+		for _, coPlayer := range membership.RecentCoPlayers {
+			archived.RecentCoPlayers = append(archived.RecentCoPlayers, ArchivedCoPlayer{
+				MembershipCode: utils.IdToCode(coPlayer.MembershipID),
+				LastPlayedAt:   coPlayer.LastPlayedAt,
+			})
+		}
+		archive.memberships = append(archive.memberships, archived)
+	}
+
+	for _, invitation := range invitations {
+		createdBy, err := addUser(invitation.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		usedBy, err := addUser(invitation.UsedBy)
+		if err != nil {
+			return nil, err
+		}
+		archived := ArchivedInvitation{
+			Code:             utils.IdToCode(invitation.ID),
+			CreatedByCode:    createdBy,
+			PlayerAlias:      invitation.PlayerAlias,
+			MembershipCode:   optionalCode(invitation.MembershipID),
+			IsUsed:           invitation.IsUsed,
+			UsedByCode:       usedBy,
+			UsedAt:           invitation.UsedAt,
+			ExpiresAt:        invitation.ExpiresAt,
+			CreatedAt:        invitation.CreatedAt,
+			MaxUses:          invitation.MaxUses,
+			RequiresApproval: invitation.RequiresApproval,
+		}
+		for _, use := range invitation.Uses {
+			userCode, err := addUser(use.UserID)
+			if err != nil {
+				return nil, err
+			}
+			archived.Uses = append(archived.Uses, ArchivedInvitationUse{
+				UserCode:       userCode,
+				MembershipCode: utils.IdToCode(use.MembershipID),
+				Alias:          use.Alias,
+				UsedAt:         use.UsedAt,
+			})
+		}
+		archive.invitations = append(archive.invitations, archived)
+	}
+
+	for _, night := range nights {
+		createdBy, err := addUser(night.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		archived := ArchivedGameNight{
+			Code:          utils.IdToCode(night.ID),
+			Title:         night.Title,
+			StartTime:     night.StartTime,
+			EndTime:       night.EndTime,
+			Timezone:      night.Timezone,
+			Location:      night.Location,
+			Notes:         night.Notes,
+			Status:        night.Status,
+			CreatedByCode: createdBy,
+		}
+		for _, rsvp := range night.RSVPs {
+			archived.RSVPs = append(archived.RSVPs, ArchivedRSVP{
+				MembershipCode: utils.IdToCode(rsvp.MembershipID),
+				Response:       rsvp.Response,
+				RespondedAt:    rsvp.RespondedAt,
+			})
+		}
+		archive.gameNights = append(archive.gameNights, archived)
+	}
+
+	gameTypes := make(map[primitive.ObjectID]bool)
+	for _, round := range rounds {
+		if !round.GameTypeID.IsZero() && !gameTypes[round.GameTypeID] {
+			gameTypes[round.GameTypeID] = true
+			gameType, err := s.gameTypeRepo.FindByID(ctx, round.GameTypeID)
+			if err != nil {
+				return nil, hexerr.Wrapf(err, "failed to find game type %s", round.GameTypeID.Hex())
+			}
+			if gameType != nil {
+				archive.gameTypes = append(archive.gameTypes, ArchivedGameType{
+					Code:        utils.IdToCode(gameType.ID),
+					Key:         gameType.Key,
+					Name:        gameType.Name,
+					Names:       gameType.Names,
+					Icon:        gameType.Icon,
+					ScoringType: gameType.ScoringType,
+					Roles:       gameType.Roles,
+					MinPlayers:  gameType.MinPlayers,
+					MaxPlayers:  gameType.MaxPlayers,
+					BuiltIn:     gameType.BuiltIn,
+					Labels:      gameType.Labels,
+					Teams:       gameType.Teams,
+				})
+			}
+		}
+
+		archived := ArchivedGameRound{
+			Code:             utils.IdToCode(round.ID),
+			Name:             round.Name,
+			GameTypeCode:     optionalCode(round.GameTypeID),
+			Status:           round.Status,
+			StartTime:        round.StartTime,
+			EndTime:          round.EndTime,
+			Players:          make([]ArchivedGamePlayer, 0, len(round.Players)),
+			TeamScores:       round.TeamScores,
+			CooperativeScore: round.CooperativeScore,
+			CooperativeWin:   round.CooperativeWin,
+			NightCode:        optionalCode(round.NightID),
+			CreatedAt:        round.CreatedAt,
+			UpdatedAt:        round.UpdatedAt,
+		}
+		for _, player := range round.Players {
+			archivedPlayer := ArchivedGamePlayer{
+				MembershipCode: optionalCode(player.MembershipID),
+				IsModerator:    player.IsModerator,
+				TeamName:       player.TeamName,
+				LabelName:      player.LabelName,
+				Score:          player.Score,
+				Position:       player.Position,
+			}
+			if player.MembershipID.IsZero() && !player.PlayerID.IsZero() {
+				// A legacy player, the user is the only reference when the user has no membership in the league
+				if membershipID, ok := membershipOfUser[player.PlayerID]; ok {
+					archivedPlayer.MembershipCode = utils.IdToCode(membershipID)
+				} else if archivedPlayer.UserCode, err = addUser(player.PlayerID); err != nil {
+					return nil, err
+				}
+			}
+			archived.Players = append(archived.Players, archivedPlayer)
+		}
+		archive.gameRounds = append(archive.gameRounds, archived)
+
+		game, err := s.wizardGameRepo.FindByGameRoundID(ctx, round.ID)
+		if err != nil {
+			return nil, err
+		}
+		if game == nil {
+			continue
+		}
+		game.GameRoundCode = archived.Code
+		for i := range game.Players {
+			game.Players[i].MembershipCode = utils.IdToCode(game.Players[i].MembershipID)
+		}
+		archive.wizardGames = append(archive.wizardGames, game)
+	}
+
+	return archive, nil
+}
+
+func (s *leagueArchiveServiceInstance) Import(ctx context.Context, archiveReader io.ReaderAt, size int64, dryRun bool, actorID primitive.ObjectID) (*LeagueImportResult, error) {
+	archive, err := readLeagueArchive(archiveReader, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := archive.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &LeagueImportResult{
+		DryRun:      dryRun,
+		SourceCode:  archive.league.Code,
+		LeagueName:  archive.league.Name,
+		Memberships: len(archive.memberships),
+		GameNights:  len(archive.gameNights),
+		GameRounds:  len(archive.gameRounds),
+		WizardGames: len(archive.wizardGames),
+	}
+
+	// Users are matched by external IDs, codes of the source deployment mean nothing here
+	userIDs := make(map[string]primitive.ObjectID)
+	for _, archived := range archive.users {
+		if len(archived.ExternalIDs) == 0 {
+			continue
+		}
+		user, err := s.userRepo.FindByExternalId(ctx, archived.ExternalIDs)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to find user %s", archived.Alias)
+		}
+		if user != nil {
+			userIDs[archived.Code] = user.ID
+		}
+	}
+
+	gameTypeIDs := make(map[string]primitive.ObjectID)
+	var newGameTypes []*models.GameType
+	for _, archived := range archive.gameTypes {
+		gameType, err := s.gameTypeRepo.FindByKey(ctx, archived.Key)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to find game type %s", archived.Key)
+		}
+		if gameType == nil {
+			gameType = &models.GameType{
+				ID:          primitive.NewObjectID(),
+				Key:         archived.Key,
+				Name:        archived.Name,
+				Names:       archived.Names,
+				Icon:        archived.Icon,
+				ScoringType: archived.ScoringType,
+				Roles:       archived.Roles,
+				MinPlayers:  archived.MinPlayers,
+				MaxPlayers:  archived.MaxPlayers,
+				Labels:      archived.Labels,
+				Teams:       archived.Teams,
+			}
+			newGameTypes = append(newGameTypes, gameType)
+			result.CreatedGameTypes = append(result.CreatedGameTypes, archived.Key)
+		}
+		gameTypeIDs[archived.Code] = gameType.ID
+	}
+
+	// New IDs are assigned up front, so that references between the documents can be rewritten before anything is stored
+	membershipIDs := make(map[string]primitive.ObjectID)
+	for _, archived := range archive.memberships {
+		membershipIDs[archived.Code] = primitive.NewObjectID()
+	}
+	invitationIDs := make(map[string]primitive.ObjectID)
+	now := time.Now()
+	for _, archived := range archive.invitations {
+		if isOpenInvitation(archived, now) {
+			invitationIDs[archived.Code] = primitive.NewObjectID()
+		}
+	}
+	result.Invitations = len(invitationIDs)
+
+	league := &models.League{
+		ID:           primitive.NewObjectID(),
+		Name:         archive.league.Name,
+		Status:       archive.league.Status,
+		PointsConfig: archive.league.PointsConfig,
+		IsPublic:     archive.league.IsPublic,
+		Language:     archive.league.Language,
+	}
+
+	memberships := make([]*models.LeagueMembership, 0, len(archive.memberships))
+	for _, archived := range archive.memberships {
+		membership := &models.LeagueMembership{
+			ID:             membershipIDs[archived.Code],
+			LeagueID:       league.ID,
+			UserID:         userIDs[archived.UserCode],
+			InvitationID:   invitationIDs[archived.InvitationCode],
+			Alias:          archived.Alias,
+			Status:         archived.Status,
+			Role:           archived.Role,
+			JoinedAt:       archived.JoinedAt,
+			LastActivityAt: archived.LastActivityAt,
+			LeftAt:         archived.LeftAt,
+		}
+		for _, coPlayer := range archived.RecentCoPlayers {
+			if id, ok := membershipIDs[coPlayer.MembershipCode]; ok {
+				membership.RecentCoPlayers = append(membership.RecentCoPlayers, models.RecentCoPlayer{MembershipID: id, LastPlayedAt: coPlayer.LastPlayedAt})
+			}
+		}
+
+		switch {
+		case archived.UserCode != "" && membership.UserID.IsZero():
+			// The user is unknown here, the member keeps the games as a virtual player
+			result.UnmatchedUsers = append(result.UnmatchedUsers, archived.Alias)
+			membership.Status = models.MembershipVirtual
+			membership.Role = ""
+			membership.LeftAt = time.Time{}
+		case archived.UserCode == "" && archived.Status == models.MembershipPending && membership.InvitationID.IsZero():
+			// The invitation of the placeholder is not recreated, nobody can accept it anymore
+			membership.Status = models.MembershipVirtual
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	// A failed import deletes what it has stored, the league is imported completely or not at all
+	var cleanup importCleanup
+	completed := false
+	defer func() {
+		if !completed {
+			cleanup.run(ctx)
+		}
+	}()
+
+	if err := s.leagueRepo.Create(ctx, league); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create league")
+	}
+	cleanup.add(func(ctx context.Context) error { return s.leagueRepo.Delete(ctx, league.ID) })
+	result.League = league
+
+	for _, gameType := range newGameTypes {
+		if err := s.gameTypeRepo.Create(ctx, gameType); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to create game type %s", gameType.Key)
+		}
+		cleanup.add(func(ctx context.Context) error { return s.gameTypeRepo.Delete(ctx, gameType.ID) })
+	}
+
+	for _, membership := range memberships {
+		if err := s.membershipRepo.Create(ctx, membership); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to create membership %s", membership.Alias)
+		}
+		cleanup.add(func(ctx context.Context) error { return s.membershipRepo.Delete(ctx, membership.ID) })
+	}
+
+	for _, archived := range archive.invitations {
+		id, ok := invitationIDs[archived.Code]
+		if !ok {
+			continue
+		}
+		token, err := generateInvitationToken()
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to generate invitation token")
+		}
+		createdBy, ok := userIDs[archived.CreatedByCode]
+		if !ok {
+			createdBy = actorID
+		}
+		invitation := &models.LeagueInvitation{
+			ID:               id,
+			LeagueID:         league.ID,
+			CreatedBy:        createdBy,
+			Token:            token,
+			PlayerAlias:      archived.PlayerAlias,
+			MembershipID:     membershipIDs[archived.MembershipCode],
+			ExpiresAt:        archived.ExpiresAt,
+			MaxUses:          archived.MaxUses,
+			RequiresApproval: archived.RequiresApproval,
+		}
+		for _, use := range archived.Uses {
+			invitation.Uses = append(invitation.Uses, models.InvitationUse{
+				UserID:       userIDs[use.UserCode],
+				MembershipID: membershipIDs[use.MembershipCode],
+				Alias:        use.Alias,
+				UsedAt:       use.UsedAt,
+			})
+		}
+		if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to create invitation")
+		}
+		cleanup.add(func(ctx context.Context) error { return s.invitationRepo.Delete(ctx, id) })
+	}
+
+	nightIDs := make(map[string]primitive.ObjectID)
+	for _, archived := range archive.gameNights {
+		createdBy, ok := userIDs[archived.CreatedByCode]
+		if !ok {
+			createdBy = actorID
+		}
+		night := &models.GameNight{
+			ID:        primitive.NewObjectID(),
+			LeagueID:  league.ID,
+			Title:     archived.Title,
+			StartTime: archived.StartTime,
+			EndTime:   archived.EndTime,
+			Timezone:  archived.Timezone,
+			Location:  archived.Location,
+			Notes:     archived.Notes,
+			Status:    archived.Status,
+			CreatedBy: createdBy,
+		}
+		for _, rsvp := range archived.RSVPs {
+			night.RSVPs = append(night.RSVPs, models.GameNightRSVP{
+				MembershipID: membershipIDs[rsvp.MembershipCode],
+				Response:     rsvp.Response,
+				RespondedAt:  rsvp.RespondedAt,
+			})
+		}
+		if err := s.gameNightRepo.Create(ctx, night); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to create game night %s", night.Title)
+		}
+		cleanup.add(func(ctx context.Context) error { return s.gameNightRepo.Delete(ctx, night.ID) })
+		nightIDs[archived.Code] = night.ID
+	}
+
+	// Legacy players without a membership in the archive get the membership of their user here, if there is one
+	membershipOfUser := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, membership := range memberships {
+		if !membership.UserID.IsZero() {
+			membershipOfUser[membership.UserID] = membership.ID
+		}
+	}
+
+	roundIDs := make(map[string]primitive.ObjectID)
+	for _, archived := range archive.gameRounds {
+		round := &models.GameRound{
+			ID:               primitive.NewObjectID(),
+			LeagueID:         league.ID,
+			Name:             archived.Name,
+			GameTypeID:       gameTypeIDs[archived.GameTypeCode],
+			Status:           archived.Status,
+			StartTime:        archived.StartTime,
+			EndTime:          archived.EndTime,
+			Players:          make([]models.GameRoundPlayer, 0, len(archived.Players)),
+			TeamScores:       archived.TeamScores,
+			CooperativeScore: archived.CooperativeScore,
+			CooperativeWin:   archived.CooperativeWin,
+			NightID:          nightIDs[archived.NightCode],
+		}
+		for _, player := range archived.Players {
+			imported := models.GameRoundPlayer{
+				MembershipID: membershipIDs[player.MembershipCode],
+				IsModerator:  player.IsModerator,
+				TeamName:     player.TeamName,
+				LabelName:    player.LabelName,
+				Score:        player.Score,
+				Position:     player.Position,
+			}
+			if imported.MembershipID.IsZero() && player.UserCode != "" {
+				if userID, ok := userIDs[player.UserCode]; ok {
+					imported.MembershipID = membershipOfUser[userID]
+					if imported.MembershipID.IsZero() {
+						imported.PlayerID = userID
+					}
+				}
+			}
+			round.Players = append(round.Players, imported)
+		}
+		if err := s.gameRoundRepo.Create(ctx, round); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to create game round %s", round.Name)
+		}
+		cleanup.add(func(ctx context.Context) error { return s.gameRoundRepo.Delete(ctx, round.ID) })
+		roundIDs[archived.Code] = round.ID
+	}
+
+	for _, game := range archive.wizardGames {
+		existing, err := s.wizardGameRepo.FindByCode(ctx, game.Code)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			// The source league was imported before, or the code is taken by chance
+			if game.Code, err = generateWizardGameCode(); err != nil {
+				return nil, hexerr.Wrapf(err, "failed to generate Wizard game code")
+			}
+		}
+		game.ID = primitive.NewObjectID()
+		game.GameRoundID = roundIDs[game.GameRoundCode]
+		for i := range game.Players {
+			game.Players[i].MembershipID = membershipIDs[game.Players[i].MembershipCode]
+		}
+		if err := s.wizardGameRepo.Create(ctx, game); err != nil {
+			return nil, err
+		}
+		cleanup.add(func(ctx context.Context) error { return s.wizardGameRepo.Delete(ctx, game.ID) })
+	}
+	completed = true
+
+	s.logAction(ctx, league.ID, actorID, AuditActionLeagueImported, AuditDetails{
+		"source_code":     archive.league.Code,
+		"memberships":     result.Memberships,
+		"game_nights":     result.GameNights,
+		"game_rounds":     result.GameRounds,
+		"unmatched_users": len(result.UnmatchedUsers),
+	})
+
+	return result, nil
+}
+
+// importCleanup deletes the documents stored by an import which failed halfway. MongoDB transactions need a replica
+// set, so the documents are deleted one by one, the latest first
+type importCleanup []func(ctx context.Context) error
+
+func (c *importCleanup) add(remove func(ctx context.Context) error) {
+	*c = append(*c, remove)
+}
+
+func (c importCleanup) run(ctx context.Context) {
+	// The import may have failed because the request was cancelled, the cleanup must run anyway
+	ctx = context.WithoutCancel(ctx)
+	for i := len(c) - 1; i >= 0; i-- {
+		if err := c[i](ctx); err != nil {
+			glog.Warn("Failed to clean up after a failed league import: %v", err)
+		}
+	}
+}
+
+func (s *leagueArchiveServiceInstance) logAction(ctx context.Context, leagueID, actorID primitive.ObjectID, action AuditAction, details AuditDetails) {
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, action, AuditTargetLeague, leagueID, details); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+	}
+}
+
+func readLeagueArchive(r io.ReaderAt, size int64) (*leagueArchive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "invalid archive")
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range zr.File {
+		files[file.Name] = file
+	}
+	read := func(name string, v interface{}) error {
+		file, ok := files[name]
+		if !ok {
+			return hexerr.New(fmt.Sprintf("archive has no %s", name))
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return hexerr.Wrapf(err, "failed to open %s", name)
+		}
+		defer rc.Close()
+		if err := json.NewDecoder(rc).Decode(v); err != nil {
+			return hexerr.Wrapf(err, "invalid %s", name)
+		}
+		return nil
+	}
+
+	archive := &leagueArchive{}
+	if err := read(archiveManifestFile, &archive.manifest); err != nil {
+		return nil, err
+	}
+	if archive.manifest.FormatVersion != LeagueArchiveFormatVersion {
+		return nil, hexerr.New(fmt.Sprintf("unsupported archive format version %d", archive.manifest.FormatVersion))
+	}
+	for name, v := range map[string]interface{}{
+		archiveLeagueFile:      &archive.league,
+		archiveUsersFile:       &archive.users,
+		archiveMembershipsFile: &archive.memberships,
+		archiveInvitationsFile: &archive.invitations,
+		archiveGameTypesFile:   &archive.gameTypes,
+		archiveGameRoundsFile:  &archive.gameRounds,
+		archiveWizardGamesFile: &archive.wizardGames,
+	} {
+		if err := read(name, v); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := files[archiveGameNightsFile]; ok {
+		if err := read(archiveGameNightsFile, &archive.gameNights); err != nil {
+			return nil, err
+		}
+	}
+	return archive, nil
+}
+
+// validate checks that every reference in the archive points to a document of the archive
+func (a *leagueArchive) validate() error {
+	if a.league.Name == "" {
+		return hexerr.New("archive has no league name")
+	}
+
+	memberships := make(map[string]bool)
+	aliases := make(map[string]bool)
+	for _, membership := range a.memberships {
+		if memberships[membership.Code] {
+			return hexerr.New(fmt.Sprintf("duplicate membership %s", membership.Code))
+		}
+		if aliases[membership.Alias] {
+			return hexerr.New(fmt.Sprintf("duplicate member alias %s", membership.Alias))
+		}
+		memberships[membership.Code] = true
+		aliases[membership.Alias] = true
+	}
+
+	gameTypes := make(map[string]bool)
+	for _, gameType := range a.gameTypes {
+		gameTypes[gameType.Code] = true
+	}
+
+	users := make(map[string]bool)
+	for _, user := range a.users {
+		users[user.Code] = true
+	}
+
+	nights := make(map[string]bool)
+	for _, night := range a.gameNights {
+		for _, rsvp := range night.RSVPs {
+			if !memberships[rsvp.MembershipCode] {
+				return hexerr.New(fmt.Sprintf("game night %s refers to unknown member %s", night.Title, rsvp.MembershipCode))
+			}
+		}
+		nights[night.Code] = true
+	}
+
+	rounds := make(map[string]bool)
+	for _, round := range a.gameRounds {
+		if round.GameTypeCode != "" && !gameTypes[round.GameTypeCode] {
+			return hexerr.New(fmt.Sprintf("game round %s refers to unknown game type %s", round.Name, round.GameTypeCode))
+		}
+		if round.NightCode != "" && !nights[round.NightCode] {
+			return hexerr.New(fmt.Sprintf("game round %s refers to unknown game night %s", round.Name, round.NightCode))
+		}
+		for _, player := range round.Players {
+			if player.MembershipCode != "" && !memberships[player.MembershipCode] {
+				return hexerr.New(fmt.Sprintf("game round %s refers to unknown member %s", round.Name, player.MembershipCode))
+			}
+			if player.UserCode != "" && !users[player.UserCode] {
+				return hexerr.New(fmt.Sprintf("game round %s refers to unknown user %s", round.Name, player.UserCode))
+			}
+		}
+		rounds[round.Code] = true
+	}
+
+	for _, game := range a.wizardGames {
+		if !rounds[game.GameRoundCode] {
+			return hexerr.New(fmt.Sprintf("Wizard game %s refers to unknown game round %s", game.Code, game.GameRoundCode))
+		}
+		for _, player := range game.Players {
+			if !memberships[player.MembershipCode] {
+				return hexerr.New(fmt.Sprintf("Wizard game %s refers to unknown member %s", game.Code, player.MembershipCode))
+			}
+		}
+	}
+
+	return nil
+}
+
+// isOpenInvitation checks if the invitation can still be accepted, only such invitations are imported
+func isOpenInvitation(invitation ArchivedInvitation, now time.Time) bool {
+	if !invitation.ExpiresAt.After(now) {
+		return false
+	}
+	if invitation.MaxUses > 0 {
+		return len(invitation.Uses) < invitation.MaxUses
+	}
+	return !invitation.IsUsed
+}
+
+func optionalCode(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return utils.IdToCode(id)
+}
+
+func generateWizardGameCode() (string, error) {
+	bytes := make([]byte, 6)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type archiveRepos struct {
+	league     *mocks.MockLeagueRepository
+	membership *mocks.MockLeagueMembershipRepository
+	invitation *mocks.MockLeagueInvitationRepository
+	user       *mocks.MockUserRepository
+	gameRound  *mocks.MockGameRoundRepository
+	gameType   *mocks.MockGameTypeRepository
+	wizardGame *mocks.MockWizardGameRepository
+	gameNight  *mocks.MockGameNightRepository
+}
+
+func newArchiveService() (LeagueArchiveService, archiveRepos) {
+	r := archiveRepos{
+		league:     new(mocks.MockLeagueRepository),
+		membership: new(mocks.MockLeagueMembershipRepository),
+		invitation: new(mocks.MockLeagueInvitationRepository),
+		user:       new(mocks.MockUserRepository),
+		gameRound:  new(mocks.MockGameRoundRepository),
+		gameType:   new(mocks.MockGameTypeRepository),
+		wizardGame: new(mocks.MockWizardGameRepository),
+		gameNight:  new(mocks.MockGameNightRepository),
+	}
+	service := NewLeagueArchiveService(r.league, r.membership, r.invitation, r.user, r.gameRound, r.gameType, r.wizardGame, r.gameNight, NewNoopAuditService())
+	return service, r
+}
+
+func TestLeagueArchive_ExportAndImport(t *testing.T) {
+	ctx := context.Background()
+	actorID := primitive.NewObjectID()
+
+	league := &models.League{ID: primitive.NewObjectID(), Name: "Friday games", Status: models.LeagueActive}
+	owner := &models.User{ID: primitive.NewObjectID(), Alias: "olena", ExternalIDs: []string{"google:1"}}
+	stranger := &models.User{ID: primitive.NewObjectID(), Alias: "petro", ExternalIDs: []string{"google:2"}}
+	ownerMember := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, UserID: owner.ID, Alias: "Olena", Status: models.MembershipActive, Role: models.LeagueRoleOwner}
+	strangerMember := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, UserID: stranger.ID, Alias: "Petro", Status: models.MembershipActive}
+	ownerMember.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: strangerMember.ID, LastPlayedAt: time.Now()}}
+	gameType := &models.GameType{ID: primitive.NewObjectID(), Key: "wizard", ScoringType: models.ScoringTypeClassic}
+	round := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: league.ID, Name: "Round 1", GameTypeID: gameType.ID, Status: models.StatusCompleted,
+		Players: []models.GameRoundPlayer{{MembershipID: ownerMember.ID, Position: 1}, {MembershipID: strangerMember.ID, Position: 2}}}
+	game := &models.WizardGame{ID: primitive.NewObjectID(), Code: "abc123", GameRoundID: round.ID,
+		Players: []models.WizardPlayer{{MembershipID: ownerMember.ID}, {MembershipID: strangerMember.ID}}}
+	usedInvitation := &models.LeagueInvitation{ID: primitive.NewObjectID(), LeagueID: league.ID, CreatedBy: owner.ID, Token: "secret",
+		IsUsed: true, UsedBy: stranger.ID, ExpiresAt: time.Now().Add(-time.Hour)}
+
+	service, r := newArchiveService()
+	r.league.On("FindByID", ctx, league.ID).Return(league, nil)
+	r.membership.On("FindByLeague", ctx, league.ID).Return([]*models.LeagueMembership{ownerMember, strangerMember}, nil)
+	r.invitation.On("FindByLeague", ctx, league.ID).Return([]*models.LeagueInvitation{usedInvitation}, nil)
+	r.gameRound.On("FindByLeague", ctx, league.ID).Return([]*models.GameRound{round}, nil)
+	r.gameNight.On("FindByLeague", ctx, league.ID, time.Time{}).Return([]*models.GameNight{}, nil)
+	r.user.On("FindByID", ctx, owner.ID).Return(owner, nil)
+	r.user.On("FindByID", ctx, stranger.ID).Return(stranger, nil)
+	r.gameType.On("FindByID", ctx, gameType.ID).Return(gameType, nil)
+	r.wizardGame.On("FindByGameRoundID", ctx, round.ID).Return(game, nil)
+
+	var archive bytes.Buffer
+	if !assert.NoError(t, service.Export(ctx, league.ID, actorID, &archive)) {
+		return
+	}
+	assert.NotContains(t, archive.String(), "secret")
+
+	t.Run("Dry run stores nothing", func(t *testing.T) {
+		service, r := newArchiveService()
+		r.user.On("FindByExternalId", ctx, owner.ExternalIDs).Return(owner, nil)
+		r.user.On("FindByExternalId", ctx, stranger.ExternalIDs).Return(nil, nil)
+		r.gameType.On("FindByKey", ctx, "wizard").Return(nil, nil)
+
+		result, err := service.Import(ctx, bytes.NewReader(archive.Bytes()), int64(archive.Len()), true, actorID)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Nil(t, result.League)
+		assert.Equal(t, "Friday games", result.LeagueName)
+		assert.Equal(t, 2, result.Memberships)
+		assert.Equal(t, 0, result.Invitations)
+		assert.Equal(t, 1, result.GameRounds)
+		assert.Equal(t, 1, result.WizardGames)
+		assert.Equal(t, []string{"wizard"}, result.CreatedGameTypes)
+		assert.Equal(t, []string{"Petro"}, result.UnmatchedUsers)
+		r.league.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		r.membership.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Recreates league with new IDs", func(t *testing.T) {
+		service, r := newArchiveService()
+		existingType := &models.GameType{ID: primitive.NewObjectID(), Key: "wizard"}
+		r.user.On("FindByExternalId", ctx, owner.ExternalIDs).Return(owner, nil)
+		r.user.On("FindByExternalId", ctx, stranger.ExternalIDs).Return(nil, nil)
+		r.gameType.On("FindByKey", ctx, "wizard").Return(existingType, nil)
+
+		var imported models.League
+		r.league.On("Create", ctx, mock.AnythingOfType("*models.League")).Run(func(args mock.Arguments) {
+			imported = *args.Get(1).(*models.League)
+		}).Return(nil)
+		members := make(map[string]*models.LeagueMembership)
+		r.membership.On("Create", ctx, mock.AnythingOfType("*models.LeagueMembership")).Run(func(args mock.Arguments) {
+			m := args.Get(1).(*models.LeagueMembership)
+			members[m.Alias] = m
+		}).Return(nil)
+		var importedRound *models.GameRound
+		r.gameRound.On("Create", ctx, mock.AnythingOfType("*models.GameRound")).Run(func(args mock.Arguments) {
+			importedRound = args.Get(1).(*models.GameRound)
+		}).Return(nil)
+		r.wizardGame.On("FindByCode", ctx, "abc123").Return(nil, nil)
+		var importedGame *models.WizardGame
+		r.wizardGame.On("Create", ctx, mock.AnythingOfType("*models.WizardGame")).Run(func(args mock.Arguments) {
+			importedGame = args.Get(1).(*models.WizardGame)
+		}).Return(nil)
+
+		result, err := service.Import(ctx, bytes.NewReader(archive.Bytes()), int64(archive.Len()), false, actorID)
+
+		if !assert.NoError(t, err) || !assert.NotNil(t, result.League) {
+			return
+		}
+		assert.NotEqual(t, league.ID, imported.ID)
+		assert.Empty(t, result.CreatedGameTypes)
+		r.gameType.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+		olena, petro := members["Olena"], members["Petro"]
+		if !assert.NotNil(t, olena) || !assert.NotNil(t, petro) {
+			return
+		}
+		assert.Equal(t, owner.ID, olena.UserID)
+		assert.Equal(t, models.LeagueRoleOwner, olena.Role)
+		assert.Equal(t, models.MembershipVirtual, petro.Status)
+		assert.True(t, petro.UserID.IsZero())
+		assert.Equal(t, petro.ID, olena.RecentCoPlayers[0].MembershipID)
+
+		assert.Equal(t, imported.ID, importedRound.LeagueID)
+		assert.Equal(t, existingType.ID, importedRound.GameTypeID)
+		assert.Equal(t, olena.ID, importedRound.Players[0].MembershipID)
+		assert.Equal(t, petro.ID, importedRound.Players[1].MembershipID)
+		assert.Equal(t, importedRound.ID, importedGame.GameRoundID)
+		assert.Equal(t, "abc123", importedGame.Code)
+		assert.Equal(t, petro.ID, importedGame.Players[1].MembershipID)
+		r.invitation.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed import deletes what it stored", func(t *testing.T) {
+		service, r := newArchiveService()
+		r.user.On("FindByExternalId", ctx, owner.ExternalIDs).Return(owner, nil)
+		r.user.On("FindByExternalId", ctx, stranger.ExternalIDs).Return(nil, nil)
+		r.gameType.On("FindByKey", ctx, "wizard").Return(nil, nil)
+
+		stored := make(map[primitive.ObjectID]string)
+		store := func(kind string, id primitive.ObjectID) { stored[id] = kind }
+		r.league.On("Create", ctx, mock.AnythingOfType("*models.League")).Run(func(args mock.Arguments) {
+			store("league", args.Get(1).(*models.League).ID)
+		}).Return(nil)
+		r.gameType.On("Create", ctx, mock.AnythingOfType("*models.GameType")).Run(func(args mock.Arguments) {
+			store("game type", args.Get(1).(*models.GameType).ID)
+		}).Return(nil)
+		r.membership.On("Create", ctx, mock.AnythingOfType("*models.LeagueMembership")).Run(func(args mock.Arguments) {
+			store("membership", args.Get(1).(*models.LeagueMembership).ID)
+		}).Return(nil)
+		r.gameRound.On("Create", ctx, mock.AnythingOfType("*models.GameRound")).Return(errors.New("connection lost"))
+
+		deleteStored := func(args mock.Arguments) { delete(stored, args.Get(1).(primitive.ObjectID)) }
+		for _, repo := range []*mock.Mock{&r.league.Mock, &r.gameType.Mock, &r.membership.Mock} {
+			repo.On("Delete", mock.Anything, mock.AnythingOfType("primitive.ObjectID")).Run(deleteStored).Return(nil)
+		}
+
+		result, err := service.Import(ctx, bytes.NewReader(archive.Bytes()), int64(archive.Len()), false, actorID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Empty(t, stored, "documents left after the failed import")
+		r.wizardGame.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLeagueArchive_KeepsLegacyPlayersAndGameNights(t *testing.T) {
+	ctx := context.Background()
+	actorID := primitive.NewObjectID()
+
+	league := &models.League{ID: primitive.NewObjectID(), Name: "Old club", Status: models.LeagueActive}
+	member := &models.User{ID: primitive.NewObjectID(), Alias: "olena", ExternalIDs: []string{"google:1"}}
+	former := &models.User{ID: primitive.NewObjectID(), Alias: "ivan", ExternalIDs: []string{"google:3"}}
+	membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, UserID: member.ID, Alias: "Olena", Status: models.MembershipActive}
+	night := &models.GameNight{ID: primitive.NewObjectID(), LeagueID: league.ID, Title: "Friday", CreatedBy: member.ID, Timezone: "Europe/Kyiv",
+		Status: models.GameNightScheduled, RSVPs: []models.GameNightRSVP{{MembershipID: membership.ID, Response: models.RSVPYes}}}
+	// Recorded before memberships: players refer to users, and the second user has no membership in the league
+	legacyRound := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: league.ID, Name: "Legacy", Status: models.StatusCompleted, NightID: night.ID,
+		Players: []models.GameRoundPlayer{{PlayerID: member.ID, Position: 1, Score: 10}, {PlayerID: former.ID, Position: 2, Score: 4}}}
+
+	service, r := newArchiveService()
+	r.league.On("FindByID", ctx, league.ID).Return(league, nil)
+	r.membership.On("FindByLeague", ctx, league.ID).Return([]*models.LeagueMembership{membership}, nil)
+	r.invitation.On("FindByLeague", ctx, league.ID).Return([]*models.LeagueInvitation{}, nil)
+	r.gameRound.On("FindByLeague", ctx, league.ID).Return([]*models.GameRound{legacyRound}, nil)
+	r.gameNight.On("FindByLeague", ctx, league.ID, time.Time{}).Return([]*models.GameNight{night}, nil)
+	r.user.On("FindByID", ctx, member.ID).Return(member, nil)
+	r.user.On("FindByID", ctx, former.ID).Return(former, nil)
+	r.wizardGame.On("FindByGameRoundID", ctx, legacyRound.ID).Return(nil, nil)
+
+	var archive bytes.Buffer
+	if !assert.NoError(t, service.Export(ctx, league.ID, actorID, &archive)) {
+		return
+	}
+
+	service, r = newArchiveService()
+	r.user.On("FindByExternalId", ctx, member.ExternalIDs).Return(member, nil)
+	r.user.On("FindByExternalId", ctx, former.ExternalIDs).Return(former, nil)
+	r.league.On("Create", ctx, mock.AnythingOfType("*models.League")).Return(nil)
+	var importedMembership *models.LeagueMembership
+	r.membership.On("Create", ctx, mock.AnythingOfType("*models.LeagueMembership")).Run(func(args mock.Arguments) {
+		importedMembership = args.Get(1).(*models.LeagueMembership)
+	}).Return(nil)
+	var importedNight *models.GameNight
+	r.gameNight.On("Create", ctx, mock.AnythingOfType("*models.GameNight")).Run(func(args mock.Arguments) {
+		importedNight = args.Get(1).(*models.GameNight)
+	}).Return(nil)
+	var importedRound *models.GameRound
+	r.gameRound.On("Create", ctx, mock.AnythingOfType("*models.GameRound")).Run(func(args mock.Arguments) {
+		importedRound = args.Get(1).(*models.GameRound)
+	}).Return(nil)
+
+	result, err := service.Import(ctx, bytes.NewReader(archive.Bytes()), int64(archive.Len()), false, actorID)
+
+	if !assert.NoError(t, err) || !assert.NotNil(t, importedRound) || !assert.NotNil(t, importedNight) {
+		return
+	}
+	assert.Equal(t, 1, result.GameNights)
+	assert.Equal(t, "Friday", importedNight.Title)
+	assert.Equal(t, member.ID, importedNight.CreatedBy)
+	assert.Equal(t, importedMembership.ID, importedNight.RSVPs[0].MembershipID)
+	assert.Equal(t, importedNight.ID, importedRound.NightID)
+	if assert.Len(t, importedRound.Players, 2) {
+		assert.Equal(t, importedMembership.ID, importedRound.Players[0].MembershipID, "legacy player gets the membership of the user")
+		assert.Equal(t, int64(10), importedRound.Players[0].Score)
+		assert.True(t, importedRound.Players[1].MembershipID.IsZero())
+		assert.Equal(t, former.ID, importedRound.Players[1].PlayerID, "legacy player without a membership keeps the user")
+		assert.Equal(t, 2, importedRound.Players[1].Position)
+	}
+}
+
+func TestLeagueArchive_ImportRejectsInvalidArchives(t *testing.T) {
+	ctx := context.Background()
+
+	writeArchive := func(files map[string]interface{}) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			fw, _ := zw.Create(name)
+			_ = json.NewEncoder(fw).Encode(content)
+		}
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+	validFiles := func() map[string]interface{} {
+		return map[string]interface{}{
+			archiveManifestFile:    LeagueArchiveManifest{FormatVersion: LeagueArchiveFormatVersion},
+			archiveLeagueFile:      ArchivedLeague{Code: "league", Name: "League"},
+			archiveUsersFile:       []ArchivedUser{},
+			archiveMembershipsFile: []ArchivedMembership{{Code: "m1", Alias: "Olena", Status: models.MembershipVirtual}},
+			archiveInvitationsFile: []ArchivedInvitation{},
+			archiveGameTypesFile:   []ArchivedGameType{},
+			archiveGameRoundsFile:  []ArchivedGameRound{},
+			archiveWizardGamesFile: []*models.WizardGame{},
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(files map[string]interface{})
+	}{
+		{"Not a ZIP", nil},
+		{"Unsupported version", func(files map[string]interface{}) {
+			files[archiveManifestFile] = LeagueArchiveManifest{FormatVersion: LeagueArchiveFormatVersion + 1}
+		}},
+		{"Missing file", func(files map[string]interface{}) {
+			delete(files, archiveGameRoundsFile)
+		}},
+		{"Unknown member in a game round", func(files map[string]interface{}) {
+			files[archiveGameRoundsFile] = []ArchivedGameRound{{Code: "r1", Name: "Round", Players: []ArchivedGamePlayer{{MembershipCode: "m2"}}}}
+		}},
+		{"Unknown game night of a game round", func(files map[string]interface{}) {
+			files[archiveGameRoundsFile] = []ArchivedGameRound{{Code: "r1", Name: "Round", NightCode: "n1"}}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			service, r := newArchiveService()
+			archive := []byte("not a zip")
+			if tc.modify != nil {
+				files := validFiles()
+				tc.modify(files)
+				archive = writeArchive(files)
+			}
+
+			_, err := service.Import(ctx, bytes.NewReader(archive), int64(len(archive)), false, primitive.NewObjectID())
+
+			assert.Error(t, err)
+			r.league.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -22,7 +22,13 @@ func TestListMyInvitations(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		leagueID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
@@ -66,7 +72,13 @@ func TestListMyInvitations(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		leagueID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
@@ -91,7 +103,13 @@ func TestCancelInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		invitationID := primitive.NewObjectID()
@@ -122,7 +140,13 @@ func TestCancelInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		ownerID := primitive.NewObjectID()
 		otherUserID := primitive.NewObjectID()
@@ -138,6 +162,8 @@ func TestCancelInvitation(t *testing.T) {
 		}
 
 		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, invitation.LeagueID, otherUserID).
+			Return(&models.LeagueMembership{LeagueID: invitation.LeagueID, UserID: otherUserID, Status: models.MembershipActive}, nil)
 
 		err := service.CancelInvitation(ctx, token, otherUserID)
 
@@ -146,6 +172,37 @@ func TestCancelInvitation(t *testing.T) {
 		mockInvitationRepo.AssertExpectations(t)
 	})
 
+	t.Run("League admin can cancel someone else's invitation", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+		})
+
+		adminID := primitive.NewObjectID()
+		token := "test-token-123"
+
+		invitation := &models.LeagueInvitation{
+			ID:        primitive.NewObjectID(),
+			LeagueID:  primitive.NewObjectID(),
+			CreatedBy: primitive.NewObjectID(),
+			Token:     token,
+			ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		}
+
+		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockInvitationRepo.On("Cancel", ctx, invitation.ID).Return(nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, invitation.LeagueID, adminID).
+			Return(&models.LeagueMembership{LeagueID: invitation.LeagueID, UserID: adminID, Status: models.MembershipActive, Role: models.LeagueRoleAdmin}, nil)
+
+		err := service.CancelInvitation(ctx, token, adminID)
+
+		assert.NoError(t, err)
+		mockInvitationRepo.AssertExpectations(t)
+	})
+
 	t.Run("Fail when invitation not found", func(t *testing.T) {
 		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
 		mockLeagueRepo := new(mocks.MockLeagueRepository)
@@ -153,7 +210,13 @@ func TestCancelInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		token := "nonexistent-token"
@@ -174,7 +237,13 @@ func TestCancelInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		token := "test-token-123"
@@ -199,7 +268,13 @@ func TestCreateInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		leagueID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
@@ -219,7 +294,7 @@ func TestCreateInvitation(t *testing.T) {
 		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(nil, nil) // Creator not a member yet
 		mockMembershipRepo.On("AddRecentCoPlayer", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 
-		invitation, err := service.CreateInvitation(ctx, leagueID, userID, playerAlias)
+		invitation, err := service.CreateInvitation(ctx, leagueID, userID, playerAlias, InvitationOptions{})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, invitation)
@@ -240,7 +315,13 @@ func TestCreateInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		leagueID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
@@ -253,7 +334,7 @@ func TestCreateInvitation(t *testing.T) {
 
 		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(league, nil)
 
-		invitation, err := service.CreateInvitation(ctx, leagueID, userID, "")
+		invitation, err := service.CreateInvitation(ctx, leagueID, userID, "", InvitationOptions{})
 
 		assert.Error(t, err)
 		assert.Nil(t, invitation)
@@ -268,14 +349,20 @@ func TestCreateInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		leagueID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
 
 		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(nil, nil)
 
-		invitation, err := service.CreateInvitation(ctx, leagueID, userID, "Петро")
+		invitation, err := service.CreateInvitation(ctx, leagueID, userID, "Петро", InvitationOptions{})
 
 		assert.Error(t, err)
 		assert.Nil(t, invitation)
@@ -294,7 +381,13 @@ func TestAcceptInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		token := "test-token"
@@ -330,7 +423,13 @@ func TestExtendInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		invitationID := primitive.NewObjectID()
@@ -372,7 +471,13 @@ func TestExtendInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		ownerID := primitive.NewObjectID()
 		otherUserID := primitive.NewObjectID()
@@ -388,6 +493,8 @@ func TestExtendInvitation(t *testing.T) {
 		}
 
 		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, invitation.LeagueID, otherUserID).
+			Return(&models.LeagueMembership{LeagueID: invitation.LeagueID, UserID: otherUserID, Status: models.MembershipActive}, nil)
 
 		result, err := service.ExtendInvitation(ctx, token, otherUserID)
 
@@ -404,7 +511,13 @@ func TestExtendInvitation(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		userID := primitive.NewObjectID()
 		token := "test-token"
@@ -439,7 +552,13 @@ func TestUpdatePendingMemberAlias(t *testing.T) {
 		mockUserRepo := new(mocks.MockUserRepository)
 		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-		service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+		})
 
 		membershipID := primitive.NewObjectID()
 		userID := primitive.NewObjectID()
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxAliasSuffix limits attempts to find a free alias for a player joining by a link
+const maxAliasSuffix = 100
+
+func (s *leagueServiceInstance) createJoinLink(ctx context.Context, leagueID, createdBy primitive.ObjectID, options InvitationOptions) (*models.LeagueInvitation, error) {
+	if options.MaxUses < 1 || options.MaxUses > MaxInvitationUses {
+		return nil, hexerr.New(fmt.Sprintf("max uses must be between 1 and %d", MaxInvitationUses))
+	}
+
+	expiresIn := options.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = DefaultInvitationLifetime
+	}
+	if expiresIn < time.Hour || expiresIn > MaxInvitationLifetime {
+		return nil, hexerr.New("link expiration must be between 1 hour and 30 days")
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to generate token")
+	}
+
+	invitation := &models.LeagueInvitation{
+		LeagueID:         leagueID,
+		CreatedBy:        createdBy,
+		Token:            token,
+		ExpiresAt:        time.Now().Add(expiresIn),
+		MaxUses:          options.MaxUses,
+		RequiresApproval: options.RequiresApproval,
+	}
+
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create invitation")
+	}
+
+	s.logAction(ctx, leagueID, createdBy, AuditActionInviteCreated, AuditTargetInvitation, invitation.ID,
+		AuditDetails{"max_uses": invitation.MaxUses, "requires_approval": invitation.RequiresApproval})
+
+	return invitation, nil
+}
+
+// acceptJoinLink creates a fresh membership for the user, active or waiting for approval as the link requires
+func (s *leagueServiceInstance) acceptJoinLink(ctx context.Context, invitation *models.LeagueInvitation, userID primitive.ObjectID, existing *models.LeagueMembership) (*models.League, error) {
+	if existing != nil && existing.Status != models.MembershipLeft {
+		switch {
+		case existing.Status == models.MembershipBanned:
+			return nil, hexerr.New("you are banned from this league")
+		case existing.AwaitsApproval():
+			return nil, hexerr.New("your membership is waiting for approval")
+		default:
+			return nil, hexerr.New("you already have a membership in this league")
+		}
+	}
+	if invitation.RemainingUses() == 0 {
+		return nil, hexerr.New("invitation has no uses left")
+	}
+
+	status := models.MembershipActive
+	if invitation.RequiresApproval {
+		status = models.MembershipPending
+	}
+
+	// A user who left the league gets the same membership back
+	if existing != nil {
+		use := models.InvitationUse{UserID: userID, MembershipID: existing.ID, Alias: existing.Alias, UsedAt: time.Now()}
+		if err := s.invitationRepo.AddUse(ctx, invitation.ID, use, invitation.MaxUses); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to accept invitation")
+		}
+		s.closeUsedUpLink(ctx, invitation, userID)
+		if err := s.rejoinLeague(ctx, existing, status); err != nil {
+			return nil, err
+		}
+		return s.GetLeague(ctx, invitation.LeagueID)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get user info")
+	}
+	if user == nil {
+		return nil, hexerr.New("user not found")
+	}
+
+	baseAlias := user.Alias
+	if baseAlias == "" {
+		baseAlias = user.Name
+	}
+	alias, err := s.availableAlias(ctx, invitation.LeagueID, baseAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	membership := &models.LeagueMembership{
+		LeagueID:       invitation.LeagueID,
+		UserID:         userID,
+		InvitationID:   invitation.ID,
+		Alias:          alias,
+		Status:         status,
+		JoinedAt:       now,
+		LastActivityAt: now,
+	}
+	if err := s.membershipRepo.Create(ctx, membership); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create membership")
+	}
+
+	use := models.InvitationUse{UserID: userID, MembershipID: membership.ID, Alias: alias, UsedAt: now}
+	if err := s.invitationRepo.AddUse(ctx, invitation.ID, use, invitation.MaxUses); err != nil {
+		// Someone else took the last use
+		if deleteErr := s.membershipRepo.Delete(ctx, membership.ID); deleteErr != nil {
+			glog.Warn("Failed to delete membership %s of the rejected link use: %v", membership.ID.Hex(), deleteErr)
+		}
+		return nil, hexerr.Wrapf(err, "failed to accept invitation")
+	}
+
+	s.closeUsedUpLink(ctx, invitation, userID)
+
+	s.logAction(ctx, invitation.LeagueID, userID, AuditActionInviteAccepted, AuditTargetInvitation, invitation.ID,
+		AuditDetails{"alias": alias, "status": string(status)})
+
+	return s.GetLeague(ctx, invitation.LeagueID)
+}
+
+// closeUsedUpLink hides the join link from the active invitations once its last use was taken
+func (s *leagueServiceInstance) closeUsedUpLink(ctx context.Context, invitation *models.LeagueInvitation, userID primitive.ObjectID) {
+	if len(invitation.Uses)+1 < invitation.MaxUses {
+		return
+	}
+	if err := s.invitationRepo.MarkAsUsed(ctx, invitation.ID, userID); err != nil {
+		glog.Warn("Failed to mark used up invitation %s: %v", invitation.ID.Hex(), err)
+	}
+}
+
+// availableAlias returns base or base with the first free number suffix in the league
+func (s *leagueServiceInstance) availableAlias(ctx context.Context, leagueID primitive.ObjectID, base string) (string, error) {
+	if base == "" {
+		base = "Player"
+	}
+
+	alias := base
+	for i := 2; i <= maxAliasSuffix; i++ {
+		existing, err := s.membershipRepo.FindByLeagueAndAlias(ctx, leagueID, alias)
+		if err != nil {
+			return "", hexerr.Wrapf(err, "failed to check alias availability")
+		}
+		if existing == nil {
+			return alias, nil
+		}
+		alias = fmt.Sprintf("%s %d", base, i)
+	}
+	return "", hexerr.New("failed to find a free alias")
+}
+
+func (s *leagueServiceInstance) ListInvitationUses(ctx context.Context, token string, userID primitive.ObjectID) ([]*InvitationUseInfo, error) {
+	invitation, err := s.invitationRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find invitation")
+	}
+	if invitation == nil {
+		return nil, hexerr.New("invitation not found")
+	}
+
+	// Verify the user is the creator or a league admin
+	if invitation.CreatedBy != userID {
+		isAdmin, err := s.isLeagueAdmin(ctx, invitation.LeagueID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, hexerr.New("you can only view uses of your own invitations")
+		}
+	}
+
+	uses := make([]*InvitationUseInfo, 0, len(invitation.Uses))
+	for _, use := range invitation.Uses {
+		info := &InvitationUseInfo{
+			MembershipID: use.MembershipID,
+			UserID:       use.UserID,
+			Alias:        use.Alias,
+			Status:       "removed",
+			UsedAt:       use.UsedAt,
+		}
+		membership, err := s.membershipRepo.FindByID(ctx, use.MembershipID)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to find membership")
+		}
+		if membership != nil {
+			info.Alias = membership.Alias
+			info.Status = string(membership.Status)
+		}
+		uses = append(uses, info)
+	}
+
+	return uses, nil
+}
+
+// findMemberAwaitingApproval returns the league membership waiting for approval or an error
+func (s *leagueServiceInstance) findMemberAwaitingApproval(ctx context.Context, leagueID, membershipID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.LeagueID != leagueID {
+		return nil, hexerr.New("membership not found")
+	}
+	if !membership.AwaitsApproval() {
+		return nil, hexerr.New("membership is not waiting for approval")
+	}
+	return membership, nil
+}
+
+func (s *leagueServiceInstance) ApproveMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.findMemberAwaitingApproval(ctx, leagueID, membershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	membership.Status = models.MembershipActive
+	if !membership.HasLeft() {
+		membership.JoinedAt = now
+	}
+	membership.LeftAt = time.Time{}
+	membership.LastActivityAt = now
+	if err := s.membershipRepo.Update(ctx, membership); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to activate membership")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionMemberApproved, AuditTargetMembership, membership.ID, AuditDetails{"alias": membership.Alias})
+
+	return membership, nil
+}
+
+func (s *leagueServiceInstance) RejectMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.findMemberAwaitingApproval(ctx, leagueID, membershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A user who left the league keeps the membership with its game history
+	if membership.HasLeft() {
+		membership.Status = models.MembershipLeft
+		if err := s.membershipRepo.Update(ctx, membership); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update membership")
+		}
+	} else if err := s.membershipRepo.Delete(ctx, membership.ID); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to delete membership")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionMemberRejected, AuditTargetMembership, membership.ID, AuditDetails{"alias": membership.Alias})
+
+	return membership, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateInvitation_JoinLink(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	newService := func(leagueRepo *mocks.MockLeagueRepository, invitationRepo *mocks.MockLeagueInvitationRepository) LeagueService {
+		leagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Test League", Status: models.LeagueActive}, nil)
+		return NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     leagueRepo,
+			InvitationRepo: invitationRepo,
+		})
+	}
+
+	t.Run("Link without alias and pending membership", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		service := newService(new(mocks.MockLeagueRepository), mockInvitationRepo)
+
+		mockInvitationRepo.On("Create", ctx, mock.MatchedBy(func(inv *models.LeagueInvitation) bool {
+			return inv.MaxUses == 10 && inv.RequiresApproval && inv.PlayerAlias == "" && inv.MembershipID.IsZero()
+		})).Return(nil)
+
+		invitation, err := service.CreateInvitation(ctx, leagueID, userID, "", InvitationOptions{MaxUses: 10, ExpiresIn: 48 * time.Hour, RequiresApproval: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, invitation.RemainingUses())
+		assert.WithinDuration(t, time.Now().Add(48*time.Hour), invitation.ExpiresAt, time.Minute)
+		mockInvitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid limits", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		service := newService(new(mocks.MockLeagueRepository), mockInvitationRepo)
+
+		_, err := service.CreateInvitation(ctx, leagueID, userID, "", InvitationOptions{MaxUses: MaxInvitationUses + 1})
+		assert.Error(t, err)
+
+		_, err = service.CreateInvitation(ctx, leagueID, userID, "", InvitationOptions{MaxUses: 5, ExpiresIn: 60 * 24 * time.Hour})
+		assert.Error(t, err)
+
+		mockInvitationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAcceptInvitation_JoinLink(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	token := "join-link"
+
+	newLink := func(maxUses, used int, requiresApproval bool) *models.LeagueInvitation {
+		invitation := &models.LeagueInvitation{
+			ID:               primitive.NewObjectID(),
+			LeagueID:         leagueID,
+			CreatedBy:        primitive.NewObjectID(),
+			Token:            token,
+			ExpiresAt:        time.Now().Add(24 * time.Hour),
+			MaxUses:          maxUses,
+			RequiresApproval: requiresApproval,
+		}
+		for i := 0; i < used; i++ {
+			invitation.Uses = append(invitation.Uses, models.InvitationUse{UserID: primitive.NewObjectID(), MembershipID: primitive.NewObjectID()})
+		}
+		return invitation
+	}
+
+	t.Run("Creates membership with a free alias", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+		})
+
+		invitation := newLink(5, 1, false)
+		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(nil, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Petro"}, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, "Petro").Return(&models.LeagueMembership{Alias: "Petro"}, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, "Petro 2").Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool {
+			return m.UserID == userID && m.Alias == "Petro 2" && m.Status == models.MembershipActive && m.InvitationID == invitation.ID
+		})).Return(nil)
+		mockInvitationRepo.On("AddUse", ctx, invitation.ID, mock.MatchedBy(func(use models.InvitationUse) bool {
+			return use.UserID == userID && use.Alias == "Petro 2"
+		}), 5).Return(nil)
+		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Test League"}, nil)
+
+		league, err := service.AcceptInvitation(ctx, token, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, leagueID, league.ID)
+		mockMembershipRepo.AssertExpectations(t)
+		mockInvitationRepo.AssertExpectations(t)
+		mockInvitationRepo.AssertNotCalled(t, "MarkAsUsed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Last use waits for approval and closes the link", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+		})
+
+		invitation := newLink(2, 1, true)
+		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(nil, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Petro", Alias: "petro"}, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, "petro").Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool {
+			return m.Status == models.MembershipPending && m.AwaitsApproval()
+		})).Return(nil)
+		mockInvitationRepo.On("AddUse", ctx, invitation.ID, mock.Anything, 2).Return(nil)
+		mockInvitationRepo.On("MarkAsUsed", ctx, invitation.ID, userID).Return(nil)
+		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Test League"}, nil)
+
+		_, err := service.AcceptInvitation(ctx, token, userID)
+
+		assert.NoError(t, err)
+		mockMembershipRepo.AssertExpectations(t)
+		mockInvitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Membership is removed when the last use was taken", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+			UserRepo:       mockUserRepo,
+		})
+
+		invitation := newLink(2, 1, false)
+		mockInvitationRepo.On("FindByToken", ctx, token).Return(invitation, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(nil, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Petro"}, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, "Petro").Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.AnythingOfType("*models.LeagueMembership")).Return(nil)
+		mockInvitationRepo.On("AddUse", ctx, invitation.ID, mock.Anything, 2).Return(assert.AnError)
+		mockMembershipRepo.On("Delete", ctx, mock.Anything).Return(nil)
+
+		_, err := service.AcceptInvitation(ctx, token, userID)
+
+		assert.Error(t, err)
+		mockMembershipRepo.AssertCalled(t, "Delete", ctx, mock.Anything)
+	})
+
+	t.Run("Banned user can't join", func(t *testing.T) {
+		mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			InvitationRepo: mockInvitationRepo,
+		})
+
+		mockInvitationRepo.On("FindByToken", ctx, token).Return(newLink(5, 0, false), nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(&models.LeagueMembership{LeagueID: leagueID, UserID: userID, Status: models.MembershipBanned}, nil)
+
+		_, err := service.AcceptInvitation(ctx, token, userID)
+
+		assert.ErrorContains(t, err, "banned")
+		mockMembershipRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestApproveAndRejectMember(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	newService := func(membershipRepo *mocks.MockLeagueMembershipRepository) LeagueService {
+		return NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: membershipRepo,
+		})
+	}
+
+	t.Run("Approve activates membership", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Status: models.MembershipPending}
+		mockMembershipRepo.On("FindByID", ctx, membership.ID).Return(membership, nil)
+		mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+
+		result, err := service.ApproveMember(ctx, leagueID, membership.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipActive, result.Status)
+	})
+
+	t.Run("Reject deletes membership", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Status: models.MembershipPending}
+		mockMembershipRepo.On("FindByID", ctx, membership.ID).Return(membership, nil)
+		mockMembershipRepo.On("Delete", ctx, membership.ID).Return(nil)
+
+		removed, err := service.RejectMember(ctx, leagueID, membership.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, membership.UserID, removed.UserID)
+		mockMembershipRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invited placeholder is not waiting for approval", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		invited := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Guest", Status: models.MembershipPending}
+		mockMembershipRepo.On("FindByID", ctx, invited.ID).Return(invited, nil)
+
+		_, err := service.ApproveMember(ctx, leagueID, invited.ID, actorID)
+		assert.Error(t, err)
+
+		_, err = service.RejectMember(ctx, leagueID, invited.ID, actorID)
+		assert.Error(t, err)
+
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockMembershipRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *leagueServiceInstance) SetLeaguePublic(ctx context.Context, leagueID primitive.ObjectID, isPublic bool, actorID primitive.ObjectID) (*models.League, error) {
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league.IsPublic == isPublic {
+		return league, nil
+	}
+
+	league.IsPublic = isPublic
+	if err := s.leagueRepo.Update(ctx, league); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update league")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionLeagueVisibilityChanged, AuditTargetLeague, leagueID, AuditDetails{"is_public": isPublic})
+
+	return league, nil
+}
+
+func (s *leagueServiceInstance) ListPublicLeagues(ctx context.Context) ([]*models.League, error) {
+	leagues, err := s.leagueRepo.FindPublic(ctx)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list public leagues")
+	}
+	return leagues, nil
+}
+
+func (s *leagueServiceInstance) RequestToJoin(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if !league.IsPublic || league.Status != models.LeagueActive {
+		return nil, hexerr.New("league is not open for join requests")
+	}
+
+	existing, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to check membership")
+	}
+	if existing != nil {
+		switch {
+		case existing.Status == models.MembershipActive:
+			return nil, &AlreadyMemberError{LeagueCode: utils.IdToCode(leagueID)}
+		case existing.Status == models.MembershipBanned:
+			return nil, hexerr.New("you are banned from this league")
+		case existing.Status == models.MembershipLeft:
+			// A user who left the league asks to get the same membership back
+			if err := s.rejoinLeague(ctx, existing, models.MembershipPending); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		case existing.AwaitsApproval():
+			return nil, hexerr.New("you already requested to join this league")
+		default:
+			return nil, hexerr.New("you already have a membership in this league")
+		}
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get user info")
+	}
+	if user == nil {
+		return nil, hexerr.New("user not found")
+	}
+
+	baseAlias := user.Alias
+	if baseAlias == "" {
+		baseAlias = user.Name
+	}
+	alias, err := s.availableAlias(ctx, leagueID, baseAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	membership := &models.LeagueMembership{
+		LeagueID:       leagueID,
+		UserID:         userID,
+		Alias:          alias,
+		Status:         models.MembershipPending,
+		JoinedAt:       now,
+		LastActivityAt: now,
+	}
+	if err := s.membershipRepo.Create(ctx, membership); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create membership")
+	}
+
+	s.logAction(ctx, leagueID, userID, AuditActionJoinRequested, AuditTargetMembership, membership.ID, AuditDetails{"alias": alias})
+
+	return membership, nil
+}
+
+func (s *leagueServiceInstance) ListJoinRequests(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueMemberInfo, error) {
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league memberships")
+	}
+
+	requests := make([]*LeagueMemberInfo, 0)
+	for _, membership := range memberships {
+		if !membership.AwaitsApproval() {
+			continue
+		}
+
+		request := &LeagueMemberInfo{
+			MembershipID: membership.ID,
+			UserID:       membership.UserID,
+			UserAlias:    membership.Alias,
+			Status:       membership.Status,
+			Role:         membership.EffectiveRole(),
+			JoinedAt:     membership.JoinedAt,
+		}
+		user, err := s.userRepo.FindByID(ctx, membership.UserID)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to get user %s", membership.UserID.Hex())
+		}
+		if user != nil {
+			request.UserName = user.Name
+			request.UserAvatar = user.Avatar
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
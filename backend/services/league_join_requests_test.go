@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRequestToJoin(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	newService := func(leagueRepo *mocks.MockLeagueRepository, membershipRepo *mocks.MockLeagueMembershipRepository, userRepo *mocks.MockUserRepository) LeagueService {
+		return NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     leagueRepo,
+			MembershipRepo: membershipRepo,
+			UserRepo:       userRepo,
+		})
+	}
+	publicLeague := &models.League{ID: leagueID, Name: "Test League", Status: models.LeagueActive, IsPublic: true}
+
+	t.Run("Creates membership waiting for approval", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, mockUserRepo)
+
+		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(publicLeague, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(nil, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Petro", Alias: "petro"}, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, "petro").Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool {
+			return m.UserID == userID && m.Alias == "petro" && m.AwaitsApproval()
+		})).Return(nil)
+
+		membership, err := service.RequestToJoin(ctx, leagueID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipPending, membership.Status)
+		mockMembershipRepo.AssertExpectations(t)
+	})
+
+	t.Run("Private league", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, new(mocks.MockUserRepository))
+
+		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Status: models.LeagueActive}, nil)
+
+		_, err := service.RequestToJoin(ctx, leagueID, userID)
+
+		assert.Error(t, err)
+		mockMembershipRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Existing memberships", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			existing *models.LeagueMembership
+		}{
+			{"Active member", &models.LeagueMembership{UserID: userID, Status: models.MembershipActive}},
+			{"Banned user", &models.LeagueMembership{UserID: userID, Status: models.MembershipBanned}},
+			{"Already requested", &models.LeagueMembership{UserID: userID, Status: models.MembershipPending}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				mockLeagueRepo := new(mocks.MockLeagueRepository)
+				mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+				service := newService(mockLeagueRepo, mockMembershipRepo, new(mocks.MockUserRepository))
+
+				mockLeagueRepo.On("FindByID", ctx, leagueID).Return(publicLeague, nil)
+				mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(tc.existing, nil)
+
+				_, err := service.RequestToJoin(ctx, leagueID, userID)
+
+				assert.Error(t, err)
+				mockMembershipRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("Active member gets the league code", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, new(mocks.MockUserRepository))
+
+		mockLeagueRepo.On("FindByID", ctx, leagueID).Return(publicLeague, nil)
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(&models.LeagueMembership{UserID: userID, Status: models.MembershipActive}, nil)
+
+		_, err := service.RequestToJoin(ctx, leagueID, userID)
+
+		_, ok := IsAlreadyMemberError(err)
+		assert.True(t, ok)
+	})
+}
+
+func TestSetLeaguePublic(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:   mockLeagueRepo,
+		AuditService: NewAuditService(mockAuditLogRepo),
+	})
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Status: models.LeagueActive}, nil)
+	mockLeagueRepo.On("Update", ctx, mock.MatchedBy(func(l *models.League) bool { return l.IsPublic })).Return(nil)
+	mockAuditLogRepo.On("Create", ctx, mock.MatchedBy(func(log *models.AuditLog) bool {
+		return log.Action == string(AuditActionLeagueVisibilityChanged) && log.Details["is_public"] == true
+	})).Return(nil)
+
+	league, err := service.SetLeaguePublic(ctx, leagueID, true, actorID)
+
+	assert.NoError(t, err)
+	assert.True(t, league.IsPublic)
+	mockLeagueRepo.AssertExpectations(t)
+	mockAuditLogRepo.AssertExpectations(t)
+}
+
+func TestListJoinRequests(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	service := NewLeagueService(LeagueServiceDeps{
+		MembershipRepo: mockMembershipRepo,
+		UserRepo:       mockUserRepo,
+	})
+
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{
+		{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Member", Status: models.MembershipActive},
+		{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Invited", Status: models.MembershipPending},
+		{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Alias: "petro", Status: models.MembershipPending},
+	}, nil)
+	mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Petro", Avatar: "avatar.png"}, nil)
+
+	requests, err := service.ListJoinRequests(ctx, leagueID)
+
+	assert.NoError(t, err)
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "petro", requests[0].UserAlias)
+		assert.Equal(t, "Petro", requests[0].UserName)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *leagueServiceInstance) LeaveLeague(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.Status != models.MembershipActive {
+		return nil, hexerr.New("you are not an active member of this league")
+	}
+	if membership.EffectiveRole() == models.LeagueRoleOwner {
+		return nil, hexerr.New("transfer the league ownership before leaving")
+	}
+
+	// League roles are not kept, a user who rejoins is an ordinary member
+	membership.Status = models.MembershipLeft
+	membership.Role = ""
+	membership.LeftAt = time.Now()
+	if err := s.membershipRepo.Update(ctx, membership); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to leave league")
+	}
+
+	s.logAction(ctx, leagueID, userID, AuditActionMemberLeft, AuditTargetMembership, membership.ID, AuditDetails{"alias": membership.Alias})
+
+	return membership, nil
+}
+
+// rejoinLeague brings back the membership of a user who left the league instead of creating a new one.
+// A pending membership keeps LeftAt, so that rejecting it returns it to the left status
+func (s *leagueServiceInstance) rejoinLeague(ctx context.Context, membership *models.LeagueMembership, status models.LeagueMembershipStatus) error {
+	membership.Status = status
+	if status == models.MembershipActive {
+		membership.LeftAt = time.Time{}
+	}
+	membership.LastActivityAt = time.Now()
+	if err := s.membershipRepo.Update(ctx, membership); err != nil {
+		return hexerr.Wrapf(err, "failed to restore membership")
+	}
+
+	s.logAction(ctx, membership.LeagueID, membership.UserID, AuditActionMemberRejoined, AuditTargetMembership, membership.ID,
+		AuditDetails{"alias": membership.Alias, "status": string(status)})
+
+	return nil
+}
+
+// rejoinByInvitation restores the membership of a user who left the league by a personal invitation.
+// The invitation placeholder is removed unless it already played games, then a league admin can merge it
+func (s *leagueServiceInstance) rejoinByInvitation(ctx context.Context, invitation *models.LeagueInvitation, membership *models.LeagueMembership) (*models.League, error) {
+	if err := s.rejoinLeague(ctx, membership, models.MembershipActive); err != nil {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.MarkAsUsed(ctx, invitation.ID, membership.UserID); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to mark invitation as used")
+	}
+
+	if !invitation.MembershipID.IsZero() && invitation.MembershipID != membership.ID {
+		hasGames, err := s.gameRoundRepo.HasGamesForMembership(ctx, invitation.MembershipID)
+		if err != nil {
+			glog.Warn("Failed to check games of invitation placeholder %s: %v", invitation.MembershipID.Hex(), err)
+		} else if !hasGames {
+			if err := s.membershipRepo.Delete(ctx, invitation.MembershipID); err != nil {
+				glog.Warn("Failed to delete invitation placeholder %s: %v", invitation.MembershipID.Hex(), err)
+			}
+		}
+	}
+
+	s.logAction(ctx, invitation.LeagueID, membership.UserID, AuditActionInviteAccepted, AuditTargetInvitation, invitation.ID, AuditDetails{"alias": membership.Alias})
+
+	return s.GetLeague(ctx, invitation.LeagueID)
+}
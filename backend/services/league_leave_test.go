@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLeaveLeague(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	newService := func(membershipRepo *mocks.MockLeagueMembershipRepository) LeagueService {
+		return NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: membershipRepo,
+		})
+	}
+
+	t.Run("Keeps membership with left status", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Alias: "Petro", Status: models.MembershipActive, Role: models.LeagueRoleAdmin}
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+
+		left, err := service.LeaveLeague(ctx, leagueID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipLeft, left.Status)
+		assert.Equal(t, models.LeagueRoleMember, left.EffectiveRole())
+		assert.True(t, left.HasLeft())
+		mockMembershipRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Owner has to transfer the league first", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).
+			Return(&models.LeagueMembership{LeagueID: leagueID, UserID: userID, Status: models.MembershipActive, Role: models.LeagueRoleOwner}, nil)
+
+		_, err := service.LeaveLeague(ctx, leagueID, userID)
+
+		assert.ErrorContains(t, err, "transfer")
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not an active member", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo)
+
+		mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).
+			Return(&models.LeagueMembership{LeagueID: leagueID, UserID: userID, Status: models.MembershipBanned}, nil)
+
+		_, err := service.LeaveLeague(ctx, leagueID, userID)
+
+		assert.Error(t, err)
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRejoinLeague(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	creatorID := primitive.NewObjectID()
+	league := &models.League{ID: leagueID, Name: "Test League", Status: models.LeagueActive, IsPublic: true}
+
+	type repos struct {
+		league     *mocks.MockLeagueRepository
+		membership *mocks.MockLeagueMembershipRepository
+		invitation *mocks.MockLeagueInvitationRepository
+		gameRound  *mocks.MockGameRoundRepository
+	}
+	newService := func() (LeagueService, repos) {
+		r := repos{
+			league:     new(mocks.MockLeagueRepository),
+			membership: new(mocks.MockLeagueMembershipRepository),
+			invitation: new(mocks.MockLeagueInvitationRepository),
+			gameRound:  new(mocks.MockGameRoundRepository),
+		}
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     r.league,
+			MembershipRepo: r.membership,
+			InvitationRepo: r.invitation,
+			GameRoundRepo:  r.gameRound,
+		})
+		r.league.On("FindByID", ctx, leagueID).Return(league, nil)
+		return service, r
+	}
+	leftMembership := func() *models.LeagueMembership {
+		return &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: userID, Alias: "Petro",
+			Status: models.MembershipLeft, LeftAt: time.Now().Add(-time.Hour), JoinedAt: time.Now().AddDate(-1, 0, 0)}
+	}
+
+	t.Run("Personal invitation restores the same membership", func(t *testing.T) {
+		service, r := newService()
+		membership := leftMembership()
+		invitation := &models.LeagueInvitation{ID: primitive.NewObjectID(), LeagueID: leagueID, Token: "token", CreatedBy: creatorID,
+			MembershipID: primitive.NewObjectID(), ExpiresAt: time.Now().Add(time.Hour)}
+
+		r.invitation.On("FindByToken", ctx, "token").Return(invitation, nil)
+		r.membership.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		r.membership.On("Update", ctx, membership).Return(nil)
+		r.invitation.On("MarkAsUsed", ctx, invitation.ID, userID).Return(nil)
+		r.gameRound.On("HasGamesForMembership", ctx, invitation.MembershipID).Return(false, nil)
+		r.membership.On("Delete", ctx, invitation.MembershipID).Return(nil)
+
+		_, err := service.AcceptInvitation(ctx, "token", userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipActive, membership.Status)
+		assert.False(t, membership.HasLeft())
+		r.membership.AssertExpectations(t)
+		r.membership.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Join link restores the same membership", func(t *testing.T) {
+		service, r := newService()
+		membership := leftMembership()
+		invitation := &models.LeagueInvitation{ID: primitive.NewObjectID(), LeagueID: leagueID, Token: "link", CreatedBy: creatorID,
+			MaxUses: 5, ExpiresAt: time.Now().Add(time.Hour)}
+
+		r.invitation.On("FindByToken", ctx, "link").Return(invitation, nil)
+		r.membership.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		r.invitation.On("AddUse", ctx, invitation.ID, mock.MatchedBy(func(use models.InvitationUse) bool {
+			return use.MembershipID == membership.ID
+		}), 5).Return(nil)
+		r.membership.On("Update", ctx, membership).Return(nil)
+
+		_, err := service.AcceptInvitation(ctx, "link", userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipActive, membership.Status)
+		r.invitation.AssertExpectations(t)
+		r.membership.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejected join request returns membership to left", func(t *testing.T) {
+		service, r := newService()
+		membership := leftMembership()
+		joinedAt := membership.JoinedAt
+
+		r.membership.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(membership, nil)
+		r.membership.On("Update", ctx, membership).Return(nil)
+		r.membership.On("FindByID", ctx, membership.ID).Return(membership, nil)
+
+		requested, err := service.RequestToJoin(ctx, leagueID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, membership.ID, requested.ID)
+		assert.True(t, requested.AwaitsApproval())
+
+		_, err = service.RejectMember(ctx, leagueID, membership.ID, creatorID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipLeft, membership.Status)
+		r.membership.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+		_, err = service.RequestToJoin(ctx, leagueID, userID)
+		assert.NoError(t, err)
+		_, err = service.ApproveMember(ctx, leagueID, membership.ID, creatorID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.MembershipActive, membership.Status)
+		assert.Equal(t, joinedAt, membership.JoinedAt)
+		assert.False(t, membership.HasLeft())
+	})
+}
@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MembershipMergeUndoWindow is how long a merge of memberships can be undone
+const MembershipMergeUndoWindow = 7 * 24 * time.Hour
+
+// MergeMemberships moves the game history of the source membership to the target one and deletes the source.
+// The collections are written one by one without a transaction: every step can be repeated, and a merge interrupted
+// halfway is resumed by merging the same memberships again
+func (s *leagueServiceInstance) MergeMemberships(ctx context.Context, leagueID, sourceID, targetID, actorID primitive.ObjectID) (*models.MembershipMerge, error) {
+	if sourceID == targetID {
+		return nil, hexerr.New("can't merge a membership into itself")
+	}
+
+	merge, err := s.mergeRepo.FindUnfinished(ctx, leagueID, sourceID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find unfinished merge")
+	}
+	if merge != nil && merge.Target.ID != targetID {
+		return nil, hexerr.New(fmt.Sprintf("unfinished merge of %s into %s has to be completed first", merge.Source.Alias, merge.Target.Alias))
+	}
+
+	// The source is already deleted when the merge was interrupted at its last steps
+	var source *models.LeagueMembership
+	if merge != nil {
+		source = &merge.Source
+	} else {
+		source, err = s.findLeagueMembership(ctx, leagueID, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if source.Status != models.MembershipVirtual && source.Status != models.MembershipPending {
+			return nil, hexerr.New("only virtual or pending memberships can be merged")
+		}
+	}
+
+	target, err := s.findLeagueMembership(ctx, leagueID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != models.MembershipActive || target.UserID.IsZero() {
+		return nil, hexerr.New("memberships can only be merged into an active member")
+	}
+
+	rounds, err := s.gameRoundRepo.FindByMembership(ctx, leagueID, sourceID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find game rounds")
+	}
+	for _, round := range rounds {
+		if roundHasMembership(round, targetID) {
+			return nil, hexerr.New(fmt.Sprintf("both members played in %s", round.Name))
+		}
+	}
+
+	wizardGames, err := s.wizardGameRepo.FindByMembership(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league members")
+	}
+
+	resumed := merge != nil
+	if !resumed {
+		now := time.Now()
+		merge = &models.MembershipMerge{
+			LeagueID:  leagueID,
+			Source:    *source,
+			Target:    *target,
+			MergedBy:  actorID,
+			MergedAt:  now,
+			UndoUntil: now.Add(MembershipMergeUndoWindow),
+		}
+	}
+	// A resumed merge keeps the backups taken before its first step and adds what is still left to move
+	for _, round := range rounds {
+		merge.GameRoundIDs = appendMissingID(merge.GameRoundIDs, round.ID)
+	}
+	for _, game := range wizardGames {
+		merge.WizardGameIDs = appendMissingID(merge.WizardGameIDs, game.ID)
+	}
+	var coPlayers []*models.LeagueMembership
+	for _, membership := range memberships {
+		if membership.ID == sourceID || membership.ID == targetID || !hasCoPlayer(membership.RecentCoPlayers, sourceID) {
+			continue
+		}
+		if !hasCoPlayersBackup(merge.CoPlayers, membership.ID) {
+			merge.CoPlayers = append(merge.CoPlayers, models.CoPlayersBackup{MembershipID: membership.ID, RecentCoPlayers: membership.RecentCoPlayers})
+		}
+		coPlayers = append(coPlayers, membership)
+	}
+
+	// The record is stored first, so that a merge failed halfway can still be undone or resumed
+	if resumed {
+		err = s.mergeRepo.Update(ctx, merge)
+	} else {
+		err = s.mergeRepo.Create(ctx, merge)
+	}
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to record merge")
+	}
+	defer s.forgetLeagueMemberships(leagueID)
+
+	for _, round := range rounds {
+		replaceRoundMembership(round, sourceID, targetID)
+		if err := s.gameRoundRepo.Update(ctx, round); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update game round %s", round.ID.Hex())
+		}
+	}
+	for _, game := range wizardGames {
+		replaceWizardMembership(game, sourceID, targetID)
+		if err := s.wizardGameRepo.Update(ctx, game); err != nil {
+			return nil, err
+		}
+	}
+
+	// Recent co-players are a cache, point everything that referred to the source to the target
+	for _, membership := range coPlayers {
+		membership.RecentCoPlayers = mergeCoPlayers(replaceCoPlayer(membership.RecentCoPlayers, sourceID, targetID), nil, membership.ID)
+		if err := s.membershipRepo.Update(ctx, membership); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update recent co-players")
+		}
+	}
+
+	target.RecentCoPlayers = mergeCoPlayers(target.RecentCoPlayers, source.RecentCoPlayers, targetID, sourceID)
+	if source.LastActivityAt.After(target.LastActivityAt) {
+		target.LastActivityAt = source.LastActivityAt
+	}
+	if !source.JoinedAt.IsZero() && source.JoinedAt.Before(target.JoinedAt) {
+		target.JoinedAt = source.JoinedAt
+	}
+	if err := s.membershipRepo.Update(ctx, target); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update target membership")
+	}
+
+	// The placeholder is gone, its personal invitation can't be accepted anymore
+	if !source.InvitationID.IsZero() && source.Status == models.MembershipPending {
+		if err := s.invitationRepo.Cancel(ctx, source.InvitationID); err != nil {
+			glog.Warn("Failed to cancel invitation %s of merged membership: %v", source.InvitationID.Hex(), err)
+		}
+	}
+
+	if err := s.membershipRepo.Delete(ctx, sourceID); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to delete merged membership")
+	}
+
+	merge.Completed = true
+	if err := s.mergeRepo.Update(ctx, merge); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to complete merge")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionMembersMerged, AuditTargetMembership, targetID, AuditDetails{
+		"source_alias": source.Alias,
+		"target_alias": target.Alias,
+		"game_rounds":  len(merge.GameRoundIDs),
+		"wizard_games": len(merge.WizardGameIDs),
+		"resumed":      resumed,
+	})
+
+	return merge, nil
+}
+
+func (s *leagueServiceInstance) ListUndoableMerges(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error) {
+	merges, err := s.mergeRepo.FindUndoable(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list merges")
+	}
+	return merges, nil
+}
+
+func (s *leagueServiceInstance) UndoMerge(ctx context.Context, leagueID, mergeID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	merge, err := s.mergeRepo.FindByID(ctx, mergeID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find merge")
+	}
+	if merge == nil || merge.LeagueID != leagueID {
+		return nil, hexerr.New("merge not found")
+	}
+	if time.Now().After(merge.UndoUntil) {
+		return nil, hexerr.New("merge can no longer be undone")
+	}
+
+	sourceID, targetID := merge.Source.ID, merge.Target.ID
+	target, err := s.findLeagueMembership(ctx, leagueID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	// The source is already restored when undo was interrupted halfway, then it's repeated from the next step
+	taken, err := s.membershipRepo.FindByLeagueAndAlias(ctx, leagueID, merge.Source.Alias)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to check alias availability")
+	}
+	if taken != nil && taken.ID != sourceID {
+		return nil, hexerr.New(fmt.Sprintf("alias %s is already taken", merge.Source.Alias))
+	}
+	defer s.forgetLeagueMemberships(leagueID)
+
+	source := merge.Source
+	if taken == nil {
+		if err := s.membershipRepo.Create(ctx, &source); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to restore merged membership")
+		}
+	}
+
+	for _, roundID := range merge.GameRoundIDs {
+		round, err := s.gameRoundRepo.FindByID(ctx, roundID)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to find game round %s", roundID.Hex())
+		}
+		if round == nil {
+			continue
+		}
+		replaceRoundMembership(round, targetID, sourceID)
+		if err := s.gameRoundRepo.Update(ctx, round); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update game round %s", roundID.Hex())
+		}
+	}
+	for _, gameID := range merge.WizardGameIDs {
+		game, err := s.wizardGameRepo.FindByID(ctx, gameID)
+		if err != nil {
+			return nil, err
+		}
+		if game == nil {
+			continue
+		}
+		replaceWizardMembership(game, targetID, sourceID)
+		if err := s.wizardGameRepo.Update(ctx, game); err != nil {
+			return nil, err
+		}
+	}
+
+	// Games finalized since the merge updated the members too, only what the merge itself changed is reversed
+	for _, backup := range merge.CoPlayers {
+		membership, err := s.membershipRepo.FindByID(ctx, backup.MembershipID)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to find membership")
+		}
+		if membership == nil {
+			continue
+		}
+		membership.RecentCoPlayers = undoCoPlayersMerge(membership.RecentCoPlayers, backup.RecentCoPlayers, sourceID, targetID)
+		if err := s.membershipRepo.Update(ctx, membership); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update recent co-players")
+		}
+	}
+
+	target.RecentCoPlayers = unmergeCoPlayers(target.RecentCoPlayers, merge.Target.RecentCoPlayers, merge.Source.RecentCoPlayers)
+	if target.LastActivityAt.Equal(latestTime(merge.Target.LastActivityAt, merge.Source.LastActivityAt)) {
+		target.LastActivityAt = merge.Target.LastActivityAt
+	}
+	if target.JoinedAt.Equal(merge.Source.JoinedAt) {
+		target.JoinedAt = merge.Target.JoinedAt
+	}
+	if err := s.membershipRepo.Update(ctx, target); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update target membership")
+	}
+
+	if err := s.mergeRepo.Delete(ctx, mergeID); err != nil {
+		glog.Warn("Failed to delete undone merge %s: %v", mergeID.Hex(), err)
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionMembersMergeUndone, AuditTargetMembership, sourceID, AuditDetails{
+		"source_alias": source.Alias,
+		"target_alias": target.Alias,
+	})
+
+	return &source, nil
+}
+
+// forgetLeagueMemberships drops cached memberships of the league, merges change several of them at once
+func (s *leagueServiceInstance) forgetLeagueMemberships(leagueID primitive.ObjectID) {
+	if s.memberships != nil {
+		s.memberships.RemoveByLeague(leagueID)
+	}
+}
+
+// findLeagueMembership returns the membership if it belongs to the league or an error
+func (s *leagueServiceInstance) findLeagueMembership(ctx context.Context, leagueID, membershipID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.LeagueID != leagueID {
+		return nil, hexerr.New("membership not found")
+	}
+	return membership, nil
+}
+
+func roundHasMembership(round *models.GameRound, membershipID primitive.ObjectID) bool {
+	for _, player := range round.Players {
+		if player.MembershipID == membershipID {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceRoundMembership(round *models.GameRound, from, to primitive.ObjectID) {
+	for i := range round.Players {
+		if round.Players[i].MembershipID == from {
+			round.Players[i].MembershipID = to
+		}
+	}
+}
+
+func replaceWizardMembership(game *models.WizardGame, from, to primitive.ObjectID) {
+	for i := range game.Players {
+		if game.Players[i].MembershipID == from {
+			game.Players[i].MembershipID = to
+		}
+	}
+}
+
+func hasCoPlayersBackup(backups []models.CoPlayersBackup, membershipID primitive.ObjectID) bool {
+	for _, backup := range backups {
+		if backup.MembershipID == membershipID {
+			return true
+		}
+	}
+	return false
+}
+
+func appendMissingID(ids []primitive.ObjectID, id primitive.ObjectID) []primitive.ObjectID {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+func hasCoPlayer(coPlayers []models.RecentCoPlayer, membershipID primitive.ObjectID) bool {
+	for _, coPlayer := range coPlayers {
+		if coPlayer.MembershipID == membershipID {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceCoPlayer(coPlayers []models.RecentCoPlayer, from, to primitive.ObjectID) []models.RecentCoPlayer {
+	replaced := make([]models.RecentCoPlayer, 0, len(coPlayers))
+	for _, coPlayer := range coPlayers {
+		if coPlayer.MembershipID == from {
+			coPlayer.MembershipID = to
+		}
+		replaced = append(replaced, coPlayer)
+	}
+	return replaced
+}
+
+// undoCoPlayersMerge points back to the source the co-player entry which the merge moved from the source to the target,
+// backup is the list before the merge
+func undoCoPlayersMerge(current, backup []models.RecentCoPlayer, sourceID, targetID primitive.ObjectID) []models.RecentCoPlayer {
+	var others, fromSource []models.RecentCoPlayer
+	for _, coPlayer := range backup {
+		if coPlayer.MembershipID == sourceID {
+			fromSource = append(fromSource, coPlayer)
+		} else {
+			others = append(others, coPlayer)
+		}
+	}
+	if len(fromSource) == 0 {
+		return current
+	}
+
+	moved := replaceCoPlayer(fromSource, sourceID, targetID)
+	return mergeCoPlayers(unmergeCoPlayers(current, others, moved), fromSource)
+}
+
+// unmergeCoPlayers reverses mergeCoPlayers(before, added): an entry which still has the value the merge gave it gets
+// its value from before back or is dropped if the merge added it, entries updated by later games are kept
+func unmergeCoPlayers(current, before, added []models.RecentCoPlayer) []models.RecentCoPlayer {
+	beforeByID := make(map[primitive.ObjectID]models.RecentCoPlayer, len(before))
+	for _, coPlayer := range before {
+		beforeByID[coPlayer.MembershipID] = coPlayer
+	}
+	addedByID := make(map[primitive.ObjectID]models.RecentCoPlayer, len(added))
+	for _, coPlayer := range added {
+		addedByID[coPlayer.MembershipID] = coPlayer
+	}
+
+	restored := make([]models.RecentCoPlayer, 0, len(current))
+	for _, coPlayer := range current {
+		fromAdded, wasAdded := addedByID[coPlayer.MembershipID]
+		previous, existed := beforeByID[coPlayer.MembershipID]
+		if !wasAdded || !coPlayer.LastPlayedAt.Equal(latestTime(previous.LastPlayedAt, fromAdded.LastPlayedAt)) {
+			restored = append(restored, coPlayer)
+			continue
+		}
+		if existed {
+			restored = append(restored, previous)
+		}
+	}
+	return mergeCoPlayers(restored, nil)
+}
+
+func latestTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// mergeCoPlayers joins two co-player lists keeping the latest game with each co-player, sorted by LastPlayedAt DESC
+// and trimmed to MaxRecentCoPlayers, the excluded memberships are dropped
+func mergeCoPlayers(a, b []models.RecentCoPlayer, exclude ...primitive.ObjectID) []models.RecentCoPlayer {
+	latest := make(map[primitive.ObjectID]models.RecentCoPlayer)
+	for _, coPlayer := range append(append([]models.RecentCoPlayer{}, a...), b...) {
+		if existing, ok := latest[coPlayer.MembershipID]; !ok || coPlayer.LastPlayedAt.After(existing.LastPlayedAt) {
+			latest[coPlayer.MembershipID] = coPlayer
+		}
+	}
+	for _, id := range exclude {
+		delete(latest, id)
+	}
+
+	merged := make([]models.RecentCoPlayer, 0, len(latest))
+	for _, coPlayer := range latest {
+		merged = append(merged, coPlayer)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].LastPlayedAt.After(merged[j].LastPlayedAt)
+	})
+	if len(merged) > models.MaxRecentCoPlayers {
+		merged = merged[:models.MaxRecentCoPlayers]
+	}
+	return merged
+}
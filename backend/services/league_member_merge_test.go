@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergeMemberships(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	type repos struct {
+		membership *mocks.MockLeagueMembershipRepository
+		gameRound  *mocks.MockGameRoundRepository
+		wizardGame *mocks.MockWizardGameRepository
+		merge      *mocks.MockMembershipMergeRepository
+	}
+	newService := func() (LeagueService, repos) {
+		r := repos{
+			membership: new(mocks.MockLeagueMembershipRepository),
+			gameRound:  new(mocks.MockGameRoundRepository),
+			wizardGame: new(mocks.MockWizardGameRepository),
+			merge:      new(mocks.MockMembershipMergeRepository),
+		}
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: r.membership,
+			GameRoundRepo:  r.gameRound,
+			WizardGameRepo: r.wizardGame,
+			MergeRepo:      r.merge,
+		})
+		return service, r
+	}
+
+	now := time.Now()
+	newMembers := func() (source, target, other *models.LeagueMembership) {
+		source = &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Petro (guest)", Status: models.MembershipVirtual}
+		target = &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Petro", Status: models.MembershipActive}
+		other = &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Olena", Status: models.MembershipActive}
+		source.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: other.ID, LastPlayedAt: now.Add(-time.Hour)}, {MembershipID: target.ID, LastPlayedAt: now}}
+		target.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: other.ID, LastPlayedAt: now.Add(-2 * time.Hour)}}
+		other.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: source.ID, LastPlayedAt: now.Add(-time.Hour)}, {MembershipID: target.ID, LastPlayedAt: now.Add(-2 * time.Hour)}}
+		return source, target, other
+	}
+
+	t.Run("Moves game history to the target", func(t *testing.T) {
+		service, r := newService()
+		source, target, other := newMembers()
+
+		round := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, Players: []models.GameRoundPlayer{{MembershipID: source.ID}, {MembershipID: other.ID}}}
+		game := &models.WizardGame{ID: primitive.NewObjectID(), Players: []models.WizardPlayer{{MembershipID: source.ID}, {MembershipID: other.ID}}}
+
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(nil, nil)
+		r.membership.On("FindByID", ctx, source.ID).Return(source, nil)
+		r.membership.On("FindByID", ctx, target.ID).Return(target, nil)
+		r.membership.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{source, target, other}, nil)
+		r.gameRound.On("FindByMembership", ctx, leagueID, source.ID).Return([]*models.GameRound{round}, nil)
+		r.wizardGame.On("FindByMembership", ctx, source.ID).Return([]*models.WizardGame{game}, nil)
+		r.merge.On("Create", ctx, mock.MatchedBy(func(m *models.MembershipMerge) bool {
+			return m.Source.ID == source.ID && len(m.GameRoundIDs) == 1 && len(m.WizardGameIDs) == 1 && len(m.CoPlayers) == 1
+		})).Return(nil)
+		r.gameRound.On("Update", ctx, round).Return(nil)
+		r.wizardGame.On("Update", ctx, game).Return(nil)
+		r.membership.On("Update", ctx, mock.AnythingOfType("*models.LeagueMembership")).Return(nil)
+		r.membership.On("Delete", ctx, source.ID).Return(nil)
+		r.merge.On("Update", ctx, mock.MatchedBy(func(m *models.MembershipMerge) bool { return m.Completed })).Return(nil)
+
+		merge, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.True(t, merge.Completed)
+		assert.WithinDuration(t, time.Now().Add(MembershipMergeUndoWindow), merge.UndoUntil, time.Minute)
+		assert.Equal(t, target.ID, round.Players[0].MembershipID)
+		assert.Equal(t, target.ID, game.Players[0].MembershipID)
+		// The target keeps the latest game with each co-player, without itself
+		assert.Equal(t, []models.RecentCoPlayer{{MembershipID: other.ID, LastPlayedAt: now.Add(-time.Hour)}}, target.RecentCoPlayers)
+		// Other members see the target instead of the source, only once
+		assert.Equal(t, []models.RecentCoPlayer{{MembershipID: target.ID, LastPlayedAt: now.Add(-time.Hour)}}, other.RecentCoPlayers)
+		r.membership.AssertExpectations(t)
+		r.merge.AssertExpectations(t)
+	})
+
+	t.Run("Only virtual or pending members into active ones", func(t *testing.T) {
+		service, r := newService()
+		source, target, other := newMembers()
+
+		r.membership.On("FindByID", ctx, source.ID).Return(source, nil)
+		r.membership.On("FindByID", ctx, target.ID).Return(target, nil)
+		r.membership.On("FindByID", ctx, other.ID).Return(other, nil)
+		r.merge.On("FindUnfinished", ctx, leagueID, mock.Anything).Return(nil, nil)
+
+		_, err := service.MergeMemberships(ctx, leagueID, other.ID, target.ID, actorID)
+		assert.Error(t, err)
+
+		target.Status = models.MembershipBanned
+		_, err = service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+		assert.Error(t, err)
+
+		_, err = service.MergeMemberships(ctx, leagueID, source.ID, source.ID, actorID)
+		assert.Error(t, err)
+
+		r.merge.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Members who played together can't be merged", func(t *testing.T) {
+		service, r := newService()
+		source, target, _ := newMembers()
+
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(nil, nil)
+		r.membership.On("FindByID", ctx, source.ID).Return(source, nil)
+		r.membership.On("FindByID", ctx, target.ID).Return(target, nil)
+		r.gameRound.On("FindByMembership", ctx, leagueID, source.ID).Return([]*models.GameRound{
+			{ID: primitive.NewObjectID(), Name: "Friday game", Players: []models.GameRoundPlayer{{MembershipID: source.ID}, {MembershipID: target.ID}}},
+		}, nil)
+
+		_, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.ErrorContains(t, err, "Friday game")
+		r.merge.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Merge failed halfway is resumed", func(t *testing.T) {
+		service, r := newService()
+		source, target, other := newMembers()
+
+		round := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, Players: []models.GameRoundPlayer{{MembershipID: source.ID}}}
+		game := &models.WizardGame{ID: primitive.NewObjectID(), Players: []models.WizardPlayer{{MembershipID: source.ID}}}
+		gameAfterFailure := &models.WizardGame{ID: game.ID, Players: []models.WizardPlayer{{MembershipID: source.ID}}}
+
+		var recorded *models.MembershipMerge
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(nil, nil).Once()
+		r.membership.On("FindByID", ctx, source.ID).Return(source, nil)
+		r.membership.On("FindByID", ctx, target.ID).Return(target, nil)
+		r.membership.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{source, target, other}, nil)
+		r.gameRound.On("FindByMembership", ctx, leagueID, source.ID).Return([]*models.GameRound{round}, nil).Once()
+		r.wizardGame.On("FindByMembership", ctx, source.ID).Return([]*models.WizardGame{game}, nil).Once()
+		r.merge.On("Create", ctx, mock.AnythingOfType("*models.MembershipMerge")).Run(func(args mock.Arguments) {
+			recorded = args.Get(1).(*models.MembershipMerge)
+		}).Return(nil)
+		r.gameRound.On("Update", ctx, round).Return(nil)
+		r.wizardGame.On("Update", ctx, game).Return(errors.New("connection reset")).Once()
+
+		_, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.Error(t, err)
+		assert.Equal(t, target.ID, round.Players[0].MembershipID)
+		assert.False(t, recorded.Completed)
+		r.membership.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+		// The moved round isn't found by the source anymore, the rest is moved by the retry
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(recorded, nil).Once()
+		r.gameRound.On("FindByMembership", ctx, leagueID, source.ID).Return([]*models.GameRound{}, nil).Once()
+		r.wizardGame.On("FindByMembership", ctx, source.ID).Return([]*models.WizardGame{gameAfterFailure}, nil).Once()
+		r.wizardGame.On("Update", ctx, gameAfterFailure).Return(nil).Once()
+		r.merge.On("Update", ctx, recorded).Return(nil)
+		r.membership.On("Update", ctx, mock.AnythingOfType("*models.LeagueMembership")).Return(nil)
+		r.membership.On("Delete", ctx, source.ID).Return(nil)
+
+		merge, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.True(t, merge.Completed)
+		assert.Equal(t, []primitive.ObjectID{round.ID}, merge.GameRoundIDs)
+		assert.Equal(t, []primitive.ObjectID{game.ID}, merge.WizardGameIDs)
+		assert.Len(t, merge.CoPlayers, 1)
+		assert.Equal(t, target.ID, gameAfterFailure.Players[0].MembershipID)
+		r.merge.AssertNumberOfCalls(t, "Create", 1)
+		r.membership.AssertNumberOfCalls(t, "Delete", 1)
+	})
+
+	t.Run("Unfinished merge into another member has to be completed first", func(t *testing.T) {
+		service, r := newService()
+		source, target, other := newMembers()
+
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(&models.MembershipMerge{LeagueID: leagueID, Source: *source, Target: *other}, nil)
+
+		_, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.ErrorContains(t, err, "unfinished merge")
+		r.membership.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Forgets cached memberships of the league", func(t *testing.T) {
+		r := repos{
+			membership: new(mocks.MockLeagueMembershipRepository),
+			gameRound:  new(mocks.MockGameRoundRepository),
+			wizardGame: new(mocks.MockWizardGameRepository),
+			merge:      new(mocks.MockMembershipMergeRepository),
+		}
+		cache := NewMembershipCache()
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo:  r.membership,
+			GameRoundRepo:   r.gameRound,
+			WizardGameRepo:  r.wizardGame,
+			MergeRepo:       r.merge,
+			MembershipCache: cache,
+		})
+		source, target, other := newMembers()
+		cache.Set(leagueID, target.UserID, target)
+		cache.Set(leagueID, other.UserID, other)
+
+		r.merge.On("FindUnfinished", ctx, leagueID, source.ID).Return(nil, nil)
+		r.membership.On("FindByID", ctx, source.ID).Return(source, nil)
+		r.membership.On("FindByID", ctx, target.ID).Return(target, nil)
+		r.membership.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{source, target, other}, nil)
+		r.gameRound.On("FindByMembership", ctx, leagueID, source.ID).Return([]*models.GameRound{}, nil)
+		r.wizardGame.On("FindByMembership", ctx, source.ID).Return([]*models.WizardGame{}, nil)
+		r.merge.On("Create", ctx, mock.AnythingOfType("*models.MembershipMerge")).Return(nil)
+		r.membership.On("Update", ctx, mock.AnythingOfType("*models.LeagueMembership")).Return(nil)
+		r.membership.On("Delete", ctx, source.ID).Return(nil)
+		r.merge.On("Update", ctx, mock.AnythingOfType("*models.MembershipMerge")).Return(nil)
+
+		_, err := service.MergeMemberships(ctx, leagueID, source.ID, target.ID, actorID)
+
+		assert.NoError(t, err)
+		_, cached := cache.Get(leagueID, other.UserID)
+		assert.False(t, cached)
+		_, cached = cache.Get(leagueID, target.UserID)
+		assert.False(t, cached)
+	})
+}
+
+func TestUndoMerge(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	source := models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Petro (guest)", Status: models.MembershipVirtual}
+	target := models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Petro", Status: models.MembershipActive}
+
+	t.Run("Restores the source and its games", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+		mockMergeRepo := new(mocks.MockMembershipMergeRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+			MergeRepo:      mockMergeRepo,
+		})
+
+		round := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, Players: []models.GameRoundPlayer{{MembershipID: target.ID}}}
+		merge := &models.MembershipMerge{ID: primitive.NewObjectID(), LeagueID: leagueID, Source: source, Target: target,
+			GameRoundIDs: []primitive.ObjectID{round.ID}, UndoUntil: time.Now().Add(time.Hour)}
+		currentTarget := target
+		currentTarget.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: primitive.NewObjectID(), LastPlayedAt: time.Now()}}
+
+		mockMergeRepo.On("FindByID", ctx, merge.ID).Return(merge, nil)
+		mockMembershipRepo.On("FindByID", ctx, target.ID).Return(&currentTarget, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, source.Alias).Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool { return m.ID == source.ID })).Return(nil)
+		mockGameRoundRepo.On("FindByID", ctx, round.ID).Return(round, nil)
+		mockGameRoundRepo.On("Update", ctx, round).Return(nil)
+		mockMembershipRepo.On("Update", ctx, &currentTarget).Return(nil)
+		mockMergeRepo.On("Delete", ctx, merge.ID).Return(nil)
+
+		restored, err := service.UndoMerge(ctx, leagueID, merge.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, source.ID, restored.ID)
+		assert.Equal(t, source.ID, round.Players[0].MembershipID)
+		// The source had no co-players, so the merge didn't add the target's one
+		assert.Len(t, currentTarget.RecentCoPlayers, 1)
+		mockMembershipRepo.AssertExpectations(t)
+		mockMergeRepo.AssertExpectations(t)
+	})
+
+	t.Run("Keeps updates of games finalized after the merge", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+		mockMergeRepo := new(mocks.MockMembershipMergeRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+			MergeRepo:      mockMergeRepo,
+		})
+
+		now := time.Now()
+		sourcePlayed, targetPlayed, playedSinceMerge := now.Add(-time.Hour), now.Add(-2*time.Hour), now.Add(-10*time.Minute)
+		regular, newcomer, other := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		partner := models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Olena", Status: models.MembershipActive}
+		occasional := models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Ivan", Status: models.MembershipActive}
+
+		mergedSource := source
+		mergedSource.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: partner.ID, LastPlayedAt: sourcePlayed}}
+		mergedSource.LastActivityAt = sourcePlayed
+		mergedSource.JoinedAt = now.Add(-30 * 24 * time.Hour)
+		mergedTarget := target
+		mergedTarget.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: regular, LastPlayedAt: targetPlayed}}
+		mergedTarget.LastActivityAt = targetPlayed
+		mergedTarget.JoinedAt = now.Add(-24 * time.Hour)
+
+		merge := &models.MembershipMerge{ID: primitive.NewObjectID(), LeagueID: leagueID, Source: mergedSource, Target: mergedTarget,
+			CoPlayers: []models.CoPlayersBackup{
+				{MembershipID: partner.ID, RecentCoPlayers: []models.RecentCoPlayer{{MembershipID: source.ID, LastPlayedAt: sourcePlayed}, {MembershipID: other, LastPlayedAt: targetPlayed}}},
+				{MembershipID: occasional.ID, RecentCoPlayers: []models.RecentCoPlayer{{MembershipID: source.ID, LastPlayedAt: sourcePlayed}}},
+			},
+			UndoUntil: now.Add(time.Hour)}
+
+		// After the merge the target played with the partner and a newcomer
+		currentTarget := mergedTarget
+		currentTarget.RecentCoPlayers = []models.RecentCoPlayer{
+			{MembershipID: partner.ID, LastPlayedAt: playedSinceMerge},
+			{MembershipID: newcomer, LastPlayedAt: playedSinceMerge},
+			{MembershipID: regular, LastPlayedAt: targetPlayed},
+		}
+		currentTarget.LastActivityAt = playedSinceMerge
+		currentTarget.JoinedAt = mergedSource.JoinedAt
+		currentPartner := partner
+		currentPartner.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: target.ID, LastPlayedAt: playedSinceMerge}, {MembershipID: other, LastPlayedAt: targetPlayed}}
+		currentOccasional := occasional
+		currentOccasional.RecentCoPlayers = []models.RecentCoPlayer{{MembershipID: target.ID, LastPlayedAt: sourcePlayed}}
+
+		mockMergeRepo.On("FindByID", ctx, merge.ID).Return(merge, nil)
+		mockMembershipRepo.On("FindByID", ctx, target.ID).Return(&currentTarget, nil)
+		mockMembershipRepo.On("FindByID", ctx, partner.ID).Return(&currentPartner, nil)
+		mockMembershipRepo.On("FindByID", ctx, occasional.ID).Return(&currentOccasional, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, source.Alias).Return(nil, nil)
+		mockMembershipRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockMembershipRepo.On("Update", ctx, mock.Anything).Return(nil)
+		mockMergeRepo.On("Delete", ctx, merge.ID).Return(nil)
+
+		_, err := service.UndoMerge(ctx, leagueID, merge.ID, actorID)
+
+		assert.NoError(t, err)
+		// Co-players of later games stay, the partner's game with the source was the only one the merge brought
+		assert.ElementsMatch(t, []models.RecentCoPlayer{
+			{MembershipID: partner.ID, LastPlayedAt: playedSinceMerge},
+			{MembershipID: newcomer, LastPlayedAt: playedSinceMerge},
+			{MembershipID: regular, LastPlayedAt: targetPlayed},
+		}, currentTarget.RecentCoPlayers)
+		assert.Equal(t, playedSinceMerge, currentTarget.LastActivityAt)
+		assert.Equal(t, mergedTarget.JoinedAt, currentTarget.JoinedAt)
+		// The partner played with both: the source before the merge and the target after it
+		assert.Equal(t, []models.RecentCoPlayer{
+			{MembershipID: target.ID, LastPlayedAt: playedSinceMerge},
+			{MembershipID: source.ID, LastPlayedAt: sourcePlayed},
+			{MembershipID: other, LastPlayedAt: targetPlayed},
+		}, currentPartner.RecentCoPlayers)
+		assert.Equal(t, []models.RecentCoPlayer{{MembershipID: source.ID, LastPlayedAt: sourcePlayed}}, currentOccasional.RecentCoPlayers)
+	})
+
+	t.Run("Undo interrupted after the source was restored is repeated", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+		mockMergeRepo := new(mocks.MockMembershipMergeRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			GameRoundRepo:  mockGameRoundRepo,
+			MergeRepo:      mockMergeRepo,
+		})
+
+		round := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, Players: []models.GameRoundPlayer{{MembershipID: target.ID}}}
+		merge := &models.MembershipMerge{ID: primitive.NewObjectID(), LeagueID: leagueID, Source: source, Target: target,
+			GameRoundIDs: []primitive.ObjectID{round.ID}, UndoUntil: time.Now().Add(time.Hour)}
+		currentTarget := target
+		restoredSource := source
+
+		mockMergeRepo.On("FindByID", ctx, merge.ID).Return(merge, nil)
+		mockMembershipRepo.On("FindByID", ctx, target.ID).Return(&currentTarget, nil)
+		mockMembershipRepo.On("FindByLeagueAndAlias", ctx, leagueID, source.Alias).Return(&restoredSource, nil)
+		mockGameRoundRepo.On("FindByID", ctx, round.ID).Return(round, nil)
+		mockGameRoundRepo.On("Update", ctx, round).Return(nil)
+		mockMembershipRepo.On("Update", ctx, &currentTarget).Return(nil)
+		mockMergeRepo.On("Delete", ctx, merge.ID).Return(nil)
+
+		restored, err := service.UndoMerge(ctx, leagueID, merge.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, source.ID, restored.ID)
+		assert.Equal(t, source.ID, round.Players[0].MembershipID)
+		mockMembershipRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockMergeRepo.AssertExpectations(t)
+	})
+
+	t.Run("Grace window has passed", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockMergeRepo := new(mocks.MockMembershipMergeRepository)
+		service := NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: mockMembershipRepo,
+			MergeRepo:      mockMergeRepo,
+		})
+
+		merge := &models.MembershipMerge{ID: primitive.NewObjectID(), LeagueID: leagueID, Source: source, Target: target, UndoUntil: time.Now().Add(-time.Minute)}
+		mockMergeRepo.On("FindByID", ctx, merge.ID).Return(merge, nil)
+
+		_, err := service.UndoMerge(ctx, leagueID, merge.ID, actorID)
+
+		assert.Error(t, err)
+		mockMembershipRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
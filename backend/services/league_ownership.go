@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultMaxOwnedLeagues is the number of leagues an ordinary user can own when MAX_OWNED_LEAGUES is not set
+const DefaultMaxOwnedLeagues = 3
+
+// OwnedLeaguesLimitError is returned when a user who already owns the maximum number of leagues creates another one
+type OwnedLeaguesLimitError struct {
+	Limit int
+}
+
+func (e *OwnedLeaguesLimitError) Error() string {
+	return fmt.Sprintf("you can own at most %d leagues", e.Limit)
+}
+
+// IsOwnedLeaguesLimitError checks if the error is OwnedLeaguesLimitError
+func IsOwnedLeaguesLimitError(err error) bool {
+	var limitErr *OwnedLeaguesLimitError
+	return errors.As(err, &limitErr)
+}
+
+// LeagueOverview is a league with its owner and size, for the superadmin review of leagues
+type LeagueOverview struct {
+	League       *models.League
+	Owner        *LeagueMemberInfo // nil for a league without active members who could own it
+	MembersCount int               // Active members
+}
+
+// maxOwnedLeaguesFromEnv reads the per-user cap of owned leagues from MAX_OWNED_LEAGUES, 0 disables the cap
+func maxOwnedLeaguesFromEnv() int {
+	value := os.Getenv("MAX_OWNED_LEAGUES")
+	if value == "" {
+		return DefaultMaxOwnedLeagues
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		glog.Warn("Invalid MAX_OWNED_LEAGUES %q, using %d", value, DefaultMaxOwnedLeagues)
+		return DefaultMaxOwnedLeagues
+	}
+	return limit
+}
+
+// checkOwnedLeaguesLimit fails with OwnedLeaguesLimitError if the user can't own one more league
+func (s *leagueServiceInstance) checkOwnedLeaguesLimit(ctx context.Context, userID primitive.ObjectID) error {
+	if s.maxOwnedLeagues == 0 {
+		return nil
+	}
+
+	memberships, err := s.membershipRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get user memberships")
+	}
+
+	owned := 0
+	for _, membership := range memberships {
+		if membership.EffectiveRole() == models.LeagueRoleOwner {
+			owned++
+		}
+	}
+	if owned >= s.maxOwnedLeagues {
+		return &OwnedLeaguesLimitError{Limit: s.maxOwnedLeagues}
+	}
+	return nil
+}
+
+func (s *leagueServiceInstance) ListLeagueOverviews(ctx context.Context) ([]*LeagueOverview, error) {
+	leagues, err := s.leagueRepo.FindAll(ctx)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list leagues")
+	}
+
+	overviews := make([]*LeagueOverview, 0, len(leagues))
+	for _, league := range leagues {
+		memberships, err := s.membershipRepo.FindByLeague(ctx, league.ID)
+		if err != nil {
+			return nil, hexerr.Wrapf(err, "failed to get members of league %s", league.ID.Hex())
+		}
+
+		overview := &LeagueOverview{League: league}
+		for _, membership := range memberships {
+			if membership.Status == models.MembershipActive {
+				overview.MembersCount++
+			}
+			if membership.EffectiveRole() != models.LeagueRoleOwner {
+				continue
+			}
+
+			owner := &LeagueMemberInfo{
+				MembershipID: membership.ID,
+				UserID:       membership.UserID,
+				UserAlias:    membership.Alias,
+				Status:       membership.Status,
+				Role:         membership.Role,
+				JoinedAt:     membership.JoinedAt,
+			}
+			user, err := s.userRepo.FindByID(ctx, membership.UserID)
+			if err != nil {
+				return nil, hexerr.Wrapf(err, "failed to get user %s", membership.UserID.Hex())
+			}
+			if user != nil {
+				owner.UserName = user.Name
+				owner.UserAvatar = user.Avatar
+			}
+			overview.Owner = owner
+		}
+		overviews = append(overviews, overview)
+	}
+
+	return overviews, nil
+}
+
+func (s *leagueServiceInstance) TransferOwnership(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	newOwner, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if newOwner == nil || newOwner.LeagueID != leagueID {
+		return nil, hexerr.New("membership not found")
+	}
+	if newOwner.Status != models.MembershipActive || newOwner.UserID.IsZero() {
+		return nil, hexerr.New("ownership can only be transferred to an active member")
+	}
+	if newOwner.EffectiveRole() == models.LeagueRoleOwner {
+		return newOwner, nil
+	}
+
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league members")
+	}
+
+	// The previous owner stays in the league as an admin
+	previousOwnerAlias := ""
+	for _, membership := range memberships {
+		if membership.EffectiveRole() != models.LeagueRoleOwner {
+			continue
+		}
+		membership.Role = models.LeagueRoleAdmin
+		if err := s.membershipRepo.Update(ctx, membership); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to update previous owner")
+		}
+		previousOwnerAlias = membership.Alias
+	}
+
+	newOwner.Role = models.LeagueRoleOwner
+	if err := s.membershipRepo.Update(ctx, newOwner); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update new owner")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionOwnershipTransferred, AuditTargetMembership, newOwner.ID,
+		AuditDetails{"alias": newOwner.Alias, "previous_owner": previousOwnerAlias})
+
+	return newOwner, nil
+}
+
+func (s *leagueServiceInstance) AssignMissingOwners(ctx context.Context) (int, error) {
+	leagues, err := s.leagueRepo.FindAll(ctx)
+	if err != nil {
+		return 0, hexerr.Wrapf(err, "failed to list leagues")
+	}
+
+	assigned := 0
+	for _, league := range leagues {
+		memberships, err := s.membershipRepo.FindByLeague(ctx, league.ID)
+		if err != nil {
+			return assigned, hexerr.Wrapf(err, "failed to get members of league %s", league.ID.Hex())
+		}
+
+		owner := ownerCandidate(memberships)
+		if owner == nil {
+			continue
+		}
+		previousRole := owner.EffectiveRole()
+		owner.Role = models.LeagueRoleOwner
+		if err := s.membershipRepo.Update(ctx, owner); err != nil {
+			return assigned, hexerr.Wrapf(err, "failed to make %s the owner of league %s", owner.Alias, league.ID.Hex())
+		}
+
+		s.logAction(ctx, league.ID, primitive.NilObjectID, AuditActionMemberRoleChanged, AuditTargetMembership, owner.ID,
+			AuditDetails{"alias": owner.Alias, "from": string(previousRole), "to": string(models.LeagueRoleOwner)})
+		assigned++
+	}
+
+	return assigned, nil
+}
+
+// ownerCandidate returns the active member with an account whose membership was created first, that's the creator
+// of the league if the creator joined it. Nil when the league already has an owner or nobody can own it
+func ownerCandidate(memberships []*models.LeagueMembership) *models.LeagueMembership {
+	var candidate *models.LeagueMembership
+	for _, membership := range memberships {
+		if membership.EffectiveRole() == models.LeagueRoleOwner {
+			return nil
+		}
+		if membership.Status != models.MembershipActive || membership.UserID.IsZero() {
+			continue
+		}
+		// Object IDs start with their creation time
+		if candidate == nil || bytes.Compare(membership.ID[:], candidate.ID[:]) < 0 {
+			candidate = membership
+		}
+	}
+	return candidate
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateLeague_CreatorBecomesOwner(t *testing.T) {
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	newService := func(leagueRepo *mocks.MockLeagueRepository, membershipRepo *mocks.MockLeagueMembershipRepository, userRepo *mocks.MockUserRepository) LeagueService {
+		auditLogRepo := new(mocks.MockAuditLogRepository)
+		auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+		service := NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     leagueRepo,
+			MembershipRepo: membershipRepo,
+			UserRepo:       userRepo,
+			AuditService:   NewAuditService(auditLogRepo),
+		})
+		service.(*leagueServiceInstance).maxOwnedLeagues = 2
+		return service
+	}
+	ownerOf := func(count int) []*models.LeagueMembership {
+		memberships := []*models.LeagueMembership{{ID: primitive.NewObjectID(), UserID: userID, Status: models.MembershipActive}}
+		for i := 0; i < count; i++ {
+			memberships = append(memberships, &models.LeagueMembership{ID: primitive.NewObjectID(), UserID: userID, Status: models.MembershipActive, Role: models.LeagueRoleOwner})
+		}
+		return memberships
+	}
+
+	t.Run("Owner membership is created with the user alias", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, mockUserRepo)
+
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Alice Smith", Alias: "alice"}, nil)
+		mockMembershipRepo.On("FindByUser", ctx, userID).Return(ownerOf(1), nil)
+		mockLeagueRepo.On("Create", ctx, mock.AnythingOfType("*models.League")).Return(nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool {
+			return m.UserID == userID && m.Alias == "alice" && m.Role == models.LeagueRoleOwner && m.Status == models.MembershipActive
+		})).Return(nil)
+
+		league, err := service.CreateLeague(ctx, "Board Games", userID, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Board Games", league.Name)
+		mockLeagueRepo.AssertExpectations(t)
+		mockMembershipRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owned leagues limit reached", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, mockUserRepo)
+
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Alice Smith"}, nil)
+		mockMembershipRepo.On("FindByUser", ctx, userID).Return(ownerOf(2), nil)
+
+		_, err := service.CreateLeague(ctx, "Board Games", userID, false)
+
+		assert.True(t, IsOwnedLeaguesLimitError(err))
+		mockLeagueRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Superadmin isn't limited", func(t *testing.T) {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		service := newService(mockLeagueRepo, mockMembershipRepo, mockUserRepo)
+
+		mockUserRepo.On("FindByID", ctx, userID).Return(&models.User{ID: userID, Name: "Alice Smith"}, nil)
+		mockLeagueRepo.On("Create", ctx, mock.AnythingOfType("*models.League")).Return(nil)
+		mockMembershipRepo.On("Create", ctx, mock.MatchedBy(func(m *models.LeagueMembership) bool {
+			return m.Alias == "Alice Smith" && m.Role == models.LeagueRoleOwner
+		})).Return(nil)
+
+		_, err := service.CreateLeague(ctx, "Board Games", userID, true)
+
+		assert.NoError(t, err)
+		mockMembershipRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransferOwnership(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	newService := func(membershipRepo *mocks.MockLeagueMembershipRepository, auditLogRepo *mocks.MockAuditLogRepository) LeagueService {
+		return NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: membershipRepo,
+			AuditService:   NewAuditService(auditLogRepo),
+		})
+	}
+
+	t.Run("Previous owner becomes admin", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+		service := newService(mockMembershipRepo, mockAuditLogRepo)
+
+		owner := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipActive, Role: models.LeagueRoleOwner}
+		member := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipActive}
+		mockMembershipRepo.On("FindByID", ctx, member.ID).Return(member, nil)
+		mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{owner, member}, nil)
+		mockMembershipRepo.On("Update", ctx, mock.AnythingOfType("*models.LeagueMembership")).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.MatchedBy(func(log *models.AuditLog) bool {
+			return log.Action == string(AuditActionOwnershipTransferred) && log.ActorID == actorID && log.Details["previous_owner"] == "Alice"
+		})).Return(nil)
+
+		result, err := service.TransferOwnership(ctx, leagueID, member.ID, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.LeagueRoleOwner, result.Role)
+		assert.Equal(t, models.LeagueRoleAdmin, owner.Role)
+		mockMembershipRepo.AssertNumberOfCalls(t, "Update", 2)
+		mockAuditLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("Only to active members of the league", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo, new(mocks.MockAuditLogRepository))
+
+		virtual := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Guest", Status: models.MembershipVirtual}
+		otherLeague := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: models.MembershipActive}
+		mockMembershipRepo.On("FindByID", ctx, virtual.ID).Return(virtual, nil)
+		mockMembershipRepo.On("FindByID", ctx, otherLeague.ID).Return(otherLeague, nil)
+
+		_, err := service.TransferOwnership(ctx, leagueID, virtual.ID, actorID)
+		assert.Error(t, err)
+
+		_, err = service.TransferOwnership(ctx, leagueID, otherLeague.ID, actorID)
+		assert.Error(t, err)
+
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAssignMissingOwners(t *testing.T) {
+	ctx := context.Background()
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+	})
+
+	legacy := &models.League{ID: primitive.NewObjectID(), Name: "Created before roles"}
+	owned := &models.League{ID: primitive.NewObjectID(), Name: "Has owner"}
+	empty := &models.League{ID: primitive.NewObjectID(), Name: "Only guests"}
+
+	creator := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: legacy.ID, UserID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipActive}
+	guest := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: legacy.ID, Alias: "Guest", Status: models.MembershipVirtual}
+	banned := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: legacy.ID, UserID: primitive.NewObjectID(), Alias: "Carol", Status: models.MembershipBanned}
+	joinedLater := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: legacy.ID, UserID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipActive}
+	owner := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: owned.ID, UserID: primitive.NewObjectID(), Status: models.MembershipActive, Role: models.LeagueRoleOwner}
+	member := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: owned.ID, UserID: primitive.NewObjectID(), Status: models.MembershipActive}
+
+	mockLeagueRepo.On("FindAll", ctx).Return([]*models.League{legacy, owned, empty}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, legacy.ID).Return([]*models.LeagueMembership{joinedLater, banned, guest, creator}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, owned.ID).Return([]*models.LeagueMembership{member, owner}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, empty.ID).Return([]*models.LeagueMembership{}, nil)
+	mockMembershipRepo.On("Update", ctx, creator).Return(nil)
+
+	assigned, err := service.AssignMissingOwners(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, assigned)
+	assert.Equal(t, models.LeagueRoleOwner, creator.Role)
+	assert.Empty(t, joinedLater.Role)
+	assert.Empty(t, member.Role)
+	mockMembershipRepo.AssertNumberOfCalls(t, "Update", 1)
+
+	// Running it again at the next start changes nothing
+	mockMembershipRepo.On("FindByLeague", ctx, legacy.ID).Unset()
+	mockMembershipRepo.On("FindByLeague", ctx, legacy.ID).Return([]*models.LeagueMembership{joinedLater, creator}, nil)
+
+	assigned, err = service.AssignMissingOwners(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, assigned)
+	mockMembershipRepo.AssertNumberOfCalls(t, "Update", 1)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSetMemberRole(t *testing.T) {
+	ctx := context.Background()
+	leagueID := primitive.NewObjectID()
+	actorID := primitive.NewObjectID()
+
+	newService := func(membershipRepo *mocks.MockLeagueMembershipRepository, auditLogRepo *mocks.MockAuditLogRepository) LeagueService {
+		return NewLeagueService(LeagueServiceDeps{
+			MembershipRepo: membershipRepo,
+			AuditService:   NewAuditService(auditLogRepo),
+		})
+	}
+
+	t.Run("Promote member to admin", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockAuditLogRepo := new(mocks.MockAuditLogRepository)
+		service := newService(mockMembershipRepo, mockAuditLogRepo)
+
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipActive}
+		mockMembershipRepo.On("FindByID", ctx, membership.ID).Return(membership, nil)
+		mockMembershipRepo.On("Update", ctx, membership).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.MatchedBy(func(log *models.AuditLog) bool {
+			return log.Action == string(AuditActionMemberRoleChanged) && log.ActorID == actorID &&
+				log.Details["from"] == "member" && log.Details["to"] == "admin"
+		})).Return(nil)
+
+		result, err := service.SetMemberRole(ctx, leagueID, membership.ID, models.LeagueRoleAdmin, actorID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.LeagueRoleAdmin, result.Role)
+		mockMembershipRepo.AssertExpectations(t)
+		mockAuditLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owner role can't be changed", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo, new(mocks.MockAuditLogRepository))
+
+		membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.MembershipActive, Role: models.LeagueRoleOwner}
+		mockMembershipRepo.On("FindByID", ctx, membership.ID).Return(membership, nil)
+
+		_, err := service.SetMemberRole(ctx, leagueID, membership.ID, models.LeagueRoleMember, actorID)
+
+		assert.Error(t, err)
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Only active members of the league", func(t *testing.T) {
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		service := newService(mockMembershipRepo, new(mocks.MockAuditLogRepository))
+
+		virtual := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.MembershipVirtual}
+		otherLeague := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID(), Status: models.MembershipActive}
+		mockMembershipRepo.On("FindByID", ctx, virtual.ID).Return(virtual, nil)
+		mockMembershipRepo.On("FindByID", ctx, otherLeague.ID).Return(otherLeague, nil)
+
+		_, err := service.SetMemberRole(ctx, leagueID, virtual.ID, models.LeagueRoleAdmin, actorID)
+		assert.Error(t, err)
+
+		_, err = service.SetMemberRole(ctx, leagueID, otherLeague.ID, models.LeagueRoleAdmin, actorID)
+		assert.Error(t, err)
+
+		mockMembershipRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Owner role can't be granted", func(t *testing.T) {
+		service := newService(new(mocks.MockLeagueMembershipRepository), new(mocks.MockAuditLogRepository))
+
+		_, err := service.SetMemberRole(ctx, leagueID, primitive.NewObjectID(), models.LeagueRoleOwner, actorID)
+
+		assert.Error(t, err)
+	})
+}
@@ -5,19 +5,21 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
 	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/repositories"
 	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
 	"github.com/andriyg76/hexerr"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type LeagueService interface {
-	// Створення ліги (тільки суперадмін)
-	CreateLeague(ctx context.Context, name string) (*models.League, error)
+	// Створення ліги, творець стає її власником (кількість ліг у власності обмежена, крім суперадміна)
+	CreateLeague(ctx context.Context, name string, createdBy primitive.ObjectID, isSuperAdmin bool) (*models.League, error)
 
 	// Отримання інформації про лігу
 	GetLeague(ctx context.Context, leagueID primitive.ObjectID) (*models.League, error)
@@ -25,20 +27,32 @@ type LeagueService interface {
 	ListActiveLeagues(ctx context.Context) ([]*models.League, error)
 
 	// Управління лігою (тільки суперадмін)
-	ArchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error
-	UnarchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error
+	ArchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error
+	UnarchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error
+	UpdateLeagueSettings(ctx context.Context, leagueID primitive.ObjectID, settings LeagueSettings) (*models.League, error)
 
 	// Управління членством
 	GetLeagueMembers(ctx context.Context, leagueID primitive.ObjectID) ([]*models.User, error)
 	GetLeagueMemberships(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueMemberInfo, error)
 	GetMemberByID(ctx context.Context, membershipID primitive.ObjectID) (*models.LeagueMembership, error)
 	IsUserMember(ctx context.Context, leagueID, userID primitive.ObjectID) (bool, error)
-	BanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error
-	UnbanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error
+	BanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error
+	UnbanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error
 	CreateMembershipForSuperAdmin(ctx context.Context, leagueID, userID primitive.ObjectID, alias string) (*models.LeagueMembership, error)
+	// SetMemberRole promotes an active member to league admin or demotes back to member, the owner role can't be changed this way
+	SetMemberRole(ctx context.Context, leagueID, membershipID primitive.ObjectID, role models.LeagueRole, actorID primitive.ObjectID) (*models.LeagueMembership, error)
+
+	// Власність ліг (тільки суперадмін)
+	ListLeagueOverviews(ctx context.Context) ([]*LeagueOverview, error)
+	// TransferOwnership makes an active member the league owner, the previous owner becomes an admin
+	TransferOwnership(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error)
+	// AssignMissingOwners makes the earliest active member the owner of every league without one, for leagues created
+	// before league roles. Returns the number of leagues which got an owner
+	AssignMissingOwners(ctx context.Context) (int, error)
 
 	// Запрошення
-	CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string) (*models.LeagueInvitation, error)
+	// CreateInvitation creates a personal invitation for playerAlias, or a reusable join link when options.MaxUses is set
+	CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string, options InvitationOptions) (*models.LeagueInvitation, error)
 	AcceptInvitation(ctx context.Context, token string, userID primitive.ObjectID) (*models.League, error)
 	PreviewInvitation(ctx context.Context, token string) (*InvitationPreview, error)
 	GetInvitationByToken(ctx context.Context, token string) (*models.LeagueInvitation, error)
@@ -46,10 +60,41 @@ type LeagueService interface {
 	ListMyExpiredInvitations(ctx context.Context, leagueID, userID primitive.ObjectID) ([]*models.LeagueInvitation, error)
 	CancelInvitation(ctx context.Context, token string, userID primitive.ObjectID) error
 	ExtendInvitation(ctx context.Context, token string, userID primitive.ObjectID) (*models.LeagueInvitation, error)
+	// ListInvitationUses returns the players who joined by a reusable join link, for its creator or a league admin
+	ListInvitationUses(ctx context.Context, token string, userID primitive.ObjectID) ([]*InvitationUseInfo, error)
+
+	// SetLeagueLanguage sets the language of league messages sent outside of the app (Discord and other chats)
+	SetLeagueLanguage(ctx context.Context, leagueID primitive.ObjectID, language string, actorID primitive.ObjectID) (*models.League, error)
+
+	// Публічні ліги і запити на вступ
+	SetLeaguePublic(ctx context.Context, leagueID primitive.ObjectID, isPublic bool, actorID primitive.ObjectID) (*models.League, error)
+	ListPublicLeagues(ctx context.Context) ([]*models.League, error)
+	// RequestToJoin creates a membership of the user in a public league, pending until a league admin approves it
+	RequestToJoin(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error)
+	// ListJoinRequests returns memberships waiting for approval, from join requests and join links
+	ListJoinRequests(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueMemberInfo, error)
+	// ApproveMember activates a membership waiting for approval, RejectMember removes it and returns the removed one
+	ApproveMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error)
+	RejectMember(ctx context.Context, leagueID, membershipID, actorID primitive.ObjectID) (*models.LeagueMembership, error)
 	UpdatePendingMemberAlias(ctx context.Context, membershipID primitive.ObjectID, userID primitive.ObjectID, newAlias string) error
+	// LeaveLeague turns the active membership of the user into a left one, the owner has to transfer the ownership first.
+	// Joining again by an invitation, a join link or a join request restores the same membership
+	LeaveLeague(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error)
+
+	// Об'єднання учасників
+	// MergeMemberships moves the game history of a virtual or pending membership to an active member and deletes it,
+	// the merge can be undone within MembershipMergeUndoWindow
+	MergeMemberships(ctx context.Context, leagueID, sourceID, targetID, actorID primitive.ObjectID) (*models.MembershipMerge, error)
+	ListUndoableMerges(ctx context.Context, leagueID primitive.ObjectID) ([]*models.MembershipMerge, error)
+	// UndoMerge restores the merged membership with its game history and returns it
+	UndoMerge(ctx context.Context, leagueID, mergeID, actorID primitive.ObjectID) (*models.LeagueMembership, error)
 
 	// Рейтинг
-	GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueStanding, error)
+	GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID, filter StandingsFilter) ([]*LeagueStanding, error)
+	GetLeagueStandingsByGameType(ctx context.Context, leagueID primitive.ObjectID) ([]*GameTypeStandings, error)
+	GetLeagueRatings(ctx context.Context, leagueID primitive.ObjectID, algorithm RatingAlgorithm) ([]*MemberRating, error)
+	// GetLastFinishedRound returns the league round which finished last, nil if no round is finished yet
+	GetLastFinishedRound(ctx context.Context, leagueID primitive.ObjectID) (*models.GameRound, error)
 
 	// Підтримка вибору гравців для гри
 	UpdatePlayersAfterGame(ctx context.Context, playerMembershipIDs []primitive.ObjectID) error
@@ -57,6 +102,18 @@ type LeagueService interface {
 	GetMembershipByLeagueAndUser(ctx context.Context, leagueID, userID primitive.ObjectID) (*models.LeagueMembership, error)
 }
 
+// LeagueSettings contains the league settings editable by a superadmin
+type LeagueSettings struct {
+	PointsConfig *models.LeaguePointsConfig // nil - reset to the default points system
+}
+
+// GameTypeStandings is a leaderboard of a single game type within a league
+type GameTypeStandings struct {
+	GameTypeID  primitive.ObjectID
+	RoundsCount int
+	Standings   []*LeagueStanding
+}
+
 // LeagueMemberInfo represents a member with user and membership info
 type LeagueMemberInfo struct {
 	MembershipID    primitive.ObjectID
@@ -65,6 +122,7 @@ type LeagueMemberInfo struct {
 	UserAlias       string
 	UserAvatar      string
 	Status          models.LeagueMembershipStatus
+	Role            models.LeagueRole
 	JoinedAt        time.Time
 	InvitationToken string // Token of the invitation if exists (for virtual/pending members)
 }
@@ -81,6 +139,8 @@ type SuggestedPlayer struct {
 // SuggestedPlayersResponse contains suggested players for game creation
 type SuggestedPlayersResponse struct {
 	CurrentPlayer       *SuggestedPlayer  `json:"current_player"`
+	NightCode           string            `json:"night_code,omitempty"`    // Game night going on now, rounds can be linked to it
+	NightPlayers        []SuggestedPlayer `json:"night_players,omitempty"` // Members who answered yes or maybe for the night, yes first
 	RecentPlayers       []SuggestedPlayer `json:"recent_players"`
 	OtherPlayers        []SuggestedPlayer `json:"other_players"`
 	CanCreateMembership bool              `json:"can_create_membership,omitempty"` // true if superadmin without membership
@@ -89,11 +149,39 @@ type SuggestedPlayersResponse struct {
 
 // InvitationPreview represents public invitation preview data
 type InvitationPreview struct {
-	LeagueName   string
-	InviterAlias string
-	PlayerAlias  string
-	ExpiresAt    time.Time
-	Status       string // "valid", "expired", "used"
+	LeagueName       string
+	InviterAlias     string
+	PlayerAlias      string
+	ExpiresAt        time.Time
+	Status           string // "valid", "expired", "used"
+	MaxUses          int    // 0 for personal invitations
+	RemainingUses    int
+	RequiresApproval bool
+}
+
+const (
+	// MaxInvitationUses limits the number of players who can join by one reusable link
+	MaxInvitationUses = 100
+	// MaxInvitationLifetime limits the expiration of reusable links
+	MaxInvitationLifetime = 30 * 24 * time.Hour
+	// DefaultInvitationLifetime is the expiration of invitations and links created without one
+	DefaultInvitationLifetime = 7 * 24 * time.Hour
+)
+
+// InvitationOptions configures a reusable join link, the zero value creates a personal single-use invitation
+type InvitationOptions struct {
+	MaxUses          int           // Number of players who can join by the link, 0 for a personal invitation
+	ExpiresIn        time.Duration // 0 - DefaultInvitationLifetime
+	RequiresApproval bool          // Joined players are pending until a league admin approves them
+}
+
+// InvitationUseInfo is a player who joined by a reusable join link
+type InvitationUseInfo struct {
+	MembershipID primitive.ObjectID
+	UserID       primitive.ObjectID
+	Alias        string
+	Status       string // Membership status, "removed" if the membership was rejected or deleted
+	UsedAt       time.Time
 }
 
 // AlreadyMemberError is returned when user is already a member of the league
@@ -120,31 +208,75 @@ type leagueServiceInstance struct {
 	invitationRepo repositories.LeagueInvitationRepository
 	userRepo       repositories.UserRepository
 	gameRoundRepo  repositories.GameRoundRepository
+	gameTypeRepo   repositories.GameTypeRepository
+	wizardGameRepo repositories.WizardGameRepository
+	mergeRepo      repositories.MembershipMergeRepository
+	nightRepo      repositories.GameNightRepository
+	auditService   AuditService
+	memberships    MembershipCache // nil - memberships are not cached
 	pointsConfig   PointsConfig
+
+	maxOwnedLeagues int // 0 - unlimited
 }
 
-func NewLeagueService(
-	leagueRepo repositories.LeagueRepository,
-	membershipRepo repositories.LeagueMembershipRepository,
-	invitationRepo repositories.LeagueInvitationRepository,
-	userRepo repositories.UserRepository,
-	gameRoundRepo repositories.GameRoundRepository,
-) LeagueService {
+// LeagueServiceDeps lists the repositories and services the league service works with.
+// Tests set only the ones the tested methods use
+type LeagueServiceDeps struct {
+	LeagueRepo      repositories.LeagueRepository
+	MembershipRepo  repositories.LeagueMembershipRepository
+	InvitationRepo  repositories.LeagueInvitationRepository
+	UserRepo        repositories.UserRepository
+	GameRoundRepo   repositories.GameRoundRepository
+	GameTypeRepo    repositories.GameTypeRepository
+	WizardGameRepo  repositories.WizardGameRepository
+	MergeRepo       repositories.MembershipMergeRepository
+	NightRepo       repositories.GameNightRepository
+	AuditService    AuditService    // Actions are not recorded when nil
+	MembershipCache MembershipCache // Invalidated when memberships are merged, optional
+}
+
+func NewLeagueService(deps LeagueServiceDeps) LeagueService {
+	auditService := deps.AuditService
+	if auditService == nil {
+		auditService = NewNoopAuditService()
+	}
 	return &leagueServiceInstance{
-		leagueRepo:     leagueRepo,
-		membershipRepo: membershipRepo,
-		invitationRepo: invitationRepo,
-		userRepo:       userRepo,
-		gameRoundRepo:  gameRoundRepo,
+		leagueRepo:     deps.LeagueRepo,
+		membershipRepo: deps.MembershipRepo,
+		invitationRepo: deps.InvitationRepo,
+		userRepo:       deps.UserRepo,
+		gameRoundRepo:  deps.GameRoundRepo,
+		gameTypeRepo:   deps.GameTypeRepo,
+		wizardGameRepo: deps.WizardGameRepo,
+		mergeRepo:      deps.MergeRepo,
+		nightRepo:      deps.NightRepo,
+		auditService:   auditService,
+		memberships:    deps.MembershipCache,
 		pointsConfig:   DefaultPointsConfig,
+
+		maxOwnedLeagues: maxOwnedLeaguesFromEnv(),
 	}
 }
 
-func (s *leagueServiceInstance) CreateLeague(ctx context.Context, name string) (*models.League, error) {
+func (s *leagueServiceInstance) CreateLeague(ctx context.Context, name string, createdBy primitive.ObjectID, isSuperAdmin bool) (*models.League, error) {
 	if name == "" {
 		return nil, hexerr.New("league name is required")
 	}
 
+	creator, err := s.userRepo.FindByID(ctx, createdBy)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get user info")
+	}
+	if creator == nil {
+		return nil, hexerr.New("user not found")
+	}
+
+	if !isSuperAdmin {
+		if err := s.checkOwnedLeaguesLimit(ctx, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
 	league := &models.League{
 		Name:   name,
 		Status: models.LeagueActive,
@@ -154,6 +286,26 @@ func (s *leagueServiceInstance) CreateLeague(ctx context.Context, name string) (
 		return nil, hexerr.Wrapf(err, "failed to create league")
 	}
 
+	alias := creator.Alias
+	if alias == "" {
+		alias = creator.Name
+	}
+	now := time.Now()
+	owner := &models.LeagueMembership{
+		LeagueID:       league.ID,
+		UserID:         createdBy,
+		Alias:          alias,
+		Status:         models.MembershipActive,
+		Role:           models.LeagueRoleOwner,
+		JoinedAt:       now,
+		LastActivityAt: now,
+	}
+	if err := s.membershipRepo.Create(ctx, owner); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create owner membership")
+	}
+
+	s.logAction(ctx, league.ID, createdBy, AuditActionLeagueCreated, AuditTargetLeague, league.ID, AuditDetails{"name": league.Name})
+
 	return league, nil
 }
 
@@ -184,7 +336,7 @@ func (s *leagueServiceInstance) ListActiveLeagues(ctx context.Context) ([]*model
 	return leagues, nil
 }
 
-func (s *leagueServiceInstance) ArchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error {
+func (s *leagueServiceInstance) ArchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error {
 	league, err := s.GetLeague(ctx, leagueID)
 	if err != nil {
 		return err
@@ -199,10 +351,12 @@ func (s *leagueServiceInstance) ArchiveLeague(ctx context.Context, leagueID prim
 		return hexerr.Wrapf(err, "failed to archive league")
 	}
 
+	s.logAction(ctx, leagueID, actorID, AuditActionLeagueArchived, AuditTargetLeague, leagueID, nil)
+
 	return nil
 }
 
-func (s *leagueServiceInstance) UnarchiveLeague(ctx context.Context, leagueID primitive.ObjectID) error {
+func (s *leagueServiceInstance) UnarchiveLeague(ctx context.Context, leagueID, actorID primitive.ObjectID) error {
 	league, err := s.GetLeague(ctx, leagueID)
 	if err != nil {
 		return err
@@ -217,9 +371,52 @@ func (s *leagueServiceInstance) UnarchiveLeague(ctx context.Context, leagueID pr
 		return hexerr.Wrapf(err, "failed to unarchive league")
 	}
 
+	s.logAction(ctx, leagueID, actorID, AuditActionLeagueUnarchived, AuditTargetLeague, leagueID, nil)
+
 	return nil
 }
 
+func (s *leagueServiceInstance) UpdateLeagueSettings(ctx context.Context, leagueID primitive.ObjectID, settings LeagueSettings) (*models.League, error) {
+	if err := ValidatePointsConfig(settings.PointsConfig); err != nil {
+		return nil, err
+	}
+
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	league.PointsConfig = CompletePointsConfig(settings.PointsConfig)
+	if err := s.leagueRepo.Update(ctx, league); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update league settings")
+	}
+
+	return league, nil
+}
+
+func (s *leagueServiceInstance) SetLeagueLanguage(ctx context.Context, leagueID primitive.ObjectID, language string, actorID primitive.ObjectID) (*models.League, error) {
+	if !slices.Contains(models.LeagueLanguages, language) {
+		return nil, hexerr.New("unsupported language " + language)
+	}
+
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if league.GetLanguage() == language {
+		return league, nil
+	}
+
+	league.Language = language
+	if err := s.leagueRepo.Update(ctx, league); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update league")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionLeagueLanguageChanged, AuditTargetLeague, leagueID, AuditDetails{"language": language})
+
+	return league, nil
+}
+
 func (s *leagueServiceInstance) GetLeagueMembers(ctx context.Context, leagueID primitive.ObjectID) ([]*models.User, error) {
 	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
 	if err != nil {
@@ -257,6 +454,7 @@ func (s *leagueServiceInstance) GetLeagueMemberships(ctx context.Context, league
 			UserID:       membership.UserID,
 			UserAlias:    membership.Alias,
 			Status:       membership.Status,
+			Role:         membership.EffectiveRole(),
 			JoinedAt:     membership.JoinedAt,
 		}
 
@@ -320,7 +518,7 @@ func (s *leagueServiceInstance) IsUserMember(ctx context.Context, leagueID, user
 	return s.membershipRepo.IsActiveMember(ctx, leagueID, userID)
 }
 
-func (s *leagueServiceInstance) BanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error {
+func (s *leagueServiceInstance) BanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error {
 	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
 	if err != nil {
 		return hexerr.Wrapf(err, "failed to find membership")
@@ -333,15 +531,57 @@ func (s *leagueServiceInstance) BanUserFromLeague(ctx context.Context, leagueID,
 		return hexerr.New("user is already banned")
 	}
 
+	if membership.EffectiveRole() == models.LeagueRoleOwner {
+		return hexerr.New("cannot ban the league owner")
+	}
+
 	membership.Status = models.MembershipBanned
 	if err := s.membershipRepo.Update(ctx, membership); err != nil {
 		return hexerr.Wrapf(err, "failed to ban user")
 	}
 
+	s.logAction(ctx, leagueID, actorID, AuditActionUserBanned, AuditTargetUser, userID, AuditDetails{"alias": membership.Alias})
+
 	return nil
 }
 
-func (s *leagueServiceInstance) UnbanUserFromLeague(ctx context.Context, leagueID, userID primitive.ObjectID) error {
+func (s *leagueServiceInstance) SetMemberRole(ctx context.Context, leagueID, membershipID primitive.ObjectID, role models.LeagueRole, actorID primitive.ObjectID) (*models.LeagueMembership, error) {
+	if role != models.LeagueRoleAdmin && role != models.LeagueRoleMember {
+		return nil, hexerr.New("role can only be changed to admin or member")
+	}
+
+	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find membership")
+	}
+	if membership == nil || membership.LeagueID != leagueID {
+		return nil, hexerr.New("membership not found")
+	}
+
+	if membership.Status != models.MembershipActive {
+		return nil, hexerr.New("only active members can change role")
+	}
+
+	previousRole := membership.EffectiveRole()
+	if previousRole == models.LeagueRoleOwner {
+		return nil, hexerr.New("cannot change role of the league owner")
+	}
+	if previousRole == role {
+		return membership, nil
+	}
+
+	membership.Role = role
+	if err := s.membershipRepo.Update(ctx, membership); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update membership role")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionMemberRoleChanged, AuditTargetMembership, membership.ID,
+		AuditDetails{"alias": membership.Alias, "from": string(previousRole), "to": string(role)})
+
+	return membership, nil
+}
+
+func (s *leagueServiceInstance) UnbanUserFromLeague(ctx context.Context, leagueID, userID, actorID primitive.ObjectID) error {
 	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
 	if err != nil {
 		return hexerr.Wrapf(err, "failed to find membership")
@@ -359,15 +599,21 @@ func (s *leagueServiceInstance) UnbanUserFromLeague(ctx context.Context, leagueI
 		return hexerr.Wrapf(err, "failed to unban user")
 	}
 
+	s.logAction(ctx, leagueID, actorID, AuditActionUserUnbanned, AuditTargetUser, userID, AuditDetails{"alias": membership.Alias})
+
 	return nil
 }
 
-func (s *leagueServiceInstance) CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string) (*models.LeagueInvitation, error) {
+func (s *leagueServiceInstance) CreateInvitation(ctx context.Context, leagueID, createdBy primitive.ObjectID, playerAlias string, options InvitationOptions) (*models.LeagueInvitation, error) {
 	// Verify league exists
 	if _, err := s.GetLeague(ctx, leagueID); err != nil {
 		return nil, err
 	}
 
+	if options.MaxUses != 0 {
+		return s.createJoinLink(ctx, leagueID, createdBy, options)
+	}
+
 	// Validate alias
 	if playerAlias == "" {
 		return nil, hexerr.New("player alias is required")
@@ -457,6 +703,8 @@ func (s *leagueServiceInstance) CreateInvitation(ctx context.Context, leagueID,
 		_ = s.membershipRepo.AddRecentCoPlayer(ctx, creatorMembership.ID, membership.ID, now)
 	}
 
+	s.logAction(ctx, leagueID, createdBy, AuditActionInviteCreated, AuditTargetInvitation, invitation.ID, AuditDetails{"alias": playerAlias})
+
 	return invitation, nil
 }
 
@@ -497,6 +745,13 @@ func (s *leagueServiceInstance) AcceptInvitation(ctx context.Context, token stri
 		return nil, &AlreadyMemberError{LeagueCode: leagueCode}
 	}
 
+	if invitation.IsReusable() {
+		return s.acceptJoinLink(ctx, invitation, userID, existing)
+	}
+	if existing != nil && existing.Status == models.MembershipLeft {
+		return s.rejoinByInvitation(ctx, invitation, existing)
+	}
+
 	// Get the pending membership created with the invitation
 	membership, err := s.membershipRepo.FindByID(ctx, invitation.MembershipID)
 	if err != nil {
@@ -520,6 +775,8 @@ func (s *leagueServiceInstance) AcceptInvitation(ctx context.Context, token stri
 		return nil, hexerr.Wrapf(err, "failed to mark invitation as used")
 	}
 
+	s.logAction(ctx, invitation.LeagueID, userID, AuditActionInviteAccepted, AuditTargetInvitation, invitation.ID, AuditDetails{"alias": membership.Alias})
+
 	// Get and return the league
 	return s.GetLeague(ctx, invitation.LeagueID)
 }
@@ -556,28 +813,181 @@ func (s *leagueServiceInstance) PreviewInvitation(ctx context.Context, token str
 
 	// Determine status
 	status := "valid"
-	if invitation.IsUsed {
+	if invitation.IsUsed || invitation.RemainingUses() == 0 {
 		status = "used"
 	} else if time.Now().After(invitation.ExpiresAt) {
 		status = "expired"
 	}
 
 	return &InvitationPreview{
-		LeagueName:   league.Name,
-		InviterAlias: inviterAlias,
-		PlayerAlias:  invitation.PlayerAlias,
-		ExpiresAt:    invitation.ExpiresAt,
-		Status:       status,
+		LeagueName:       league.Name,
+		InviterAlias:     inviterAlias,
+		PlayerAlias:      invitation.PlayerAlias,
+		ExpiresAt:        invitation.ExpiresAt,
+		Status:           status,
+		MaxUses:          invitation.MaxUses,
+		RemainingUses:    invitation.RemainingUses(),
+		RequiresApproval: invitation.RequiresApproval,
 	}, nil
 }
 
-func (s *leagueServiceInstance) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID) ([]*LeagueStanding, error) {
-	// Get all game rounds for this league
+func (s *leagueServiceInstance) GetLeagueStandings(ctx context.Context, leagueID primitive.ObjectID, filter StandingsFilter) ([]*LeagueStanding, error) {
+	data, err := s.loadStandingsData(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let the database sum up the rounds when the repository can, fall back to calculating in memory
+	if aggregator, ok := s.gameRoundRepo.(repositories.StandingsAggregator); ok {
+		aggregates, err := aggregator.AggregateStandings(ctx, leagueID, NewStandingsAggregationConfig(filter, data.scoringTypes, data.pointsConfig))
+		if err == nil {
+			return StandingsFromAggregates(aggregates, data.memberships, data.users), nil
+		}
+		glog.Warn("Standings aggregation failed for league %s, calculating in memory: %v", leagueID.Hex(), err)
+	}
+
+	rounds, err := s.gameRoundRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game rounds")
+	}
+
+	rounds = FilterRounds(rounds, filter)
+	return CalculateStandings(ctx, rounds, data.memberships, data.users, data.scoringTypes, data.pointsConfig), nil
+}
+
+func (s *leagueServiceInstance) GetLastFinishedRound(ctx context.Context, leagueID primitive.ObjectID) (*models.GameRound, error) {
+	rounds, err := s.gameRoundRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game rounds")
+	}
+
+	var last *models.GameRound
+	for _, round := range rounds {
+		if round.EndTime.IsZero() {
+			continue
+		}
+		if last == nil || round.EndTime.After(last.EndTime) {
+			last = round
+		}
+	}
+	return last, nil
+}
+
+func (s *leagueServiceInstance) GetLeagueStandingsByGameType(ctx context.Context, leagueID primitive.ObjectID) ([]*GameTypeStandings, error) {
+	data, err := s.loadStandingsData(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*GameTypeStandings
+	if aggregator, ok := s.gameRoundRepo.(repositories.StandingsAggregator); ok {
+		result, err = aggregateStandingsByGameType(ctx, aggregator, leagueID, data)
+		if err != nil {
+			glog.Warn("Standings aggregation by game type failed for league %s, calculating in memory: %v", leagueID.Hex(), err)
+		}
+	}
+	if result == nil {
+		if result, err = s.calculateStandingsByGameType(ctx, leagueID, data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Most played game types first
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RoundsCount == result[j].RoundsCount {
+			return result[i].GameTypeID.Hex() < result[j].GameTypeID.Hex()
+		}
+		return result[i].RoundsCount > result[j].RoundsCount
+	})
+
+	return result, nil
+}
+
+// aggregateStandingsByGameType lets the database sum up the rounds of every game type
+func aggregateStandingsByGameType(ctx context.Context, aggregator repositories.StandingsAggregator, leagueID primitive.ObjectID, data *standingsData) ([]*GameTypeStandings, error) {
+	roundCounts, err := aggregator.CountFinishedRoundsByGameType(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewStandingsAggregationConfig(StandingsFilter{}, data.scoringTypes, data.pointsConfig)
+	config.ByGameType = true
+	aggregates, err := aggregator.AggregateStandings(ctx, leagueID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregatesByGameType := make(map[primitive.ObjectID][]*repositories.StandingsAggregate, len(roundCounts))
+	for _, aggregate := range aggregates {
+		aggregatesByGameType[aggregate.Key.GameTypeID] = append(aggregatesByGameType[aggregate.Key.GameTypeID], aggregate)
+	}
+
+	result := make([]*GameTypeStandings, 0, len(roundCounts))
+	for gameTypeID, roundsCount := range roundCounts {
+		standings := StandingsFromAggregates(aggregatesByGameType[gameTypeID], data.memberships, data.users)
+		result = append(result, &GameTypeStandings{
+			GameTypeID:  gameTypeID,
+			RoundsCount: roundsCount,
+			Standings:   playedStandings(standings),
+		})
+	}
+	return result, nil
+}
+
+// calculateStandingsByGameType loads all league rounds and calculates standings of every game type in memory
+func (s *leagueServiceInstance) calculateStandingsByGameType(ctx context.Context, leagueID primitive.ObjectID, data *standingsData) ([]*GameTypeStandings, error) {
 	rounds, err := s.gameRoundRepo.FindByLeague(ctx, leagueID)
 	if err != nil {
 		return nil, hexerr.Wrapf(err, "failed to get game rounds")
 	}
 
+	roundsByGameType := make(map[primitive.ObjectID][]*models.GameRound)
+	for _, round := range rounds {
+		// Rounds without game type can't be attributed to any leaderboard
+		if round.GameTypeID.IsZero() || round.EndTime.IsZero() {
+			continue
+		}
+		roundsByGameType[round.GameTypeID] = append(roundsByGameType[round.GameTypeID], round)
+	}
+
+	result := make([]*GameTypeStandings, 0, len(roundsByGameType))
+	for gameTypeID, rounds := range roundsByGameType {
+		standings := CalculateStandings(ctx, rounds, data.memberships, data.users, data.scoringTypes, data.pointsConfig)
+		result = append(result, &GameTypeStandings{
+			GameTypeID:  gameTypeID,
+			RoundsCount: len(rounds),
+			Standings:   playedStandings(standings),
+		})
+	}
+	return result, nil
+}
+
+// playedStandings keeps only members who played, leaderboards of a game type list just them
+func playedStandings(standings []*LeagueStanding) []*LeagueStanding {
+	played := make([]*LeagueStanding, 0, len(standings))
+	for _, standing := range standings {
+		if standing.GamesPlayed > 0 {
+			played = append(played, standing)
+		}
+	}
+	rankStandings(played)
+	return played
+}
+
+// standingsData contains everything needed to calculate league standings except the rounds
+type standingsData struct {
+	memberships  []*models.LeagueMembership
+	users        map[primitive.ObjectID]*models.User
+	scoringTypes map[primitive.ObjectID]models.ScoringType
+	pointsConfig PointsConfig
+}
+
+func (s *leagueServiceInstance) loadStandingsData(ctx context.Context, leagueID primitive.ObjectID) (*standingsData, error) {
+	league, err := s.GetLeague(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get all memberships
 	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
 	if err != nil {
@@ -585,6 +995,60 @@ func (s *leagueServiceInstance) GetLeagueStandings(ctx context.Context, leagueID
 	}
 
 	// Get all users
+	usersMap, err := s.getMembershipUsers(ctx, memberships)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scoring types decide how the rounds are scored
+	gameTypes, err := s.gameTypeRepo.FindAll(ctx)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game types")
+	}
+	scoringTypes := make(map[primitive.ObjectID]models.ScoringType, len(gameTypes))
+	for _, gameType := range gameTypes {
+		scoringTypes[gameType.ID] = gameType.ScoringType
+	}
+
+	pointsConfig := s.pointsConfig
+	if league.PointsConfig != nil {
+		pointsConfig = PointsConfigForLeague(league)
+	}
+
+	return &standingsData{
+		memberships:  memberships,
+		users:        usersMap,
+		scoringTypes: scoringTypes,
+		pointsConfig: pointsConfig,
+	}, nil
+}
+
+func (s *leagueServiceInstance) GetLeagueRatings(ctx context.Context, leagueID primitive.ObjectID, algorithm RatingAlgorithm) ([]*MemberRating, error) {
+	engine := NewRatingEngine(algorithm)
+	if engine == nil {
+		return nil, hexerr.New(fmt.Sprintf("unknown rating algorithm: %s", algorithm))
+	}
+
+	rounds, err := s.gameRoundRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game rounds")
+	}
+
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get memberships")
+	}
+
+	usersMap, err := s.getMembershipUsers(ctx, memberships)
+	if err != nil {
+		return nil, err
+	}
+
+	return CalculateRatings(rounds, memberships, usersMap, engine), nil
+}
+
+// getMembershipUsers loads users of the memberships, keyed by user ID
+func (s *leagueServiceInstance) getMembershipUsers(ctx context.Context, memberships []*models.LeagueMembership) (map[primitive.ObjectID]*models.User, error) {
 	usersMap := make(map[primitive.ObjectID]*models.User)
 	for _, membership := range memberships {
 		user, err := s.userRepo.FindByID(ctx, membership.UserID)
@@ -595,11 +1059,7 @@ func (s *leagueServiceInstance) GetLeagueStandings(ctx context.Context, leagueID
 			usersMap[user.ID] = user
 		}
 	}
-
-	// Calculate standings
-	standings := CalculateStandings(ctx, rounds, memberships, usersMap, s.pointsConfig)
-
-	return standings, nil
+	return usersMap, nil
 }
 
 func (s *leagueServiceInstance) GetInvitationByToken(ctx context.Context, token string) (*models.LeagueInvitation, error) {
@@ -631,9 +1091,15 @@ func (s *leagueServiceInstance) CancelInvitation(ctx context.Context, token stri
 		return hexerr.New("invitation not found")
 	}
 
-	// Verify the user is the creator
+	// Verify the user is the creator or a league admin
 	if invitation.CreatedBy != userID {
-		return hexerr.New("you can only cancel your own invitations")
+		isAdmin, err := s.isLeagueAdmin(ctx, invitation.LeagueID, userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return hexerr.New("you can only cancel your own invitations")
+		}
 	}
 
 	// Cancel the invitation
@@ -672,6 +1138,8 @@ func (s *leagueServiceInstance) CancelInvitation(ctx context.Context, token stri
 		}
 	}
 
+	s.logAction(ctx, invitation.LeagueID, userID, AuditActionInviteCancelled, AuditTargetInvitation, invitation.ID, AuditDetails{"alias": invitation.PlayerAlias})
+
 	return nil
 }
 
@@ -693,9 +1161,15 @@ func (s *leagueServiceInstance) ExtendInvitation(ctx context.Context, token stri
 		return nil, hexerr.New("invitation not found")
 	}
 
-	// Verify the user is the creator
+	// Verify the user is the creator or a league admin
 	if invitation.CreatedBy != userID {
-		return nil, hexerr.New("you can only extend your own invitations")
+		isAdmin, err := s.isLeagueAdmin(ctx, invitation.LeagueID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, hexerr.New("you can only extend your own invitations")
+		}
 	}
 
 	// Can only extend if not used
@@ -729,10 +1203,29 @@ func (s *leagueServiceInstance) ExtendInvitation(ctx context.Context, token stri
 		}
 	}
 
+	s.logAction(ctx, invitation.LeagueID, userID, AuditActionInviteExtended, AuditTargetInvitation, invitation.ID, AuditDetails{"alias": invitation.PlayerAlias})
+
 	// Return updated invitation
 	return s.invitationRepo.FindByToken(ctx, token)
 }
 
+// isLeagueAdmin checks if the user is an active admin or owner of the league
+func (s *leagueServiceInstance) isLeagueAdmin(ctx context.Context, leagueID, userID primitive.ObjectID) (bool, error) {
+	membership, err := s.membershipRepo.FindByLeagueAndUser(ctx, leagueID, userID)
+	if err != nil {
+		return false, hexerr.Wrapf(err, "failed to find membership")
+	}
+	return membership != nil && membership.Status == models.MembershipActive &&
+		membership.EffectiveRole().AtLeast(models.LeagueRoleAdmin), nil
+}
+
+// logAction records the action in the audit log, a failure to record doesn't fail the action
+func (s *leagueServiceInstance) logAction(ctx context.Context, leagueID, actorID primitive.ObjectID, action AuditAction, targetType AuditTargetType, targetID primitive.ObjectID, details AuditDetails) {
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, action, targetType, targetID, details); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+	}
+}
+
 func (s *leagueServiceInstance) UpdatePendingMemberAlias(ctx context.Context, membershipID primitive.ObjectID, userID primitive.ObjectID, newAlias string) error {
 	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
 	if err != nil {
@@ -756,9 +1249,15 @@ func (s *leagueServiceInstance) UpdatePendingMemberAlias(ctx context.Context, me
 		return hexerr.New("associated invitation not found")
 	}
 
-	// Verify the user is the creator of the invitation
+	// Verify the user is the creator of the invitation or a league admin
 	if invitation.CreatedBy != userID {
-		return hexerr.New("you can only edit aliases for invitations you created")
+		isAdmin, err := s.isLeagueAdmin(ctx, invitation.LeagueID, userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return hexerr.New("you can only edit aliases for invitations you created")
+		}
 	}
 
 	if newAlias == "" {
@@ -839,9 +1338,21 @@ func (s *leagueServiceInstance) GetSuggestedPlayers(ctx context.Context, leagueI
 	if currentMembership != nil && currentMembership.Status == models.MembershipActive {
 		response.CurrentPlayer = s.membershipToSuggestedPlayer(currentMembership, nil)
 		excludeIDs = append(excludeIDs, currentMembership.ID)
+	}
 
+	// Members coming to the game night going on now are the current group, they go before recent co-players
+	nightPlayers, err := s.suggestNightPlayers(ctx, leagueID, response, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+	excludeIDs = append(excludeIDs, nightPlayers...)
+
+	if response.CurrentPlayer != nil {
 		// Add recent co-players from cache
 		for _, coPlayer := range currentMembership.RecentCoPlayers {
+			if slices.Contains(nightPlayers, coPlayer.MembershipID) {
+				continue
+			}
 			coPlayerMembership, err := s.membershipRepo.FindByID(ctx, coPlayer.MembershipID)
 			if err != nil {
 				continue
@@ -884,6 +1395,37 @@ func (s *leagueServiceInstance) GetSuggestedPlayers(ctx context.Context, leagueI
 	return response, nil
 }
 
+// suggestNightPlayers fills the game night going on now and its players into the response and returns their membership IDs
+func (s *leagueServiceInstance) suggestNightPlayers(ctx context.Context, leagueID primitive.ObjectID, response *SuggestedPlayersResponse, excludeIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	night, err := s.nightRepo.FindCurrent(ctx, leagueID, time.Now())
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to find current game night")
+	}
+	if night == nil {
+		return nil, nil
+	}
+	response.NightCode = utils.IdToCode(night.ID)
+
+	var playerIDs []primitive.ObjectID
+	for _, answer := range []models.RSVPResponse{models.RSVPYes, models.RSVPMaybe} {
+		for _, rsvp := range night.RSVPs {
+			if rsvp.Response != answer || slices.Contains(excludeIDs, rsvp.MembershipID) {
+				continue
+			}
+			membership, err := s.membershipRepo.FindByID(ctx, rsvp.MembershipID)
+			if err != nil {
+				return nil, hexerr.Wrapf(err, "failed to find game night player")
+			}
+			if membership == nil || membership.Status != models.MembershipActive {
+				continue
+			}
+			response.NightPlayers = append(response.NightPlayers, *s.membershipToSuggestedPlayer(membership, nil))
+			playerIDs = append(playerIDs, membership.ID)
+		}
+	}
+	return playerIDs, nil
+}
+
 // membershipToSuggestedPlayer converts a membership to a SuggestedPlayer
 func (s *leagueServiceInstance) membershipToSuggestedPlayer(membership *models.LeagueMembership, lastPlayedAt *time.Time) *SuggestedPlayer {
 	player := &SuggestedPlayer{
@@ -916,9 +1458,10 @@ func (s *leagueServiceInstance) CreateMembershipForSuperAdmin(ctx context.Contex
 		if existing.Status == models.MembershipActive {
 			return nil, hexerr.New("user is already an active member of this league")
 		}
-		// If there's a pending membership, activate it
+		// If there's a pending or left membership, activate it
 		existing.UserID = userID
 		existing.Status = models.MembershipActive
+		existing.LeftAt = time.Time{}
 		existing.JoinedAt = time.Now()
 		existing.LastActivityAt = time.Now()
 		if alias != "" && existing.Alias != alias {
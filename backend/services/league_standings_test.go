@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFilterRounds_ByGameType(t *testing.T) {
+	mafia := primitive.NewObjectID()
+	catan := primitive.NewObjectID()
+	rounds := []*models.GameRound{
+		{Name: "mafia-1", GameTypeID: mafia},
+		{Name: "catan-1", GameTypeID: catan},
+		{Name: "mafia-2", GameTypeID: mafia},
+	}
+
+	assert.Len(t, FilterRounds(rounds, StandingsFilter{}), 3)
+
+	filtered := FilterRounds(rounds, StandingsFilter{GameTypeID: mafia})
+	if assert.Len(t, filtered, 2) {
+		assert.Equal(t, "mafia-1", filtered[0].Name)
+		assert.Equal(t, "mafia-2", filtered[1].Name)
+	}
+}
+
+func TestFilterRounds_ByStatus(t *testing.T) {
+	rounds := []*models.GameRound{
+		{Name: "completed", Status: models.StatusCompleted},
+		{Name: "scoring", Status: models.StatusScoring},
+	}
+
+	filtered := FilterRounds(rounds, StandingsFilter{Status: models.StatusCompleted})
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "completed", filtered[0].Name)
+	}
+}
+
+func TestGetLeagueStandingsByGameType_OneLeaderboardPerGameType(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		GameTypeRepo:   mockGameTypeRepo,
+	})
+
+	leagueID := primitive.NewObjectID()
+	mafia := primitive.NewObjectID()
+	catan := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Alice", Status: models.MembershipVirtual}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Bob", Status: models.MembershipVirtual}
+	carol := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Carol", Status: models.MembershipVirtual}
+
+	now := time.Now()
+	rounds := []*models.GameRound{
+		{GameTypeID: mafia, EndTime: now, Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1}, {MembershipID: bob.ID, Position: 2}}},
+		{GameTypeID: mafia, EndTime: now, Players: []models.GameRoundPlayer{{MembershipID: bob.ID, Position: 1}, {MembershipID: alice.ID, Position: 2}}},
+		{GameTypeID: catan, EndTime: now, Players: []models.GameRoundPlayer{{MembershipID: carol.ID, Position: 1}, {MembershipID: alice.ID, Position: 2}}},
+		// Unfinished and untyped rounds are not attributed to any leaderboard
+		{GameTypeID: catan, Players: []models.GameRoundPlayer{{MembershipID: bob.ID, Position: 1}}},
+		{EndTime: now, Players: []models.GameRoundPlayer{{MembershipID: bob.ID, Position: 1}}},
+	}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID}, nil)
+	mockGameRoundRepo.On("FindByLeague", ctx, leagueID).Return(rounds, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{alice, bob, carol}, nil)
+	mockUserRepo.On("FindByID", ctx, mock.Anything).Return(nil, nil)
+
+	result, err := service.GetLeagueStandingsByGameType(ctx, leagueID)
+
+	assert.NoError(t, err)
+	if !assert.Len(t, result, 2) {
+		return
+	}
+
+	assert.Equal(t, mafia, result[0].GameTypeID)
+	assert.Equal(t, 2, result[0].RoundsCount)
+	assert.Len(t, result[0].Standings, 2) // Carol didn't play mafia
+
+	assert.Equal(t, catan, result[1].GameTypeID)
+	assert.Equal(t, 1, result[1].RoundsCount)
+	if assert.Len(t, result[1].Standings, 2) {
+		assert.Equal(t, carol.ID, result[1].Standings[0].MembershipID)
+		assert.Equal(t, 1, result[1].Standings[0].GamesPlayed)
+	}
+}
+
+func TestTeamResults(t *testing.T) {
+	win := TeamResults(&models.GameRound{TeamScores: []models.TeamScore{
+		{Name: "civilians", Score: 1, Position: 1},
+		{Name: "mafia", Score: 0, Position: 2},
+	}})
+	assert.Equal(t, map[string]TeamResult{"civilians": TeamWin, "mafia": TeamLoss}, win)
+
+	draw := TeamResults(&models.GameRound{TeamScores: []models.TeamScore{
+		{Name: "red", Score: 5, Position: 1},
+		{Name: "blue", Score: 5, Position: 2},
+		{Name: "green", Score: 3, Position: 3},
+	}})
+	assert.Equal(t, map[string]TeamResult{"red": TeamDraw, "blue": TeamDraw, "green": TeamLoss}, draw)
+
+	assert.Nil(t, TeamResults(&models.GameRound{}))
+}
+
+func TestCalculateStandings_TeamGameUsesTeamResult(t *testing.T) {
+	mafiaType := primitive.NewObjectID()
+	classicType := primitive.NewObjectID()
+	scoringTypes := map[primitive.ObjectID]models.ScoringType{
+		mafiaType:   models.ScoringTypeMafia,
+		classicType: models.ScoringTypeClassic,
+	}
+
+	civilian1 := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Civilian 1", Status: models.MembershipVirtual}
+	civilian2 := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Civilian 2", Status: models.MembershipVirtual}
+	mafioso := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Mafioso", Status: models.MembershipVirtual}
+	host := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Host", Status: models.MembershipVirtual}
+
+	rounds := []*models.GameRound{{
+		GameTypeID: mafiaType,
+		EndTime:    time.Now(),
+		Players: []models.GameRoundPlayer{
+			// Individual positions are ignored for team games
+			{MembershipID: mafioso.ID, TeamName: "mafia", Position: 1},
+			{MembershipID: civilian1.ID, TeamName: "civilians", Position: 2},
+			{MembershipID: civilian2.ID, TeamName: "civilians", Position: 3},
+			{MembershipID: host.ID, IsModerator: true, Position: 4},
+		},
+		TeamScores: []models.TeamScore{
+			{Name: "civilians", Score: 1, Position: 1},
+			{Name: "mafia", Score: 0, Position: 2},
+		},
+	}}
+
+	config := DefaultPointsConfig
+	config.TeamWinPoints = 6
+	config.TeamDrawPoints = 3
+	config.TeamLossPoints = 1
+
+	standings := CalculateStandings(context.Background(), rounds,
+		[]*models.LeagueMembership{civilian1, civilian2, mafioso, host}, nil, scoringTypes, config)
+
+	byMember := make(map[primitive.ObjectID]*LeagueStanding)
+	for _, standing := range standings {
+		byMember[standing.MembershipID] = standing
+	}
+
+	assert.Equal(t, int64(6), byMember[civilian1.ID].PositionPoints)
+	assert.Equal(t, 1, byMember[civilian1.ID].TeamWins)
+	assert.Equal(t, int64(6), byMember[civilian2.ID].PositionPoints)
+	// Civilians tied, so they share the first place and the next member is third
+	assert.Equal(t, 1, byMember[civilian1.ID].Rank)
+	assert.Equal(t, 1, byMember[civilian2.ID].Rank)
+	assert.Equal(t, 3, standings[2].Rank)
+	assert.Equal(t, int64(1), byMember[mafioso.ID].PositionPoints)
+	assert.Equal(t, 1, byMember[mafioso.ID].TeamLosses)
+	assert.Equal(t, 0, byMember[mafioso.ID].FirstPlaceCount)
+	// Moderator has no team: participation and moderation points only
+	assert.Equal(t, int64(0), byMember[host.ID].PositionPoints)
+	assert.Equal(t, config.ParticipationPoints+config.ModerationPoints, byMember[host.ID].TotalPoints)
+
+	// The same round of a classic game is scored by positions
+	rounds[0].GameTypeID = classicType
+	standings = CalculateStandings(context.Background(), rounds,
+		[]*models.LeagueMembership{civilian1, civilian2, mafioso, host}, nil, scoringTypes, config)
+	for _, standing := range standings {
+		if standing.MembershipID == mafioso.ID {
+			assert.Equal(t, int64(10), standing.PositionPoints)
+			assert.Equal(t, 0, standing.TeamLosses)
+		}
+	}
+}
+
+func TestCalculateStandings_CooperativeGroupResult(t *testing.T) {
+	coopType := primitive.NewObjectID()
+	scoringTypes := map[primitive.ObjectID]models.ScoringType{coopType: models.ScoringTypeCoopWithModerator}
+
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipVirtual}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipVirtual}
+	host := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Host", Status: models.MembershipVirtual}
+
+	won, lost := true, false
+	players := []models.GameRoundPlayer{
+		{MembershipID: alice.ID, Position: 1},
+		{MembershipID: bob.ID, Position: 2},
+		{MembershipID: host.ID, IsModerator: true},
+	}
+	rounds := []*models.GameRound{
+		{GameTypeID: coopType, EndTime: time.Now(), CooperativeWin: &won, Players: players},
+		{GameTypeID: coopType, EndTime: time.Now(), CooperativeWin: &won, Players: players},
+		{GameTypeID: coopType, EndTime: time.Now(), CooperativeWin: &lost, Players: players},
+	}
+
+	config := DefaultPointsConfig
+	config.CoopWinPoints = 4
+
+	standings := CalculateStandings(context.Background(), rounds,
+		[]*models.LeagueMembership{alice, bob, host}, nil, scoringTypes, config)
+
+	byMember := make(map[primitive.ObjectID]*LeagueStanding)
+	for _, standing := range standings {
+		byMember[standing.MembershipID] = standing
+	}
+
+	// Individual positions are ignored, both players share the group result
+	for _, member := range []*models.LeagueMembership{alice, bob} {
+		standing := byMember[member.ID]
+		assert.Equal(t, int64(8), standing.PositionPoints)
+		assert.Equal(t, 2, standing.CoopWins)
+		assert.Equal(t, 1, standing.CoopLosses)
+		assert.InDelta(t, 2.0/3.0, standing.CoopWinRate(), 0.0001)
+		assert.Equal(t, 0, standing.FirstPlaceCount)
+	}
+
+	assert.Equal(t, 0, byMember[host.ID].CoopWins+byMember[host.ID].CoopLosses)
+	assert.Equal(t, 0.0, byMember[host.ID].CoopWinRate())
+	assert.Equal(t, int64(0), byMember[host.ID].PositionPoints)
+}
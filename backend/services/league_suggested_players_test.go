@@ -7,6 +7,7 @@ import (
 
 	"github.com/andriyg76/bgl/models"
 	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -21,7 +22,13 @@ func TestUpdatePlayersAfterGame_UpdatesRecentCoPlayersForEachMember(t *testing.T
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-	service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+	})
 
 	id1 := primitive.NewObjectID()
 	id2 := primitive.NewObjectID()
@@ -49,7 +56,13 @@ func TestUpdatePlayersAfterGame_NoPlayersDoesNothing(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-	service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+	})
 
 	err := service.UpdatePlayersAfterGame(ctx, []primitive.ObjectID{})
 
@@ -66,10 +79,20 @@ func TestGetSuggestedPlayers_ForMemberWithRecentAndOtherPlayers(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-	service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+	mockNightRepo := new(mocks.MockGameNightRepository)
+
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		NightRepo:      mockNightRepo,
+	})
 
 	leagueID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
+	mockNightRepo.On("FindCurrent", ctx, leagueID, mock.Anything).Return(nil, nil)
 
 	now := time.Now()
 
@@ -148,10 +171,20 @@ func TestGetSuggestedPlayers_ForSuperAdminWithoutMembership_UsesLargerLimit(t *t
 	mockUserRepo := new(mocks.MockUserRepository)
 	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
 
-	service := NewLeagueService(mockLeagueRepo, mockMembershipRepo, mockInvitationRepo, mockUserRepo, mockGameRoundRepo)
+	mockNightRepo := new(mocks.MockGameNightRepository)
+
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		NightRepo:      mockNightRepo,
+	})
 
 	leagueID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
+	mockNightRepo.On("FindCurrent", ctx, leagueID, mock.Anything).Return(nil, nil)
 
 	// Superadmin has no membership in this league
 	mockMembershipRepo.
@@ -173,3 +206,61 @@ func TestGetSuggestedPlayers_ForSuperAdminWithoutMembership_UsesLargerLimit(t *t
 
 	mockMembershipRepo.AssertExpectations(t)
 }
+
+func TestGetSuggestedPlayers_PutsGameNightPlayersFirst(t *testing.T) {
+	ctx := context.Background()
+
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockNightRepo := new(mocks.MockGameNightRepository)
+	service := NewLeagueService(LeagueServiceDeps{
+		MembershipRepo: mockMembershipRepo,
+		NightRepo:      mockNightRepo,
+	})
+
+	leagueID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	maybe := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Maybe", Status: models.MembershipActive}
+	going := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Going", Status: models.MembershipActive}
+	declined := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Declined", Status: models.MembershipActive}
+	current := &models.LeagueMembership{
+		ID:              primitive.NewObjectID(),
+		Alias:           "Current",
+		Status:          models.MembershipActive,
+		RecentCoPlayers: []models.RecentCoPlayer{{MembershipID: going.ID, LastPlayedAt: time.Now().Add(-time.Hour)}},
+	}
+	night := &models.GameNight{
+		ID: primitive.NewObjectID(),
+		RSVPs: []models.GameNightRSVP{
+			{MembershipID: maybe.ID, Response: models.RSVPMaybe},
+			{MembershipID: declined.ID, Response: models.RSVPNo},
+			{MembershipID: current.ID, Response: models.RSVPYes},
+			{MembershipID: going.ID, Response: models.RSVPYes},
+		},
+	}
+
+	mockMembershipRepo.On("FindByLeagueAndUser", ctx, leagueID, userID).Return(current, nil)
+	mockNightRepo.On("FindCurrent", ctx, leagueID, mock.Anything).Return(night, nil)
+	mockMembershipRepo.On("FindByID", ctx, going.ID).Return(going, nil)
+	mockMembershipRepo.On("FindByID", ctx, maybe.ID).Return(maybe, nil)
+	mockMembershipRepo.On("FindByLeagueSortedByActivity", ctx, leagueID, []primitive.ObjectID{current.ID, going.ID, maybe.ID}, 10).
+		Return([]*models.LeagueMembership{declined}, nil)
+
+	resp, err := service.GetSuggestedPlayers(ctx, leagueID, userID, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, utils.IdToCode(night.ID), resp.NightCode)
+	if assert.Len(t, resp.NightPlayers, 2) {
+		assert.Equal(t, "Going", resp.NightPlayers[0].Alias)
+		assert.Equal(t, "Maybe", resp.NightPlayers[1].Alias)
+	}
+	// The co-player coming to the night is not repeated
+	assert.Empty(t, resp.RecentPlayers)
+	if assert.Len(t, resp.OtherPlayers, 1) {
+		assert.Equal(t, "Declined", resp.OtherPlayers[0].Alias)
+	}
+
+	mockMembershipRepo.AssertExpectations(t)
+}
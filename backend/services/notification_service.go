@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationUnreadCountEvent is the event type pushed to the user's notification stream when the unread count changes
+const NotificationUnreadCountEvent = "unread_count"
+
+// NotificationService creates in-app notifications and keeps the users' notification streams up to date
+type NotificationService interface {
+	// NotifyInvitationAccepted tells the invitation creator that the invited player joined the league or waits for approval
+	NotifyInvitationAccepted(ctx context.Context, invitation *models.LeagueInvitation, membership *models.LeagueMembership) error
+	// NotifyJoinRequested tells the league admins that a user requested to join the league
+	NotifyJoinRequested(ctx context.Context, membership *models.LeagueMembership) error
+	// NotifyJoinRequestDecided tells the user whether their request to join the league was approved
+	NotifyJoinRequestDecided(ctx context.Context, membership *models.LeagueMembership, approved bool) error
+	// NotifyMemberBanned tells the user that they were banned from the league
+	NotifyMemberBanned(ctx context.Context, leagueID, userID primitive.ObjectID) error
+	// NotifyGameFinalized tells every player of the finalized league game round about it
+	NotifyGameFinalized(ctx context.Context, round *models.GameRound) error
+	// NotifyRankChanges tells members whose place changed between two standings of the league
+	NotifyRankChanges(ctx context.Context, leagueID primitive.ObjectID, before, after []models.FrozenStanding) error
+
+	List(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]*models.Notification, error)
+	UnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) error
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type notificationServiceInstance struct {
+	notificationRepo repositories.NotificationRepository
+	leagueRepo       repositories.LeagueRepository
+	membershipRepo   repositories.LeagueMembershipRepository
+	eventHub         GameEventHub // Notification streams, keyed by user code
+}
+
+func NewNotificationService(
+	notificationRepo repositories.NotificationRepository,
+	leagueRepo repositories.LeagueRepository,
+	membershipRepo repositories.LeagueMembershipRepository,
+	eventHub GameEventHub,
+) NotificationService {
+	return &notificationServiceInstance{
+		notificationRepo: notificationRepo,
+		leagueRepo:       leagueRepo,
+		membershipRepo:   membershipRepo,
+		eventHub:         eventHub,
+	}
+}
+
+func (s *notificationServiceInstance) NotifyInvitationAccepted(ctx context.Context, invitation *models.LeagueInvitation, membership *models.LeagueMembership) error {
+	leagueName, err := s.leagueName(ctx, invitation.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	notification := &models.Notification{
+		UserID:   invitation.CreatedBy,
+		Type:     models.NotificationLeagueJoin,
+		Title:    "New league member",
+		Message:  fmt.Sprintf("%s accepted your invitation to %s", membership.Alias, leagueName),
+		LeagueID: invitation.LeagueID,
+	}
+	if membership.AwaitsApproval() {
+		notification.Title = "Membership waits for approval"
+		notification.Message = fmt.Sprintf("%s joined %s by your link and waits for approval", membership.Alias, leagueName)
+	}
+	return s.notify(ctx, notification)
+}
+
+func (s *notificationServiceInstance) NotifyJoinRequested(ctx context.Context, membership *models.LeagueMembership) error {
+	leagueName, err := s.leagueName(ctx, membership.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	memberships, err := s.membershipRepo.FindByLeague(ctx, membership.LeagueID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get league members")
+	}
+
+	for _, admin := range memberships {
+		if admin.Status != models.MembershipActive || !admin.EffectiveRole().AtLeast(models.LeagueRoleAdmin) {
+			continue
+		}
+		if err := s.notify(ctx, &models.Notification{
+			UserID:   admin.UserID,
+			Type:     models.NotificationJoinRequest,
+			Title:    "Join request",
+			Message:  fmt.Sprintf("%s requested to join %s", membership.Alias, leagueName),
+			LeagueID: membership.LeagueID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *notificationServiceInstance) NotifyJoinRequestDecided(ctx context.Context, membership *models.LeagueMembership, approved bool) error {
+	leagueName, err := s.leagueName(ctx, membership.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	notification := &models.Notification{
+		UserID:   membership.UserID,
+		Type:     models.NotificationJoinRequest,
+		Title:    "Join request approved",
+		Message:  fmt.Sprintf("You are now a member of %s", leagueName),
+		LeagueID: membership.LeagueID,
+	}
+	if !approved {
+		notification.Title = "Join request rejected"
+		notification.Message = fmt.Sprintf("Your request to join %s was rejected", leagueName)
+	}
+	return s.notify(ctx, notification)
+}
+
+func (s *notificationServiceInstance) NotifyMemberBanned(ctx context.Context, leagueID, userID primitive.ObjectID) error {
+	leagueName, err := s.leagueName(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+
+	return s.notify(ctx, &models.Notification{
+		UserID:   userID,
+		Type:     models.NotificationLeagueBan,
+		Title:    "Banned from league",
+		Message:  fmt.Sprintf("You were banned from %s", leagueName),
+		LeagueID: leagueID,
+	})
+}
+
+func (s *notificationServiceInstance) NotifyGameFinalized(ctx context.Context, round *models.GameRound) error {
+	if round.LeagueID.IsZero() {
+		return nil
+	}
+
+	leagueName, err := s.leagueName(ctx, round.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	notified := make(map[primitive.ObjectID]bool, len(round.Players))
+	for _, player := range round.Players {
+		// Legacy rounds reference the user directly
+		userID := player.PlayerID
+		if !player.MembershipID.IsZero() {
+			membership, err := s.membershipRepo.FindByID(ctx, player.MembershipID)
+			if err != nil {
+				return hexerr.Wrapf(err, "failed to get membership %s", player.MembershipID.Hex())
+			}
+			if membership == nil {
+				continue
+			}
+			userID = membership.UserID
+		}
+		// Virtual and pending members have no user to notify
+		if userID.IsZero() || notified[userID] {
+			continue
+		}
+		notified[userID] = true
+
+		message := fmt.Sprintf("%s in %s is finalized", round.Name, leagueName)
+		if player.Position > 0 && !player.IsModerator {
+			message = fmt.Sprintf("%s in %s is finalized, you finished #%d", round.Name, leagueName, player.Position)
+		}
+		if err := s.notify(ctx, &models.Notification{
+			UserID:      userID,
+			Type:        models.NotificationGameFinalized,
+			Title:       "Game finalized",
+			Message:     message,
+			LeagueID:    round.LeagueID,
+			GameRoundID: round.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationServiceInstance) NotifyRankChanges(ctx context.Context, leagueID primitive.ObjectID, before, after []models.FrozenStanding) error {
+	previousRanks := make(map[primitive.ObjectID]int, len(before))
+	for _, standing := range before {
+		previousRanks[standing.MembershipID] = standing.Rank
+	}
+
+	var leagueName string
+	for _, standing := range after {
+		previousRank, ok := previousRanks[standing.MembershipID]
+		if !ok || previousRank == standing.Rank || standing.UserID.IsZero() {
+			continue
+		}
+
+		if leagueName == "" {
+			name, err := s.leagueName(ctx, leagueID)
+			if err != nil {
+				return err
+			}
+			leagueName = name
+		}
+
+		direction := "up"
+		if standing.Rank > previousRank {
+			direction = "down"
+		}
+		if err := s.notify(ctx, &models.Notification{
+			UserID:   standing.UserID,
+			Type:     models.NotificationRankChanged,
+			Title:    "Standings changed",
+			Message:  fmt.Sprintf("You moved %s from #%d to #%d in %s", direction, previousRank, standing.Rank, leagueName),
+			LeagueID: leagueID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationServiceInstance) List(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, limit int64) ([]*models.Notification, error) {
+	notifications, err := s.notificationRepo.FindByUser(ctx, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list notifications")
+	}
+	if notifications == nil {
+		notifications = []*models.Notification{}
+	}
+	return notifications, nil
+}
+
+func (s *notificationServiceInstance) UnreadCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, hexerr.Wrapf(err, "failed to count unread notifications")
+	}
+	return count, nil
+}
+
+func (s *notificationServiceInstance) MarkRead(ctx context.Context, userID, notificationID primitive.ObjectID) error {
+	found, err := s.notificationRepo.MarkRead(ctx, userID, notificationID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to mark notification as read")
+	}
+	if !found {
+		return hexerr.New("notification not found")
+	}
+	return s.publishUnreadCount(ctx, userID)
+}
+
+func (s *notificationServiceInstance) MarkAllRead(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := s.notificationRepo.MarkAllRead(ctx, userID); err != nil {
+		return hexerr.Wrapf(err, "failed to mark notifications as read")
+	}
+	return s.publishUnreadCount(ctx, userID)
+}
+
+// notify stores the notification and pushes the new unread count to the user's stream
+func (s *notificationServiceInstance) notify(ctx context.Context, notification *models.Notification) error {
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return hexerr.Wrapf(err, "failed to create notification")
+	}
+	return s.publishUnreadCount(ctx, notification.UserID)
+}
+
+func (s *notificationServiceInstance) publishUnreadCount(ctx context.Context, userID primitive.ObjectID) error {
+	userCode := utils.IdToCode(userID)
+	if s.eventHub.GetSubscriberCount(userCode) == 0 {
+		return nil
+	}
+
+	count, err := s.UnreadCount(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.eventHub.Broadcast(userCode, NotificationUnreadCountEvent, map[string]int64{"unread_count": count})
+	return nil
+}
+
+func (s *notificationServiceInstance) leagueName(ctx context.Context, leagueID primitive.ObjectID) (string, error) {
+	league, err := s.leagueRepo.FindByID(ctx, leagueID)
+	if err != nil {
+		return "", hexerr.Wrapf(err, "failed to get league")
+	}
+	if league == nil {
+		return "", hexerr.New("league not found")
+	}
+	return league.Name, nil
+}
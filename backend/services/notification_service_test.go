@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNotifyGameFinalized_NotifiesPlayersWithAccounts(t *testing.T) {
+	ctx := context.Background()
+	mockNotificationRepo := new(mocks.MockNotificationRepository)
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	service := NewNotificationService(mockNotificationRepo, mockLeagueRepo, mockMembershipRepo, NewGameEventHub())
+
+	leagueID := primitive.NewObjectID()
+	aliceUserID := primitive.NewObjectID()
+	legacyUserID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: aliceUserID, Status: models.MembershipActive}
+	virtual := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Status: models.MembershipVirtual}
+	round := &models.GameRound{
+		ID:       primitive.NewObjectID(),
+		Name:     "Friday game",
+		LeagueID: leagueID,
+		Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 1},
+			{MembershipID: virtual.ID, Position: 2},
+			{PlayerID: legacyUserID, IsModerator: true},
+		},
+	}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Board Games"}, nil)
+	mockMembershipRepo.On("FindByID", ctx, alice.ID).Return(alice, nil)
+	mockMembershipRepo.On("FindByID", ctx, virtual.ID).Return(virtual, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == aliceUserID && n.Type == models.NotificationGameFinalized && n.GameRoundID == round.ID &&
+			n.Message == "Friday game in Board Games is finalized, you finished #1"
+	})).Return(nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == legacyUserID && n.Message == "Friday game in Board Games is finalized"
+	})).Return(nil).Once()
+
+	err := service.NotifyGameFinalized(ctx, round)
+
+	assert.NoError(t, err)
+	mockNotificationRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestNotifyRankChanges_OnlyMovedMembers(t *testing.T) {
+	ctx := context.Background()
+	mockNotificationRepo := new(mocks.MockNotificationRepository)
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	service := NewNotificationService(mockNotificationRepo, mockLeagueRepo, new(mocks.MockLeagueMembershipRepository), NewGameEventHub())
+
+	leagueID := primitive.NewObjectID()
+	alice := models.FrozenStanding{MembershipID: primitive.NewObjectID(), UserID: primitive.NewObjectID()}
+	bob := models.FrozenStanding{MembershipID: primitive.NewObjectID(), UserID: primitive.NewObjectID()}
+	carol := models.FrozenStanding{MembershipID: primitive.NewObjectID(), UserID: primitive.NewObjectID()}
+	newcomer := models.FrozenStanding{MembershipID: primitive.NewObjectID(), UserID: primitive.NewObjectID()}
+
+	ranked := func(standing models.FrozenStanding, rank int) models.FrozenStanding {
+		standing.Rank = rank
+		return standing
+	}
+	before := []models.FrozenStanding{ranked(alice, 1), ranked(bob, 2), ranked(carol, 3)}
+	after := []models.FrozenStanding{ranked(bob, 1), ranked(alice, 2), ranked(carol, 3), ranked(newcomer, 4)}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Board Games"}, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == bob.UserID && n.Type == models.NotificationRankChanged && n.Message == "You moved up from #2 to #1 in Board Games"
+	})).Return(nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == alice.UserID && n.Message == "You moved down from #1 to #2 in Board Games"
+	})).Return(nil).Once()
+
+	err := service.NotifyRankChanges(ctx, leagueID, before, after)
+
+	assert.NoError(t, err)
+	mockNotificationRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestNotifyJoinRequested_NotifiesLeagueAdmins(t *testing.T) {
+	ctx := context.Background()
+	mockNotificationRepo := new(mocks.MockNotificationRepository)
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	service := NewNotificationService(mockNotificationRepo, mockLeagueRepo, mockMembershipRepo, NewGameEventHub())
+
+	leagueID := primitive.NewObjectID()
+	owner := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Status: models.MembershipActive, Role: models.LeagueRoleOwner}
+	admin := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Status: models.MembershipActive, Role: models.LeagueRoleAdmin}
+	member := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Status: models.MembershipActive}
+	request := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Petro", Status: models.MembershipPending}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID, Name: "Board Games"}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{owner, admin, member, request}, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return (n.UserID == owner.UserID || n.UserID == admin.UserID) && n.Type == models.NotificationJoinRequest &&
+			n.Message == "Petro requested to join Board Games"
+	})).Return(nil).Twice()
+
+	err := service.NotifyJoinRequested(ctx, request)
+
+	assert.NoError(t, err)
+	mockNotificationRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestMarkAllRead_PushesUnreadCountToSubscribers(t *testing.T) {
+	ctx := context.Background()
+	mockNotificationRepo := new(mocks.MockNotificationRepository)
+	hub := NewGameEventHub()
+	service := NewNotificationService(mockNotificationRepo, new(mocks.MockLeagueRepository), new(mocks.MockLeagueMembershipRepository), hub)
+
+	userID := primitive.NewObjectID()
+	client := hub.Subscribe(utils.IdToCode(userID), "client")
+	defer hub.Unsubscribe(client)
+
+	mockNotificationRepo.On("MarkAllRead", ctx, userID).Return(int64(3), nil)
+	mockNotificationRepo.On("CountUnread", ctx, userID).Return(int64(0), nil)
+
+	err := service.MarkAllRead(ctx, userID)
+
+	assert.NoError(t, err)
+	event := <-client.Channel
+	assert.Equal(t, NotificationUnreadCountEvent, event.Type)
+	assert.Equal(t, map[string]int64{"unread_count": 0}, event.Data)
+}
+
+func TestMarkRead_UnknownNotification(t *testing.T) {
+	ctx := context.Background()
+	mockNotificationRepo := new(mocks.MockNotificationRepository)
+	service := NewNotificationService(mockNotificationRepo, new(mocks.MockLeagueRepository), new(mocks.MockLeagueMembershipRepository), NewGameEventHub())
+
+	userID := primitive.NewObjectID()
+	notificationID := primitive.NewObjectID()
+	mockNotificationRepo.On("MarkRead", ctx, userID, notificationID).Return(false, nil)
+
+	err := service.MarkRead(ctx, userID, notificationID)
+
+	assert.Error(t, err)
+	mockNotificationRepo.AssertNotCalled(t, "CountUnread", mock.Anything, mock.Anything)
+}
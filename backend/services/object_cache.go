@@ -78,6 +78,7 @@ type MembershipCacheKey struct {
 type MembershipCache interface {
 	Get(leagueID, userID primitive.ObjectID) (*models.LeagueMembership, bool)
 	Set(leagueID, userID primitive.ObjectID, membership *models.LeagueMembership)
+	RemoveByLeague(leagueID primitive.ObjectID) int
 }
 
 type membershipCacheImpl struct {
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/hexerr"
+)
+
+// MaxPositionPointsEntries limits the size of a league's position points table
+const MaxPositionPointsEntries = 20
+
+// PointsConfigForLeague returns the points configuration used to calculate the league standings
+func PointsConfigForLeague(league *models.League) PointsConfig {
+	if league == nil || league.PointsConfig == nil {
+		return DefaultPointsConfig
+	}
+
+	cfg := CompletePointsConfig(league.PointsConfig)
+	positionPoints := make(map[int]int64, len(cfg.PositionPoints))
+	for i, points := range cfg.PositionPoints {
+		positionPoints[i+1] = points
+	}
+
+	return PointsConfig{
+		ParticipationPoints: cfg.ParticipationPoints,
+		ModerationPoints:    cfg.ModerationPoints,
+		PositionPoints:      positionPoints,
+		TeamWinPoints:       *cfg.TeamWinPoints,
+		TeamDrawPoints:      *cfg.TeamDrawPoints,
+		TeamLossPoints:      *cfg.TeamLossPoints,
+		CoopWinPoints:       *cfg.CoopWinPoints,
+	}
+}
+
+// CompletePointsConfig returns a copy of the stored config with the fields it lacks, because it was saved before
+// they were added, taken from DefaultPointsConfig. Nil stays nil
+func CompletePointsConfig(cfg *models.LeaguePointsConfig) *models.LeaguePointsConfig {
+	if cfg == nil {
+		return nil
+	}
+
+	complete := *cfg
+	if complete.TeamWinPoints == nil {
+		complete.TeamWinPoints = int64Ptr(DefaultPointsConfig.TeamWinPoints)
+	}
+	if complete.TeamDrawPoints == nil {
+		complete.TeamDrawPoints = int64Ptr(DefaultPointsConfig.TeamDrawPoints)
+	}
+	if complete.TeamLossPoints == nil {
+		complete.TeamLossPoints = int64Ptr(DefaultPointsConfig.TeamLossPoints)
+	}
+	if complete.CoopWinPoints == nil {
+		complete.CoopWinPoints = int64Ptr(DefaultPointsConfig.CoopWinPoints)
+	}
+	return &complete
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+// DefaultLeaguePointsConfig returns DefaultPointsConfig in the form stored on a league
+func DefaultLeaguePointsConfig() *models.LeaguePointsConfig {
+	positionPoints := make([]int64, len(DefaultPointsConfig.PositionPoints))
+	for position, points := range DefaultPointsConfig.PositionPoints {
+		positionPoints[position-1] = points
+	}
+
+	return &models.LeaguePointsConfig{
+		ParticipationPoints: DefaultPointsConfig.ParticipationPoints,
+		ModerationPoints:    DefaultPointsConfig.ModerationPoints,
+		PositionPoints:      positionPoints,
+		TeamWinPoints:       int64Ptr(DefaultPointsConfig.TeamWinPoints),
+		TeamDrawPoints:      int64Ptr(DefaultPointsConfig.TeamDrawPoints),
+		TeamLossPoints:      int64Ptr(DefaultPointsConfig.TeamLossPoints),
+		CoopWinPoints:       int64Ptr(DefaultPointsConfig.CoopWinPoints),
+	}
+}
+
+// ValidatePointsConfig checks that a league points configuration is usable for standings
+func ValidatePointsConfig(cfg *models.LeaguePointsConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	cfg = CompletePointsConfig(cfg)
+	if cfg.ParticipationPoints < 0 {
+		return hexerr.New("participation points must not be negative")
+	}
+	if cfg.ModerationPoints < 0 {
+		return hexerr.New("moderation points must not be negative")
+	}
+	if *cfg.TeamLossPoints < 0 {
+		return hexerr.New("team loss points must not be negative")
+	}
+	if *cfg.TeamDrawPoints < *cfg.TeamLossPoints || *cfg.TeamWinPoints < *cfg.TeamDrawPoints {
+		return hexerr.New("team points must not decrease from loss to draw to win")
+	}
+	if *cfg.CoopWinPoints < 0 {
+		return hexerr.New("cooperative win points must not be negative")
+	}
+	if len(cfg.PositionPoints) == 0 {
+		return hexerr.New("position points table must not be empty")
+	}
+	if len(cfg.PositionPoints) > MaxPositionPointsEntries {
+		return hexerr.New(fmt.Sprintf("position points table must not have more than %d entries", MaxPositionPointsEntries))
+	}
+	for i, points := range cfg.PositionPoints {
+		if points < 0 {
+			return hexerr.New(fmt.Sprintf("points for position %d must not be negative", i+1))
+		}
+		// A better position must never earn fewer points than a worse one
+		if i > 0 && points > cfg.PositionPoints[i-1] {
+			return hexerr.New(fmt.Sprintf("points for position %d must not exceed points for position %d", i+1, i))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidatePointsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *models.LeaguePointsConfig
+		wantErr bool
+	}{
+		{name: "nil resets to default", cfg: nil},
+		{name: "default table", cfg: DefaultLeaguePointsConfig()},
+		{name: "flat table", cfg: &models.LeaguePointsConfig{PositionPoints: []int64{1, 1, 1}}},
+		{name: "negative participation", cfg: &models.LeaguePointsConfig{ParticipationPoints: -1, PositionPoints: []int64{1}}, wantErr: true},
+		{name: "negative moderation", cfg: &models.LeaguePointsConfig{ModerationPoints: -1, PositionPoints: []int64{1}}, wantErr: true},
+		{name: "empty table", cfg: &models.LeaguePointsConfig{}, wantErr: true},
+		{name: "too many entries", cfg: &models.LeaguePointsConfig{PositionPoints: make([]int64, MaxPositionPointsEntries+1)}, wantErr: true},
+		{name: "negative position points", cfg: &models.LeaguePointsConfig{PositionPoints: []int64{3, -1}}, wantErr: true},
+		{name: "increasing table", cfg: &models.LeaguePointsConfig{PositionPoints: []int64{5, 7, 3}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePointsConfig(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPointsConfigForLeague_DefaultWhenNotConfigured(t *testing.T) {
+	cfg := PointsConfigForLeague(&models.League{})
+
+	assert.Equal(t, int64(10), cfg.getPositionPoints(1))
+	assert.Equal(t, int64(1), cfg.getPositionPoints(5))
+	assert.Equal(t, int64(0), cfg.getPositionPoints(6))
+}
+
+func TestDefaultLeaguePointsConfig_RoundTrips(t *testing.T) {
+	stored := DefaultLeaguePointsConfig()
+	assert.NoError(t, ValidatePointsConfig(stored))
+
+	// Saving the defaults as the league settings must not change the standings
+	cfg := PointsConfigForLeague(&models.League{PointsConfig: stored})
+	assert.Equal(t, DefaultPointsConfig, cfg)
+	for position := 1; position <= MaxPositionPointsEntries+1; position++ {
+		assert.Equal(t, DefaultPointsConfig.getPositionPoints(position), cfg.getPositionPoints(position), "position %d", position)
+	}
+}
+
+func TestPointsConfigForLeague_CustomTableHasNoFallback(t *testing.T) {
+	cfg := PointsConfigForLeague(&models.League{
+		PointsConfig: &models.LeaguePointsConfig{
+			ParticipationPoints: 2,
+			ModerationPoints:    0,
+			PositionPoints:      []int64{25, 18, 15},
+		},
+	})
+
+	assert.Equal(t, int64(2), cfg.ParticipationPoints)
+	assert.Equal(t, int64(0), cfg.ModerationPoints)
+	assert.Equal(t, int64(25), cfg.getPositionPoints(1))
+	assert.Equal(t, int64(15), cfg.getPositionPoints(3))
+	assert.Equal(t, int64(0), cfg.getPositionPoints(4))
+}
+
+func TestGetLeagueStandings_UsesLeaguePointsConfig(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockInvitationRepo := new(mocks.MockLeagueInvitationRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		InvitationRepo: mockInvitationRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		GameTypeRepo:   mockGameTypeRepo,
+	})
+
+	leagueID := primitive.NewObjectID()
+	winner := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Winner", Status: models.MembershipVirtual}
+	loser := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Loser", Status: models.MembershipVirtual}
+
+	league := &models.League{
+		ID: leagueID,
+		PointsConfig: &models.LeaguePointsConfig{
+			ParticipationPoints: 0,
+			ModerationPoints:    0,
+			PositionPoints:      []int64{3},
+		},
+	}
+	rounds := []*models.GameRound{{
+		LeagueID: leagueID,
+		EndTime:  time.Now(),
+		Players: []models.GameRoundPlayer{
+			{MembershipID: winner.ID, Position: 1},
+			{MembershipID: loser.ID, Position: 2},
+		},
+	}}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(league, nil)
+	mockGameRoundRepo.On("FindByLeague", ctx, leagueID).Return(rounds, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{winner, loser}, nil)
+	mockUserRepo.On("FindByID", ctx, mock.Anything).Return(nil, nil)
+
+	standings, err := service.GetLeagueStandings(ctx, leagueID, StandingsFilter{})
+
+	assert.NoError(t, err)
+	if !assert.Len(t, standings, 2) {
+		return
+	}
+	assert.Equal(t, winner.ID, standings[0].MembershipID)
+	assert.Equal(t, int64(3), standings[0].TotalPoints)
+	assert.Equal(t, loser.ID, standings[1].MembershipID)
+	assert.Equal(t, int64(0), standings[1].TotalPoints)
+}
+
+func TestUpdateLeagueSettings_RejectsInvalidConfig(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	service := NewLeagueService(LeagueServiceDeps{LeagueRepo: mockLeagueRepo})
+
+	_, err := service.UpdateLeagueSettings(ctx, primitive.NewObjectID(), LeagueSettings{
+		PointsConfig: &models.LeaguePointsConfig{PositionPoints: []int64{1, 2}},
+	})
+
+	assert.Error(t, err)
+	mockLeagueRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestValidatePointsConfig_TeamPoints(t *testing.T) {
+	cfg := DefaultLeaguePointsConfig()
+	assert.NoError(t, ValidatePointsConfig(cfg))
+
+	cfg.TeamDrawPoints = int64Ptr(*cfg.TeamWinPoints + 1)
+	assert.Error(t, ValidatePointsConfig(cfg))
+
+	cfg = DefaultLeaguePointsConfig()
+	cfg.TeamLossPoints = int64Ptr(-1)
+	cfg.TeamDrawPoints = int64Ptr(-1)
+	cfg.TeamWinPoints = int64Ptr(-1)
+	assert.Error(t, ValidatePointsConfig(cfg))
+}
+
+func TestPointsConfigForLeague_DefaultsTeamPointsMissingFromStoredConfig(t *testing.T) {
+	// Saved before team scoring, the document has no team_* fields
+	document, err := bson.Marshal(bson.M{"participation_points": 2, "moderation_points": 1, "position_points": bson.A{5, 3}})
+	assert.NoError(t, err)
+	stored := &models.LeaguePointsConfig{}
+	assert.NoError(t, bson.Unmarshal(document, stored))
+	assert.NoError(t, ValidatePointsConfig(stored))
+
+	cfg := PointsConfigForLeague(&models.League{PointsConfig: stored})
+	assert.Equal(t, int64(2), cfg.ParticipationPoints)
+	assert.Equal(t, DefaultPointsConfig.TeamWinPoints, cfg.TeamWinPoints)
+	assert.Equal(t, DefaultPointsConfig.TeamDrawPoints, cfg.TeamDrawPoints)
+	assert.Equal(t, DefaultPointsConfig.TeamLossPoints, cfg.TeamLossPoints)
+	assert.Equal(t, DefaultPointsConfig.CoopWinPoints, cfg.CoopWinPoints)
+
+	// Zero saved on purpose is kept
+	stored.TeamDrawPoints = int64Ptr(0)
+	assert.Equal(t, int64(0), PointsConfigForLeague(&models.League{PointsConfig: stored}).TeamDrawPoints)
+}
+
+func TestPointsConfigForLeague_DefaultsCoopPointsMissingFromStoredConfig(t *testing.T) {
+	// Saved with team points but before cooperative scoring
+	document, err := bson.Marshal(bson.M{"participation_points": 1, "position_points": bson.A{3},
+		"team_win_points": 4, "team_draw_points": 2, "team_loss_points": 0})
+	assert.NoError(t, err)
+	stored := &models.LeaguePointsConfig{}
+	assert.NoError(t, bson.Unmarshal(document, stored))
+	assert.NoError(t, ValidatePointsConfig(stored))
+
+	cfg := PointsConfigForLeague(&models.League{PointsConfig: stored})
+	assert.Equal(t, int64(4), cfg.TeamWinPoints)
+	assert.Equal(t, DefaultPointsConfig.CoopWinPoints, cfg.CoopWinPoints)
+
+	stored.CoopWinPoints = int64Ptr(0)
+	assert.Equal(t, int64(0), PointsConfigForLeague(&models.League{PointsConfig: stored}).CoopWinPoints)
+}
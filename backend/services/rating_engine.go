@@ -0,0 +1,340 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RatingAlgorithm identifies a skill rating algorithm
+type RatingAlgorithm string
+
+const (
+	RatingElo     RatingAlgorithm = "elo"
+	RatingGlicko2 RatingAlgorithm = "glicko2"
+)
+
+// RatingHistoryPoint is a member's rating right after a rated game round
+type RatingHistoryPoint struct {
+	GameRoundID primitive.ObjectID
+	PlayedAt    time.Time
+	Rating      float64
+	Deviation   float64
+}
+
+// MemberRating represents a member's skill rating in a league
+type MemberRating struct {
+	MembershipID primitive.ObjectID
+	UserID       primitive.ObjectID
+	UserName     string
+	UserAlias    string
+	UserAvatar   string
+	IsPending    bool
+	Rating       float64
+	Deviation    float64 // Glicko-2 only
+	Volatility   float64 // Glicko-2 only
+	GamesRated   int
+	History      []RatingHistoryPoint
+}
+
+// RatedPlayer is a participant of a game round together with its finishing position
+type RatedPlayer struct {
+	Rating   *MemberRating
+	Position int
+}
+
+// RatingEngine updates member ratings after a single game round
+type RatingEngine interface {
+	Algorithm() RatingAlgorithm
+	// Init sets the initial rating of a member which has not played yet
+	Init(rating *MemberRating)
+	// ApplyRound updates ratings of all participants of a finished game round
+	ApplyRound(players []RatedPlayer)
+}
+
+// NewRatingEngine returns the rating engine for the algorithm, or nil if the algorithm is unknown
+func NewRatingEngine(algorithm RatingAlgorithm) RatingEngine {
+	switch algorithm {
+	case RatingElo:
+		return &EloEngine{InitialRating: 1500, KFactor: 32}
+	case RatingGlicko2:
+		return &Glicko2Engine{InitialRating: 1500, InitialDeviation: 350, InitialVolatility: 0.06, Tau: 0.5}
+	}
+	return nil
+}
+
+// pairScore returns the result of a against b based on finishing positions: 1 - win, 0.5 - draw, 0 - loss
+func pairScore(a, b RatedPlayer) float64 {
+	switch {
+	case a.Position < b.Position:
+		return 1
+	case a.Position > b.Position:
+		return 0
+	}
+	return 0.5
+}
+
+// EloEngine is a multiplayer Elo: every game is split into pairwise matches between all participants,
+// the K-factor is divided by the number of opponents so a game weighs the same regardless of its size.
+type EloEngine struct {
+	InitialRating float64
+	KFactor       float64
+}
+
+func (e *EloEngine) Algorithm() RatingAlgorithm {
+	return RatingElo
+}
+
+func (e *EloEngine) Init(rating *MemberRating) {
+	rating.Rating = e.InitialRating
+}
+
+func (e *EloEngine) ApplyRound(players []RatedPlayer) {
+	if len(players) < 2 {
+		return
+	}
+
+	k := e.KFactor / float64(len(players)-1)
+	deltas := make([]float64, len(players))
+	for i, player := range players {
+		for j, opponent := range players {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (opponent.Rating.Rating-player.Rating.Rating)/400))
+			deltas[i] += k * (pairScore(player, opponent) - expected)
+		}
+	}
+
+	for i, player := range players {
+		player.Rating.Rating += deltas[i]
+	}
+}
+
+// Glicko2Engine implements Glicko-2 (http://www.glicko.net/glicko/glicko2.pdf).
+// Every game round is a rating period for its participants, opponents are all other participants.
+type Glicko2Engine struct {
+	InitialRating     float64
+	InitialDeviation  float64
+	InitialVolatility float64
+	Tau               float64 // constrains the change in volatility over time
+}
+
+// glicko2Scale converts between the Glicko and the Glicko-2 scales
+const glicko2Scale = 173.7178
+
+func (e *Glicko2Engine) Algorithm() RatingAlgorithm {
+	return RatingGlicko2
+}
+
+func (e *Glicko2Engine) Init(rating *MemberRating) {
+	rating.Rating = e.InitialRating
+	rating.Deviation = e.InitialDeviation
+	rating.Volatility = e.InitialVolatility
+}
+
+type glicko2Opponent struct {
+	rating    float64
+	deviation float64
+	score     float64
+}
+
+func (e *Glicko2Engine) ApplyRound(players []RatedPlayer) {
+	if len(players) < 2 {
+		return
+	}
+
+	type result struct{ rating, deviation, volatility float64 }
+	results := make([]result, len(players))
+	for i, player := range players {
+		opponents := make([]glicko2Opponent, 0, len(players)-1)
+		for j, opponent := range players {
+			if i == j {
+				continue
+			}
+			opponents = append(opponents, glicko2Opponent{
+				rating:    opponent.Rating.Rating,
+				deviation: opponent.Rating.Deviation,
+				score:     pairScore(player, opponent),
+			})
+		}
+		r, rd, vol := e.update(player.Rating.Rating, player.Rating.Deviation, player.Rating.Volatility, opponents)
+		results[i] = result{r, rd, vol}
+	}
+
+	for i, player := range players {
+		player.Rating.Rating = results[i].rating
+		player.Rating.Deviation = results[i].deviation
+		player.Rating.Volatility = results[i].volatility
+	}
+}
+
+// update performs steps 2-8 of the Glicko-2 algorithm for a single player
+func (e *Glicko2Engine) update(rating, deviation, volatility float64, opponents []glicko2Opponent) (float64, float64, float64) {
+	mu := (rating - 1500) / glicko2Scale
+	phi := deviation / glicko2Scale
+
+	var vInv, deltaSum float64
+	for _, o := range opponents {
+		muJ := (o.rating - 1500) / glicko2Scale
+		phiJ := o.deviation / glicko2Scale
+		g := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-g*(mu-muJ)))
+		vInv += g * g * expected * (1 - expected)
+		deltaSum += g * (o.score - expected)
+	}
+	v := 1 / vInv
+	delta := v * deltaSum
+
+	// Step 5: new volatility by the Illinois algorithm
+	a := math.Log(volatility * volatility)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(e.Tau*e.Tau)
+	}
+	const epsilon = 0.000001
+	bigA := a
+	var bigB float64
+	if delta*delta > phi*phi+v {
+		bigB = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*e.Tau) < 0 {
+			k++
+		}
+		bigB = a - k*e.Tau
+	}
+	fA, fB := f(bigA), f(bigB)
+	for math.Abs(bigB-bigA) > epsilon {
+		bigC := bigA + (bigA-bigB)*fA/(fB-fA)
+		fC := f(bigC)
+		if fC*fB <= 0 {
+			bigA, fA = bigB, fB
+		} else {
+			fA /= 2
+		}
+		bigB, fB = bigC, fC
+	}
+	newVolatility := math.Exp(bigA / 2)
+
+	phiStar := math.Sqrt(phi*phi + newVolatility*newVolatility)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*deltaSum
+
+	return newMu*glicko2Scale + 1500, newPhi * glicko2Scale, newVolatility
+}
+
+// CalculateRatings replays finished game rounds in EndTime order and returns member ratings.
+// Moderators and players without a position are not rated.
+func CalculateRatings(
+	rounds []*models.GameRound,
+	members []*models.LeagueMembership,
+	users map[primitive.ObjectID]*models.User,
+	engine RatingEngine,
+) []*MemberRating {
+	ratingsByMembership := make(map[primitive.ObjectID]*MemberRating)
+	ratingsByUser := make(map[primitive.ObjectID]*MemberRating)
+
+	for _, member := range members {
+		if member.Status != models.MembershipActive && member.Status != models.MembershipPending && member.Status != models.MembershipVirtual &&
+			member.Status != models.MembershipLeft {
+			continue
+		}
+
+		var userName, userAvatar string
+		if !member.UserID.IsZero() {
+			if user, ok := users[member.UserID]; ok && user != nil {
+				userName = user.Name
+				userAvatar = user.Avatar
+			}
+		}
+		if member.Alias != "" {
+			userName = member.Alias
+		}
+
+		rating := &MemberRating{
+			MembershipID: member.ID,
+			UserID:       member.UserID,
+			UserName:     userName,
+			UserAlias:    member.Alias,
+			UserAvatar:   userAvatar,
+			IsPending:    member.Status == models.MembershipPending || member.Status == models.MembershipVirtual,
+		}
+		engine.Init(rating)
+
+		ratingsByMembership[member.ID] = rating
+		if !member.UserID.IsZero() {
+			ratingsByUser[member.UserID] = rating
+		}
+	}
+
+	finished := make([]*models.GameRound, 0, len(rounds))
+	for _, round := range rounds {
+		if !round.EndTime.IsZero() {
+			finished = append(finished, round)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].EndTime.Before(finished[j].EndTime)
+	})
+
+	for _, round := range finished {
+		players := make([]RatedPlayer, 0, len(round.Players))
+		for _, player := range round.Players {
+			if player.IsModerator || player.Position <= 0 {
+				continue
+			}
+
+			var rating *MemberRating
+			var ok bool
+			if !player.MembershipID.IsZero() {
+				rating, ok = ratingsByMembership[player.MembershipID]
+			}
+			if !ok && !player.PlayerID.IsZero() {
+				rating, ok = ratingsByUser[player.PlayerID]
+			}
+			if !ok {
+				continue
+			}
+
+			players = append(players, RatedPlayer{Rating: rating, Position: player.Position})
+		}
+
+		if len(players) < 2 {
+			continue
+		}
+
+		engine.ApplyRound(players)
+
+		for _, player := range players {
+			player.Rating.GamesRated++
+			player.Rating.History = append(player.Rating.History, RatingHistoryPoint{
+				GameRoundID: round.ID,
+				PlayedAt:    round.EndTime,
+				Rating:      player.Rating.Rating,
+				Deviation:   player.Rating.Deviation,
+			})
+		}
+	}
+
+	ratings := make([]*MemberRating, 0, len(ratingsByMembership))
+	for _, rating := range ratingsByMembership {
+		ratings = append(ratings, rating)
+	}
+
+	// Sort by rating (descending), then by rated games (descending), then by name
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		if ratings[i].GamesRated != ratings[j].GamesRated {
+			return ratings[i].GamesRated > ratings[j].GamesRated
+		}
+		return ratings[i].UserName < ratings[j].UserName
+	})
+
+	return ratings
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGlicko2Update_GlickmanExample(t *testing.T) {
+	// Example from http://www.glicko.net/glicko/glicko2.pdf
+	engine := NewRatingEngine(RatingGlicko2).(*Glicko2Engine)
+
+	rating, deviation, volatility := engine.update(1500, 200, 0.06, []glicko2Opponent{
+		{rating: 1400, deviation: 30, score: 1},
+		{rating: 1550, deviation: 100, score: 0},
+		{rating: 1700, deviation: 300, score: 0},
+	})
+
+	assert.InDelta(t, 1464.06, rating, 0.01)
+	assert.InDelta(t, 151.52, deviation, 0.01)
+	assert.InDelta(t, 0.05999, volatility, 0.00001)
+}
+
+func TestEloEngine_TwoPlayersExchangeHalfK(t *testing.T) {
+	engine := NewRatingEngine(RatingElo)
+	winner, loser := &MemberRating{}, &MemberRating{}
+	engine.Init(winner)
+	engine.Init(loser)
+
+	engine.ApplyRound([]RatedPlayer{{Rating: winner, Position: 1}, {Rating: loser, Position: 2}})
+
+	assert.InDelta(t, 1516, winner.Rating, 0.001)
+	assert.InDelta(t, 1484, loser.Rating, 0.001)
+}
+
+func TestEloEngine_MultiplayerKeepsRatingSum(t *testing.T) {
+	engine := NewRatingEngine(RatingElo)
+	players := make([]RatedPlayer, 4)
+	for i := range players {
+		players[i] = RatedPlayer{Rating: &MemberRating{Rating: 1400 + float64(i)*50}, Position: i + 1}
+	}
+
+	engine.ApplyRound(players)
+
+	var sum float64
+	for _, p := range players {
+		sum += p.Rating.Rating
+	}
+	assert.InDelta(t, 4*1400+50*(0+1+2+3), sum, 0.0001)
+	assert.Greater(t, players[0].Rating.Rating, 1400.0)
+	assert.Less(t, players[3].Rating.Rating, 1550.0)
+}
+
+func TestCalculateRatings_ReplaysRoundsInEndTimeOrder(t *testing.T) {
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipVirtual}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipVirtual}
+	host := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Host", Status: models.MembershipVirtual}
+
+	start := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	later := &models.GameRound{
+		ID:      primitive.NewObjectID(),
+		EndTime: start.Add(2 * time.Hour),
+		Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 1},
+			{MembershipID: bob.ID, Position: 2},
+		},
+	}
+	earlier := &models.GameRound{
+		ID:      primitive.NewObjectID(),
+		EndTime: start,
+		Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 2},
+			{MembershipID: bob.ID, Position: 1},
+			{MembershipID: host.ID, IsModerator: true},
+		},
+	}
+	unfinished := &models.GameRound{
+		ID: primitive.NewObjectID(),
+		Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 1},
+			{MembershipID: bob.ID, Position: 2},
+		},
+	}
+
+	ratings := CalculateRatings(
+		[]*models.GameRound{later, unfinished, earlier},
+		[]*models.LeagueMembership{alice, bob, host},
+		nil,
+		NewRatingEngine(RatingElo),
+	)
+
+	if !assert.Len(t, ratings, 3) {
+		return
+	}
+	byMember := make(map[primitive.ObjectID]*MemberRating)
+	for _, rating := range ratings {
+		byMember[rating.MembershipID] = rating
+	}
+
+	assert.Equal(t, 2, byMember[alice.ID].GamesRated)
+	if assert.Len(t, byMember[alice.ID].History, 2) {
+		assert.Equal(t, earlier.ID, byMember[alice.ID].History[0].GameRoundID)
+		assert.Equal(t, later.ID, byMember[alice.ID].History[1].GameRoundID)
+		assert.InDelta(t, 1484, byMember[alice.ID].History[0].Rating, 0.001)
+	}
+	// Alice lost to an unrated Bob and then beat a stronger Bob, so she gains more than she lost
+	assert.Greater(t, byMember[alice.ID].Rating, 1500.0)
+	assert.Equal(t, alice.ID, ratings[0].MembershipID)
+
+	// Moderator is not rated
+	assert.Equal(t, 0, byMember[host.ID].GamesRated)
+	assert.Equal(t, 1500.0, byMember[host.ID].Rating)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SeasonService manages time-boxed standings periods inside leagues
+type SeasonService interface {
+	CreateSeason(ctx context.Context, leagueID primitive.ObjectID, name string, startDate, endDate time.Time) (*models.Season, error)
+	GetSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) (*models.Season, error)
+	ListSeasons(ctx context.Context, leagueID primitive.ObjectID) ([]*models.Season, error)
+	UpdateSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID, name string, startDate, endDate time.Time) (*models.Season, error)
+	DeleteSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) error
+	// CloseSeason freezes the season standings, closed seasons can't be edited
+	CloseSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) (*models.Season, error)
+	// GetSeasonStandings returns the frozen standings of a closed season or live standings of an open one
+	GetSeasonStandings(ctx context.Context, leagueID, seasonID primitive.ObjectID) ([]*LeagueStanding, error)
+}
+
+type seasonServiceInstance struct {
+	seasonRepo    repositories.SeasonRepository
+	leagueService LeagueService
+}
+
+func NewSeasonService(seasonRepo repositories.SeasonRepository, leagueService LeagueService) SeasonService {
+	return &seasonServiceInstance{
+		seasonRepo:    seasonRepo,
+		leagueService: leagueService,
+	}
+}
+
+// SeasonStandingsFilter returns the standings filter selecting rounds finished inside the season
+func SeasonStandingsFilter(season *models.Season) StandingsFilter {
+	return StandingsFilter{From: season.StartDate, To: season.EndDate}
+}
+
+func (s *seasonServiceInstance) CreateSeason(ctx context.Context, leagueID primitive.ObjectID, name string, startDate, endDate time.Time) (*models.Season, error) {
+	season := &models.Season{
+		LeagueID:  leagueID,
+		Name:      strings.TrimSpace(name),
+		StartDate: startDate,
+		EndDate:   endDate,
+		Status:    models.SeasonOpen,
+	}
+	if err := s.validate(ctx, season); err != nil {
+		return nil, err
+	}
+
+	if err := s.seasonRepo.Create(ctx, season); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create season")
+	}
+
+	return season, nil
+}
+
+func (s *seasonServiceInstance) GetSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) (*models.Season, error) {
+	season, err := s.seasonRepo.FindByID(ctx, seasonID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get season")
+	}
+	if season == nil || season.LeagueID != leagueID {
+		return nil, hexerr.New("season not found")
+	}
+	return season, nil
+}
+
+func (s *seasonServiceInstance) ListSeasons(ctx context.Context, leagueID primitive.ObjectID) ([]*models.Season, error) {
+	seasons, err := s.seasonRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list seasons")
+	}
+	return seasons, nil
+}
+
+func (s *seasonServiceInstance) UpdateSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID, name string, startDate, endDate time.Time) (*models.Season, error) {
+	season, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season.Status == models.SeasonClosed {
+		return nil, hexerr.New("season is closed")
+	}
+
+	season.Name = strings.TrimSpace(name)
+	season.StartDate = startDate
+	season.EndDate = endDate
+	if err := s.validate(ctx, season); err != nil {
+		return nil, err
+	}
+
+	if err := s.seasonRepo.Update(ctx, season); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update season")
+	}
+
+	return season, nil
+}
+
+func (s *seasonServiceInstance) DeleteSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) error {
+	if _, err := s.GetSeason(ctx, leagueID, seasonID); err != nil {
+		return err
+	}
+
+	if err := s.seasonRepo.Delete(ctx, seasonID); err != nil {
+		return hexerr.Wrapf(err, "failed to delete season")
+	}
+
+	return nil
+}
+
+func (s *seasonServiceInstance) CloseSeason(ctx context.Context, leagueID, seasonID primitive.ObjectID) (*models.Season, error) {
+	season, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season.Status == models.SeasonClosed {
+		return nil, hexerr.New("season is already closed")
+	}
+
+	standings, err := s.leagueService.GetLeagueStandings(ctx, leagueID, SeasonStandingsFilter(season))
+	if err != nil {
+		return nil, err
+	}
+
+	season.FinalStandings = FreezeStandings(standings)
+	season.Status = models.SeasonClosed
+	season.ClosedAt = time.Now()
+
+	if err := s.seasonRepo.Update(ctx, season); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to close season")
+	}
+
+	return season, nil
+}
+
+func (s *seasonServiceInstance) GetSeasonStandings(ctx context.Context, leagueID, seasonID primitive.ObjectID) ([]*LeagueStanding, error) {
+	season, err := s.GetSeason(ctx, leagueID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if season.Status != models.SeasonClosed {
+		return s.leagueService.GetLeagueStandings(ctx, leagueID, SeasonStandingsFilter(season))
+	}
+
+	return ThawStandings(season.FinalStandings), nil
+}
+
+// validate checks season dates and that it doesn't overlap other seasons of the league
+func (s *seasonServiceInstance) validate(ctx context.Context, season *models.Season) error {
+	if season.Name == "" {
+		return hexerr.New("season name is required")
+	}
+	if season.StartDate.IsZero() || season.EndDate.IsZero() {
+		return hexerr.New("season start and end dates are required")
+	}
+	if !season.EndDate.After(season.StartDate) {
+		return hexerr.New("season end must be after its start")
+	}
+
+	seasons, err := s.seasonRepo.FindByLeague(ctx, season.LeagueID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to list seasons")
+	}
+	for _, other := range seasons {
+		if other.ID == season.ID {
+			continue
+		}
+		if !season.StartDate.After(other.EndDate) && !other.StartDate.After(season.EndDate) {
+			return hexerr.New("season overlaps season " + other.Name)
+		}
+	}
+
+	return nil
+}
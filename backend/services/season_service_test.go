@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStandingsFilter_MatchesEndTimeInsideSeason(t *testing.T) {
+	season := &models.Season{
+		StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC),
+	}
+	filter := SeasonStandingsFilter(season)
+
+	assert.True(t, filter.Matches(&models.GameRound{EndTime: season.StartDate}))
+	assert.True(t, filter.Matches(&models.GameRound{EndTime: season.EndDate}))
+	assert.True(t, filter.Matches(&models.GameRound{EndTime: time.Date(2025, 2, 14, 20, 0, 0, 0, time.UTC)}))
+	assert.False(t, filter.Matches(&models.GameRound{EndTime: season.StartDate.Add(-time.Second)}))
+	assert.False(t, filter.Matches(&models.GameRound{EndTime: season.EndDate.Add(time.Second)}))
+	assert.False(t, filter.Matches(&models.GameRound{}))
+}
+
+func TestCreateSeason_RejectsOverlappingSeason(t *testing.T) {
+	ctx := context.Background()
+	mockSeasonRepo := new(mocks.MockSeasonRepository)
+	service := NewSeasonService(mockSeasonRepo, nil)
+
+	leagueID := primitive.NewObjectID()
+	existing := &models.Season{
+		ID:        primitive.NewObjectID(),
+		LeagueID:  leagueID,
+		Name:      "Q1",
+		StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+	mockSeasonRepo.On("FindByLeague", ctx, leagueID).Return([]*models.Season{existing}, nil)
+
+	_, err := service.CreateSeason(ctx, leagueID, "Overlap", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+
+	_, err = service.CreateSeason(ctx, leagueID, "Backwards", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+
+	mockSeasonRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCloseSeason_SnapshotsStandingsOfSeasonRounds(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockSeasonRepo := new(mocks.MockSeasonRepository)
+
+	leagueService := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		GameTypeRepo:   mockGameTypeRepo,
+	})
+	service := NewSeasonService(mockSeasonRepo, leagueService)
+
+	leagueID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Alice", Status: models.MembershipVirtual}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Bob", Status: models.MembershipVirtual}
+	season := &models.Season{
+		ID:        primitive.NewObjectID(),
+		LeagueID:  leagueID,
+		Name:      "Q1",
+		StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		Status:    models.SeasonOpen,
+	}
+	rounds := []*models.GameRound{
+		// Inside the season: Bob wins
+		{EndTime: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Players: []models.GameRoundPlayer{{MembershipID: bob.ID, Position: 1}, {MembershipID: alice.ID, Position: 2}}},
+		// Outside the season: Alice wins twice
+		{EndTime: time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC), Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1}, {MembershipID: bob.ID, Position: 2}}},
+		{EndTime: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1}, {MembershipID: bob.ID, Position: 2}}},
+	}
+
+	mockSeasonRepo.On("FindByID", ctx, season.ID).Return(season, nil)
+	mockSeasonRepo.On("Update", ctx, season).Return(nil)
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID}, nil)
+	mockGameRoundRepo.On("FindByLeague", ctx, leagueID).Return(rounds, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{alice, bob}, nil)
+	mockUserRepo.On("FindByID", ctx, mock.Anything).Return(nil, nil)
+
+	closed, err := service.CloseSeason(ctx, leagueID, season.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SeasonClosed, closed.Status)
+	assert.False(t, closed.ClosedAt.IsZero())
+	if assert.Len(t, closed.FinalStandings, 2) {
+		assert.Equal(t, 1, closed.FinalStandings[0].Rank)
+		assert.Equal(t, bob.ID, closed.FinalStandings[0].MembershipID)
+		assert.Equal(t, 1, closed.FinalStandings[0].GamesPlayed)
+	}
+
+	// Closed season standings come from the snapshot, not from the rounds
+	mockGameRoundRepo.ExpectedCalls = nil
+	standings, err := service.GetSeasonStandings(ctx, leagueID, season.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, standings, 2) {
+		assert.Equal(t, bob.ID, standings[0].MembershipID)
+	}
+	mockGameRoundRepo.AssertNumberOfCalls(t, "FindByLeague", 1)
+
+	_, err = service.CloseSeason(ctx, leagueID, season.ID)
+	assert.Error(t, err)
+}
+
+func TestGetSeason_FromAnotherLeagueIsNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockSeasonRepo := new(mocks.MockSeasonRepository)
+	service := NewSeasonService(mockSeasonRepo, nil)
+
+	season := &models.Season{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID()}
+	mockSeasonRepo.On("FindByID", ctx, season.ID).Return(season, nil)
+
+	_, err := service.GetSeason(ctx, primitive.NewObjectID(), season.ID)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewStandingsAggregationConfig translates the standings filter, scoring types and points
+// into the configuration of the database-side standings calculation
+func NewStandingsAggregationConfig(
+	filter StandingsFilter,
+	scoringTypes map[primitive.ObjectID]models.ScoringType,
+	config PointsConfig,
+) repositories.StandingsAggregationConfig {
+	aggregationConfig := repositories.StandingsAggregationConfig{
+		GameTypeID:          filter.GameTypeID,
+		Status:              filter.Status,
+		From:                filter.From,
+		To:                  filter.To,
+		ExcludeRoundID:      filter.ExcludeRoundID,
+		ParticipationPoints: config.ParticipationPoints,
+		ModerationPoints:    config.ModerationPoints,
+		TeamWinPoints:       config.TeamWinPoints,
+		TeamDrawPoints:      config.TeamDrawPoints,
+		TeamLossPoints:      config.TeamLossPoints,
+		CoopWinPoints:       config.CoopWinPoints,
+	}
+
+	for gameTypeID, scoringType := range scoringTypes {
+		switch {
+		case IsCooperativeScoring(scoringType):
+			aggregationConfig.CooperativeGameTypeIDs = append(aggregationConfig.CooperativeGameTypeIDs, gameTypeID)
+		case IsTeamScoring(scoringType):
+			aggregationConfig.TeamGameTypeIDs = append(aggregationConfig.TeamGameTypeIDs, gameTypeID)
+		}
+	}
+
+	// The table covers every configured position, gaps get the same points as in CalculateStandings
+	maxPosition := 0
+	for position := range config.PositionPoints {
+		if position > maxPosition {
+			maxPosition = position
+		}
+	}
+	aggregationConfig.PositionPoints = make([]int64, maxPosition)
+	for i := range aggregationConfig.PositionPoints {
+		aggregationConfig.PositionPoints[i] = config.getPositionPoints(i + 1)
+	}
+
+	return aggregationConfig
+}
+
+// StandingsFromAggregates builds standings of league members from database-side aggregates,
+// matching players to members the same way CalculateStandings does
+func StandingsFromAggregates(
+	aggregates []*repositories.StandingsAggregate,
+	members []*models.LeagueMembership,
+	users map[primitive.ObjectID]*models.User,
+) []*LeagueStanding {
+	index := newStandingsIndex(members, users)
+
+	for _, aggregate := range aggregates {
+		standing, ok := index.find(aggregate.Key.MembershipID, aggregate.Key.PlayerID)
+		if !ok {
+			// Player is not a member, skip
+			continue
+		}
+
+		standing.GamesPlayed += aggregate.GamesPlayed
+		standing.GamesModerated += aggregate.GamesModerated
+		standing.FirstPlaceCount += aggregate.FirstPlaceCount
+		standing.SecondPlaceCount += aggregate.SecondPlaceCount
+		standing.ThirdPlaceCount += aggregate.ThirdPlaceCount
+		standing.ParticipationPoints += aggregate.ParticipationPoints
+		standing.PositionPoints += aggregate.PositionPoints
+		standing.ModerationPoints += aggregate.ModerationPoints
+		standing.TeamWins += aggregate.TeamWins
+		standing.TeamDraws += aggregate.TeamDraws
+		standing.TeamLosses += aggregate.TeamLosses
+		standing.CoopWins += aggregate.CoopWins
+		standing.CoopLosses += aggregate.CoopLosses
+		standing.TotalPoints = standing.ParticipationPoints +
+			standing.PositionPoints +
+			standing.ModerationPoints
+	}
+
+	return index.sorted()
+}
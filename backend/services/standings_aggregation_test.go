@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/db"
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// aggregatingGameRoundRepository is a game round repository mock which also aggregates standings
+type aggregatingGameRoundRepository struct {
+	*mocks.MockGameRoundRepository
+}
+
+func (r aggregatingGameRoundRepository) AggregateStandings(ctx context.Context, leagueID primitive.ObjectID, config repositories.StandingsAggregationConfig) ([]*repositories.StandingsAggregate, error) {
+	args := r.Called(ctx, leagueID, config)
+	aggregates := args.Get(0)
+	if aggregates == nil {
+		return nil, args.Error(1)
+	}
+	return aggregates.([]*repositories.StandingsAggregate), args.Error(1)
+}
+
+func (r aggregatingGameRoundRepository) CountFinishedRoundsByGameType(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	args := r.Called(ctx, leagueID)
+	counts := args.Get(0)
+	if counts == nil {
+		return nil, args.Error(1)
+	}
+	return counts.(map[primitive.ObjectID]int), args.Error(1)
+}
+
+func TestNewStandingsAggregationConfig_PositionTable(t *testing.T) {
+	config := PointsConfig{PositionPoints: map[int]int64{1: 10, 3: 4}}
+
+	aggregationConfig := NewStandingsAggregationConfig(StandingsFilter{}, nil, config)
+	// Position 2 is missing from the table, so it gets no points
+	assert.Equal(t, []int64{10, 0, 4}, aggregationConfig.PositionPoints)
+}
+
+func TestStandingsFromAggregates_MatchesMembersAndLegacyPlayers(t *testing.T) {
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: models.MembershipActive}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipVirtual}
+	users := map[primitive.ObjectID]*models.User{alice.UserID: {ID: alice.UserID, Name: "Alice"}}
+
+	aggregates := []*repositories.StandingsAggregate{
+		{Key: repositories.StandingsAggregateKey{MembershipID: alice.ID}, GamesPlayed: 2, ParticipationPoints: 2, PositionPoints: 17, FirstPlaceCount: 1},
+		// Legacy rounds reference the user instead of the membership
+		{Key: repositories.StandingsAggregateKey{PlayerID: alice.UserID}, GamesPlayed: 1, ParticipationPoints: 1, GamesModerated: 1, ModerationPoints: 2},
+		{Key: repositories.StandingsAggregateKey{MembershipID: bob.ID}, GamesPlayed: 1, ParticipationPoints: 1, PositionPoints: 10},
+		// Not a member
+		{Key: repositories.StandingsAggregateKey{MembershipID: primitive.NewObjectID()}, GamesPlayed: 5, PositionPoints: 50},
+	}
+
+	standings := StandingsFromAggregates(aggregates, []*models.LeagueMembership{alice, bob}, users)
+
+	if assert.Len(t, standings, 2) {
+		assert.Equal(t, "Alice", standings[0].UserName)
+		assert.Equal(t, 3, standings[0].GamesPlayed)
+		assert.Equal(t, 1, standings[0].GamesModerated)
+		assert.Equal(t, int64(22), standings[0].TotalPoints)
+		assert.Equal(t, "Bob", standings[1].UserName)
+		assert.Equal(t, int64(11), standings[1].TotalPoints)
+	}
+}
+
+func TestGetLeagueStandings_FallsBackWhenAggregationFails(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockGameRoundRepo := aggregatingGameRoundRepository{new(mocks.MockGameRoundRepository)}
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+
+	service := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		GameTypeRepo:   mockGameTypeRepo,
+	})
+
+	league := &models.League{ID: primitive.NewObjectID()}
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, Alias: "Alice", Status: models.MembershipVirtual}
+	rounds := []*models.GameRound{
+		{EndTime: time.Now(), Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1}}},
+	}
+
+	mockLeagueRepo.On("FindByID", ctx, league.ID).Return(league, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, league.ID).Return([]*models.LeagueMembership{alice}, nil)
+	mockUserRepo.On("FindByID", ctx, primitive.NilObjectID).Return(nil, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{}, nil)
+	mockGameRoundRepo.On("AggregateStandings", ctx, league.ID, mock.Anything).Return(nil, errors.New("pipeline not supported"))
+	mockGameRoundRepo.On("FindByLeague", ctx, league.ID).Return(rounds, nil)
+
+	standings, err := service.GetLeagueStandings(ctx, league.ID, StandingsFilter{})
+
+	assert.NoError(t, err)
+	if assert.Len(t, standings, 1) {
+		assert.Equal(t, int64(11), standings[0].TotalPoints)
+	}
+	mockGameRoundRepo.AssertExpectations(t)
+}
+
+// standingsParityFixture is a league with members of all statuses and random rounds of every scoring kind,
+// including legacy player references, shared and missing positions, unfinished rounds and rounds without game type
+type standingsParityFixture struct {
+	leagueID     primitive.ObjectID
+	start        time.Time
+	mafia        primitive.ObjectID
+	scoringTypes map[primitive.ObjectID]models.ScoringType
+	members      []*models.LeagueMembership
+	rounds       []*models.GameRound
+}
+
+func newStandingsParityFixture() *standingsParityFixture {
+	classic := primitive.NewObjectID()
+	mafia := primitive.NewObjectID()
+	coop := primitive.NewObjectID()
+	f := &standingsParityFixture{
+		leagueID: primitive.NewObjectID(),
+		start:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		mafia:    mafia,
+		scoringTypes: map[primitive.ObjectID]models.ScoringType{
+			classic: models.ScoringTypeClassic,
+			mafia:   models.ScoringTypeMafia,
+			coop:    models.ScoringTypeCoopWithModerator,
+		},
+	}
+	gameTypes := []primitive.ObjectID{classic, mafia, coop, primitive.NilObjectID}
+
+	statuses := []models.LeagueMembershipStatus{models.MembershipActive, models.MembershipVirtual, models.MembershipPending, models.MembershipBanned}
+	for i := 0; i < 8; i++ {
+		f.members = append(f.members, &models.LeagueMembership{
+			ID:       primitive.NewObjectID(),
+			LeagueID: f.leagueID,
+			UserID:   primitive.NewObjectID(),
+			Status:   statuses[i%len(statuses)],
+		})
+	}
+
+	random := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		round := &models.GameRound{
+			ID:         primitive.NewObjectID(),
+			LeagueID:   f.leagueID,
+			GameTypeID: gameTypes[random.Intn(len(gameTypes))],
+			Name:       "parity",
+			Status:     []models.GameRoundStatus{models.StatusCompleted, models.StatusScoring}[random.Intn(2)],
+		}
+		// Some rounds are not finished
+		if random.Intn(10) > 0 {
+			round.EndTime = f.start.Add(time.Duration(i) * time.Hour)
+		}
+
+		playersCount := 2 + random.Intn(7)
+		for position, index := range random.Perm(len(f.members))[:playersCount] {
+			player := models.GameRoundPlayer{MembershipID: f.members[index].ID, Position: position + 1}
+			switch random.Intn(6) {
+			case 0:
+				player.IsModerator = true
+			case 1:
+				// Legacy player reference
+				player = models.GameRoundPlayer{PlayerID: f.members[index].UserID, Position: position + 1}
+			case 2:
+				player.Position = random.Intn(3) + 1 // Shared positions
+			case 3:
+				player.Position = 0
+			}
+			player.TeamName = []string{"", "Mafia", "Citizens"}[random.Intn(3)]
+			player.LabelName = "role"
+			round.Players = append(round.Players, player)
+		}
+
+		if random.Intn(3) > 0 {
+			round.TeamScores = []models.TeamScore{{Name: "Mafia", Score: int64(random.Intn(3))}, {Name: "Citizens", Score: int64(random.Intn(3))}}
+		}
+		if random.Intn(3) > 0 {
+			won := random.Intn(2) == 0
+			round.CooperativeWin = &won
+		}
+		f.rounds = append(f.rounds, round)
+	}
+	return f
+}
+
+func (f *standingsParityFixture) configs() []PointsConfig {
+	return []PointsConfig{
+		DefaultPointsConfig,
+		{ParticipationPoints: 2, ModerationPoints: 3, PositionPoints: map[int]int64{1: 12, 2: 6}, TeamWinPoints: 7, TeamDrawPoints: 3, TeamLossPoints: 1, CoopWinPoints: 4},
+		{ParticipationPoints: 1, PositionPoints: map[int]int64{1: 5, 4: 1}},
+	}
+}
+
+func (f *standingsParityFixture) filters() []StandingsFilter {
+	return []StandingsFilter{
+		{},
+		{GameTypeID: f.mafia},
+		{Status: models.StatusCompleted},
+		{From: f.start.Add(50 * time.Hour), To: f.start.Add(150 * time.Hour)},
+		{ExcludeRoundID: f.rounds[len(f.rounds)-1].ID},
+	}
+}
+
+// checkParity compares standings aggregated by the pipeline with CalculateStandings on the same rounds
+func (f *standingsParityFixture) checkParity(t *testing.T, rounds []*models.GameRound,
+	aggregate func(config repositories.StandingsAggregationConfig) ([]*repositories.StandingsAggregate, error)) {
+	for _, config := range f.configs() {
+		for _, filter := range f.filters() {
+			expected := CalculateStandings(context.Background(), FilterRounds(rounds, filter), f.members, nil, f.scoringTypes, config)
+
+			aggregates, err := aggregate(NewStandingsAggregationConfig(filter, f.scoringTypes, config))
+			if !assert.NoError(t, err) {
+				return
+			}
+			actual := StandingsFromAggregates(aggregates, f.members, nil)
+
+			// Ties are ordered arbitrarily, compare standings by member
+			assert.ElementsMatch(t, expected, actual, "config %+v, filter %+v", config, filter)
+		}
+	}
+}
+
+// testMongoDB connects to MONGO_URI (e.g. mongodb://localhost:27017/bgl_test) for the tests running the standings
+// pipelines on a real MongoDB, they are skipped when it's not set
+func testMongoDB(t *testing.T) *db.MongoDB {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+
+	mongodb, err := db.NewMongoDB(uri)
+	if err != nil {
+		t.Fatalf("failed to connect to MongoDB: %v", err)
+	}
+	return mongodb
+}
+
+// storeRounds creates the rounds of the fixture in the database, they are deleted when the test ends
+func (f *standingsParityFixture) storeRounds(t *testing.T, mongodb *db.MongoDB) repositories.GameRoundRepository {
+	ctx := context.Background()
+	gameRoundRepo, err := repositories.NewGameRoundRepository(mongodb)
+	if err != nil {
+		t.Fatalf("failed to create game round repository: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_, _ = mongodb.Collection("game_rounds").DeleteMany(ctx, bson.M{"league_id": f.leagueID})
+	})
+	for _, round := range f.rounds {
+		if err := gameRoundRepo.Create(ctx, round); err != nil {
+			t.Fatalf("failed to create game round: %v", err)
+		}
+	}
+	return gameRoundRepo
+}
+
+func TestGetLeagueStandingsByGameType_AggregatedAsCalculatedInMemory(t *testing.T) {
+	mongodb := testMongoDB(t)
+	ctx := context.Background()
+	f := newStandingsParityFixture()
+	aggregating := f.storeRounds(t, mongodb)
+
+	newService := func(gameRoundRepo repositories.GameRoundRepository) LeagueService {
+		mockLeagueRepo := new(mocks.MockLeagueRepository)
+		mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+		mockUserRepo := new(mocks.MockUserRepository)
+		mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+		mockLeagueRepo.On("FindByID", ctx, f.leagueID).Return(&models.League{ID: f.leagueID}, nil)
+		mockMembershipRepo.On("FindByLeague", ctx, f.leagueID).Return(f.members, nil)
+		mockUserRepo.On("FindByID", ctx, mock.Anything).Return(nil, nil)
+		gameTypes := make([]*models.GameType, 0, len(f.scoringTypes))
+		for id, scoringType := range f.scoringTypes {
+			gameTypes = append(gameTypes, &models.GameType{ID: id, ScoringType: scoringType})
+		}
+		mockGameTypeRepo.On("FindAll", ctx).Return(gameTypes, nil)
+		return NewLeagueService(LeagueServiceDeps{
+			LeagueRepo:     mockLeagueRepo,
+			MembershipRepo: mockMembershipRepo,
+			UserRepo:       mockUserRepo,
+			GameRoundRepo:  gameRoundRepo,
+			GameTypeRepo:   mockGameTypeRepo,
+		})
+	}
+
+	// Embedding the interface hides the aggregation methods, so the service calculates in memory
+	inMemory := struct {
+		repositories.GameRoundRepository
+	}{aggregating}
+
+	expected, err := newService(inMemory).GetLeagueStandingsByGameType(ctx, f.leagueID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	actual, err := newService(aggregating).GetLeagueStandingsByGameType(ctx, f.leagueID)
+	if !assert.NoError(t, err) || !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	assert.Len(t, actual, 3, "rounds without game type are skipped")
+	for i := range expected {
+		assert.Equal(t, expected[i].GameTypeID, actual[i].GameTypeID)
+		assert.Equal(t, expected[i].RoundsCount, actual[i].RoundsCount)
+		assert.ElementsMatch(t, expected[i].Standings, actual[i].Standings)
+	}
+}
+
+// TestAggregateStandings_ParityWithCalculateStandings runs the pipeline on random rounds in MongoDB and compares
+// the result with CalculateStandings on the same rounds
+func TestAggregateStandings_ParityWithCalculateStandings(t *testing.T) {
+	mongodb := testMongoDB(t)
+	ctx := context.Background()
+	f := newStandingsParityFixture()
+	gameRoundRepo := f.storeRounds(t, mongodb)
+	aggregator := gameRoundRepo.(repositories.StandingsAggregator)
+
+	// Times are stored with millisecond precision, the rounds are compared as read back
+	rounds, err := gameRoundRepo.FindByLeague(ctx, f.leagueID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	f.checkParity(t, rounds, func(config repositories.StandingsAggregationConfig) ([]*repositories.StandingsAggregate, error) {
+		return aggregator.AggregateStandings(ctx, f.leagueID, config)
+	})
+}
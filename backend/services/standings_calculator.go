@@ -5,13 +5,64 @@ import (
 	"github.com/andriyg76/bgl/models"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"sort"
+	"time"
 )
 
 // PointsConfig defines the points awarded for different achievements
 type PointsConfig struct {
 	ParticipationPoints int64
 	ModerationPoints    int64
-	PositionPoints      map[int]int64 // position -> points
+	PositionPoints      map[int]int64 // position -> points, positions missing from the table earn no position points
+	// Points for the team result in team_vs_team and mafia games, awarded instead of position points
+	TeamWinPoints  int64
+	TeamDrawPoints int64
+	TeamLossPoints int64
+	// Points for every player when the group wins a cooperative game, awarded instead of position points
+	CoopWinPoints int64
+}
+
+// StandingsFilter narrows the game rounds counted in standings
+type StandingsFilter struct {
+	GameTypeID     primitive.ObjectID     // zero - all game types
+	Status         models.GameRoundStatus // empty - any status
+	From           time.Time              // zero - no lower bound on round EndTime
+	To             time.Time              // zero - no upper bound on round EndTime
+	ExcludeRoundID primitive.ObjectID     // zero - none, the standings before a round leave it out
+}
+
+// Matches reports whether the round is counted with the filter; From and To are inclusive
+func (f StandingsFilter) Matches(round *models.GameRound) bool {
+	if !f.GameTypeID.IsZero() && round.GameTypeID != f.GameTypeID {
+		return false
+	}
+	if f.Status != "" && round.Status != f.Status {
+		return false
+	}
+	if !f.From.IsZero() && round.EndTime.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && round.EndTime.After(f.To) {
+		return false
+	}
+	if !f.ExcludeRoundID.IsZero() && round.ID == f.ExcludeRoundID {
+		return false
+	}
+	return true
+}
+
+// FilterRounds returns the rounds matching the filter
+func FilterRounds(rounds []*models.GameRound, filter StandingsFilter) []*models.GameRound {
+	if filter == (StandingsFilter{}) {
+		return rounds
+	}
+
+	filtered := make([]*models.GameRound, 0, len(rounds))
+	for _, round := range rounds {
+		if filter.Matches(round) {
+			filtered = append(filtered, round)
+		}
+	}
+	return filtered
 }
 
 // DefaultPointsConfig provides the default points configuration
@@ -25,10 +76,15 @@ var DefaultPointsConfig = PointsConfig{
 		4: 3,
 		5: 1,
 	},
+	TeamWinPoints:  10,
+	TeamDrawPoints: 5,
+	TeamLossPoints: 0,
+	CoopWinPoints:  5,
 }
 
 // LeagueStanding represents a player's standing in a league
 type LeagueStanding struct {
+	Rank                int // Members with equal points and games played share the place
 	MembershipID        primitive.ObjectID
 	UserID              primitive.ObjectID
 	UserName            string
@@ -44,70 +100,126 @@ type LeagueStanding struct {
 	ParticipationPoints int64
 	PositionPoints      int64
 	ModerationPoints    int64
+	TeamWins            int // Team results in team_vs_team and mafia games
+	TeamDraws           int
+	TeamLosses          int
+	CoopWins            int // Group results in cooperative games
+	CoopLosses          int
 }
 
-// getPositionPoints returns points for a given position
-func (c *PointsConfig) getPositionPoints(position int) int64 {
-	if points, ok := c.PositionPoints[position]; ok {
-		return points
-	}
-	// For positions > 5: max(0, 11 - position)
-	points := int64(11 - position)
-	if points < 0 {
+// CoopWinRate returns the share of won cooperative games, 0 when none were played
+func (s *LeagueStanding) CoopWinRate() float64 {
+	games := s.CoopWins + s.CoopLosses
+	if games == 0 {
 		return 0
 	}
-	return points
+	return float64(s.CoopWins) / float64(games)
 }
 
-// CalculateStandings computes the standings for all players in a league
-func CalculateStandings(
-	ctx context.Context,
-	rounds []*models.GameRound,
-	members []*models.LeagueMembership,
-	users map[primitive.ObjectID]*models.User,
-	config PointsConfig,
-) []*LeagueStanding {
-	// Create maps for standings lookup
-	// Key by MembershipID for new format, and by UserID for backward compatibility
-	standingsByMembership := make(map[primitive.ObjectID]*LeagueStanding)
-	standingsByUser := make(map[primitive.ObjectID]*LeagueStanding)
+// IsCooperativeScoring reports whether standings for the scoring type are derived from the group result
+func IsCooperativeScoring(scoringType models.ScoringType) bool {
+	return scoringType == models.ScoringTypeCooperative || scoringType == models.ScoringTypeCoopWithModerator
+}
 
-	// Initialize standings for all active, pending, and virtual members
-	for _, member := range members {
-		if member.Status != models.MembershipActive && member.Status != models.MembershipPending && member.Status != models.MembershipVirtual {
-			continue
-		}
+// TeamResult is the outcome of a team in a team game round
+type TeamResult string
 
-		isPending := member.Status == models.MembershipPending || member.Status == models.MembershipVirtual
-		var userName, userAvatar string
+const (
+	TeamWin  TeamResult = "win"
+	TeamDraw TeamResult = "draw"
+	TeamLoss TeamResult = "loss"
+)
 
-		if !member.UserID.IsZero() {
-			user, ok := users[member.UserID]
-			if ok && user != nil {
-				userName = user.Name
-				userAvatar = user.Avatar
-			}
-		}
+// IsTeamScoring reports whether standings for the scoring type are derived from team results
+func IsTeamScoring(scoringType models.ScoringType) bool {
+	return scoringType == models.ScoringTypeTeamVsTeam || scoringType == models.ScoringTypeMafia
+}
 
-		// Use alias if available (especially for pending members)
-		if member.Alias != "" {
-			userName = member.Alias
+// TeamResults returns the outcome of every team of the round: the team with the highest score wins,
+// several teams sharing the highest score draw, all others lose
+func TeamResults(round *models.GameRound) map[string]TeamResult {
+	if len(round.TeamScores) == 0 {
+		return nil
+	}
+
+	best := round.TeamScores[0].Score
+	for _, team := range round.TeamScores[1:] {
+		if team.Score > best {
+			best = team.Score
+		}
+	}
+	leaders := 0
+	for _, team := range round.TeamScores {
+		if team.Score == best {
+			leaders++
 		}
+	}
 
-		standing := &LeagueStanding{
-			MembershipID: member.ID,
-			UserID:       member.UserID,
-			UserName:     userName,
-			UserAlias:    member.Alias,
-			UserAvatar:   userAvatar,
-			IsPending:    isPending,
+	results := make(map[string]TeamResult, len(round.TeamScores))
+	for _, team := range round.TeamScores {
+		switch {
+		case team.Score != best:
+			results[team.Name] = TeamLoss
+		case leaders > 1:
+			results[team.Name] = TeamDraw
+		default:
+			results[team.Name] = TeamWin
 		}
+	}
+	return results
+}
 
-		standingsByMembership[member.ID] = standing
-		if !member.UserID.IsZero() {
-			standingsByUser[member.UserID] = standing
+// getTeamResultPoints returns points for a team result
+func (c *PointsConfig) getTeamResultPoints(result TeamResult) int64 {
+	switch result {
+	case TeamWin:
+		return c.TeamWinPoints
+	case TeamDraw:
+		return c.TeamDrawPoints
+	}
+	return c.TeamLossPoints
+}
+
+// getPositionPoints returns points for a given position
+func (c *PointsConfig) getPositionPoints(position int) int64 {
+	return c.PositionPoints[position]
+}
+
+// RoundPoints returns the points a player earned in a finished round, the same way CalculateStandings adds them up
+func RoundPoints(round *models.GameRound, player *models.GameRoundPlayer, scoringType models.ScoringType, config PointsConfig) int64 {
+	points := config.ParticipationPoints
+	if player.IsModerator {
+		points += config.ModerationPoints
+	}
+
+	switch {
+	case IsCooperativeScoring(scoringType) && round.CooperativeWin != nil:
+		if !player.IsModerator && *round.CooperativeWin {
+			points += config.CoopWinPoints
 		}
+	case IsTeamScoring(scoringType) && len(round.TeamScores) > 0:
+		if result, ok := TeamResults(round)[player.TeamName]; ok && player.TeamName != "" {
+			points += config.getTeamResultPoints(result)
+		}
+	case player.Position > 0:
+		points += config.getPositionPoints(player.Position)
 	}
+	return points
+}
+
+// CalculateStandings computes the standings for all players in a league.
+// scoringTypes maps game type IDs to their scoring types; players of team_vs_team and mafia rounds
+// with team scores get points for their team result instead of their own position, players of
+// cooperative rounds with the group result set get points for the group win.
+func CalculateStandings(
+	ctx context.Context,
+	rounds []*models.GameRound,
+	members []*models.LeagueMembership,
+	users map[primitive.ObjectID]*models.User,
+	scoringTypes map[primitive.ObjectID]models.ScoringType,
+	config PointsConfig,
+) []*LeagueStanding {
+	index := newStandingsIndex(members, users)
 
 	// Process all completed rounds
 	for _, round := range rounds {
@@ -116,20 +228,15 @@ func CalculateStandings(
 			continue
 		}
 
+		var teamResults map[string]TeamResult
+		if IsTeamScoring(scoringTypes[round.GameTypeID]) {
+			teamResults = TeamResults(round)
+		}
+		coopResult := IsCooperativeScoring(scoringTypes[round.GameTypeID]) && round.CooperativeWin != nil
+
 		// Process each player in the round
 		for _, player := range round.Players {
-			var standing *LeagueStanding
-			var ok bool
-
-			// Try MembershipID first (new format)
-			if !player.MembershipID.IsZero() {
-				standing, ok = standingsByMembership[player.MembershipID]
-			}
-			// Fall back to PlayerID (legacy format)
-			if !ok && !player.PlayerID.IsZero() {
-				standing, ok = standingsByUser[player.PlayerID]
-			}
-
+			standing, ok := index.find(player.MembershipID, player.PlayerID)
 			if !ok {
 				// Player is not a member, skip
 				continue
@@ -141,8 +248,31 @@ func CalculateStandings(
 			// Add participation points
 			standing.ParticipationPoints += config.ParticipationPoints
 
-			// Add position points if position is valid
-			if player.Position > 0 {
+			if coopResult {
+				// Cooperative game: all players share the group result, the moderator doesn't play
+				if !player.IsModerator {
+					if *round.CooperativeWin {
+						standing.PositionPoints += config.CoopWinPoints
+						standing.CoopWins++
+					} else {
+						standing.CoopLosses++
+					}
+				}
+			} else if teamResults != nil {
+				// Team game: the player shares the result of the team
+				if result, ok := teamResults[player.TeamName]; ok && player.TeamName != "" {
+					standing.PositionPoints += config.getTeamResultPoints(result)
+					switch result {
+					case TeamWin:
+						standing.TeamWins++
+					case TeamDraw:
+						standing.TeamDraws++
+					default:
+						standing.TeamLosses++
+					}
+				}
+			} else if player.Position > 0 {
+				// Add position points if position is valid
 				posPoints := config.getPositionPoints(player.Position)
 				standing.PositionPoints += posPoints
 
@@ -170,19 +300,106 @@ func CalculateStandings(
 		}
 	}
 
-	// Convert map to slice (use standingsByMembership to avoid duplicates)
-	standings := make([]*LeagueStanding, 0, len(standingsByMembership))
-	for _, standing := range standingsByMembership {
+	return index.sorted()
+}
+
+// standingsIndex holds standings of league members.
+// Key by MembershipID for new format, and by UserID for backward compatibility
+type standingsIndex struct {
+	byMembership map[primitive.ObjectID]*LeagueStanding
+	byUser       map[primitive.ObjectID]*LeagueStanding
+}
+
+func newStandingsIndex(members []*models.LeagueMembership, users map[primitive.ObjectID]*models.User) *standingsIndex {
+	index := &standingsIndex{
+		byMembership: make(map[primitive.ObjectID]*LeagueStanding),
+		byUser:       make(map[primitive.ObjectID]*LeagueStanding),
+	}
+
+	// Initialize standings for all active, pending, virtual and left members
+	for _, member := range members {
+		if member.Status != models.MembershipActive && member.Status != models.MembershipPending && member.Status != models.MembershipVirtual &&
+			member.Status != models.MembershipLeft {
+			continue
+		}
+
+		isPending := member.Status == models.MembershipPending || member.Status == models.MembershipVirtual
+		var userName, userAvatar string
+
+		if !member.UserID.IsZero() {
+			user, ok := users[member.UserID]
+			if ok && user != nil {
+				userName = user.Name
+				userAvatar = user.Avatar
+			}
+		}
+
+		// Use alias if available (especially for pending members)
+		if member.Alias != "" {
+			userName = member.Alias
+		}
+
+		standing := &LeagueStanding{
+			MembershipID: member.ID,
+			UserID:       member.UserID,
+			UserName:     userName,
+			UserAlias:    member.Alias,
+			UserAvatar:   userAvatar,
+			IsPending:    isPending,
+		}
+
+		index.byMembership[member.ID] = standing
+		if !member.UserID.IsZero() {
+			index.byUser[member.UserID] = standing
+		}
+	}
+
+	return index
+}
+
+// find returns the standing of a round player
+func (idx *standingsIndex) find(membershipID, playerID primitive.ObjectID) (*LeagueStanding, bool) {
+	var standing *LeagueStanding
+	var ok bool
+
+	// Try MembershipID first (new format)
+	if !membershipID.IsZero() {
+		standing, ok = idx.byMembership[membershipID]
+	}
+	// Fall back to PlayerID (legacy format)
+	if !ok && !playerID.IsZero() {
+		standing, ok = idx.byUser[playerID]
+	}
+	return standing, ok
+}
+
+// sorted returns the standings ordered by total points (descending), then by games played (ascending)
+func (idx *standingsIndex) sorted() []*LeagueStanding {
+	// Convert map to slice (use byMembership to avoid duplicates)
+	standings := make([]*LeagueStanding, 0, len(idx.byMembership))
+	for _, standing := range idx.byMembership {
 		standings = append(standings, standing)
 	}
 
-	// Sort by total points (descending), then by games played (ascending)
 	sort.Slice(standings, func(i, j int) bool {
 		if standings[i].TotalPoints == standings[j].TotalPoints {
 			return standings[i].GamesPlayed < standings[j].GamesPlayed
 		}
 		return standings[i].TotalPoints > standings[j].TotalPoints
 	})
+	rankStandings(standings)
 
 	return standings
 }
+
+// rankStandings numbers ordered standings, tied members get the same rank and the next rank is skipped
+func rankStandings(standings []*LeagueStanding) {
+	for i, standing := range standings {
+		previous := i - 1
+		if previous >= 0 && standings[previous].TotalPoints == standing.TotalPoints && standings[previous].GamesPlayed == standing.GamesPlayed {
+			standing.Rank = standings[previous].Rank
+		} else {
+			standing.Rank = i + 1
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StandingsHistoryService keeps snapshots of league standings taken after every finalized game round
+type StandingsHistoryService interface {
+	// RecordSnapshot stores the current league standings after the game round was finalized
+	RecordSnapshot(ctx context.Context, leagueID, gameRoundID primitive.ObjectID) error
+	// GetPreviousRanks returns member ranks before the last finalized game round, recalculated with the current rounds,
+	// members and points. Nil for the first game of the league
+	GetPreviousRanks(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]int, error)
+	// GetStandingsAsOf returns the league standings as they were at the moment
+	GetStandingsAsOf(ctx context.Context, leagueID primitive.ObjectID, at time.Time) ([]*LeagueStanding, error)
+	// RefreshLatest recalculates the latest snapshot in place after league members were merged or the merge was undone,
+	// nobody is notified as no game was played
+	RefreshLatest(ctx context.Context, leagueID primitive.ObjectID) error
+}
+
+type standingsHistoryServiceInstance struct {
+	snapshotRepo        repositories.StandingsSnapshotRepository
+	leagueService       LeagueService
+	notificationService NotificationService
+	webhookService      WebhookService
+}
+
+func NewStandingsHistoryService(snapshotRepo repositories.StandingsSnapshotRepository, leagueService LeagueService,
+	notificationService NotificationService, webhookService WebhookService) StandingsHistoryService {
+	return &standingsHistoryServiceInstance{
+		snapshotRepo:        snapshotRepo,
+		leagueService:       leagueService,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+	}
+}
+
+func (s *standingsHistoryServiceInstance) RecordSnapshot(ctx context.Context, leagueID, gameRoundID primitive.ObjectID) error {
+	standings, err := s.leagueService.GetLeagueStandings(ctx, leagueID, StandingsFilter{})
+	if err != nil {
+		return err
+	}
+
+	snapshot := &models.StandingsSnapshot{
+		LeagueID:    leagueID,
+		GameRoundID: gameRoundID,
+		TakenAt:     time.Now(),
+		Standings:   FreezeStandings(standings),
+	}
+	previous, err := s.snapshotRepo.FindRecent(ctx, leagueID, 1)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get standings snapshots")
+	}
+
+	if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
+		return hexerr.Wrapf(err, "failed to save standings snapshot")
+	}
+
+	// Nothing to compare with for the first snapshot of the league
+	if len(previous) > 0 && s.notificationService != nil {
+		if err := s.notificationService.NotifyRankChanges(ctx, leagueID, previous[0].Standings, snapshot.Standings); err != nil {
+			glog.Warn("Failed to notify about rank changes in league %s: %v", leagueID.Hex(), err)
+		}
+	}
+	if s.webhookService != nil {
+		if err := s.webhookService.PublishStandingsChanged(ctx, leagueID, gameRoundID, snapshot.Standings); err != nil {
+			glog.Warn("Failed to publish standings of league %s to webhooks: %v", leagueID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *standingsHistoryServiceInstance) GetPreviousRanks(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	// The latest snapshot tells which game was finalized last. The snapshot before it is not compared with: score
+	// corrections, points system changes, merges and leaves since then would count as movement
+	snapshots, err := s.snapshotRepo.FindRecent(ctx, leagueID, 2)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get standings snapshots")
+	}
+	if len(snapshots) < 2 {
+		return nil, nil
+	}
+
+	standings, err := s.leagueService.GetLeagueStandings(ctx, leagueID, StandingsFilter{ExcludeRoundID: snapshots[0].GameRoundID})
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[primitive.ObjectID]int, len(standings))
+	for _, standing := range standings {
+		// Members who hadn't played yet only share the last place, there's no movement to show for their first game
+		if standing.GamesPlayed > 0 {
+			ranks[standing.MembershipID] = standing.Rank
+		}
+	}
+	return ranks, nil
+}
+
+func (s *standingsHistoryServiceInstance) GetStandingsAsOf(ctx context.Context, leagueID primitive.ObjectID, at time.Time) ([]*LeagueStanding, error) {
+	snapshot, err := s.snapshotRepo.FindLatestAt(ctx, leagueID, at)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get standings snapshot")
+	}
+	if snapshot != nil {
+		return ThawStandings(snapshot.Standings), nil
+	}
+
+	// No snapshot yet at the moment (e.g. rounds finished before snapshots were introduced):
+	// recalculate from rounds finished by then
+	return s.leagueService.GetLeagueStandings(ctx, leagueID, StandingsFilter{To: at})
+}
+
+func (s *standingsHistoryServiceInstance) RefreshLatest(ctx context.Context, leagueID primitive.ObjectID) error {
+	latest, err := s.snapshotRepo.FindRecent(ctx, leagueID, 1)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get standings snapshots")
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+
+	standings, err := s.leagueService.GetLeagueStandings(ctx, leagueID, StandingsFilter{})
+	if err != nil {
+		return err
+	}
+	latest[0].Standings = FreezeStandings(standings)
+	if err := s.snapshotRepo.Update(ctx, latest[0]); err != nil {
+		return hexerr.Wrapf(err, "failed to update standings snapshot")
+	}
+	return nil
+}
+
+// FreezeStandings converts ordered standings to the stored form
+func FreezeStandings(standings []*LeagueStanding) []models.FrozenStanding {
+	frozen := make([]models.FrozenStanding, 0, len(standings))
+	for _, standing := range standings {
+		frozen = append(frozen, models.FrozenStanding{
+			Rank:                standing.Rank,
+			MembershipID:        standing.MembershipID,
+			UserID:              standing.UserID,
+			UserName:            standing.UserName,
+			UserAvatar:          standing.UserAvatar,
+			TotalPoints:         standing.TotalPoints,
+			GamesPlayed:         standing.GamesPlayed,
+			GamesModerated:      standing.GamesModerated,
+			FirstPlaceCount:     standing.FirstPlaceCount,
+			SecondPlaceCount:    standing.SecondPlaceCount,
+			ThirdPlaceCount:     standing.ThirdPlaceCount,
+			ParticipationPoints: standing.ParticipationPoints,
+			PositionPoints:      standing.PositionPoints,
+			ModerationPoints:    standing.ModerationPoints,
+			TeamWins:            standing.TeamWins,
+			TeamDraws:           standing.TeamDraws,
+			TeamLosses:          standing.TeamLosses,
+			CoopWins:            standing.CoopWins,
+			CoopLosses:          standing.CoopLosses,
+		})
+	}
+	return frozen
+}
+
+// ThawStandings converts stored standings back, keeping their order
+func ThawStandings(frozen []models.FrozenStanding) []*LeagueStanding {
+	standings := make([]*LeagueStanding, 0, len(frozen))
+	for _, standing := range frozen {
+		standings = append(standings, &LeagueStanding{
+			Rank:                standing.Rank,
+			MembershipID:        standing.MembershipID,
+			UserID:              standing.UserID,
+			UserName:            standing.UserName,
+			UserAvatar:          standing.UserAvatar,
+			TotalPoints:         standing.TotalPoints,
+			GamesPlayed:         standing.GamesPlayed,
+			GamesModerated:      standing.GamesModerated,
+			FirstPlaceCount:     standing.FirstPlaceCount,
+			SecondPlaceCount:    standing.SecondPlaceCount,
+			ThirdPlaceCount:     standing.ThirdPlaceCount,
+			ParticipationPoints: standing.ParticipationPoints,
+			PositionPoints:      standing.PositionPoints,
+			ModerationPoints:    standing.ModerationPoints,
+			TeamWins:            standing.TeamWins,
+			TeamDraws:           standing.TeamDraws,
+			TeamLosses:          standing.TeamLosses,
+			CoopWins:            standing.CoopWins,
+			CoopLosses:          standing.CoopLosses,
+		})
+	}
+	return standings
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetPreviousRanks_RecalculatesStandingsBeforeLastGame(t *testing.T) {
+	ctx := context.Background()
+	mockSnapshotRepo := new(mocks.MockStandingsSnapshotRepository)
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	leagueService := NewLeagueService(LeagueServiceDeps{
+		LeagueRepo:     mockLeagueRepo,
+		MembershipRepo: mockMembershipRepo,
+		UserRepo:       mockUserRepo,
+		GameRoundRepo:  mockGameRoundRepo,
+		GameTypeRepo:   mockGameTypeRepo,
+	})
+	service := NewStandingsHistoryService(mockSnapshotRepo, leagueService, nil, nil)
+
+	leagueID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Alice", Status: models.MembershipVirtual}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Bob", Status: models.MembershipVirtual}
+	newcomer := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Ivan", Status: models.MembershipVirtual}
+	// The first round was corrected after both games were finalized: Alice won it, not Bob
+	first := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, EndTime: time.Now().Add(-2 * time.Hour),
+		Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1}, {MembershipID: bob.ID, Position: 2}}}
+	last := &models.GameRound{ID: primitive.NewObjectID(), LeagueID: leagueID, EndTime: time.Now().Add(-time.Hour),
+		Players: []models.GameRoundPlayer{{MembershipID: bob.ID, Position: 1}, {MembershipID: newcomer.ID, Position: 2}, {MembershipID: alice.ID, Position: 3}}}
+
+	mockLeagueRepo.On("FindByID", ctx, leagueID).Return(&models.League{ID: leagueID}, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{alice, bob, newcomer}, nil)
+	mockUserRepo.On("FindByID", ctx, mock.Anything).Return(nil, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{}, nil)
+	mockGameRoundRepo.On("FindByLeague", ctx, leagueID).Return([]*models.GameRound{first, last}, nil)
+
+	stale := &models.StandingsSnapshot{GameRoundID: first.ID, Standings: []models.FrozenStanding{{Rank: 1, MembershipID: bob.ID}, {Rank: 2, MembershipID: alice.ID}}}
+	latest := &models.StandingsSnapshot{GameRoundID: last.ID, Standings: []models.FrozenStanding{{Rank: 1, MembershipID: bob.ID}, {Rank: 2, MembershipID: alice.ID}}}
+	mockSnapshotRepo.On("FindRecent", ctx, leagueID, int64(2)).Return([]*models.StandingsSnapshot{latest, stale}, nil).Once()
+
+	ranks, err := service.GetPreviousRanks(ctx, leagueID)
+
+	assert.NoError(t, err)
+	// The newcomer played the first time in the last game
+	assert.Equal(t, map[primitive.ObjectID]int{alice.ID: 1, bob.ID: 2}, ranks)
+
+	// The first game has nothing to compare with
+	mockSnapshotRepo.On("FindRecent", ctx, leagueID, int64(2)).Return([]*models.StandingsSnapshot{stale}, nil).Once()
+
+	ranks, err = service.GetPreviousRanks(ctx, leagueID)
+
+	assert.NoError(t, err)
+	assert.Nil(t, ranks)
+}
+
+func TestGetStandingsAsOf_ServesSnapshot(t *testing.T) {
+	ctx := context.Background()
+	mockSnapshotRepo := new(mocks.MockStandingsSnapshotRepository)
+	service := NewStandingsHistoryService(mockSnapshotRepo, nil, nil, nil)
+
+	leagueID := primitive.NewObjectID()
+	at := time.Date(2025, 3, 1, 23, 59, 59, 0, time.UTC)
+	standings := []*LeagueStanding{
+		{Rank: 1, MembershipID: primitive.NewObjectID(), UserName: "Alice", TotalPoints: 30, GamesPlayed: 3, FirstPlaceCount: 2},
+		{Rank: 2, MembershipID: primitive.NewObjectID(), UserName: "Bob", TotalPoints: 12, GamesPlayed: 3, TeamWins: 1},
+	}
+	snapshot := &models.StandingsSnapshot{LeagueID: leagueID, Standings: FreezeStandings(standings)}
+	mockSnapshotRepo.On("FindLatestAt", ctx, leagueID, at).Return(snapshot, nil)
+
+	result, err := service.GetStandingsAsOf(ctx, leagueID, at)
+
+	assert.NoError(t, err)
+	assert.Equal(t, standings, result)
+	assert.Equal(t, 2, snapshot.Standings[1].Rank)
+}
+
+func TestRefreshLatest_RewritesLatestSnapshotWithoutNotifying(t *testing.T) {
+	ctx := context.Background()
+	mockSnapshotRepo := new(mocks.MockStandingsSnapshotRepository)
+	standings := []*LeagueStanding{{Rank: 1, MembershipID: primitive.NewObjectID(), UserName: "Alice", TotalPoints: 30, GamesPlayed: 3}}
+	service := NewStandingsHistoryService(mockSnapshotRepo, &chatLeagueService{standings: standings}, nil, nil)
+
+	leagueID := primitive.NewObjectID()
+	roundID := primitive.NewObjectID()
+	merged := primitive.NewObjectID()
+	latest := &models.StandingsSnapshot{ID: primitive.NewObjectID(), LeagueID: leagueID, GameRoundID: roundID,
+		Standings: []models.FrozenStanding{{Rank: 1, MembershipID: merged}}}
+	mockSnapshotRepo.On("FindRecent", ctx, leagueID, int64(1)).Return([]*models.StandingsSnapshot{latest}, nil)
+	mockSnapshotRepo.On("Update", ctx, latest).Return(nil)
+
+	err := service.RefreshLatest(ctx, leagueID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, FreezeStandings(standings), latest.Standings)
+	assert.Equal(t, roundID, latest.GameRoundID)
+	mockSnapshotRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
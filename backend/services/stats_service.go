@@ -0,0 +1,560 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatsService calculates member statistics from the league game history
+type StatsService interface {
+	// HeadToHead compares two league members over the games they played together
+	HeadToHead(ctx context.Context, leagueID, membershipA, membershipB primitive.ObjectID, lastGames int) (*HeadToHead, error)
+	// MemberStats summarises the full game history of a league member
+	MemberStats(ctx context.Context, leagueID, membershipID primitive.ObjectID) (*MemberStats, error)
+}
+
+// HeadToHeadOutcome is the result of member A against member B in a single game
+type HeadToHeadOutcome string
+
+const (
+	HeadToHeadAAhead    HeadToHeadOutcome = "a"
+	HeadToHeadBAhead    HeadToHeadOutcome = "b"
+	HeadToHeadTie       HeadToHeadOutcome = "tie"
+	HeadToHeadTeammates HeadToHeadOutcome = "teammates" // Same team or a cooperative game
+	HeadToHeadNone      HeadToHeadOutcome = "none"      // Not comparable: moderated or no positions
+)
+
+// HeadToHeadRecord counts outcomes of shared games
+type HeadToHeadRecord struct {
+	Games     int
+	AAhead    int
+	BAhead    int
+	Ties      int
+	Teammates int
+}
+
+func (r *HeadToHeadRecord) add(outcome HeadToHeadOutcome) {
+	r.Games++
+	switch outcome {
+	case HeadToHeadAAhead:
+		r.AAhead++
+	case HeadToHeadBAhead:
+		r.BAhead++
+	case HeadToHeadTie:
+		r.Ties++
+	case HeadToHeadTeammates:
+		r.Teammates++
+	}
+}
+
+// HeadToHeadGameTypeRecord is a head-to-head record for a single game type
+type HeadToHeadGameTypeRecord struct {
+	GameTypeID primitive.ObjectID
+	HeadToHeadRecord
+}
+
+// HeadToHeadGame is a single game both members played
+type HeadToHeadGame struct {
+	GameRoundID primitive.ObjectID
+	GameTypeID  primitive.ObjectID
+	Name        string
+	PlayedAt    time.Time
+	APosition   int
+	BPosition   int
+	Outcome     HeadToHeadOutcome
+}
+
+// HeadToHead is a comparison of two league members
+type HeadToHead struct {
+	MemberA    *models.LeagueMembership
+	MemberB    *models.LeagueMembership
+	Total      HeadToHeadRecord
+	ByGameType []HeadToHeadGameTypeRecord
+	LastGames  []HeadToHeadGame // Latest first
+}
+
+// MemberResult is the outcome of a single game for a member
+type MemberResult string
+
+const (
+	MemberWin       MemberResult = "win"
+	MemberDraw      MemberResult = "draw"
+	MemberLoss      MemberResult = "loss"
+	MemberUndecided MemberResult = "" // Moderated, no position or no group result
+)
+
+// MemberGameTypeStats is a member's record for a single game type
+type MemberGameTypeStats struct {
+	GameTypeID                primitive.ObjectID
+	Games                     int // Including moderated
+	GamesModerated            int
+	Wins                      int
+	Draws                     int
+	Losses                    int
+	RankedGames               int     // Games counted in AverageNormalizedPosition
+	AverageNormalizedPosition float64 // 0 - always first, 1 - always last
+}
+
+// MemberRoleStats counts games a member played with a role or in a team
+type MemberRoleStats struct {
+	Name  string
+	Games int
+	Wins  int
+}
+
+// MemberPointsPoint is a member's points right after a game round
+type MemberPointsPoint struct {
+	GameRoundID primitive.ObjectID
+	PlayedAt    time.Time
+	Points      int64 // Earned in the round
+	TotalPoints int64 // Accumulated up to and including the round
+}
+
+// MemberStats is a summary of a member's league game history
+type MemberStats struct {
+	Member                    *models.LeagueMembership
+	Games                     int // Including moderated
+	GamesModerated            int
+	Wins                      int
+	Draws                     int
+	Losses                    int
+	WinRate                   float64 // Share of wins among decided games
+	RankedGames               int     // Games counted in AverageNormalizedPosition
+	AverageNormalizedPosition float64 // 0 - always first, 1 - always last
+	BestStreak                int     // Longest run of wins
+	WorstStreak               int     // Longest run of losses
+	CurrentStreak             int     // Positive - wins, negative - losses
+	ByGameType                []MemberGameTypeStats
+	FavouriteRoles            []MemberRoleStats // By LabelName, most played first
+	FavouriteTeams            []MemberRoleStats // By TeamName, most played first
+	PointsOverTime            []MemberPointsPoint
+}
+
+type statsServiceInstance struct {
+	leagueRepo     repositories.LeagueRepository
+	gameRoundRepo  repositories.GameRoundRepository
+	gameTypeRepo   repositories.GameTypeRepository
+	membershipRepo repositories.LeagueMembershipRepository
+}
+
+func NewStatsService(
+	leagueRepo repositories.LeagueRepository,
+	gameRoundRepo repositories.GameRoundRepository,
+	gameTypeRepo repositories.GameTypeRepository,
+	membershipRepo repositories.LeagueMembershipRepository,
+) StatsService {
+	return &statsServiceInstance{
+		leagueRepo:     leagueRepo,
+		gameRoundRepo:  gameRoundRepo,
+		gameTypeRepo:   gameTypeRepo,
+		membershipRepo: membershipRepo,
+	}
+}
+
+func (s *statsServiceInstance) HeadToHead(ctx context.Context, leagueID, membershipA, membershipB primitive.ObjectID, lastGames int) (*HeadToHead, error) {
+	if membershipA == membershipB {
+		return nil, hexerr.New("can't compare a member with themselves")
+	}
+
+	memberA, err := s.getLeagueMember(ctx, leagueID, membershipA)
+	if err != nil {
+		return nil, err
+	}
+	memberB, err := s.getLeagueMember(ctx, leagueID, membershipB)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds, err := s.gameRoundRepo.FindFinishedByMemberships(ctx, leagueID, []*models.LeagueMembership{memberA, memberB})
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get shared game rounds")
+	}
+
+	scoringTypes, err := s.getScoringTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HeadToHead{
+		MemberA:   memberA,
+		MemberB:   memberB,
+		LastGames: make([]HeadToHeadGame, 0, lastGames),
+	}
+	byGameType := make(map[primitive.ObjectID]*HeadToHeadGameTypeRecord)
+
+	for _, round := range rounds {
+		playerA := findRoundPlayer(round, memberA)
+		playerB := findRoundPlayer(round, memberB)
+		if playerA == nil || playerB == nil {
+			continue
+		}
+
+		outcome := compareRoundPlayers(round, scoringTypes[round.GameTypeID], playerA, playerB)
+		result.Total.add(outcome)
+
+		record, ok := byGameType[round.GameTypeID]
+		if !ok {
+			record = &HeadToHeadGameTypeRecord{GameTypeID: round.GameTypeID}
+			byGameType[round.GameTypeID] = record
+		}
+		record.add(outcome)
+
+		if len(result.LastGames) < lastGames {
+			result.LastGames = append(result.LastGames, HeadToHeadGame{
+				GameRoundID: round.ID,
+				GameTypeID:  round.GameTypeID,
+				Name:        round.Name,
+				PlayedAt:    round.EndTime,
+				APosition:   playerA.Position,
+				BPosition:   playerB.Position,
+				Outcome:     outcome,
+			})
+		}
+	}
+
+	result.ByGameType = make([]HeadToHeadGameTypeRecord, 0, len(byGameType))
+	for _, record := range byGameType {
+		result.ByGameType = append(result.ByGameType, *record)
+	}
+	// Most played game types first
+	sort.Slice(result.ByGameType, func(i, j int) bool {
+		if result.ByGameType[i].Games == result.ByGameType[j].Games {
+			return result.ByGameType[i].GameTypeID.Hex() < result.ByGameType[j].GameTypeID.Hex()
+		}
+		return result.ByGameType[i].Games > result.ByGameType[j].Games
+	})
+
+	return result, nil
+}
+
+func (s *statsServiceInstance) MemberStats(ctx context.Context, leagueID, membershipID primitive.ObjectID) (*MemberStats, error) {
+	member, err := s.getLeagueMember(ctx, leagueID, membershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	league, err := s.leagueRepo.FindByID(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league")
+	}
+	if league == nil {
+		return nil, hexerr.New("league not found")
+	}
+	config := PointsConfigForLeague(league)
+
+	rounds, err := s.gameRoundRepo.FindFinishedByMemberships(ctx, leagueID, []*models.LeagueMembership{member})
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game rounds")
+	}
+
+	scoringTypes, err := s.getScoringTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Streaks and points are accumulated in the order games were played
+	sort.SliceStable(rounds, func(i, j int) bool {
+		return rounds[i].EndTime.Before(rounds[j].EndTime)
+	})
+
+	stats := &MemberStats{
+		Member:         member,
+		PointsOverTime: make([]MemberPointsPoint, 0, len(rounds)),
+	}
+	byGameType := make(map[primitive.ObjectID]*MemberGameTypeStats)
+	roles := make(map[string]*MemberRoleStats)
+	teams := make(map[string]*MemberRoleStats)
+	var normalizedSum float64
+	normalizedByGameType := make(map[primitive.ObjectID]float64)
+	var totalPoints int64
+
+	for _, round := range rounds {
+		player := findRoundPlayer(round, member)
+		if player == nil {
+			continue
+		}
+		scoringType := scoringTypes[round.GameTypeID]
+
+		gameTypeStats, ok := byGameType[round.GameTypeID]
+		if !ok {
+			gameTypeStats = &MemberGameTypeStats{GameTypeID: round.GameTypeID}
+			byGameType[round.GameTypeID] = gameTypeStats
+		}
+		stats.Games++
+		gameTypeStats.Games++
+		if player.IsModerator {
+			stats.GamesModerated++
+			gameTypeStats.GamesModerated++
+		}
+
+		result := memberRoundResult(round, scoringType, player)
+		switch result {
+		case MemberWin:
+			stats.Wins++
+			gameTypeStats.Wins++
+		case MemberDraw:
+			stats.Draws++
+			gameTypeStats.Draws++
+		case MemberLoss:
+			stats.Losses++
+			gameTypeStats.Losses++
+		}
+		stats.applyStreak(result)
+
+		if normalized, ok := normalizedPosition(round, scoringType, player); ok {
+			stats.RankedGames++
+			normalizedSum += normalized
+			gameTypeStats.RankedGames++
+			normalizedByGameType[round.GameTypeID] += normalized
+		}
+
+		if !player.IsModerator {
+			countRole(roles, player.LabelName, result)
+			countRole(teams, player.TeamName, result)
+		}
+
+		points := RoundPoints(round, player, scoringType, config)
+		totalPoints += points
+		stats.PointsOverTime = append(stats.PointsOverTime, MemberPointsPoint{
+			GameRoundID: round.ID,
+			PlayedAt:    round.EndTime,
+			Points:      points,
+			TotalPoints: totalPoints,
+		})
+	}
+
+	if decided := stats.Wins + stats.Draws + stats.Losses; decided > 0 {
+		stats.WinRate = float64(stats.Wins) / float64(decided)
+	}
+	if stats.RankedGames > 0 {
+		stats.AverageNormalizedPosition = normalizedSum / float64(stats.RankedGames)
+	}
+
+	stats.ByGameType = make([]MemberGameTypeStats, 0, len(byGameType))
+	for gameTypeID, gameTypeStats := range byGameType {
+		if gameTypeStats.RankedGames > 0 {
+			gameTypeStats.AverageNormalizedPosition = normalizedByGameType[gameTypeID] / float64(gameTypeStats.RankedGames)
+		}
+		stats.ByGameType = append(stats.ByGameType, *gameTypeStats)
+	}
+	// Most played game types first
+	sort.Slice(stats.ByGameType, func(i, j int) bool {
+		if stats.ByGameType[i].Games == stats.ByGameType[j].Games {
+			return stats.ByGameType[i].GameTypeID.Hex() < stats.ByGameType[j].GameTypeID.Hex()
+		}
+		return stats.ByGameType[i].Games > stats.ByGameType[j].Games
+	})
+
+	stats.FavouriteRoles = sortedRoleStats(roles)
+	stats.FavouriteTeams = sortedRoleStats(teams)
+
+	return stats, nil
+}
+
+// applyStreak extends the current streak with the result of the next game; draws break streaks
+func (s *MemberStats) applyStreak(result MemberResult) {
+	switch result {
+	case MemberWin:
+		if s.CurrentStreak < 0 {
+			s.CurrentStreak = 0
+		}
+		s.CurrentStreak++
+		if s.CurrentStreak > s.BestStreak {
+			s.BestStreak = s.CurrentStreak
+		}
+	case MemberLoss:
+		if s.CurrentStreak > 0 {
+			s.CurrentStreak = 0
+		}
+		s.CurrentStreak--
+		if -s.CurrentStreak > s.WorstStreak {
+			s.WorstStreak = -s.CurrentStreak
+		}
+	case MemberDraw:
+		s.CurrentStreak = 0
+	}
+}
+
+func (s *statsServiceInstance) getLeagueMember(ctx context.Context, leagueID, membershipID primitive.ObjectID) (*models.LeagueMembership, error) {
+	membership, err := s.membershipRepo.FindByID(ctx, membershipID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get membership")
+	}
+	if membership == nil || membership.LeagueID != leagueID {
+		return nil, hexerr.New("member not found in this league")
+	}
+	return membership, nil
+}
+
+func (s *statsServiceInstance) getScoringTypes(ctx context.Context) (map[primitive.ObjectID]models.ScoringType, error) {
+	gameTypes, err := s.gameTypeRepo.FindAll(ctx)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get game types")
+	}
+	scoringTypes := make(map[primitive.ObjectID]models.ScoringType, len(gameTypes))
+	for _, gameType := range gameTypes {
+		scoringTypes[gameType.ID] = gameType.ScoringType
+	}
+	return scoringTypes, nil
+}
+
+// findRoundPlayer returns the member's player of the round, a legacy player without membership is found by the user
+func findRoundPlayer(round *models.GameRound, member *models.LeagueMembership) *models.GameRoundPlayer {
+	for i := range round.Players {
+		player := &round.Players[i]
+		if player.MembershipID == member.ID ||
+			player.MembershipID.IsZero() && !member.UserID.IsZero() && player.PlayerID == member.UserID {
+			return player
+		}
+	}
+	return nil
+}
+
+// teamResultRank orders team results for comparison
+var teamResultRank = map[TeamResult]int{TeamWin: 2, TeamDraw: 1, TeamLoss: 0}
+
+// compareRoundPlayers decides who of the two players finished ahead, consistently with standings scoring
+func compareRoundPlayers(round *models.GameRound, scoringType models.ScoringType, a, b *models.GameRoundPlayer) HeadToHeadOutcome {
+	if a.IsModerator || b.IsModerator {
+		return HeadToHeadNone
+	}
+
+	if IsCooperativeScoring(scoringType) {
+		return HeadToHeadTeammates
+	}
+
+	if IsTeamScoring(scoringType) && len(round.TeamScores) > 0 {
+		if a.TeamName == "" || b.TeamName == "" {
+			return HeadToHeadNone
+		}
+		if a.TeamName == b.TeamName {
+			return HeadToHeadTeammates
+		}
+		results := TeamResults(round)
+		resultA, okA := results[a.TeamName]
+		resultB, okB := results[b.TeamName]
+		if !okA || !okB {
+			return HeadToHeadNone
+		}
+		return compareRanks(teamResultRank[resultA], teamResultRank[resultB])
+	}
+
+	if a.Position <= 0 || b.Position <= 0 {
+		return HeadToHeadNone
+	}
+	// Lower position is better
+	return compareRanks(b.Position, a.Position)
+}
+
+// compareRanks compares ranks where higher is better
+func compareRanks(a, b int) HeadToHeadOutcome {
+	switch {
+	case a > b:
+		return HeadToHeadAAhead
+	case a < b:
+		return HeadToHeadBAhead
+	}
+	return HeadToHeadTie
+}
+
+// memberRoundResult decides whether the player won the round, consistently with standings scoring
+func memberRoundResult(round *models.GameRound, scoringType models.ScoringType, player *models.GameRoundPlayer) MemberResult {
+	if player.IsModerator {
+		return MemberUndecided
+	}
+
+	if IsCooperativeScoring(scoringType) {
+		if round.CooperativeWin == nil {
+			return MemberUndecided
+		}
+		if *round.CooperativeWin {
+			return MemberWin
+		}
+		return MemberLoss
+	}
+
+	if IsTeamScoring(scoringType) && len(round.TeamScores) > 0 {
+		result, ok := TeamResults(round)[player.TeamName]
+		if !ok || player.TeamName == "" {
+			return MemberUndecided
+		}
+		switch result {
+		case TeamWin:
+			return MemberWin
+		case TeamDraw:
+			return MemberDraw
+		}
+		return MemberLoss
+	}
+
+	switch {
+	case player.Position == 1:
+		return MemberWin
+	case player.Position > 1:
+		return MemberLoss
+	}
+	return MemberUndecided
+}
+
+// normalizedPosition maps the player's position to [0, 1] by the number of ranked players: 0 - first, 1 - last.
+// Team, cooperative and moderated games, and games with a single ranked player are not ranked.
+func normalizedPosition(round *models.GameRound, scoringType models.ScoringType, player *models.GameRoundPlayer) (float64, bool) {
+	if player.IsModerator || player.Position <= 0 || IsCooperativeScoring(scoringType) {
+		return 0, false
+	}
+	if IsTeamScoring(scoringType) && len(round.TeamScores) > 0 {
+		return 0, false
+	}
+
+	ranked := 0
+	for _, p := range round.Players {
+		if !p.IsModerator && p.Position > 0 {
+			ranked++
+		}
+	}
+	if ranked < 2 {
+		return 0, false
+	}
+
+	normalized := float64(player.Position-1) / float64(ranked-1)
+	if normalized > 1 {
+		normalized = 1
+	}
+	return normalized, true
+}
+
+func countRole(roles map[string]*MemberRoleStats, name string, result MemberResult) {
+	if name == "" {
+		return
+	}
+	role, ok := roles[name]
+	if !ok {
+		role = &MemberRoleStats{Name: name}
+		roles[name] = role
+	}
+	role.Games++
+	if result == MemberWin {
+		role.Wins++
+	}
+}
+
+// sortedRoleStats returns roles ordered by games played (descending), then by name
+func sortedRoleStats(roles map[string]*MemberRoleStats) []MemberRoleStats {
+	result := make([]MemberRoleStats, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, *role)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Games == result[j].Games {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Games > result[j].Games
+	})
+	return result
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHeadToHead_CountsRecordPerGameType(t *testing.T) {
+	ctx := context.Background()
+
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+
+	service := NewStatsService(new(mocks.MockLeagueRepository), mockGameRoundRepo, mockGameTypeRepo, mockMembershipRepo)
+
+	leagueID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipActive}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, UserID: primitive.NewObjectID(), Alias: "Bob", Status: models.MembershipActive}
+
+	catan := &models.GameType{ID: primitive.NewObjectID(), ScoringType: models.ScoringTypeClassic}
+	mafia := &models.GameType{ID: primitive.NewObjectID(), ScoringType: models.ScoringTypeMafia}
+	pandemic := &models.GameType{ID: primitive.NewObjectID(), ScoringType: models.ScoringTypeCooperative}
+
+	now := time.Now()
+	rounds := []*models.GameRound{
+		// Latest first, as returned by the repository
+		{ID: primitive.NewObjectID(), Name: "catan-3", GameTypeID: catan.ID, EndTime: now, Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 2}, {MembershipID: bob.ID, Position: 1}}},
+		{ID: primitive.NewObjectID(), Name: "catan-2", GameTypeID: catan.ID, EndTime: now.Add(-time.Hour), Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 1}, {MembershipID: bob.ID, Position: 3}}},
+		// Legacy round, players recorded by user
+		{ID: primitive.NewObjectID(), Name: "catan-1", GameTypeID: catan.ID, EndTime: now.Add(-2 * time.Hour), Players: []models.GameRoundPlayer{
+			{PlayerID: alice.UserID, Position: 1}, {PlayerID: bob.UserID, Position: 1}}},
+		{ID: primitive.NewObjectID(), Name: "mafia-2", GameTypeID: mafia.ID, EndTime: now.Add(-3 * time.Hour),
+			TeamScores: []models.TeamScore{{Name: "Mafia", Score: 1}, {Name: "Citizens", Score: 0}},
+			Players: []models.GameRoundPlayer{
+				{MembershipID: alice.ID, TeamName: "Mafia"}, {MembershipID: bob.ID, TeamName: "Citizens"}}},
+		{ID: primitive.NewObjectID(), Name: "mafia-1", GameTypeID: mafia.ID, EndTime: now.Add(-4 * time.Hour),
+			TeamScores: []models.TeamScore{{Name: "Mafia", Score: 1}, {Name: "Citizens", Score: 0}},
+			Players: []models.GameRoundPlayer{
+				{MembershipID: alice.ID, TeamName: "Citizens"}, {MembershipID: bob.ID, IsModerator: true}}},
+		{ID: primitive.NewObjectID(), Name: "pandemic-1", GameTypeID: pandemic.ID, EndTime: now.Add(-5 * time.Hour), Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID}, {MembershipID: bob.ID}}},
+	}
+
+	mockMembershipRepo.On("FindByID", ctx, alice.ID).Return(alice, nil)
+	mockMembershipRepo.On("FindByID", ctx, bob.ID).Return(bob, nil)
+	mockGameRoundRepo.On("FindFinishedByMemberships", ctx, leagueID, []*models.LeagueMembership{alice, bob}).Return(rounds, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{catan, mafia, pandemic}, nil)
+
+	result, err := service.HeadToHead(ctx, leagueID, alice.ID, bob.ID, 2)
+
+	assert.NoError(t, err)
+	if !assert.NotNil(t, result) {
+		return
+	}
+	assert.Equal(t, HeadToHeadRecord{Games: 6, AAhead: 2, BAhead: 1, Ties: 1, Teammates: 1}, result.Total)
+
+	if assert.Len(t, result.ByGameType, 3) {
+		assert.Equal(t, catan.ID, result.ByGameType[0].GameTypeID)
+		assert.Equal(t, HeadToHeadRecord{Games: 3, AAhead: 1, BAhead: 1, Ties: 1}, result.ByGameType[0].HeadToHeadRecord)
+		assert.Equal(t, mafia.ID, result.ByGameType[1].GameTypeID)
+		assert.Equal(t, HeadToHeadRecord{Games: 2, AAhead: 1}, result.ByGameType[1].HeadToHeadRecord)
+		assert.Equal(t, pandemic.ID, result.ByGameType[2].GameTypeID)
+		assert.Equal(t, HeadToHeadRecord{Games: 1, Teammates: 1}, result.ByGameType[2].HeadToHeadRecord)
+	}
+
+	if assert.Len(t, result.LastGames, 2) {
+		assert.Equal(t, "catan-3", result.LastGames[0].Name)
+		assert.Equal(t, HeadToHeadBAhead, result.LastGames[0].Outcome)
+		assert.Equal(t, 2, result.LastGames[0].APosition)
+		assert.Equal(t, 1, result.LastGames[0].BPosition)
+		assert.Equal(t, "catan-2", result.LastGames[1].Name)
+		assert.Equal(t, HeadToHeadAAhead, result.LastGames[1].Outcome)
+	}
+
+	mockGameRoundRepo.AssertExpectations(t)
+}
+
+func TestHeadToHead_RejectsMemberOfAnotherLeague(t *testing.T) {
+	ctx := context.Background()
+
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+
+	service := NewStatsService(new(mocks.MockLeagueRepository), mockGameRoundRepo, mockGameTypeRepo, mockMembershipRepo)
+
+	leagueID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID}
+	stranger := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: primitive.NewObjectID()}
+
+	mockMembershipRepo.On("FindByID", ctx, alice.ID).Return(alice, nil)
+	mockMembershipRepo.On("FindByID", ctx, stranger.ID).Return(stranger, nil)
+
+	result, err := service.HeadToHead(ctx, leagueID, alice.ID, stranger.ID, 10)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockGameRoundRepo.AssertNotCalled(t, "FindFinishedByMemberships", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = service.HeadToHead(ctx, leagueID, alice.ID, alice.ID, 10)
+	assert.Error(t, err)
+}
+
+func TestMemberStats_SummarisesHistory(t *testing.T) {
+	ctx := context.Background()
+
+	mockLeagueRepo := new(mocks.MockLeagueRepository)
+	mockGameRoundRepo := new(mocks.MockGameRoundRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+
+	service := NewStatsService(mockLeagueRepo, mockGameRoundRepo, mockGameTypeRepo, mockMembershipRepo)
+
+	league := &models.League{ID: primitive.NewObjectID()}
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: league.ID, UserID: primitive.NewObjectID(), Alias: "Alice", Status: models.MembershipActive}
+	other := primitive.NewObjectID()
+
+	catan := &models.GameType{ID: primitive.NewObjectID(), ScoringType: models.ScoringTypeClassic}
+	mafia := &models.GameType{ID: primitive.NewObjectID(), ScoringType: models.ScoringTypeMafia}
+
+	now := time.Now()
+	mafiaScores := []models.TeamScore{{Name: "Mafia", Score: 0}, {Name: "Citizens", Score: 1}}
+	rounds := []*models.GameRound{
+		// Latest first, as returned by the repository
+		{ID: primitive.NewObjectID(), GameTypeID: mafia.ID, EndTime: now, Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, IsModerator: true}, {MembershipID: other, TeamName: "Citizens"}}, TeamScores: mafiaScores},
+		{ID: primitive.NewObjectID(), GameTypeID: mafia.ID, EndTime: now.Add(-time.Hour), Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, TeamName: "Mafia", LabelName: "don"}, {MembershipID: other, TeamName: "Citizens"}}, TeamScores: mafiaScores},
+		{ID: primitive.NewObjectID(), GameTypeID: catan.ID, EndTime: now.Add(-2 * time.Hour), Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 3}, {MembershipID: other, Position: 1}, {MembershipID: primitive.NewObjectID(), Position: 2}}},
+		{ID: primitive.NewObjectID(), GameTypeID: catan.ID, EndTime: now.Add(-3 * time.Hour), Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 1}, {MembershipID: other, Position: 2}}},
+		// Legacy round, the player recorded by user
+		{ID: primitive.NewObjectID(), GameTypeID: catan.ID, EndTime: now.Add(-4 * time.Hour), Players: []models.GameRoundPlayer{
+			{PlayerID: alice.UserID, Position: 1}, {MembershipID: other, Position: 2}}},
+	}
+
+	firstRoundID := rounds[4].ID
+
+	mockMembershipRepo.On("FindByID", ctx, alice.ID).Return(alice, nil)
+	mockLeagueRepo.On("FindByID", ctx, league.ID).Return(league, nil)
+	mockGameRoundRepo.On("FindFinishedByMemberships", ctx, league.ID, []*models.LeagueMembership{alice}).Return(rounds, nil)
+	mockGameTypeRepo.On("FindAll", ctx).Return([]*models.GameType{catan, mafia}, nil)
+
+	stats, err := service.MemberStats(ctx, league.ID, alice.ID)
+
+	assert.NoError(t, err)
+	if !assert.NotNil(t, stats) {
+		return
+	}
+	assert.Equal(t, 5, stats.Games)
+	assert.Equal(t, 1, stats.GamesModerated)
+	assert.Equal(t, 2, stats.Wins)
+	assert.Equal(t, 2, stats.Losses)
+	assert.Equal(t, 0.5, stats.WinRate)
+	// Two first places of two and the last place of three
+	assert.Equal(t, 3, stats.RankedGames)
+	assert.InDelta(t, 1.0/3, stats.AverageNormalizedPosition, 0.0001)
+	assert.Equal(t, 2, stats.BestStreak)
+	assert.Equal(t, 2, stats.WorstStreak)
+	assert.Equal(t, -2, stats.CurrentStreak)
+
+	if assert.Len(t, stats.ByGameType, 2) {
+		assert.Equal(t, catan.ID, stats.ByGameType[0].GameTypeID)
+		assert.Equal(t, 3, stats.ByGameType[0].Games)
+		assert.Equal(t, 2, stats.ByGameType[0].Wins)
+		assert.Equal(t, mafia.ID, stats.ByGameType[1].GameTypeID)
+		assert.Equal(t, 1, stats.ByGameType[1].GamesModerated)
+		assert.Equal(t, 1, stats.ByGameType[1].Losses)
+	}
+	assert.Equal(t, []MemberRoleStats{{Name: "don", Games: 1}}, stats.FavouriteRoles)
+	assert.Equal(t, []MemberRoleStats{{Name: "Mafia", Games: 1}}, stats.FavouriteTeams)
+
+	// Default points: 1 + 10, 1 + 10, 1 + 5, 1 + 0 (team loss), 1 + 2 (moderation)
+	if assert.Len(t, stats.PointsOverTime, 5) {
+		assert.Equal(t, firstRoundID, stats.PointsOverTime[0].GameRoundID)
+		assert.Equal(t, int64(11), stats.PointsOverTime[0].Points)
+		assert.Equal(t, int64(3), stats.PointsOverTime[4].Points)
+		assert.Equal(t, int64(32), stats.PointsOverTime[4].TotalPoints)
+	}
+}
+
+func TestRoundPoints_MatchesStandings(t *testing.T) {
+	ctx := context.Background()
+
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), Status: models.MembershipActive}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), Status: models.MembershipActive}
+	catan := primitive.NewObjectID()
+	mafia := primitive.NewObjectID()
+	pandemic := primitive.NewObjectID()
+	scoringTypes := map[primitive.ObjectID]models.ScoringType{
+		catan:    models.ScoringTypeClassic,
+		mafia:    models.ScoringTypeMafia,
+		pandemic: models.ScoringTypeCoopWithModerator,
+	}
+	won := true
+
+	now := time.Now()
+	rounds := []*models.GameRound{
+		{GameTypeID: catan, EndTime: now, Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID, Position: 7}, {MembershipID: bob.ID, Position: 2}}},
+		{GameTypeID: mafia, EndTime: now, TeamScores: []models.TeamScore{{Name: "Mafia", Score: 1}, {Name: "Citizens", Score: 1}},
+			Players: []models.GameRoundPlayer{{MembershipID: alice.ID, TeamName: "Mafia"}, {MembershipID: bob.ID, TeamName: "Citizens"}}},
+		{GameTypeID: pandemic, EndTime: now, CooperativeWin: &won, Players: []models.GameRoundPlayer{
+			{MembershipID: alice.ID}, {MembershipID: bob.ID, IsModerator: true}}},
+	}
+
+	standings := CalculateStandings(ctx, rounds, []*models.LeagueMembership{alice, bob}, nil, scoringTypes, DefaultPointsConfig)
+
+	for _, standing := range standings {
+		var total int64
+		for _, round := range rounds {
+			total += RoundPoints(round, findRoundPlayer(round, &models.LeagueMembership{ID: standing.MembershipID}), scoringTypes[round.GameTypeID], DefaultPointsConfig)
+		}
+		assert.Equal(t, standing.TotalPoints, total)
+	}
+}
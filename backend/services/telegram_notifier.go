@@ -0,0 +1,528 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"html"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelegramLinkCodeTTL is how long a code for linking a Telegram chat to a league can be used
+const TelegramLinkCodeTTL = 15 * time.Minute
+
+const (
+	telegramQueueSize     = 100
+	telegramStandingsSize = 10 // Places answered to /standings
+)
+
+// TelegramConfig is the bot the league results are posted by
+type TelegramConfig struct {
+	BotToken      string
+	BotUsername   string // Without @, used for links which open the bot
+	WebhookSecret string // Secret token given to setWebhook, updates without it are rejected
+	APIURL        string // Bot API address, the Telegram one when empty
+}
+
+// TelegramConfigFromEnv reads the bot configuration from TELEGRAM_* environment variables
+func TelegramConfigFromEnv() TelegramConfig {
+	config := TelegramConfig{
+		BotToken:      os.Getenv("TELEGRAM_BOT_TOKEN"),
+		BotUsername:   strings.TrimPrefix(os.Getenv("TELEGRAM_BOT_USERNAME"), "@"),
+		WebhookSecret: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		APIURL:        os.Getenv("TELEGRAM_API_URL"),
+	}
+	if config.BotToken == "" {
+		glog.Warn("TELEGRAM_BOT_TOKEN is not set, Telegram bot will be disabled")
+	} else if config.WebhookSecret == "" {
+		glog.Warn("TELEGRAM_WEBHOOK_SECRET is not set, Telegram bot commands will be rejected")
+	}
+	return config
+}
+
+// TelegramNotifier posts results of finalized games to the Telegram chats linked to leagues and answers bot commands
+type TelegramNotifier interface {
+	ChatNotifier
+	// Enabled tells whether the bot is configured
+	Enabled() bool
+	// BotUsername returns the bot username without @, empty when it isn't configured
+	BotUsername() string
+	// CreateLinkCode creates a one-time code which links a chat to the league when sent to the bot
+	CreateLinkCode(ctx context.Context, leagueID, actorID primitive.ObjectID) (*models.TelegramLinkCode, error)
+	// ListChats returns the chats linked to the league
+	ListChats(ctx context.Context, leagueID primitive.ObjectID) ([]*models.TelegramChat, error)
+	// UnlinkChat stops posting the league results to the chat
+	UnlinkChat(ctx context.Context, leagueID, linkID, actorID primitive.ObjectID) error
+	// CheckWebhookSecret tells whether the secret token of an incoming update is the configured one
+	CheckWebhookSecret(secret string) bool
+	// HandleUpdate answers a command sent to the bot, other updates are ignored
+	HandleUpdate(ctx context.Context, update *utils.TelegramUpdate) error
+}
+
+type telegramNotifierInstance struct {
+	config        TelegramConfig
+	bot           *utils.TelegramBot
+	leagueService LeagueService
+	chatRepo      repositories.TelegramChatRepository
+	linkCodeRepo  repositories.TelegramLinkCodeRepository
+	gameTypeRepo  repositories.GameTypeRepository
+	auditService  AuditService
+	queue         *chatQueue
+}
+
+func NewTelegramNotifier(config TelegramConfig, leagueService LeagueService, chatRepo repositories.TelegramChatRepository,
+	linkCodeRepo repositories.TelegramLinkCodeRepository, gameTypeRepo repositories.GameTypeRepository, auditService AuditService) TelegramNotifier {
+	return &telegramNotifierInstance{
+		config:        config,
+		bot:           utils.NewTelegramBot(config.APIURL, config.BotToken),
+		leagueService: leagueService,
+		chatRepo:      chatRepo,
+		linkCodeRepo:  linkCodeRepo,
+		gameTypeRepo:  gameTypeRepo,
+		auditService:  auditService,
+		queue:         newChatQueue("Telegram", telegramQueueSize),
+	}
+}
+
+func (s *telegramNotifierInstance) Enabled() bool {
+	return s.config.BotToken != ""
+}
+
+func (s *telegramNotifierInstance) BotUsername() string {
+	return s.config.BotUsername
+}
+
+func (s *telegramNotifierInstance) NotifyGameFinalized(ctx context.Context, round *models.GameRound) error {
+	if !s.Enabled() || round.LeagueID.IsZero() {
+		return nil
+	}
+
+	chats, err := s.chatRepo.FindByLeague(ctx, round.LeagueID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get Telegram chats")
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+
+	league, err := s.leagueService.GetLeague(ctx, round.LeagueID)
+	if err != nil {
+		return err
+	}
+
+	result, err := buildChatGameResult(ctx, s.leagueService, s.gameTypeRepo, league, round)
+	if err != nil {
+		return err
+	}
+
+	text := TelegramGameMessage(result)
+	for _, chat := range chats {
+		chatID := chat.ChatID
+		s.queue.enqueue(fmt.Sprintf("result of %s in %s", round.Name, league.Name), func(ctx context.Context) error {
+			return s.bot.SendMessage(ctx, chatID, text)
+		})
+	}
+	return nil
+}
+
+func (s *telegramNotifierInstance) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	go s.queue.run(ctx)
+}
+
+func (s *telegramNotifierInstance) CreateLinkCode(ctx context.Context, leagueID, actorID primitive.ObjectID) (*models.TelegramLinkCode, error) {
+	if !s.Enabled() {
+		return nil, hexerr.New("Telegram bot is not configured")
+	}
+
+	code, err := generateTelegramLinkCode()
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to generate link code")
+	}
+
+	linkCode := &models.TelegramLinkCode{
+		LeagueID:  leagueID,
+		Code:      code,
+		CreatedBy: actorID,
+		ExpiresAt: time.Now().Add(TelegramLinkCodeTTL),
+	}
+	if err := s.linkCodeRepo.Create(ctx, linkCode); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create link code")
+	}
+	return linkCode, nil
+}
+
+func (s *telegramNotifierInstance) ListChats(ctx context.Context, leagueID primitive.ObjectID) ([]*models.TelegramChat, error) {
+	chats, err := s.chatRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get Telegram chats")
+	}
+	return chats, nil
+}
+
+func (s *telegramNotifierInstance) UnlinkChat(ctx context.Context, leagueID, linkID, actorID primitive.ObjectID) error {
+	chat, err := s.chatRepo.FindByID(ctx, linkID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get Telegram chat")
+	}
+	if chat == nil || chat.LeagueID != leagueID {
+		return hexerr.New("Telegram chat not found")
+	}
+
+	if err := s.chatRepo.Delete(ctx, chat.ID); err != nil {
+		return hexerr.Wrapf(err, "failed to unlink Telegram chat")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionTelegramChatUnlinked, AuditDetails{"chat": chat.Title})
+	return nil
+}
+
+func (s *telegramNotifierInstance) CheckWebhookSecret(secret string) bool {
+	return s.config.WebhookSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.config.WebhookSecret)) == 1
+}
+
+func (s *telegramNotifierInstance) HandleUpdate(ctx context.Context, update *utils.TelegramUpdate) error {
+	message := update.Message
+	if message == nil || !strings.HasPrefix(message.Text, "/") {
+		return nil
+	}
+
+	fields := strings.Fields(message.Text)
+	command, args := fields[0], fields[1:]
+	// In groups commands can be addressed to a bot as /command@bot
+	if at := strings.Index(command, "@"); at >= 0 {
+		if s.config.BotUsername != "" && !strings.EqualFold(command[at+1:], s.config.BotUsername) {
+			return nil
+		}
+		command = command[:at]
+	}
+
+	language := telegramUserLanguage(message)
+	var reply string
+	var err error
+	switch command {
+	case "/start", "/link":
+		if len(args) == 0 {
+			reply = telegramTextsFor(language).Help
+			break
+		}
+		reply, err = s.linkChat(ctx, message.Chat, args[0], language)
+	case "/standings":
+		reply, err = s.answerStandings(ctx, message.Chat.ID, strings.Join(args, " "), language)
+	case "/last":
+		reply, err = s.answerLastGame(ctx, message.Chat.ID, strings.Join(args, " "), language)
+	case "/stats":
+		if len(args) == 0 {
+			reply = telegramTextsFor(language).StatsUsage
+			break
+		}
+		reply, err = s.answerMemberStats(ctx, message.Chat.ID, args[0], strings.Join(args[1:], " "), language)
+	case "/help":
+		reply = telegramTextsFor(language).Help
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.bot.SendMessage(ctx, message.Chat.ID, reply)
+}
+
+func (s *telegramNotifierInstance) linkChat(ctx context.Context, chat utils.TelegramChat, code, language string) (string, error) {
+	linkCode, err := s.linkCodeRepo.Consume(ctx, code, time.Now())
+	if err != nil {
+		return "", hexerr.Wrapf(err, "failed to check link code")
+	}
+	if linkCode == nil {
+		return telegramTextsFor(language).InvalidCode, nil
+	}
+
+	league, err := s.leagueService.GetLeague(ctx, linkCode.LeagueID)
+	if err != nil {
+		return "", err
+	}
+	texts := telegramTextsFor(league.GetLanguage())
+
+	existing, err := s.chatRepo.FindByLeagueAndChat(ctx, league.ID, chat.ID)
+	if err != nil {
+		return "", hexerr.Wrapf(err, "failed to get Telegram chat")
+	}
+	if existing != nil {
+		return fmt.Sprintf(texts.AlreadyLinked, html.EscapeString(league.Name)), nil
+	}
+
+	link := &models.TelegramChat{
+		LeagueID: league.ID,
+		ChatID:   chat.ID,
+		Title:    chat.Name(),
+		LinkedBy: linkCode.CreatedBy,
+	}
+	if err := s.chatRepo.Create(ctx, link); err != nil {
+		return "", hexerr.Wrapf(err, "failed to link Telegram chat")
+	}
+
+	s.logAction(ctx, league.ID, linkCode.CreatedBy, AuditActionTelegramChatLinked, AuditDetails{"chat": link.Title})
+	return fmt.Sprintf(texts.Linked, html.EscapeString(league.Name)), nil
+}
+
+func (s *telegramNotifierInstance) answerStandings(ctx context.Context, chatID int64, leagueName, language string) (string, error) {
+	league, reply, err := s.findChatLeague(ctx, chatID, leagueName, language)
+	if league == nil {
+		return reply, err
+	}
+
+	standings, err := s.leagueService.GetLeagueStandings(ctx, league.ID, StandingsFilter{})
+	if err != nil {
+		return "", err
+	}
+	texts := telegramTextsFor(league.GetLanguage())
+	if len(standings) == 0 {
+		return fmt.Sprintf(texts.NoGames, html.EscapeString(league.Name)), nil
+	}
+	if len(standings) > telegramStandingsSize {
+		standings = standings[:telegramStandingsSize]
+	}
+
+	lines := []string{fmt.Sprintf("<b>%s</b> - %s", texts.Standings, html.EscapeString(league.Name))}
+	lines = append(lines, telegramEscapeLines(chatStandingLines(standings, chatTextsFor(league.GetLanguage())))...)
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *telegramNotifierInstance) answerLastGame(ctx context.Context, chatID int64, leagueName, language string) (string, error) {
+	league, reply, err := s.findChatLeague(ctx, chatID, leagueName, language)
+	if league == nil {
+		return reply, err
+	}
+
+	round, err := s.leagueService.GetLastFinishedRound(ctx, league.ID)
+	if err != nil {
+		return "", err
+	}
+	if round == nil {
+		return fmt.Sprintf(telegramTextsFor(league.GetLanguage()).NoGames, html.EscapeString(league.Name)), nil
+	}
+
+	result, err := buildChatGameResult(ctx, s.leagueService, s.gameTypeRepo, league, round)
+	if err != nil {
+		return "", err
+	}
+	return TelegramGameMessage(result), nil
+}
+
+func (s *telegramNotifierInstance) answerMemberStats(ctx context.Context, chatID int64, alias, leagueName, language string) (string, error) {
+	league, reply, err := s.findChatLeague(ctx, chatID, leagueName, language)
+	if league == nil {
+		return reply, err
+	}
+
+	standings, err := s.leagueService.GetLeagueStandings(ctx, league.ID, StandingsFilter{})
+	if err != nil {
+		return "", err
+	}
+
+	texts := telegramTextsFor(league.GetLanguage())
+	alias = strings.TrimPrefix(alias, "@")
+	i := slices.IndexFunc(standings, func(standing *LeagueStanding) bool {
+		return strings.EqualFold(standingAlias(standing), alias)
+	})
+	if i < 0 {
+		return fmt.Sprintf(texts.UnknownPlayer, html.EscapeString("@"+alias), html.EscapeString(league.Name)), nil
+	}
+
+	standing := standings[i]
+	lines := []string{
+		fmt.Sprintf("<b>%s</b> - %s", html.EscapeString(standingAlias(standing)), html.EscapeString(league.Name)),
+		fmt.Sprintf("%s: %d / %d", texts.Place, standing.Rank, len(standings)),
+		fmt.Sprintf("%s: %d", texts.Points, standing.TotalPoints),
+		fmt.Sprintf("%s: %d, %s: %d", texts.Games, standing.GamesPlayed, texts.Moderated, standing.GamesModerated),
+		fmt.Sprintf("🥇 %d  🥈 %d  🥉 %d", standing.FirstPlaceCount, standing.SecondPlaceCount, standing.ThirdPlaceCount),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// findChatLeague returns the league linked to the chat by its name or code, the name can be omitted when a single league is linked.
+// When no league is found it returns the reply explaining why.
+func (s *telegramNotifierInstance) findChatLeague(ctx context.Context, chatID int64, leagueName, language string) (*models.League, string, error) {
+	links, err := s.chatRepo.FindByChat(ctx, chatID)
+	if err != nil {
+		return nil, "", hexerr.Wrapf(err, "failed to get Telegram chat")
+	}
+
+	texts := telegramTextsFor(language)
+	if len(links) == 0 {
+		return nil, texts.NotLinked, nil
+	}
+
+	leagues := make([]*models.League, 0, len(links))
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		league, err := s.leagueService.GetLeague(ctx, link.LeagueID)
+		if err != nil {
+			return nil, "", err
+		}
+		leagues = append(leagues, league)
+		names = append(names, html.EscapeString(league.Name))
+	}
+
+	leagueName = strings.TrimSpace(leagueName)
+	if leagueName == "" {
+		if len(leagues) == 1 {
+			return leagues[0], "", nil
+		}
+		return nil, fmt.Sprintf(texts.ChooseLeague, strings.Join(names, ", ")), nil
+	}
+
+	for _, league := range leagues {
+		if strings.EqualFold(league.Name, leagueName) || utils.IdToCode(league.ID) == leagueName {
+			return league, "", nil
+		}
+	}
+	return nil, fmt.Sprintf(texts.UnknownLeague, html.EscapeString(leagueName), strings.Join(names, ", ")), nil
+}
+
+func (s *telegramNotifierInstance) logAction(ctx context.Context, leagueID, actorID primitive.ObjectID, action AuditAction, details AuditDetails) {
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, action, AuditTargetLeague, leagueID, details); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+	}
+}
+
+// TelegramGameMessage formats the game result as an HTML Telegram message in the league language
+func TelegramGameMessage(result *ChatGameResult) string {
+	texts := chatTextsFor(result.League.GetLanguage())
+
+	lines := []string{"<b>" + html.EscapeString(result.Title()) + "</b>"}
+	lines = append(lines, telegramEscapeLines(chatResultLines(result, texts))...)
+	if standings := chatStandingLines(result.TopStandings, texts); len(standings) > 0 {
+		lines = append(lines, "", "<b>"+texts.Standings+"</b>")
+		lines = append(lines, telegramEscapeLines(standings)...)
+	}
+	lines = append(lines, "", "<i>"+html.EscapeString(result.League.Name)+"</i>")
+	return strings.Join(lines, "\n")
+}
+
+func telegramEscapeLines(lines []string) []string {
+	escaped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		escaped = append(escaped, html.EscapeString(line))
+	}
+	return escaped
+}
+
+// telegramUserLanguage returns the language of the user who sent the message if the app has it, English otherwise
+func telegramUserLanguage(message *utils.TelegramMessage) string {
+	if message.From != nil {
+		// Telegram sends IETF tags like en-US
+		language, _, _ := strings.Cut(strings.ToLower(message.From.LanguageCode), "-")
+		if slices.Contains(models.LeagueLanguages, language) {
+			return language
+		}
+	}
+	return models.DefaultLeagueLanguage
+}
+
+// generateTelegramLinkCode generates a short random code, easy to type in a chat
+func generateTelegramLinkCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return utils.EncodeBase58(b), nil
+}
+
+// telegramTexts are the bot replies in one language
+type telegramTexts struct {
+	Help          string
+	Linked        string // Has the league name placeholder
+	AlreadyLinked string // Has the league name placeholder
+	InvalidCode   string
+	NotLinked     string
+	ChooseLeague  string // Has the linked league names placeholder
+	UnknownLeague string // Has the asked league and the linked league names placeholders
+	NoGames       string // Has the league name placeholder
+	StatsUsage    string
+	UnknownPlayer string // Has the alias and the league name placeholders
+	Standings     string
+	Place         string
+	Points        string
+	Games         string
+	Moderated     string
+}
+
+var telegramTranslations = map[string]telegramTexts{
+	"en": {
+		Help: "Commands:\n/standings [league] - league standings\n/last [league] - the last finished game\n" +
+			"/stats @alias [league] - player statistics\n/link code - link this chat to a league, the code is in the league settings",
+		Linked:        "This chat is linked to %s, game results will be posted here",
+		AlreadyLinked: "This chat is already linked to %s",
+		InvalidCode:   "The code is invalid or expired, generate a new one in the league settings",
+		NotLinked:     "This chat isn't linked to a league yet. Generate a code in the league settings and send /link code",
+		ChooseLeague:  "Several leagues are linked to this chat, add the league name: %s",
+		UnknownLeague: "League %s isn't linked to this chat, linked leagues: %s",
+		NoGames:       "No finished games in %s yet",
+		StatsUsage:    "Send /stats @alias",
+		UnknownPlayer: "No player %s in %s",
+		Standings:     "Standings",
+		Place:         "Place",
+		Points:        "Points",
+		Games:         "Games",
+		Moderated:     "moderated",
+	},
+	"uk": {
+		Help: "Команди:\n/standings [ліга] - рейтинг ліги\n/last [ліга] - остання завершена гра\n" +
+			"/stats @псевдонім [ліга] - статистика гравця\n/link код - прив'язати цей чат до ліги, код є в налаштуваннях ліги",
+		Linked:        "Чат прив'язано до ліги %s, сюди надходитимуть результати ігор",
+		AlreadyLinked: "Чат уже прив'язано до ліги %s",
+		InvalidCode:   "Код недійсний або прострочений, створіть новий у налаштуваннях ліги",
+		NotLinked:     "Чат ще не прив'язано до ліги. Створіть код у налаштуваннях ліги і надішліть /link код",
+		ChooseLeague:  "До чату прив'язано кілька ліг, додайте назву ліги: %s",
+		UnknownLeague: "Ліги %s немає серед прив'язаних до чату: %s",
+		NoGames:       "У лізі %s ще немає завершених ігор",
+		StatsUsage:    "Надішліть /stats @псевдонім",
+		UnknownPlayer: "Гравця %s немає в лізі %s",
+		Standings:     "Рейтинг",
+		Place:         "Місце",
+		Points:        "Очки",
+		Games:         "Ігор",
+		Moderated:     "модерував",
+	},
+	"et": {
+		Help: "Käsud:\n/standings [liiga] - liiga edetabel\n/last [liiga] - viimane lõpetatud mäng\n" +
+			"/stats @hüüdnimi [liiga] - mängija statistika\n/link kood - seo see vestlus liigaga, kood on liiga seadetes",
+		Linked:        "Vestlus on seotud liigaga %s, siia postitatakse mängude tulemused",
+		AlreadyLinked: "Vestlus on juba seotud liigaga %s",
+		InvalidCode:   "Kood on vale või aegunud, loo liiga seadetes uus",
+		NotLinked:     "Vestlus pole veel liigaga seotud. Loo liiga seadetes kood ja saada /link kood",
+		ChooseLeague:  "Vestlusega on seotud mitu liigat, lisa liiga nimi: %s",
+		UnknownLeague: "Liiga %s pole selle vestlusega seotud, seotud liigad: %s",
+		NoGames:       "Liigas %s pole veel lõpetatud mänge",
+		StatsUsage:    "Saada /stats @hüüdnimi",
+		UnknownPlayer: "Liigas %[2]s pole mängijat %[1]s",
+		Standings:     "Edetabel",
+		Place:         "Koht",
+		Points:        "Punktid",
+		Games:         "Mänge",
+		Moderated:     "modereeritud",
+	},
+}
+
+// telegramTextsFor returns the bot replies in the language, English for unknown languages
+func telegramTextsFor(language string) telegramTexts {
+	if texts, ok := telegramTranslations[language]; ok {
+		return texts
+	}
+	return telegramTranslations[models.DefaultLeagueLanguage]
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const telegramTestToken = "123:token"
+
+// telegramSentMessage is a sendMessage call received by the stubbed Bot API
+type telegramSentMessage struct {
+	ChatID    int64  `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+// telegramBotAPI is a stubbed Telegram Bot API which records sent messages, the first failures calls fail
+type telegramBotAPI struct {
+	mu       sync.Mutex
+	failures int
+	messages []telegramSentMessage
+	received chan struct{}
+}
+
+func newTelegramBotAPI(t *testing.T, failures int) (*telegramBotAPI, *httptest.Server) {
+	api := &telegramBotAPI{failures: failures, received: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		if r.URL.Path != "/bot"+telegramTestToken+"/sendMessage" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+			return
+		}
+		if api.failures > 0 {
+			api.failures--
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Too Many Requests: retry after 1"}`))
+			return
+		}
+
+		var message telegramSentMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		api.messages = append(api.messages, message)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+		api.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+// sent returns the messages sent so far and forgets them
+func (api *telegramBotAPI) sent() []telegramSentMessage {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	messages := api.messages
+	api.messages = nil
+	return messages
+}
+
+func (api *telegramBotAPI) wait(t *testing.T, count int) bool {
+	for i := 0; i < count; i++ {
+		select {
+		case <-api.received:
+		case <-time.After(2 * time.Second):
+			t.Errorf("%d of %d messages were sent to Telegram", i, count)
+			return false
+		}
+	}
+	return true
+}
+
+type telegramFixture struct {
+	api          *telegramBotAPI
+	notifier     TelegramNotifier
+	league       *chatLeagueService
+	round        *models.GameRound
+	chatRepo     *mocks.MockTelegramChatRepository
+	linkCodeRepo *mocks.MockTelegramLinkCodeRepository
+}
+
+func newTelegramFixture(t *testing.T, failures int) *telegramFixture {
+	api, server := newTelegramBotAPI(t, failures)
+	leagueService, round, gameTypeRepo := newChatFixture("")
+	leagueService.lastRound = round
+	fixture := &telegramFixture{
+		api:          api,
+		league:       leagueService,
+		round:        round,
+		chatRepo:     new(mocks.MockTelegramChatRepository),
+		linkCodeRepo: new(mocks.MockTelegramLinkCodeRepository),
+	}
+	fixture.notifier = NewTelegramNotifier(TelegramConfig{BotToken: telegramTestToken, BotUsername: "bgl_bot", WebhookSecret: "hook-secret", APIURL: server.URL},
+		leagueService, fixture.chatRepo, fixture.linkCodeRepo, gameTypeRepo, NewNoopAuditService())
+	return fixture
+}
+
+func telegramCommand(chatID int64, text string) *utils.TelegramUpdate {
+	return &utils.TelegramUpdate{UpdateID: 1, Message: &utils.TelegramMessage{
+		MessageID: 1,
+		From:      &utils.TelegramUser{ID: 7, LanguageCode: "en-GB"},
+		Chat:      utils.TelegramChat{ID: chatID, Type: "group", Title: "Board gamers"},
+		Text:      text,
+	}}
+}
+
+func TestTelegramNotifier_LinksChatWithOneTimeCode(t *testing.T) {
+	ctx := context.Background()
+	f := newTelegramFixture(t, 0)
+	adminID := primitive.NewObjectID()
+	f.linkCodeRepo.On("Consume", ctx, "abc123", mock.AnythingOfType("time.Time")).
+		Return(&models.TelegramLinkCode{LeagueID: f.league.league.ID, Code: "abc123", CreatedBy: adminID}, nil).Once()
+	f.linkCodeRepo.On("Consume", ctx, "abc123", mock.AnythingOfType("time.Time")).Return(nil, nil)
+	f.chatRepo.On("FindByLeagueAndChat", ctx, f.league.league.ID, int64(-100)).Return(nil, nil)
+	f.chatRepo.On("Create", ctx, mock.AnythingOfType("*models.TelegramChat")).Return(nil)
+
+	if !assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/link@bgl_bot abc123"))) {
+		return
+	}
+	f.chatRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(chat *models.TelegramChat) bool {
+		return chat.LeagueID == f.league.league.ID && chat.ChatID == -100 && chat.Title == "Board gamers" && chat.LinkedBy == adminID
+	}))
+
+	// The code can't be used again
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-200, "/link abc123")))
+
+	messages := f.api.sent()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, int64(-100), messages[0].ChatID)
+		assert.Equal(t, "HTML", messages[0].ParseMode)
+		// Replies about the league are in the league language, others in the language of the user
+		assert.Equal(t, "Чат прив'язано до ліги Friday club, сюди надходитимуть результати ігор", messages[0].Text)
+		assert.Equal(t, int64(-200), messages[1].ChatID)
+		assert.Equal(t, telegramTranslations["en"].InvalidCode, messages[1].Text)
+	}
+	f.chatRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestTelegramNotifier_AnswersCommands(t *testing.T) {
+	ctx := context.Background()
+	f := newTelegramFixture(t, 0)
+	f.chatRepo.On("FindByChat", ctx, int64(-100)).Return([]*models.TelegramChat{{LeagueID: f.league.league.ID, ChatID: -100}}, nil)
+	f.chatRepo.On("FindByChat", ctx, int64(-200)).Return(nil, nil)
+
+	for _, command := range []string{"/standings", "/last", "/stats @bob", "/stats @nobody", "/standings Other league", "/help"} {
+		assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, command)), command)
+	}
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-200, "/last")))
+	// Other bots' commands and plain messages are ignored
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/standings@other_bot")))
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "good game")))
+
+	messages := f.api.sent()
+	if !assert.Len(t, messages, 7) {
+		return
+	}
+	assert.Equal(t, "<b>Рейтинг</b> - Friday club\n1. Alice - 42 оч.\n2. Bob - 30 оч.", messages[0].Text)
+	assert.Equal(t, "<b>Мафія: Round 7</b>\n🥇 Alice - 12\n🥈 Bob - 8\nCarol - Модератор\n\n"+
+		"<b>Рейтинг (топ-5)</b>\n1. Alice - 42 оч.\n2. Bob - 30 оч.\n\n<i>Friday club</i>", messages[1].Text)
+	assert.Equal(t, "<b>Bob</b> - Friday club\nМісце: 2 / 2\nОчки: 30\nІгор: 0, модерував: 0\n🥇 0  🥈 0  🥉 0", messages[2].Text)
+	assert.Equal(t, "Гравця @nobody немає в лізі Friday club", messages[3].Text)
+	assert.Equal(t, "League Other league isn't linked to this chat, linked leagues: Friday club", messages[4].Text)
+	assert.Equal(t, telegramTranslations["en"].Help, messages[5].Text)
+	assert.Equal(t, int64(-200), messages[6].ChatID)
+	assert.Equal(t, telegramTranslations["en"].NotLinked, messages[6].Text)
+}
+
+func TestTelegramNotifier_StatsShowSharedPlace(t *testing.T) {
+	ctx := context.Background()
+	f := newTelegramFixture(t, 0)
+	f.league.standings = []*LeagueStanding{
+		{Rank: 1, MembershipID: primitive.NewObjectID(), UserAlias: "Alice", TotalPoints: 42},
+		{Rank: 1, MembershipID: primitive.NewObjectID(), UserAlias: "Bob", TotalPoints: 42},
+		{Rank: 3, MembershipID: primitive.NewObjectID(), UserAlias: "Carol", TotalPoints: 30},
+	}
+	f.chatRepo.On("FindByChat", ctx, int64(-100)).Return([]*models.TelegramChat{{LeagueID: f.league.league.ID, ChatID: -100}}, nil)
+
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/standings")))
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/stats @bob")))
+
+	messages := f.api.sent()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "<b>Рейтинг</b> - Friday club\n1. Alice - 42 оч.\n1. Bob - 42 оч.\n3. Carol - 30 оч.", messages[0].Text)
+		assert.Contains(t, messages[1].Text, "\nМісце: 1 / 3\n")
+	}
+}
+
+func TestTelegramNotifier_AsksForLeagueWhenSeveralAreLinked(t *testing.T) {
+	ctx := context.Background()
+	f := newTelegramFixture(t, 0)
+	other := &models.League{ID: primitive.NewObjectID(), Name: "Sunday <club>"}
+	f.league.others = []*models.League{other}
+	f.chatRepo.On("FindByChat", ctx, int64(-100)).Return([]*models.TelegramChat{
+		{LeagueID: f.league.league.ID, ChatID: -100},
+		{LeagueID: other.ID, ChatID: -100},
+	}, nil)
+
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/standings")))
+	assert.NoError(t, f.notifier.HandleUpdate(ctx, telegramCommand(-100, "/standings friday CLUB")))
+
+	messages := f.api.sent()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "Several leagues are linked to this chat, add the league name: Friday club, Sunday &lt;club&gt;", messages[0].Text)
+		assert.Contains(t, messages[1].Text, "1. Alice - 42 оч.")
+	}
+}
+
+func TestTelegramNotifier_PostsFinalizedGameToLinkedChats(t *testing.T) {
+	f := newTelegramFixture(t, 1)
+	f.chatRepo.On("FindByLeague", mock.Anything, f.league.league.ID).Return([]*models.TelegramChat{
+		{LeagueID: f.league.league.ID, ChatID: -100},
+		{LeagueID: f.league.league.ID, ChatID: -200},
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.notifier.(*telegramNotifierInstance).queue.retryDelays = []time.Duration{time.Millisecond}
+	f.notifier.Start(ctx)
+
+	// The first post fails and is retried after the second one
+	assert.NoError(t, f.notifier.NotifyGameFinalized(ctx, f.round))
+	if !f.api.wait(t, 2) {
+		return
+	}
+
+	messages := f.api.sent()
+	if assert.Len(t, messages, 2) {
+		assert.ElementsMatch(t, []int64{-100, -200}, []int64{messages[0].ChatID, messages[1].ChatID})
+		assert.Contains(t, messages[0].Text, "<b>Мафія: Round 7</b>")
+	}
+}
+
+func TestTelegramNotifier_DisabledWithoutToken(t *testing.T) {
+	leagueService, round, gameTypeRepo := newChatFixture("")
+	chatRepo := new(mocks.MockTelegramChatRepository)
+	notifier := NewTelegramNotifier(TelegramConfig{}, leagueService, chatRepo, new(mocks.MockTelegramLinkCodeRepository), gameTypeRepo, NewNoopAuditService())
+
+	assert.False(t, notifier.Enabled())
+	assert.NoError(t, notifier.NotifyGameFinalized(context.Background(), round))
+	chatRepo.AssertNotCalled(t, "FindByLeague", mock.Anything, mock.Anything)
+	_, err := notifier.CreateLinkCode(context.Background(), round.LeagueID, primitive.NewObjectID())
+	assert.Error(t, err)
+}
+
+func TestTelegramNotifier_CheckWebhookSecret(t *testing.T) {
+	f := newTelegramFixture(t, 0)
+	assert.True(t, f.notifier.CheckWebhookSecret("hook-secret"))
+	assert.False(t, f.notifier.CheckWebhookSecret("guess"))
+	assert.False(t, f.notifier.CheckWebhookSecret(""))
+
+	// Without a configured secret nobody can talk to the bot
+	leagueService, _, gameTypeRepo := newChatFixture("")
+	notifier := NewTelegramNotifier(TelegramConfig{BotToken: telegramTestToken}, leagueService, new(mocks.MockTelegramChatRepository),
+		new(mocks.MockTelegramLinkCodeRepository), gameTypeRepo, NewNoopAuditService())
+	assert.False(t, notifier.CheckWebhookSecret(""))
+}
@@ -0,0 +1,645 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/andriyg76/glog"
+	"github.com/andriyg76/hexerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Headers of webhook requests, the signature is "sha256=" and hex HMAC-SHA256 of "{timestamp}.{body}" keyed by the subscription secret
+const (
+	WebhookSignatureHeader = "X-BGL-Signature"
+	WebhookTimestampHeader = "X-BGL-Timestamp"
+	WebhookEventHeader     = "X-BGL-Event"
+	WebhookDeliveryHeader  = "X-BGL-Delivery"
+)
+
+const (
+	// WebhookMaxAttempts is how many times a delivery is tried before it's marked as failed
+	WebhookMaxAttempts = 6
+	// WebhookRetryDelay is the delay after the first failed attempt, it doubles after every next one
+	WebhookRetryDelay = time.Minute
+
+	webhookRequestTimeout = 10 * time.Second
+	webhookBatchSize      = 50
+)
+
+// WebhookInput holds the editable fields of a webhook subscription
+type WebhookInput struct {
+	URL         string
+	Description string
+	Events      []models.WebhookEventType
+	Active      *bool // nil keeps the current state, new subscriptions are active
+}
+
+// WebhookEvent is the JSON body of webhook requests
+type WebhookEvent struct {
+	ID         string                  `json:"id"`
+	Type       models.WebhookEventType `json:"type"`
+	LeagueCode string                  `json:"league_code"`
+	CreatedAt  time.Time               `json:"created_at"`
+	Data       interface{}             `json:"data"`
+}
+
+// WebhookPlayer is a player of a finalized game round in the webhook payload
+type WebhookPlayer struct {
+	MembershipCode string `json:"membership_code"`
+	Alias          string `json:"alias"`
+	Position       int    `json:"position,omitempty"`
+	Score          int64  `json:"score"`
+	Team           string `json:"team,omitempty"`
+	IsModerator    bool   `json:"is_moderator,omitempty"`
+}
+
+// WebhookGameFinalized is the data of the game.finalized event
+type WebhookGameFinalized struct {
+	RoundCode string          `json:"round_code"`
+	Name      string          `json:"name"`
+	GameType  string          `json:"game_type"`
+	StartTime time.Time       `json:"start_time"`
+	EndTime   time.Time       `json:"end_time"`
+	NightCode string          `json:"night_code,omitempty"`
+	Players   []WebhookPlayer `json:"players"`
+}
+
+// WebhookMemberJoined is the data of the member.joined event
+type WebhookMemberJoined struct {
+	MembershipCode string    `json:"membership_code"`
+	Alias          string    `json:"alias"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// WebhookStanding is one place of the league standings in the webhook payload
+type WebhookStanding struct {
+	Rank           int    `json:"rank"`
+	MembershipCode string `json:"membership_code"`
+	Alias          string `json:"alias"`
+	TotalPoints    int64  `json:"total_points"`
+	GamesPlayed    int    `json:"games_played"`
+}
+
+// WebhookStandingsChanged is the data of the standings.changed event
+type WebhookStandingsChanged struct {
+	RoundCode string            `json:"round_code"` // The finalized round which changed the standings
+	Standings []WebhookStanding `json:"standings"`
+}
+
+// WebhookService manages outbound league webhooks and delivers league events to them
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, leagueID, actorID primitive.ObjectID, input WebhookInput) (*models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, leagueID, subscriptionID primitive.ObjectID) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, leagueID primitive.ObjectID) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, leagueID, subscriptionID, actorID primitive.ObjectID, input WebhookInput) (*models.WebhookSubscription, error)
+	// DeleteSubscription removes the subscription with its delivery log
+	DeleteSubscription(ctx context.Context, leagueID, subscriptionID, actorID primitive.ObjectID) error
+	// ListDeliveries returns up to limit latest deliveries of the subscription
+	ListDeliveries(ctx context.Context, leagueID, subscriptionID primitive.ObjectID, limit int64) ([]*models.WebhookDelivery, error)
+	// SendTestEvent sends a webhook.test event right away, once, and returns the logged delivery
+	SendTestEvent(ctx context.Context, leagueID, subscriptionID primitive.ObjectID) (*models.WebhookDelivery, error)
+
+	// PublishGameFinalized queues the game.finalized event for the league subscriptions
+	PublishGameFinalized(ctx context.Context, round *models.GameRound) error
+	// PublishMemberJoined queues the member.joined event for the league subscriptions
+	PublishMemberJoined(ctx context.Context, membership *models.LeagueMembership) error
+	// PublishStandingsChanged queues the standings.changed event for the league subscriptions
+	PublishStandingsChanged(ctx context.Context, leagueID, gameRoundID primitive.ObjectID, standings []models.FrozenStanding) error
+
+	// DeliverDue makes the attempts which are due now, returns the number of attempts made
+	DeliverDue(ctx context.Context) (int, error)
+	// Start delivers queued events in background until the context is done, checking for retries every interval
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type webhookServiceInstance struct {
+	subscriptionRepo repositories.WebhookSubscriptionRepository
+	deliveryRepo     repositories.WebhookDeliveryRepository
+	membershipRepo   repositories.LeagueMembershipRepository
+	gameTypeRepo     repositories.GameTypeRepository
+	auditService     AuditService
+	client           *http.Client
+	lookupIP         func(ctx context.Context, network, host string) ([]net.IP, error)
+	wakeup           chan struct{} // Signals the delivery loop that new events were queued
+}
+
+func NewWebhookService(subscriptionRepo repositories.WebhookSubscriptionRepository, deliveryRepo repositories.WebhookDeliveryRepository,
+	membershipRepo repositories.LeagueMembershipRepository, gameTypeRepo repositories.GameTypeRepository, auditService AuditService) WebhookService {
+	return &webhookServiceInstance{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		membershipRepo:   membershipRepo,
+		gameTypeRepo:     gameTypeRepo,
+		auditService:     auditService,
+		client:           newWebhookClient(),
+		lookupIP:         net.DefaultResolver.LookupIP,
+		wakeup:           make(chan struct{}, 1),
+	}
+}
+
+// SignWebhookPayload returns the signature header value of the webhook request body sent at the timestamp (Unix seconds)
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRetryDelayAfter returns the delay before the next attempt after the given number of failed attempts
+func WebhookRetryDelayAfter(failedAttempts int) time.Duration {
+	return WebhookRetryDelay << (failedAttempts - 1)
+}
+
+func (s *webhookServiceInstance) CreateSubscription(ctx context.Context, leagueID, actorID primitive.ObjectID, input WebhookInput) (*models.WebhookSubscription, error) {
+	secret, err := generateInvitationToken()
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to generate webhook secret")
+	}
+
+	subscription := &models.WebhookSubscription{
+		LeagueID:  leagueID,
+		Secret:    secret,
+		Active:    true,
+		CreatedBy: actorID,
+	}
+	if err := s.applyWebhookInput(ctx, subscription, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to create webhook")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionWebhookCreated, subscription.ID, AuditDetails{"url": subscription.URL})
+
+	return subscription, nil
+}
+
+func (s *webhookServiceInstance) GetSubscription(ctx context.Context, leagueID, subscriptionID primitive.ObjectID) (*models.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get webhook")
+	}
+	if subscription == nil || subscription.LeagueID != leagueID {
+		return nil, hexerr.New("webhook not found")
+	}
+	return subscription, nil
+}
+
+func (s *webhookServiceInstance) ListSubscriptions(ctx context.Context, leagueID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	subscriptions, err := s.subscriptionRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list webhooks")
+	}
+	return subscriptions, nil
+}
+
+func (s *webhookServiceInstance) UpdateSubscription(ctx context.Context, leagueID, subscriptionID, actorID primitive.ObjectID, input WebhookInput) (*models.WebhookSubscription, error) {
+	subscription, err := s.GetSubscription(ctx, leagueID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyWebhookInput(ctx, subscription, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to update webhook")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionWebhookUpdated, subscription.ID, AuditDetails{
+		"url":    subscription.URL,
+		"active": subscription.Active,
+	})
+
+	return subscription, nil
+}
+
+func (s *webhookServiceInstance) DeleteSubscription(ctx context.Context, leagueID, subscriptionID, actorID primitive.ObjectID) error {
+	subscription, err := s.GetSubscription(ctx, leagueID, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.subscriptionRepo.Delete(ctx, subscription.ID); err != nil {
+		return hexerr.Wrapf(err, "failed to delete webhook")
+	}
+	if err := s.deliveryRepo.DeleteBySubscription(ctx, subscription.ID); err != nil {
+		return hexerr.Wrapf(err, "failed to delete webhook deliveries")
+	}
+
+	s.logAction(ctx, leagueID, actorID, AuditActionWebhookDeleted, subscription.ID, AuditDetails{"url": subscription.URL})
+
+	return nil
+}
+
+func (s *webhookServiceInstance) ListDeliveries(ctx context.Context, leagueID, subscriptionID primitive.ObjectID, limit int64) ([]*models.WebhookDelivery, error) {
+	subscription, err := s.GetSubscription(ctx, leagueID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryRepo.FindBySubscription(ctx, subscription.ID, limit)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to list webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+func (s *webhookServiceInstance) SendTestEvent(ctx context.Context, leagueID, subscriptionID primitive.ObjectID) (*models.WebhookDelivery, error) {
+	subscription, err := s.GetSubscription(ctx, leagueID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := newWebhookDelivery(subscription, newWebhookEvent(leagueID, models.WebhookEventTest, map[string]string{
+		"message": "Test event from Board Games League",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	// Test events are not retried, the delivery is logged with the result of the only attempt
+	delivery.ID = primitive.NewObjectID()
+	s.recordAttempt(delivery, s.send(ctx, subscription, delivery), 1)
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, hexerr.Wrapf(err, "failed to log webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+func (s *webhookServiceInstance) PublishGameFinalized(ctx context.Context, round *models.GameRound) error {
+	if round.LeagueID.IsZero() {
+		return nil
+	}
+
+	return s.publish(ctx, round.LeagueID, models.WebhookEventGameFinalized, func() (interface{}, error) {
+		aliases, err := s.memberAliases(ctx, round.LeagueID)
+		if err != nil {
+			return nil, err
+		}
+
+		var gameTypeKey string
+		if gameType, err := s.gameTypeRepo.FindByID(ctx, round.GameTypeID); err != nil {
+			return nil, hexerr.Wrapf(err, "failed to get game type")
+		} else if gameType != nil {
+			gameTypeKey = gameType.Key
+		}
+
+		data := WebhookGameFinalized{
+			RoundCode: utils.IdToCode(round.ID),
+			Name:      round.Name,
+			GameType:  gameTypeKey,
+			StartTime: round.StartTime,
+			EndTime:   round.EndTime,
+			Players:   make([]WebhookPlayer, 0, len(round.Players)),
+		}
+		if !round.NightID.IsZero() {
+			data.NightCode = utils.IdToCode(round.NightID)
+		}
+		for _, player := range round.Players {
+			data.Players = append(data.Players, WebhookPlayer{
+				MembershipCode: utils.IdToCode(player.MembershipID),
+				Alias:          aliases[player.MembershipID],
+				Position:       player.Position,
+				Score:          player.Score,
+				Team:           player.TeamName,
+				IsModerator:    player.IsModerator,
+			})
+		}
+		return data, nil
+	})
+}
+
+func (s *webhookServiceInstance) PublishMemberJoined(ctx context.Context, membership *models.LeagueMembership) error {
+	return s.publish(ctx, membership.LeagueID, models.WebhookEventMemberJoined, func() (interface{}, error) {
+		return WebhookMemberJoined{
+			MembershipCode: utils.IdToCode(membership.ID),
+			Alias:          membership.Alias,
+			JoinedAt:       membership.JoinedAt,
+		}, nil
+	})
+}
+
+func (s *webhookServiceInstance) PublishStandingsChanged(ctx context.Context, leagueID, gameRoundID primitive.ObjectID, standings []models.FrozenStanding) error {
+	return s.publish(ctx, leagueID, models.WebhookEventStandingsChanged, func() (interface{}, error) {
+		data := WebhookStandingsChanged{
+			RoundCode: utils.IdToCode(gameRoundID),
+			Standings: make([]WebhookStanding, 0, len(standings)),
+		}
+		for _, standing := range standings {
+			data.Standings = append(data.Standings, WebhookStanding{
+				Rank:           standing.Rank,
+				MembershipCode: utils.IdToCode(standing.MembershipID),
+				Alias:          standing.UserName,
+				TotalPoints:    standing.TotalPoints,
+				GamesPlayed:    standing.GamesPlayed,
+			})
+		}
+		return data, nil
+	})
+}
+
+// publish queues the event for every active league subscription asking for it,
+// the event data is built only when there is such a subscription
+func (s *webhookServiceInstance) publish(ctx context.Context, leagueID primitive.ObjectID, eventType models.WebhookEventType, buildData func() (interface{}, error)) error {
+	subscriptions, err := s.subscriptionRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return hexerr.Wrapf(err, "failed to get league webhooks")
+	}
+
+	var receivers []*models.WebhookSubscription
+	for _, subscription := range subscriptions {
+		if subscription.Active && subscription.Wants(eventType) {
+			receivers = append(receivers, subscription)
+		}
+	}
+	if len(receivers) == 0 {
+		return nil
+	}
+
+	data, err := buildData()
+	if err != nil {
+		return err
+	}
+	event := newWebhookEvent(leagueID, eventType, data)
+
+	for _, subscription := range receivers {
+		delivery, err := newWebhookDelivery(subscription, event)
+		if err != nil {
+			return err
+		}
+		delivery.NextAttemptAt = time.Now()
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			return hexerr.Wrapf(err, "failed to queue webhook delivery")
+		}
+	}
+
+	// Don't block when the delivery loop is already signalled
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *webhookServiceInstance) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.deliveryRepo.FindDue(ctx, time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, hexerr.Wrapf(err, "failed to get due webhook deliveries")
+	}
+
+	subscriptions := make(map[primitive.ObjectID]*models.WebhookSubscription)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			if subscription, err = s.subscriptionRepo.FindByID(ctx, delivery.SubscriptionID); err != nil {
+				return 0, hexerr.Wrapf(err, "failed to get webhook")
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+
+			if subscription == nil || !subscription.Active {
+				// The subscription was switched off after the event was queued
+				s.recordAttempt(delivery, models.WebhookAttempt{At: time.Now(), Error: "webhook is disabled"}, 1)
+			} else {
+				s.recordAttempt(delivery, s.send(ctx, subscription, delivery), WebhookMaxAttempts)
+			}
+			if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+				glog.Warn("Failed to update webhook delivery %s: %v", delivery.ID.Hex(), err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (s *webhookServiceInstance) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Keep going while full batches come back, the rest waits for the next tick
+			for {
+				attempts, err := s.DeliverDue(ctx)
+				if err != nil {
+					glog.Warn("Failed to deliver webhooks: %v", err)
+				}
+				if err != nil || attempts < webhookBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.wakeup:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// send posts the delivery payload to the subscription URL once
+func (s *webhookServiceInstance) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) models.WebhookAttempt {
+	attempt := models.WebhookAttempt{At: time.Now()}
+	defer func() {
+		attempt.DurationMs = time.Since(attempt.At).Milliseconds()
+	}()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	timestamp := strconv.FormatInt(attempt.At.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "BGL-Webhooks/1")
+	request.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	request.Header.Set(WebhookDeliveryHeader, utils.IdToCode(delivery.ID))
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, timestamp, []byte(delivery.Payload)))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer response.Body.Close()
+	// Drain the body, so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	attempt.StatusCode = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("unexpected status code %d", response.StatusCode)
+	}
+	return attempt
+}
+
+// recordAttempt adds the attempt to the delivery log and decides whether and when the delivery is retried
+func (s *webhookServiceInstance) recordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt, maxAttempts int) {
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.NextAttemptAt = time.Time{}
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = models.WebhookDeliverySucceeded
+	case len(delivery.Attempts) >= maxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+	default:
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = attempt.At.Add(WebhookRetryDelayAfter(len(delivery.Attempts)))
+	}
+}
+
+func (s *webhookServiceInstance) memberAliases(ctx context.Context, leagueID primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	memberships, err := s.membershipRepo.FindByLeague(ctx, leagueID)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to get league members")
+	}
+
+	aliases := make(map[primitive.ObjectID]string, len(memberships))
+	for _, membership := range memberships {
+		aliases[membership.ID] = membership.Alias
+	}
+	return aliases, nil
+}
+
+func (s *webhookServiceInstance) logAction(ctx context.Context, leagueID, actorID primitive.ObjectID, action AuditAction, subscriptionID primitive.ObjectID, details AuditDetails) {
+	if err := s.auditService.LogAction(ctx, leagueID, actorID, action, AuditTargetWebhook, subscriptionID, details); err != nil {
+		glog.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+	}
+}
+
+func newWebhookEvent(leagueID primitive.ObjectID, eventType models.WebhookEventType, data interface{}) WebhookEvent {
+	return WebhookEvent{
+		ID:         utils.IdToCode(primitive.NewObjectID()),
+		Type:       eventType,
+		LeagueCode: utils.IdToCode(leagueID),
+		CreatedAt:  time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+func newWebhookDelivery(subscription *models.WebhookSubscription, event WebhookEvent) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, hexerr.Wrapf(err, "failed to encode webhook event")
+	}
+
+	return &models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		LeagueID:       subscription.LeagueID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        string(payload),
+		Status:         models.WebhookDeliveryPending,
+	}, nil
+}
+
+// applyWebhookInput validates the input and copies it into the subscription
+func (s *webhookServiceInstance) applyWebhookInput(ctx context.Context, subscription *models.WebhookSubscription, input WebhookInput) error {
+	target, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || target.Scheme != "https" || target.Hostname() == "" {
+		return hexerr.New("webhook URL must be an absolute https URL")
+	}
+	if err := s.checkWebhookHost(ctx, target.Hostname()); err != nil {
+		return err
+	}
+	if len(input.Events) == 0 {
+		return hexerr.New("webhook needs at least one event type")
+	}
+
+	events := make([]models.WebhookEventType, 0, len(input.Events))
+	for _, eventType := range input.Events {
+		if !eventType.IsValid() {
+			return hexerr.New("unknown webhook event type " + string(eventType))
+		}
+		if !slices.Contains(events, eventType) {
+			events = append(events, eventType)
+		}
+	}
+
+	subscription.URL = target.String()
+	subscription.Description = strings.TrimSpace(input.Description)
+	subscription.Events = events
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+	return nil
+}
+
+// checkWebhookHost resolves the host and rejects it when any of its addresses is internal,
+// the addresses are checked once more when connecting, in case the DNS answer changes
+func (s *webhookServiceInstance) checkWebhookHost(ctx context.Context, host string) error {
+	ips, err := s.lookupIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return hexerr.New("webhook host " + host + " can't be resolved")
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return hexerr.New("webhook URL must point to a public address")
+		}
+	}
+	return nil
+}
+
+// isPublicIP tells whether the address is outside of the loopback, private, link-local and unspecified ranges,
+// link-local includes the cloud metadata address 169.254.169.254
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsUnspecified()
+}
+
+// newWebhookClient returns the HTTP client of webhook requests, which refuses to connect to internal addresses
+// and doesn't use a proxy, so that league admins can't make the server call hosts of its own network
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return hexerr.New("webhook connection to internal address " + host + " refused")
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhookRequestTimeout, Transport: transport}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/repositories/mocks"
+	"github.com/andriyg76/bgl/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// webhookReceiver is a local webhook endpoint answering with the queued status codes, 200 once they run out
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, body)
+	status := http.StatusOK
+	if len(rcv.statuses) > 0 {
+		status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// allowLocalWebhooks lets the service deliver to the local test server, which the real client refuses to connect to
+func allowLocalWebhooks(service WebhookService, server *httptest.Server) {
+	service.(*webhookServiceInstance).client = server.Client()
+}
+
+func TestWebhookDelivery_SignedGameFinalizedWithRetry(t *testing.T) {
+	ctx := context.Background()
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	mockMembershipRepo := new(mocks.MockLeagueMembershipRepository)
+	mockGameTypeRepo := new(mocks.MockGameTypeRepository)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, mockMembershipRepo, mockGameTypeRepo, NewNoopAuditService())
+	allowLocalWebhooks(service, server)
+
+	leagueID := primitive.NewObjectID()
+	alice := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Alice"}
+	bob := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Bob"}
+	gameType := &models.GameType{ID: primitive.NewObjectID(), Key: "catan"}
+	subscription := &models.WebhookSubscription{
+		ID: primitive.NewObjectID(), LeagueID: leagueID, URL: server.URL, Secret: "s3cret", Active: true,
+		Events: []models.WebhookEventType{models.WebhookEventGameFinalized},
+	}
+	round := &models.GameRound{
+		ID: primitive.NewObjectID(), Name: "Friday game", LeagueID: leagueID, GameTypeID: gameType.ID,
+		Players: []models.GameRoundPlayer{{MembershipID: alice.ID, Position: 1, Score: 10}, {MembershipID: bob.ID, Position: 2, Score: 7}},
+	}
+
+	var delivery *models.WebhookDelivery
+	mockSubscriptionRepo.On("FindByLeague", ctx, leagueID).Return([]*models.WebhookSubscription{subscription}, nil)
+	mockSubscriptionRepo.On("FindByID", ctx, subscription.ID).Return(subscription, nil)
+	mockMembershipRepo.On("FindByLeague", ctx, leagueID).Return([]*models.LeagueMembership{alice, bob}, nil)
+	mockGameTypeRepo.On("FindByID", ctx, gameType.ID).Return(gameType, nil)
+	mockDeliveryRepo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		delivery = args.Get(1).(*models.WebhookDelivery)
+		delivery.ID = primitive.NewObjectID()
+	}).Return(nil).Once()
+	mockDeliveryRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	if !assert.NoError(t, service.PublishGameFinalized(ctx, round)) || !assert.NotNil(t, delivery) {
+		return
+	}
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	mockDeliveryRepo.On("FindDue", ctx, mock.Anything, int64(webhookBatchSize)).Return([]*models.WebhookDelivery{delivery}, nil)
+
+	// The receiver fails the first attempt, the delivery waits for a retry
+	attempts, err := service.DeliverDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	if assert.Len(t, delivery.Attempts, 1) {
+		assert.Equal(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+		assert.Equal(t, delivery.Attempts[0].At.Add(WebhookRetryDelay), delivery.NextAttemptAt)
+	}
+
+	_, err = service.DeliverDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Len(t, delivery.Attempts, 2)
+	assert.True(t, delivery.NextAttemptAt.IsZero())
+
+	if !assert.Len(t, receiver.requests, 2) {
+		return
+	}
+	request, body := receiver.requests[1], receiver.bodies[1]
+	assert.Equal(t, "game.finalized", request.Header.Get(WebhookEventHeader))
+	assert.Equal(t, utils.IdToCode(delivery.ID), request.Header.Get(WebhookDeliveryHeader))
+	assert.Equal(t, SignWebhookPayload("s3cret", request.Header.Get(WebhookTimestampHeader), body), request.Header.Get(WebhookSignatureHeader))
+	assert.Equal(t, receiver.bodies[0], body, "retries send the same payload")
+
+	var event struct {
+		ID         string               `json:"id"`
+		Type       string               `json:"type"`
+		LeagueCode string               `json:"league_code"`
+		Data       WebhookGameFinalized `json:"data"`
+	}
+	if assert.NoError(t, json.Unmarshal(body, &event)) {
+		assert.Equal(t, delivery.EventID, event.ID)
+		assert.Equal(t, utils.IdToCode(leagueID), event.LeagueCode)
+		assert.Equal(t, "catan", event.Data.GameType)
+		assert.Equal(t, []WebhookPlayer{
+			{MembershipCode: utils.IdToCode(alice.ID), Alias: "Alice", Position: 1, Score: 10},
+			{MembershipCode: utils.IdToCode(bob.ID), Alias: "Bob", Position: 2, Score: 7},
+		}, event.Data.Players)
+	}
+}
+
+func TestWebhookDelivery_FailsAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(&webhookReceiver{statuses: []int{http.StatusBadGateway}})
+	defer server.Close()
+
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, nil, nil, NewNoopAuditService())
+	allowLocalWebhooks(service, server)
+
+	subscription := &models.WebhookSubscription{ID: primitive.NewObjectID(), URL: server.URL, Active: true}
+	delivery := &models.WebhookDelivery{
+		ID: primitive.NewObjectID(), SubscriptionID: subscription.ID, Payload: `{}`, Status: models.WebhookDeliveryPending,
+		Attempts: make([]models.WebhookAttempt, WebhookMaxAttempts-1),
+	}
+	mockSubscriptionRepo.On("FindByID", ctx, subscription.ID).Return(subscription, nil)
+	mockDeliveryRepo.On("FindDue", ctx, mock.Anything, int64(webhookBatchSize)).Return([]*models.WebhookDelivery{delivery}, nil)
+	mockDeliveryRepo.On("Update", ctx, delivery).Return(nil).Once()
+
+	_, err := service.DeliverDue(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Len(t, delivery.Attempts, WebhookMaxAttempts)
+	assert.True(t, delivery.NextAttemptAt.IsZero())
+	mockDeliveryRepo.AssertExpectations(t)
+}
+
+func TestWebhookRetryDelayAfter_Doubles(t *testing.T) {
+	assert.Equal(t, time.Minute, WebhookRetryDelayAfter(1))
+	assert.Equal(t, 2*time.Minute, WebhookRetryDelayAfter(2))
+	assert.Equal(t, 16*time.Minute, WebhookRetryDelayAfter(5))
+}
+
+func TestPublish_OnlyActiveSubscriptionsOfTheEvent(t *testing.T) {
+	ctx := context.Background()
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, nil, nil, NewNoopAuditService())
+
+	leagueID := primitive.NewObjectID()
+	standingsOnly := &models.WebhookSubscription{ID: primitive.NewObjectID(), LeagueID: leagueID, Active: true,
+		Events: []models.WebhookEventType{models.WebhookEventStandingsChanged}}
+	disabled := &models.WebhookSubscription{ID: primitive.NewObjectID(), LeagueID: leagueID, Active: false,
+		Events: []models.WebhookEventType{models.WebhookEventMemberJoined}}
+	members := &models.WebhookSubscription{ID: primitive.NewObjectID(), LeagueID: leagueID, Active: true,
+		Events: []models.WebhookEventType{models.WebhookEventMemberJoined, models.WebhookEventGameFinalized}}
+	membership := &models.LeagueMembership{ID: primitive.NewObjectID(), LeagueID: leagueID, Alias: "Carol"}
+
+	mockSubscriptionRepo.On("FindByLeague", ctx, leagueID).Return([]*models.WebhookSubscription{standingsOnly, disabled, members}, nil)
+	mockDeliveryRepo.On("Create", ctx, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.SubscriptionID == members.ID && delivery.EventType == models.WebhookEventMemberJoined &&
+			!delivery.NextAttemptAt.IsZero()
+	})).Return(nil).Once()
+
+	err := service.PublishMemberJoined(ctx, membership)
+
+	assert.NoError(t, err)
+	mockDeliveryRepo.AssertExpectations(t)
+	mockDeliveryRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestSendTestEvent_LogsSingleAttempt(t *testing.T) {
+	ctx := context.Background()
+	receiver := &webhookReceiver{statuses: []int{http.StatusNotFound}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, nil, nil, NewNoopAuditService())
+	allowLocalWebhooks(service, server)
+
+	leagueID := primitive.NewObjectID()
+	subscription := &models.WebhookSubscription{ID: primitive.NewObjectID(), LeagueID: leagueID, URL: server.URL, Secret: "key", Active: true}
+	mockSubscriptionRepo.On("FindByID", ctx, subscription.ID).Return(subscription, nil)
+	mockDeliveryRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	delivery, err := service.SendTestEvent(ctx, leagueID, subscription.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.WebhookEventTest, delivery.EventType)
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status, "test events are not retried")
+	if assert.Len(t, delivery.Attempts, 1) {
+		assert.Equal(t, http.StatusNotFound, delivery.Attempts[0].StatusCode)
+	}
+
+	delivery, err = service.SendTestEvent(ctx, leagueID, subscription.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	}
+	assert.Len(t, receiver.requests, 2)
+
+	_, err = service.SendTestEvent(ctx, primitive.NewObjectID(), subscription.ID)
+	assert.EqualError(t, err, "webhook not found")
+}
+
+func TestCreateSubscription_Validation(t *testing.T) {
+	ctx := context.Background()
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	service := NewWebhookService(mockSubscriptionRepo, nil, nil, nil, NewNoopAuditService())
+	service.(*webhookServiceInstance).lookupIP = func(ctx context.Context, network, host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	}
+	leagueID := primitive.NewObjectID()
+
+	_, err := service.CreateSubscription(ctx, leagueID, primitive.NewObjectID(), WebhookInput{
+		URL: "ftp://example.com/hook", Events: []models.WebhookEventType{models.WebhookEventGameFinalized},
+	})
+	assert.Error(t, err)
+
+	_, err = service.CreateSubscription(ctx, leagueID, primitive.NewObjectID(), WebhookInput{
+		URL: "https://example.com/hook", Events: []models.WebhookEventType{models.WebhookEventTest},
+	})
+	assert.Error(t, err, "test events can't be subscribed to")
+
+	mockSubscriptionRepo.On("Create", ctx, mock.Anything).Return(nil).Once()
+	subscription, err := service.CreateSubscription(ctx, leagueID, primitive.NewObjectID(), WebhookInput{
+		URL:    " https://example.com/hook ",
+		Events: []models.WebhookEventType{models.WebhookEventGameFinalized, models.WebhookEventGameFinalized},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://example.com/hook", subscription.URL)
+		assert.Equal(t, []models.WebhookEventType{models.WebhookEventGameFinalized}, subscription.Events)
+		assert.True(t, subscription.Active)
+		assert.NotEmpty(t, subscription.Secret)
+	}
+}
+
+func TestCreateSubscription_RejectsInternalAddresses(t *testing.T) {
+	ctx := context.Background()
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	service := NewWebhookService(mockSubscriptionRepo, nil, nil, nil, NewNoopAuditService())
+	// internal.example.com resolves to a private address, like a DNS name set up by the attacker would
+	service.(*webhookServiceInstance).lookupIP = func(ctx context.Context, network, host string) ([]net.IP, error) {
+		if host == "internal.example.com" {
+			return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("192.168.1.10")}, nil
+		}
+		return net.DefaultResolver.LookupIP(ctx, network, host)
+	}
+
+	for _, webhookURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"https://127.0.0.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://10.0.0.1/hook",
+		"https://0.0.0.0/hook",
+		"https://[::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+		"https://internal.example.com/hook",
+	} {
+		_, err := service.CreateSubscription(ctx, primitive.NewObjectID(), primitive.NewObjectID(), WebhookInput{
+			URL: webhookURL, Events: []models.WebhookEventType{models.WebhookEventGameFinalized},
+		})
+		assert.Error(t, err, webhookURL)
+	}
+	mockSubscriptionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSendTestEvent_RefusesToConnectToInternalAddresses(t *testing.T) {
+	ctx := context.Background()
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	mockSubscriptionRepo := new(mocks.MockWebhookSubscriptionRepository)
+	mockDeliveryRepo := new(mocks.MockWebhookDeliveryRepository)
+	service := NewWebhookService(mockSubscriptionRepo, mockDeliveryRepo, nil, nil, NewNoopAuditService())
+
+	// Stored before the check, or resolved to a public address when saved and to a loopback one when sent
+	leagueID := primitive.NewObjectID()
+	subscription := &models.WebhookSubscription{ID: primitive.NewObjectID(), LeagueID: leagueID, URL: server.URL, Secret: "key", Active: true}
+	mockSubscriptionRepo.On("FindByID", ctx, subscription.ID).Return(subscription, nil)
+	mockDeliveryRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	delivery, err := service.SendTestEvent(ctx, leagueID, subscription.ID)
+	if assert.NoError(t, err) && assert.Len(t, delivery.Attempts, 1) {
+		assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+		assert.Zero(t, delivery.Attempts[0].StatusCode)
+		assert.Contains(t, delivery.Attempts[0].Error, "internal address")
+	}
+	assert.Empty(t, receiver.requests)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, address := range []string{"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.0.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fc00::1", "::"} {
+		assert.False(t, isPublicIP(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"} {
+		assert.True(t, isPublicIP(net.ParseIP(address)), address)
+	}
+}
@@ -0,0 +1,242 @@
+package userapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	"github.com/andriyg76/bgl/utils"
+	log "github.com/andriyg76/glog"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 200
+)
+
+type NotificationResponse struct {
+	Code          string `json:"code"`
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Message       string `json:"message"`
+	LeagueCode    string `json:"league_code,omitempty"`
+	GameRoundCode string `json:"game_round_code,omitempty"`
+	IsRead        bool   `json:"is_read"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count"`
+}
+
+// GetNotificationsHandler returns the latest notifications of the current user, ?unread=true returns only unread ones
+func (h *Handler) GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultNotificationsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxNotificationsLimit)
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := h.notificationService.List(r.Context(), userID, unreadOnly, int64(limit))
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error fetching notifications")
+		return
+	}
+
+	unreadCount, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error counting notifications")
+		return
+	}
+
+	response := NotificationsResponse{
+		Notifications: make([]NotificationResponse, 0, len(notifications)),
+		UnreadCount:   unreadCount,
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, notificationToResponse(notification))
+	}
+
+	utils.WriteJSON(r, w, response, http.StatusOK)
+}
+
+// MarkNotificationReadHandler marks a notification of the current user as read
+func (h *Handler) MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	notificationID, err := utils.GetIDFromChiURL(r, "code")
+	if err != nil {
+		http.Error(w, "Invalid notification code", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), userID, notificationID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusNotFound, err, "error marking notification as read")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// MarkAllNotificationsReadHandler marks all notifications of the current user as read
+func (h *Handler) MarkAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(r.Context(), userID); err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error marking notifications as read")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SubscribeToNotificationsHandler streams unread notifications count changes of the current user over SSE
+func (h *Handler) SubscribeToNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUserID(w, r)
+	if !ok {
+		return
+	}
+	userCode := utils.IdToCode(userID)
+
+	unreadCount, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		utils.LogAndWriteHTTPError(r, w, http.StatusInternalServerError, err, "error counting notifications")
+		return
+	}
+
+	// Set SSE headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	clientID := uuid.New().String()
+	client := h.notificationHub.Subscribe(userCode, clientID)
+	defer h.notificationHub.Unsubscribe(client)
+
+	// The current count first, then every change of it
+	initialEvent := &services.GameEvent{
+		Type:      services.NotificationUnreadCountEvent,
+		GameCode:  userCode,
+		Timestamp: time.Now(),
+		Data:      map[string]int64{"unread_count": unreadCount},
+	}
+	if !sendSSEEvent(w, flusher, initialEvent) {
+		return
+	}
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client.Done:
+			return
+		case event := <-client.Channel:
+			if !sendSSEEvent(w, flusher, event) {
+				return
+			}
+		case <-heartbeat.C:
+			heartbeatEvent := &services.GameEvent{
+				Type:      "heartbeat",
+				GameCode:  userCode,
+				Timestamp: time.Now(),
+			}
+			if !sendSSEEvent(w, flusher, heartbeatEvent) {
+				return
+			}
+		}
+	}
+}
+
+// currentUserID resolves the current user, writes the error response if it can't
+func (h *Handler) currentUserID(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	profile, err := user_profile.GetUserProfile(r)
+	if err != nil || profile == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return primitive.NilObjectID, false
+	}
+
+	userID, err := utils.CodeToID(profile.Code)
+	if err != nil {
+		http.Error(w, "Invalid user code", http.StatusBadRequest)
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
+
+func notificationToResponse(notification *models.Notification) NotificationResponse {
+	response := NotificationResponse{
+		Code:      utils.IdToCode(notification.ID),
+		Type:      string(notification.Type),
+		Title:     notification.Title,
+		Message:   notification.Message,
+		IsRead:    notification.IsRead,
+		CreatedAt: notification.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !notification.LeagueID.IsZero() {
+		response.LeagueCode = utils.IdToCode(notification.LeagueID)
+	}
+	if !notification.GameRoundID.IsZero() {
+		response.GameRoundCode = utils.IdToCode(notification.GameRoundID)
+	}
+	return response
+}
+
+// sendSSEEvent sends an event in SSE format, returns false if the client disconnected
+func sendSSEEvent(w http.ResponseWriter, flusher http.Flusher, event *services.GameEvent) bool {
+	data, err := services.FormatSSEEvent(event)
+	if err != nil {
+		log.Warn("SSE: Failed to format event: %v", err)
+		return false
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		log.Info("SSE: Write failed (client disconnected): %v", err)
+		return false
+	}
+	flusher.Flush()
+	return true
+}
+
+// RegisterNotificationRoutes registers notification routes of the current user
+func (h *Handler) RegisterNotificationRoutes(r chi.Router) {
+	r.Route("/user/notifications", func(r chi.Router) {
+		r.Get("/", h.GetNotificationsHandler)                  // List notifications
+		r.Post("/read-all", h.MarkAllNotificationsReadHandler) // Mark all notifications as read
+		r.Post("/{code}/read", h.MarkNotificationReadHandler)  // Mark notification as read
+		r.Get("/events", h.SubscribeToNotificationsHandler)    // Unread count updates (SSE)
+	})
+}
@@ -232,9 +232,11 @@ func (h *Handler) GetUserSessionsHandler(w http.ResponseWriter, r *http.Request)
 }
 
 type Handler struct {
-	userRepository    repositories.UserRepository
-	sessionRepository repositories.SessionRepository
-	geoIPService      services.GeoIPService
+	userRepository      repositories.UserRepository
+	sessionRepository   repositories.SessionRepository
+	geoIPService        services.GeoIPService
+	notificationService services.NotificationService
+	notificationHub     services.GameEventHub // Notification streams, keyed by user code
 }
 
 func NewHandler(userRepository repositories.UserRepository) *Handler {
@@ -243,10 +245,13 @@ func NewHandler(userRepository repositories.UserRepository) *Handler {
 	}
 }
 
-func NewHandlerWithServices(userRepository repositories.UserRepository, sessionRepository repositories.SessionRepository, geoIPService services.GeoIPService) *Handler {
+func NewHandlerWithServices(userRepository repositories.UserRepository, sessionRepository repositories.SessionRepository, geoIPService services.GeoIPService,
+	notificationService services.NotificationService, notificationHub services.GameEventHub) *Handler {
 	return &Handler{
-		userRepository:    userRepository,
-		sessionRepository: sessionRepository,
-		geoIPService:      geoIPService,
+		userRepository:      userRepository,
+		sessionRepository:   sessionRepository,
+		geoIPService:        geoIPService,
+		notificationService: notificationService,
+		notificationHub:     notificationHub,
 	}
 }
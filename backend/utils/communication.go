@@ -1,11 +1,13 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/andriyg76/glog"
 	"github.com/andriyg76/hexerr"
@@ -36,15 +38,52 @@ func SendToDiscord(content string) error {
 		return nil
 	}
 
-	payload := map[string]string{
-		"content": content,
+	return SendDiscordMessage(context.Background(), discordWebhookURL, DiscordMessage{Content: content})
+}
+
+// DiscordMessage is the body of a Discord webhook request
+type DiscordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+// DiscordEmbed is a rich card of a Discord message
+type DiscordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color,omitempty"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"` // RFC 3339
+}
+
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+var discordClient = &http.Client{Timeout: 10 * time.Second}
+
+// SendDiscordMessage posts the message to the Discord webhook URL
+func SendDiscordMessage(ctx context.Context, webhookURL string, message DiscordMessage) error {
+	payloadBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
 	}
-	payloadBytes, err := json.Marshal(payload)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return err
 	}
+	request.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.Post(discordWebhookURL, "application/json", strings.NewReader(string(payloadBytes)))
+	resp, err := discordClient.Do(request)
 	if err != nil {
 		return err
 	}
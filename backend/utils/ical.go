@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalTimeLayout = "20060102T150405Z"
+	icalLineLimit  = 75 // Octets per line without the line break, longer lines are folded
+)
+
+// ICalEvent statuses
+const (
+	ICalStatusConfirmed = "CONFIRMED"
+	ICalStatusTentative = "TENTATIVE"
+	ICalStatusCancelled = "CANCELLED"
+)
+
+// ICalEvent is a VEVENT of an iCalendar (RFC 5545) feed
+type ICalEvent struct {
+	UID         string // Globally unique and stable, calendar apps match updates by it
+	Summary     string
+	Description string
+	Location    string
+	Status      string // One of ICalStatus*, empty - not set
+	Start       time.Time
+	End         time.Time // zero - no end
+	Sequence    int64     // Revision of the event, has to grow when it changes
+	Updated     time.Time // Last modification, used as DTSTAMP and LAST-MODIFIED, zero - the start is used as DTSTAMP
+}
+
+// ICalendar is a published calendar feed
+type ICalendar struct {
+	Name            string
+	RefreshInterval time.Duration // How often subscribed apps should reload the feed, zero - app default
+	Events          []ICalEvent
+}
+
+// WriteICalendar writes the calendar in the iCalendar format.
+// Times are written in UTC, so the feed doesn't need VTIMEZONE definitions and every app shows them in its local time
+func WriteICalendar(w io.Writer, calendar ICalendar) error {
+	out := &icalWriter{w: bufio.NewWriter(w)}
+
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//Board Games League//Calendar//EN")
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	if calendar.Name != "" {
+		out.line("X-WR-CALNAME:" + escapeICalText(calendar.Name))
+	}
+	if calendar.RefreshInterval > 0 {
+		interval := "PT" + strconv.Itoa(int(calendar.RefreshInterval.Minutes())) + "M"
+		out.line("REFRESH-INTERVAL;VALUE=DURATION:" + interval)
+		out.line("X-PUBLISHED-TTL:" + interval)
+	}
+
+	for _, event := range calendar.Events {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + escapeICalText(event.UID))
+		stamp := event.Updated
+		if stamp.IsZero() {
+			stamp = event.Start
+		}
+		out.line("DTSTAMP:" + formatICalTime(stamp))
+		out.line("DTSTART:" + formatICalTime(event.Start))
+		if !event.End.IsZero() {
+			out.line("DTEND:" + formatICalTime(event.End))
+		}
+		out.line("SUMMARY:" + escapeICalText(event.Summary))
+		if event.Description != "" {
+			out.line("DESCRIPTION:" + escapeICalText(event.Description))
+		}
+		if event.Location != "" {
+			out.line("LOCATION:" + escapeICalText(event.Location))
+		}
+		if event.Status != "" {
+			out.line("STATUS:" + event.Status)
+		}
+		out.line("SEQUENCE:" + strconv.FormatInt(event.Sequence, 10))
+		if !event.Updated.IsZero() {
+			out.line("LAST-MODIFIED:" + formatICalTime(event.Updated))
+		}
+		out.line("END:VEVENT")
+	}
+
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+func formatICalTime(t time.Time) string {
+	return t.UTC().Format(icalTimeLayout)
+}
+
+// escapeICalText escapes a TEXT property value
+func escapeICalText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+type icalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line terminated by CRLF, folding it at 75 octets without splitting UTF-8 characters
+func (o *icalWriter) line(content string) {
+	if o.err != nil {
+		return
+	}
+	limit := icalLineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		o.write(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = icalLineLimit - 1 // The leading space of a continuation line counts
+	}
+	o.write(content + "\r\n")
+}
+
+func (o *icalWriter) write(s string) {
+	if o.err == nil {
+		_, o.err = o.w.WriteString(s)
+	}
+}
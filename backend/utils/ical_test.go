@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	kyiv, err := time.LoadLocation("Europe/Kyiv")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out bytes.Buffer
+	err = WriteICalendar(&out, ICalendar{
+		Name:            "Friday, games",
+		RefreshInterval: time.Hour,
+		Events: []ICalEvent{{
+			UID:         "night-abc@bgl",
+			Summary:     "Game night; Catan",
+			Description: "Going: Alice, Bob\nMaybe: Carol",
+			Location:    `Club \ 2nd floor`,
+			Status:      ICalStatusConfirmed,
+			Start:       time.Date(2026, 3, 27, 19, 0, 0, 0, kyiv),
+			End:         time.Date(2026, 3, 27, 23, 0, 0, 0, kyiv),
+			Sequence:    3,
+			Updated:     time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	feed := out.String()
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(feed, "END:VCALENDAR\r\n"))
+	assert.Contains(t, feed, "X-WR-CALNAME:Friday\\, games\r\n")
+	assert.Contains(t, feed, "REFRESH-INTERVAL;VALUE=DURATION:PT60M\r\n")
+	// Kyiv is UTC+2 in March before the daylight saving switch
+	assert.Contains(t, feed, "DTSTART:20260327T170000Z\r\n")
+	assert.Contains(t, feed, "DTEND:20260327T210000Z\r\n")
+	assert.Contains(t, feed, "SUMMARY:Game night\\; Catan\r\n")
+	assert.Contains(t, feed, "DESCRIPTION:Going: Alice\\, Bob\\nMaybe: Carol\r\n")
+	assert.Contains(t, feed, "LOCATION:Club \\\\ 2nd floor\r\n")
+	assert.Contains(t, feed, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, feed, "SEQUENCE:3\r\n")
+	assert.Contains(t, feed, "DTSTAMP:20260301T100000Z\r\n")
+}
+
+func TestWriteICalendar_FoldsLongLines(t *testing.T) {
+	summary := strings.Repeat("Гра ", 60)
+
+	var out bytes.Buffer
+	err := WriteICalendar(&out, ICalendar{Events: []ICalEvent{{UID: "round@bgl", Summary: summary}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), "folding must not split characters: %q", line)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	assert.Contains(t, unfolded.String(), "\nSUMMARY:"+summary+"\n")
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andriyg76/hexerr"
+)
+
+// TelegramAPIURL is the address of the Telegram Bot API
+const TelegramAPIURL = "https://api.telegram.org"
+
+// TelegramSecretTokenHeader carries the secret token given to setWebhook in every update Telegram sends to the bot webhook
+const TelegramSecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramUpdate is an incoming update of the bot, only messages are handled
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from,omitempty"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text,omitempty"`
+}
+
+type TelegramUser struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+type TelegramChat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"` // private, group, supergroup or channel
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+}
+
+// Name returns the chat title, for private chats the user name
+func (c TelegramChat) Name() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	if c.Username != "" {
+		return "@" + c.Username
+	}
+	return c.FirstName
+}
+
+type telegramSendMessageRequest struct {
+	ChatID                int64  `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description,omitempty"`
+}
+
+// TelegramBot sends messages on behalf of a bot through the Bot API
+type TelegramBot struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewTelegramBot creates a Bot API client, an empty API URL means the Telegram one
+func NewTelegramBot(apiURL, token string) *TelegramBot {
+	if apiURL == "" {
+		apiURL = TelegramAPIURL
+	}
+	return &TelegramBot{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendMessage sends the HTML formatted text to the chat
+func (b *TelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	payloadBytes, err := json.Marshal(telegramSendMessageRequest{
+		ChatID:                chatID,
+		Text:                  text,
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The token is a part of the URL, so errors of the request mustn't be logged as they are
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", b.apiURL, b.token), bytes.NewReader(payloadBytes))
+	if err != nil {
+		return hexerr.New("invalid Telegram Bot API URL")
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(request)
+	if err != nil {
+		return hexerr.New(fmt.Sprintf("failed to send to Telegram: %s", strings.ReplaceAll(err.Error(), b.token, "***")))
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+
+	var response telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || !response.OK {
+		return hexerr.New(fmt.Sprintf("failed to send to Telegram, status code: %d %s", resp.StatusCode, response.Description))
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
 	"github.com/andriyg76/bgl/utils"
 	"github.com/andriyg76/hexerr"
 	"github.com/go-chi/chi/v5"
@@ -218,6 +219,8 @@ func (h *Handler) createGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.logAction(r, services.AuditActionGameCreated, gameRound.ID, services.AuditDetails{"name": gameRound.Name, "wizard_game_code": wizardGame.Code})
+
 	// Build response
 	playerResponses := make([]playerResponse, len(players))
 	for i, player := range players {
@@ -1,19 +1,30 @@
 package wizardapi
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/andriyg76/bgl/repositories"
 	"github.com/andriyg76/bgl/services"
+	"github.com/andriyg76/bgl/user_profile"
+	log "github.com/andriyg76/glog"
 	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Handler struct {
-	wizardRepo      repositories.WizardGameRepository
-	gameRoundRepo   repositories.GameRoundRepository
-	gameTypeRepo    repositories.GameTypeRepository
-	leagueService   services.LeagueService
-	userService     services.UserService
-	idCodeCache     services.IdAndCodeCache
-	eventHub        services.GameEventHub
+	wizardRepo              repositories.WizardGameRepository
+	gameRoundRepo           repositories.GameRoundRepository
+	gameTypeRepo            repositories.GameTypeRepository
+	leagueService           services.LeagueService
+	userService             services.UserService
+	idCodeCache             services.IdAndCodeCache
+	eventHub                services.GameEventHub
+	standingsHistoryService services.StandingsHistoryService
+	auditService            services.AuditService
+	notificationService     services.NotificationService
+	webhookService          services.WebhookService
+	chatNotifier            services.ChatNotifier
 }
 
 // RegisterRoutes registers wizard game routes (deprecated - use RegisterWizardLeagueRoutes instead)
@@ -65,22 +76,61 @@ func (h *Handler) RegisterWizardLeagueRoutes(r chi.Router) {
 	r.Get("/{code}/events", h.subscribeToEvents)
 }
 
-func NewHandler(
-	wizardRepo repositories.WizardGameRepository,
-	gameRoundRepo repositories.GameRoundRepository,
-	gameTypeRepo repositories.GameTypeRepository,
-	leagueService services.LeagueService,
-	userService services.UserService,
-	idCodeCache services.IdAndCodeCache,
-	eventHub services.GameEventHub,
-) *Handler {
+// HandlerDeps lists the repositories and services the wizard API works with
+type HandlerDeps struct {
+	WizardRepo              repositories.WizardGameRepository
+	GameRoundRepo           repositories.GameRoundRepository
+	GameTypeRepo            repositories.GameTypeRepository
+	LeagueService           services.LeagueService
+	UserService             services.UserService
+	IdCodeCache             services.IdAndCodeCache
+	EventHub                services.GameEventHub
+	StandingsHistoryService services.StandingsHistoryService
+	AuditService            services.AuditService // Actions are not recorded when nil
+	NotificationService     services.NotificationService
+	WebhookService          services.WebhookService
+	ChatNotifier            services.ChatNotifier
+}
+
+func NewHandler(deps HandlerDeps) *Handler {
+	auditService := deps.AuditService
+	if auditService == nil {
+		auditService = services.NewNoopAuditService()
+	}
 	return &Handler{
-		wizardRepo:    wizardRepo,
-		gameRoundRepo: gameRoundRepo,
-		gameTypeRepo:  gameTypeRepo,
-		leagueService: leagueService,
-		userService:   userService,
-		idCodeCache:   idCodeCache,
-		eventHub:      eventHub,
+		wizardRepo:              deps.WizardRepo,
+		gameRoundRepo:           deps.GameRoundRepo,
+		gameTypeRepo:            deps.GameTypeRepo,
+		leagueService:           deps.LeagueService,
+		userService:             deps.UserService,
+		idCodeCache:             deps.IdCodeCache,
+		eventHub:                deps.EventHub,
+		standingsHistoryService: deps.StandingsHistoryService,
+		auditService:            auditService,
+		notificationService:     deps.NotificationService,
+		webhookService:          deps.WebhookService,
+		chatNotifier:            deps.ChatNotifier,
+	}
+}
+
+// logAction records an action of the current user in the audit log of the league from the request context in background,
+// actions outside of a league are not recorded
+func (h *Handler) logAction(r *http.Request, action services.AuditAction, targetID primitive.ObjectID, details services.AuditDetails) {
+	leagueID, ok := r.Context().Value("leagueID").(primitive.ObjectID)
+	if !ok || leagueID.IsZero() {
+		return
 	}
+
+	var actorID primitive.ObjectID
+	if profile, err := user_profile.GetUserProfile(r); err == nil && profile != nil {
+		if userIdAndCode, err := h.idCodeCache.GetByCode(profile.Code); err == nil {
+			actorID = userIdAndCode.ID
+		}
+	}
+
+	go func() {
+		if err := h.auditService.LogAction(context.Background(), leagueID, actorID, action, services.AuditTargetGame, targetID, details); err != nil {
+			log.Warn("Failed to record %s for league %s: %v", action, leagueID.Hex(), err)
+		}
+	}()
 }
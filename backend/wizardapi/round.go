@@ -1,6 +1,7 @@
 package wizardapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,7 +9,9 @@ import (
 	"time"
 
 	"github.com/andriyg76/bgl/models"
+	"github.com/andriyg76/bgl/services"
 	"github.com/andriyg76/bgl/utils"
+	log "github.com/andriyg76/glog"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -319,6 +322,8 @@ func (h *Handler) editRound(w http.ResponseWriter, r *http.Request) {
 	// Broadcast update to all connected clients
 	h.broadcastGameUpdate(game, "round_edited")
 
+	h.logAction(r, services.AuditActionGameScoresUpdated, game.GameRoundID, services.AuditDetails{"wizard_game_code": game.Code, "round": roundNumber})
+
 	// Return response
 	response := editRoundResponse{
 		RoundNumber:        roundNumber,
@@ -453,6 +458,39 @@ func (h *Handler) finalizeGame(w http.ResponseWriter, r *http.Request) {
 	// Broadcast update to all connected clients
 	h.broadcastGameUpdate(wizardGame, "game_finalized")
 
+	h.logAction(r, services.AuditActionGameFinalized, gameRound.ID, services.AuditDetails{"name": gameRound.Name, "wizard_game_code": wizardGame.Code})
+
+	if !gameRound.LeagueID.IsZero() {
+		// League standings after the game are recorded before answering, so that snapshots follow the games in order
+		if err := h.standingsHistoryService.RecordSnapshot(r.Context(), gameRound.LeagueID, gameRound.ID); err != nil {
+			log.Warn("Failed to record standings snapshot of league %s after game %s: %v", gameRound.LeagueID.Hex(), gameRound.ID.Hex(), err)
+		}
+
+		if h.notificationService != nil {
+			go func() {
+				if err := h.notificationService.NotifyGameFinalized(context.Background(), gameRound); err != nil {
+					log.Warn("Failed to notify about finalized game %s: %v", gameRound.ID.Hex(), err)
+				}
+			}()
+		}
+
+		if h.webhookService != nil {
+			go func() {
+				if err := h.webhookService.PublishGameFinalized(context.Background(), gameRound); err != nil {
+					log.Warn("Failed to publish finalized game %s to webhooks: %v", gameRound.ID.Hex(), err)
+				}
+			}()
+		}
+
+		if h.chatNotifier != nil {
+			go func() {
+				if err := h.chatNotifier.NotifyGameFinalized(context.Background(), gameRound); err != nil {
+					log.Warn("Failed to post finalized game %s to chats: %v", gameRound.ID.Hex(), err)
+				}
+			}()
+		}
+	}
+
 	// Build final standings
 	type FinalStanding struct {
 		PlayerName string `json:"player_name"`